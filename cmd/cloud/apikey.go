@@ -0,0 +1,147 @@
+package main
+
+import (
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	apiKeyCmd.PersistentFlags().String("database", "sqlite://cloud.db", "The database backing the provisioning server.")
+
+	apiKeyCreateCmd.Flags().String("description", "", "A description of who or what uses the API key.")
+	apiKeyCreateCmd.Flags().StringArray("scope", []string{}, "The scope granted to the API key: read-only, installation-admin or cluster-admin. Use the flag multiple times to grant several scopes.")
+	apiKeyCreateCmd.Flags().String("owner", "", "If set, limits the API key to the installations and webhooks of this owner.")
+	apiKeyCreateCmd.MarkFlagRequired("scope")
+
+	apiKeyListCmd.Flags().String("owner", "", "The owner by which to filter API keys.")
+	apiKeyListCmd.Flags().Int("page", 0, "The page of API keys to fetch, starting at 0.")
+	apiKeyListCmd.Flags().Int("per-page", 100, "The number of API keys to fetch per page.")
+	apiKeyListCmd.Flags().Bool("include-deleted", false, "Whether to include revoked API keys.")
+
+	apiKeyRevokeCmd.Flags().String("apikey", "", "The id of the API key to be revoked.")
+	apiKeyRevokeCmd.MarkFlagRequired("apikey")
+
+	apiKeyCmd.AddCommand(apiKeyCreateCmd)
+	apiKeyCmd.AddCommand(apiKeyListCmd)
+	apiKeyCmd.AddCommand(apiKeyRevokeCmd)
+}
+
+var apiKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Manage the API keys used to authenticate with the provisioning server.",
+}
+
+var apiKeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API key. The key itself is only shown once.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		sqlStore, err := sqlStore(command)
+		if err != nil {
+			return err
+		}
+
+		description, _ := command.Flags().GetString("description")
+		scopes, _ := command.Flags().GetStringArray("scope")
+		ownerID, _ := command.Flags().GetString("owner")
+
+		err = model.ValidateAPIKeyScopes(scopes)
+		if err != nil {
+			return err
+		}
+
+		key, err := model.NewAPIKeySecret()
+		if err != nil {
+			return err
+		}
+
+		apiKey := &model.APIKey{
+			Description: description,
+			KeyHash:     model.HashAPIKey(key),
+			Scopes:      scopes,
+			OwnerID:     ownerID,
+		}
+		err = sqlStore.CreateAPIKey(apiKey)
+		if err != nil {
+			return errors.Wrap(err, "failed to create API key")
+		}
+
+		err = printJSON(struct {
+			*model.APIKey
+			Key string
+		}{apiKey, key})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var apiKeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List created API keys.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		sqlStore, err := sqlStore(command)
+		if err != nil {
+			return err
+		}
+
+		owner, _ := command.Flags().GetString("owner")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		includeDeleted, _ := command.Flags().GetBool("include-deleted")
+		apiKeys, err := sqlStore.GetAPIKeys(&model.APIKeyFilter{
+			OwnerID:        owner,
+			Page:           page,
+			PerPage:        perPage,
+			IncludeDeleted: includeDeleted,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query API keys")
+		}
+		if apiKeys == nil {
+			apiKeys = []*model.APIKey{}
+		}
+
+		err = printJSON(apiKeys)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var apiKeyRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke an API key.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		sqlStore, err := sqlStore(command)
+		if err != nil {
+			return err
+		}
+
+		apiKeyID, _ := command.Flags().GetString("apikey")
+		apiKey, err := sqlStore.GetAPIKey(apiKeyID)
+		if err != nil {
+			return errors.Wrap(err, "failed to query API key")
+		}
+		if apiKey == nil {
+			return errors.Errorf("API key %s not found", apiKeyID)
+		}
+
+		err = sqlStore.DeleteAPIKey(apiKeyID)
+		if err != nil {
+			return errors.Wrap(err, "failed to revoke API key")
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	auditCmd.PersistentFlags().String("server", "http://localhost:8075", "The provisioning server whose API will be queried.")
+
+	auditListCmd.Flags().String("apikey", "", "The ID of the API key by which to filter audit records.")
+	auditListCmd.Flags().String("resource-id", "", "The resource ID by which to filter audit records.")
+	auditListCmd.Flags().Int("page", 0, "The page of audit records to fetch, starting at 0.")
+	auditListCmd.Flags().Int("per-page", 100, "The number of audit records to fetch per page.")
+
+	auditCmd.AddCommand(auditListCmd)
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "View the audit log of API requests that changed provisioner resources.",
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List audited API requests.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		apiKeyID, _ := command.Flags().GetString("apikey")
+		resourceID, _ := command.Flags().GetString("resource-id")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		auditRecords, err := client.GetAuditRecords(&model.GetAuditRecordsRequest{
+			APIKeyID:   apiKeyID,
+			ResourceID: resourceID,
+			Page:       page,
+			PerPage:    perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query audit records")
+		}
+
+		err = printJSON(auditRecords)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os"
+
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// newClient creates a client to the given provisioning server. If the
+// CLOUD_API_KEY environment variable is set, the client authenticates with
+// it.
+func newClient(serverAddress string) *model.Client {
+	apiKey := os.Getenv("CLOUD_API_KEY")
+	if apiKey == "" {
+		return model.NewClient(serverAddress)
+	}
+
+	return model.NewClientWithHeaders(serverAddress, map[string]string{
+		model.APIKeyHeader: apiKey,
+	})
+}
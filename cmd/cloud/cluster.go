@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -24,6 +25,7 @@ func init() {
 	clusterCreateCmd.Flags().String("fluentbit-version", model.FluentbitDefaultVersion, "The version of Fluentbit to provision. Use 'stable' to provision the latest stable version published upstream.")
 	clusterCreateCmd.Flags().String("nginx-version", model.NginxDefaultVersion, "The version of Nginx to provision. Use 'stable' to provision the latest stable version published upstream.")
 	clusterCreateCmd.Flags().String("public-nginx-version", model.PublicNginxDefaultVersion, "The version of Public Nginx to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterCreateCmd.Flags().StringArray("instance-group", []string{}, "Add an additional instance group, or node pool. Accepts format: NAME=MACHINE_TYPE:MIN_COUNT:MAX_COUNT[:MAX_SPOT_PRICE]. Use the flag multiple times to add multiple instance groups.")
 	clusterCreateCmd.Flags().String("cert-manager-version", model.CertManagerDefaultVersion, "The version of Cert Manager to provision. Use 'stable' to provision the latest stable version published upstream.")
 
 	clusterProvisionCmd.Flags().String("cluster", "", "The id of the cluster to be provisioned.")
@@ -43,12 +45,22 @@ func init() {
 	clusterUpgradeCmd.MarkFlagRequired("cluster")
 	clusterUpgradeCmd.MarkFlagRequired("version")
 
+	clusterResizeCmd.Flags().String("cluster", "", "The id of the cluster to be resized.")
+	clusterResizeCmd.Flags().String("size", "", "The size constant describing the cluster. The '-HA2' or '-HA3' suffix must match the cluster's current number of master nodes.")
+	clusterResizeCmd.Flags().StringArray("instance-group", []string{}, "Add or change an additional instance group, or node pool. Accepts format: NAME=MACHINE_TYPE:MIN_COUNT:MAX_COUNT[:MAX_SPOT_PRICE]. Use the flag multiple times to set multiple instance groups.")
+	clusterResizeCmd.MarkFlagRequired("cluster")
+	clusterResizeCmd.MarkFlagRequired("size")
+
 	clusterDeleteCmd.Flags().String("cluster", "", "The id of the cluster to be deleted.")
 	clusterDeleteCmd.MarkFlagRequired("cluster")
 
 	clusterGetCmd.Flags().String("cluster", "", "The id of the cluster to be fetched.")
 	clusterGetCmd.MarkFlagRequired("cluster")
 
+	clusterListCmd.Flags().StringArray("state", []string{}, "The state by which to filter clusters. Use the flag multiple times to match any of several states.")
+	clusterListCmd.Flags().String("size", "", "The size by which to filter clusters.")
+	clusterListCmd.Flags().String("version", "", "The Kubernetes version by which to filter clusters.")
+	clusterListCmd.Flags().Bool("allow-installations", false, "Filter clusters by whether they allow new installations. Unset to include all clusters.")
 	clusterListCmd.Flags().Int("page", 0, "The page of clusters to fetch, starting at 0.")
 	clusterListCmd.Flags().Int("per-page", 100, "The number of clusters to fetch per page.")
 	clusterListCmd.Flags().Bool("include-deleted", false, "Whether to include deleted clusters.")
@@ -60,12 +72,14 @@ func init() {
 	clusterCmd.AddCommand(clusterProvisionCmd)
 	clusterCmd.AddCommand(clusterUpdateCmd)
 	clusterCmd.AddCommand(clusterUpgradeCmd)
+	clusterCmd.AddCommand(clusterResizeCmd)
 	clusterCmd.AddCommand(clusterDeleteCmd)
 	clusterCmd.AddCommand(clusterGetCmd)
 	clusterCmd.AddCommand(clusterListCmd)
 	clusterCmd.AddCommand(clusterInstallationCmd)
 	clusterCmd.AddCommand(clusterShowStateReport)
 	clusterCmd.AddCommand(clusterUtilitiesCmd)
+	clusterCmd.AddCommand(clusterSizesCmd)
 }
 
 var clusterCmd = &cobra.Command{
@@ -86,7 +100,7 @@ var clusterCreateCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		provider, _ := command.Flags().GetString("provider")
 		version, _ := command.Flags().GetString("version")
@@ -94,6 +108,12 @@ var clusterCreateCmd = &cobra.Command{
 		size, _ := command.Flags().GetString("size")
 		zones, _ := command.Flags().GetString("zones")
 		allowInstallations, _ := command.Flags().GetBool("allow-installations")
+		rawInstanceGroups, _ := command.Flags().GetStringArray("instance-group")
+
+		instanceGroups, err := parseInstanceGroupInput(rawInstanceGroups)
+		if err != nil {
+			return err
+		}
 
 		cluster, err := client.CreateCluster(&model.CreateClusterRequest{
 			Provider:               provider,
@@ -103,6 +123,7 @@ var clusterCreateCmd = &cobra.Command{
 			Zones:                  strings.Split(zones, ","),
 			AllowInstallations:     allowInstallations,
 			DesiredUtilityVersions: processUtilityFlags(command),
+			InstanceGroups:         instanceGroups,
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to create cluster")
@@ -124,7 +145,7 @@ var clusterProvisionCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 		clusterID, _ := command.Flags().GetString("cluster")
 
 		var pcr *model.ProvisionClusterRequest = nil
@@ -150,7 +171,7 @@ var clusterUpdateCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		clusterID, _ := command.Flags().GetString("cluster")
 		allowInstallations, _ := command.Flags().GetBool("allow-installations")
@@ -178,7 +199,7 @@ var clusterUpgradeCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		clusterID, _ := command.Flags().GetString("cluster")
 		version, _ := command.Flags().GetString("version")
@@ -192,6 +213,41 @@ var clusterUpgradeCmd = &cobra.Command{
 	},
 }
 
+var clusterResizeCmd = &cobra.Command{
+	Use:   "resize",
+	Short: "Resize the instance groups of a cluster.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		clusterID, _ := command.Flags().GetString("cluster")
+		size, _ := command.Flags().GetString("size")
+		rawInstanceGroups, _ := command.Flags().GetStringArray("instance-group")
+
+		instanceGroups, err := parseInstanceGroupInput(rawInstanceGroups)
+		if err != nil {
+			return err
+		}
+
+		cluster, err := client.ResizeCluster(clusterID, &model.ResizeClusterRequest{
+			Size:           size,
+			InstanceGroups: instanceGroups,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to resize cluster")
+		}
+
+		err = printJSON(cluster)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
 var clusterDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete a cluster.",
@@ -199,7 +255,7 @@ var clusterDeleteCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		clusterID, _ := command.Flags().GetString("cluster")
 
@@ -219,7 +275,7 @@ var clusterGetCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		clusterID, _ := command.Flags().GetString("cluster")
 		cluster, err := client.GetCluster(clusterID)
@@ -246,15 +302,22 @@ var clusterListCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
+		states, _ := command.Flags().GetStringArray("state")
+		size, _ := command.Flags().GetString("size")
+		version, _ := command.Flags().GetString("version")
 		page, _ := command.Flags().GetInt("page")
 		perPage, _ := command.Flags().GetInt("per-page")
 		includeDeleted, _ := command.Flags().GetBool("include-deleted")
 		clusters, err := client.GetClusters(&model.GetClustersRequest{
-			Page:           page,
-			PerPage:        perPage,
-			IncludeDeleted: includeDeleted,
+			States:             states,
+			Size:               size,
+			Version:            version,
+			AllowInstallations: getBoolFlagPointer(command, "allow-installations"),
+			Page:               page,
+			PerPage:            perPage,
+			IncludeDeleted:     includeDeleted,
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to query clusters")
@@ -276,7 +339,7 @@ var clusterUtilitiesCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 		clusterID, err := command.Flags().GetString("cluster")
 		if err != nil {
 			return err
@@ -345,3 +408,39 @@ func processUtilityFlags(command *cobra.Command) map[string]string {
 
 	return utilityVersions
 }
+
+func parseInstanceGroupInput(rawInput []string) ([]model.KopsInstanceGroup, error) {
+	var instanceGroups []model.KopsInstanceGroup
+
+	for _, raw := range rawInput {
+		nameAndSpec := strings.SplitN(raw, "=", 2)
+		if len(nameAndSpec) != 2 {
+			return nil, errors.Errorf("%s is not in a valid instance group format; expecting NAME=MACHINE_TYPE:MIN_COUNT:MAX_COUNT[:MAX_SPOT_PRICE]", raw)
+		}
+		spec := strings.Split(nameAndSpec[1], ":")
+		if len(spec) != 3 && len(spec) != 4 {
+			return nil, errors.Errorf("%s is not in a valid instance group format; expecting NAME=MACHINE_TYPE:MIN_COUNT:MAX_COUNT[:MAX_SPOT_PRICE]", raw)
+		}
+		minCount, err := strconv.ParseInt(spec[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid min count in instance group %s", raw)
+		}
+		maxCount, err := strconv.ParseInt(spec[2], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid max count in instance group %s", raw)
+		}
+
+		instanceGroup := model.KopsInstanceGroup{
+			Name:        nameAndSpec[0],
+			MachineType: spec[0],
+			MinCount:    minCount,
+			MaxCount:    maxCount,
+		}
+		if len(spec) == 4 {
+			instanceGroup.MaxPrice = spec[3]
+		}
+		instanceGroups = append(instanceGroups, instanceGroup)
+	}
+
+	return instanceGroups, nil
+}
@@ -55,7 +55,7 @@ var clusterInstallationGetCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		clusterInstallationID, _ := command.Flags().GetString("cluster-installation")
 		clusterInstallation, err := client.GetClusterInstallation(clusterInstallationID)
@@ -82,7 +82,7 @@ var clusterInstallationListCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		cluster, _ := command.Flags().GetString("cluster")
 		installation, _ := command.Flags().GetString("installation")
@@ -122,7 +122,7 @@ var clusterInstallationConfigGetCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		clusterInstallationID, _ := command.Flags().GetString("cluster-installation")
 		clusterInstallationConfig, err := client.GetClusterInstallationConfig(clusterInstallationID)
@@ -149,7 +149,7 @@ var clusterInstallationConfigSetCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		clusterInstallationID, _ := command.Flags().GetString("cluster-installation")
 		key, _ := command.Flags().GetString("key")
@@ -184,7 +184,7 @@ var clusterInstallationMattermostCLICmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		clusterInstallationID, _ := command.Flags().GetString("cluster-installation")
 		subcommand, _ := command.Flags().GetString("command")
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	clusterSizesCreateCmd.Flags().String("name", "", "The name of the cluster size, without hyphens. Append '-HA2' or '-HA3' to it when creating a cluster for multiple master nodes.")
+	clusterSizesCreateCmd.Flags().Int64("node-count", 0, "The number of worker nodes.")
+	clusterSizesCreateCmd.Flags().String("node-instance-type", "", "The AWS instance type of the worker nodes.")
+	clusterSizesCreateCmd.Flags().Int64("master-count", 1, "The number of master nodes, unless overridden by an HA suffix.")
+	clusterSizesCreateCmd.Flags().String("master-instance-type", "", "The AWS instance type of the master nodes.")
+	clusterSizesCreateCmd.MarkFlagRequired("name")
+	clusterSizesCreateCmd.MarkFlagRequired("node-count")
+	clusterSizesCreateCmd.MarkFlagRequired("node-instance-type")
+	clusterSizesCreateCmd.MarkFlagRequired("master-instance-type")
+
+	clusterSizesDeleteCmd.Flags().String("name", "", "The name of the cluster size to be deleted.")
+	clusterSizesDeleteCmd.MarkFlagRequired("name")
+
+	clusterSizesCmd.AddCommand(clusterSizesListCmd)
+	clusterSizesCmd.AddCommand(clusterSizesCreateCmd)
+	clusterSizesCmd.AddCommand(clusterSizesDeleteCmd)
+}
+
+var clusterSizesCmd = &cobra.Command{
+	Use:   "sizes",
+	Short: "Manipulate custom cluster sizes supported by the provisioning server.",
+}
+
+var clusterSizesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the custom cluster sizes supported in addition to the built-in sizes.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		clusterSizes, err := client.GetClusterSizes()
+		if err != nil {
+			return errors.Wrap(err, "failed to query cluster sizes")
+		}
+
+		err = printJSON(clusterSizes)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var clusterSizesCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a custom cluster size.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		name, _ := command.Flags().GetString("name")
+		nodeCount, _ := command.Flags().GetInt64("node-count")
+		nodeInstanceType, _ := command.Flags().GetString("node-instance-type")
+		masterCount, _ := command.Flags().GetInt64("master-count")
+		masterInstanceType, _ := command.Flags().GetString("master-instance-type")
+
+		clusterSize, err := client.CreateClusterSize(&model.ClusterSize{
+			Name:               name,
+			NodeCount:          nodeCount,
+			NodeInstanceType:   nodeInstanceType,
+			MasterCount:        masterCount,
+			MasterInstanceType: masterInstanceType,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to create cluster size")
+		}
+
+		err = printJSON(clusterSize)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var clusterSizesDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a custom cluster size that no cluster uses.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		name, _ := command.Flags().GetString("name")
+
+		err := client.DeleteClusterSize(name)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete cluster size")
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	eventCmd.PersistentFlags().String("server", "http://localhost:8075", "The provisioning server whose API will be queried.")
+
+	eventListCmd.Flags().String("resource-type", "", "The resource type by which to filter events.")
+	eventListCmd.Flags().String("resource-id", "", "The resource ID by which to filter events.")
+	eventListCmd.Flags().Int("page", 0, "The page of events to fetch, starting at 0.")
+	eventListCmd.Flags().Int("per-page", 100, "The number of events to fetch per page.")
+
+	eventCmd.AddCommand(eventListCmd)
+}
+
+var eventCmd = &cobra.Command{
+	Use:     "event",
+	Aliases: []string{"events"},
+	Short:   "View state change events recorded by the provisioning server.",
+}
+
+var eventListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded state change events.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		resourceType, _ := command.Flags().GetString("resource-type")
+		resourceID, _ := command.Flags().GetString("resource-id")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		events, err := client.GetEvents(&model.GetEventsRequest{
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			Page:         page,
+			PerPage:      perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query events")
+		}
+
+		err = printJSON(events)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -19,3 +19,21 @@ func getInt64FlagPointer(command *cobra.Command, s string) *int64 {
 
 	return nil
 }
+
+func getBoolFlagPointer(command *cobra.Command, s string) *bool {
+	if command.Flags().Changed(s) {
+		val, _ := command.Flags().GetBool(s)
+		return &val
+	}
+
+	return nil
+}
+
+func getStringArrayFlag(command *cobra.Command, s string) []string {
+	if command.Flags().Changed(s) {
+		val, _ := command.Flags().GetStringArray(s)
+		return val
+	}
+
+	return nil
+}
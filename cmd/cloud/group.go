@@ -32,6 +32,9 @@ func init() {
 	groupGetCmd.Flags().String("group", "", "The id of the group to be fetched.")
 	groupGetCmd.MarkFlagRequired("group")
 
+	groupListCmd.Flags().String("name", "", "A partial name by which to filter groups.")
+	groupListCmd.Flags().String("version", "", "The Mattermost version by which to filter groups.")
+	groupListCmd.Flags().String("image", "", "The Mattermost container image by which to filter groups.")
 	groupListCmd.Flags().Int("page", 0, "The page of groups to fetch, starting at 0.")
 	groupListCmd.Flags().Int("per-page", 100, "The number of groups to fetch per page.")
 	groupListCmd.Flags().Bool("include-deleted", false, "Whether to include deleted groups.")
@@ -66,7 +69,7 @@ var groupCreateCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		name, _ := command.Flags().GetString("name")
 		image, _ := command.Flags().GetString("image")
@@ -104,7 +107,7 @@ var groupUpdateCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		groupID, _ := command.Flags().GetString("group")
 		mattermostEnv, _ := command.Flags().GetStringArray("mattermost-env")
@@ -138,7 +141,7 @@ var groupDeleteCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		groupID, _ := command.Flags().GetString("group")
 
@@ -158,7 +161,7 @@ var groupGetCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		groupID, _ := command.Flags().GetString("group")
 		group, err := client.GetGroup(groupID)
@@ -185,12 +188,18 @@ var groupListCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
+		name, _ := command.Flags().GetString("name")
+		version, _ := command.Flags().GetString("version")
+		image, _ := command.Flags().GetString("image")
 		page, _ := command.Flags().GetInt("page")
 		perPage, _ := command.Flags().GetInt("per-page")
 		includeDeleted, _ := command.Flags().GetBool("include-deleted")
 		groups, err := client.GetGroups(&model.GetGroupsRequest{
+			Name:           name,
+			Version:        version,
+			Image:          image,
 			Page:           page,
 			PerPage:        perPage,
 			IncludeDeleted: includeDeleted,
@@ -215,7 +224,7 @@ var groupJoinCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		groupID, _ := command.Flags().GetString("group")
 		installationID, _ := command.Flags().GetString("installation")
@@ -237,7 +246,7 @@ var groupLeaveCmd = &cobra.Command{
 
 		serverAddress, _ := command.Flags().GetString("server")
 		retainConfig, _ := command.Flags().GetBool("retain-config")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		installationID, _ := command.Flags().GetString("installation")
 		request := &model.LeaveGroupRequest{RetainConfig: retainConfig}
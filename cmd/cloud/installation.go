@@ -16,11 +16,15 @@ func init() {
 	installationCreateCmd.Flags().String("version", "stable", "The Mattermost version to install.")
 	installationCreateCmd.Flags().String("image", "mattermost/mattermost-enterprise-edition", "The Mattermost container image to use.")
 	installationCreateCmd.Flags().String("dns", "", "The URL at which the Mattermost server will be available.")
+	installationCreateCmd.Flags().StringArray("dns-alias", []string{}, "An additional URL at which the Mattermost server will be available. Use the flag multiple times to set multiple aliases.")
 	installationCreateCmd.Flags().String("size", model.InstallationDefaultSize, "The size of the installation. Accepts 100users, 1000users, 5000users, 10000users, 25000users, miniSingleton, or miniHA. Defaults to 100users.")
 	installationCreateCmd.Flags().String("affinity", model.InstallationAffinityIsolated, "How other installations may be co-located in the same cluster.")
 	installationCreateCmd.Flags().String("license", "", "The Mattermost License to use in the server.")
 	installationCreateCmd.Flags().String("database", model.InstallationDatabaseMysqlOperator, "The Mattermost server database type. Accepts mysql-operator or aws-rds")
 	installationCreateCmd.Flags().String("filestore", model.InstallationFilestoreMinioOperator, "The Mattermost server filestore type. Accepts minio-operator or aws-s3")
+	installationCreateCmd.Flags().String("node-pool", "", "The additional instance group of the cluster to run the installation on. Leave empty to run on any node of the cluster.")
+	installationCreateCmd.Flags().String("zone", "", "The availability zone the cluster of the installation must be in. Leave empty to allow any zone.")
+	installationCreateCmd.Flags().String("scheduling-strategy", "", "How to choose between the clusters the installation fits on: first-fit, bin-pack or spread. Leave empty to use the server default.")
 	installationCreateCmd.Flags().StringArray("mattermost-env", []string{}, "Env vars to add to the Mattermost App. Accepts format: KEY_NAME=VALUE. Use the flag multiple times to set multiple env vars.")
 	installationCreateCmd.MarkFlagRequired("owner")
 	installationCreateCmd.MarkFlagRequired("dns")
@@ -29,12 +33,33 @@ func init() {
 	installationUpdateCmd.Flags().String("version", "stable", "The Mattermost version to target.")
 	installationUpdateCmd.Flags().String("image", "mattermost/mattermost-enterprise-edition", "The Mattermost container image to use.")
 	installationUpdateCmd.Flags().String("license", "", "The Mattermost License to use in the server.")
+	installationUpdateCmd.Flags().String("size", model.InstallationDefaultSize, "The size of the installation. Accepts 100users, 1000users, 5000users, 10000users, 25000users, miniSingleton, or miniHA.")
+	installationUpdateCmd.Flags().String("dns", "", "The URL at which the Mattermost server will be available.")
+	installationUpdateCmd.Flags().StringArray("dns-alias", []string{}, "An additional URL at which the Mattermost server will be available. Replaces all existing aliases. Use the flag multiple times to set multiple aliases.")
+	installationUpdateCmd.Flags().Bool("clear-dns-aliases", false, "Remove all DNS aliases from the installation.")
 	installationUpdateCmd.Flags().StringArray("mattermost-env", []string{}, "Env vars to add to the Mattermost App. Accepts format: KEY_NAME=VALUE. Use the flag multiple times to set multiple env vars.")
 	installationUpdateCmd.MarkFlagRequired("installation")
 
 	installationDeleteCmd.Flags().String("installation", "", "The id of the installation to be deleted.")
 	installationDeleteCmd.MarkFlagRequired("installation")
 
+	installationHibernateCmd.Flags().String("installation", "", "The id of the installation to put into hibernation.")
+	installationHibernateCmd.MarkFlagRequired("installation")
+
+	installationWakeupCmd.Flags().String("installation", "", "The id of the installation to wake up from hibernation.")
+	installationWakeupCmd.MarkFlagRequired("installation")
+
+	installationMigrateCmd.Flags().String("installation", "", "The id of the installation to be migrated.")
+	installationMigrateCmd.Flags().String("cluster", "", "The id of the cluster to migrate the installation to.")
+	installationMigrateCmd.MarkFlagRequired("installation")
+	installationMigrateCmd.MarkFlagRequired("cluster")
+
+	installationScheduleCmd.Flags().String("size", model.InstallationDefaultSize, "The size of the installation. Accepts 100users, 1000users, 5000users, 10000users, 25000users, miniSingleton, or miniHA.")
+	installationScheduleCmd.Flags().String("affinity", model.InstallationAffinityIsolated, "How other installations may be co-located in the same cluster.")
+	installationScheduleCmd.Flags().String("node-pool", "", "The additional instance group of the cluster to run the installation on.")
+	installationScheduleCmd.Flags().String("zone", "", "The availability zone the cluster of the installation must be in.")
+	installationScheduleCmd.Flags().String("scheduling-strategy", "", "How to choose between the clusters the installation fits on: first-fit, bin-pack or spread. Leave empty to use the server default.")
+
 	installationGetCmd.Flags().String("installation", "", "The id of the installation to be fetched.")
 	installationGetCmd.Flags().Bool("include-group-config", true, "Whether to include group configuration in the installation or not.")
 	installationGetCmd.Flags().Bool("include-group-config-overrides", true, "Whether to include a group configuration override summary in the installation or not.")
@@ -42,6 +67,13 @@ func init() {
 
 	installationListCmd.Flags().String("owner", "", "The owner by which to filter installations.")
 	installationListCmd.Flags().String("group", "", "The group ID by which to filter installations.")
+	installationListCmd.Flags().StringArray("state", []string{}, "The state by which to filter installations. Use the flag multiple times to match any of several states.")
+	installationListCmd.Flags().String("dns", "", "A partial DNS name by which to filter installations.")
+	installationListCmd.Flags().String("version", "", "The Mattermost version by which to filter installations.")
+	installationListCmd.Flags().String("database", "", "The database type by which to filter installations.")
+	installationListCmd.Flags().String("filestore", "", "The filestore type by which to filter installations.")
+	installationListCmd.Flags().String("affinity", "", "The affinity by which to filter installations.")
+	installationListCmd.Flags().String("size", "", "The size by which to filter installations.")
 	installationListCmd.Flags().Bool("include-group-config", true, "Whether to include group configuration in the installations or not.")
 	installationListCmd.Flags().Bool("include-group-config-overrides", true, "Whether to include a group configuration override summary in the installations or not.")
 	installationListCmd.Flags().Int("page", 0, "The page of installations to fetch, starting at 0.")
@@ -51,6 +83,11 @@ func init() {
 	installationCmd.AddCommand(installationCreateCmd)
 	installationCmd.AddCommand(installationUpdateCmd)
 	installationCmd.AddCommand(installationDeleteCmd)
+	installationCmd.AddCommand(installationHibernateCmd)
+	installationCmd.AddCommand(installationWakeupCmd)
+	installationCmd.AddCommand(installationMigrateCmd)
+	installationCmd.AddCommand(installationScheduleCmd)
+	installationCmd.AddCommand(installationBackupCmd)
 	installationCmd.AddCommand(installationGetCmd)
 	installationCmd.AddCommand(installationListCmd)
 	installationCmd.AddCommand(installationShowStateReport)
@@ -68,7 +105,7 @@ var installationCreateCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		ownerID, _ := command.Flags().GetString("owner")
 		groupID, _ := command.Flags().GetString("group")
@@ -76,10 +113,14 @@ var installationCreateCmd = &cobra.Command{
 		image, _ := command.Flags().GetString("image")
 		size, _ := command.Flags().GetString("size")
 		dns, _ := command.Flags().GetString("dns")
+		dnsAliases, _ := command.Flags().GetStringArray("dns-alias")
 		affinity, _ := command.Flags().GetString("affinity")
 		license, _ := command.Flags().GetString("license")
 		database, _ := command.Flags().GetString("database")
 		filestore, _ := command.Flags().GetString("filestore")
+		nodePool, _ := command.Flags().GetString("node-pool")
+		zone, _ := command.Flags().GetString("zone")
+		schedulingStrategy, _ := command.Flags().GetString("scheduling-strategy")
 		mattermostEnv, _ := command.Flags().GetStringArray("mattermost-env")
 
 		envVarMap, err := parseEnvVarInput(mattermostEnv)
@@ -88,17 +129,21 @@ var installationCreateCmd = &cobra.Command{
 		}
 
 		installation, err := client.CreateInstallation(&model.CreateInstallationRequest{
-			OwnerID:       ownerID,
-			GroupID:       groupID,
-			Version:       version,
-			Image:         image,
-			Size:          size,
-			DNS:           dns,
-			License:       license,
-			Affinity:      affinity,
-			Database:      database,
-			Filestore:     filestore,
-			MattermostEnv: envVarMap,
+			OwnerID:            ownerID,
+			GroupID:            groupID,
+			Version:            version,
+			Image:              image,
+			Size:               size,
+			DNS:                dns,
+			DNSAliases:         dnsAliases,
+			License:            license,
+			Affinity:           affinity,
+			Database:           database,
+			Filestore:          filestore,
+			NodePool:           nodePool,
+			Zone:               zone,
+			SchedulingStrategy: schedulingStrategy,
+			MattermostEnv:      envVarMap,
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to create installation")
@@ -115,22 +160,34 @@ var installationUpdateCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		installationID, _ := command.Flags().GetString("installation")
 		mattermostEnv, _ := command.Flags().GetStringArray("mattermost-env")
+		clearDNSAliases, _ := command.Flags().GetBool("clear-dns-aliases")
 
 		envVarMap, err := parseEnvVarInput(mattermostEnv)
 		if err != nil {
 			return err
 		}
 
+		dnsAliases := getStringArrayFlag(command, "dns-alias")
+		if clearDNSAliases {
+			if dnsAliases != nil {
+				return errors.New("dns-alias and clear-dns-aliases cannot be used together")
+			}
+			dnsAliases = []string{}
+		}
+
 		installation, err := client.UpdateInstallation(
 			installationID,
 			&model.PatchInstallationRequest{
 				Version:       getStringFlagPointer(command, "version"),
 				Image:         getStringFlagPointer(command, "image"),
 				License:       getStringFlagPointer(command, "license"),
+				Size:          getStringFlagPointer(command, "size"),
+				DNS:           getStringFlagPointer(command, "dns"),
+				DNSAliases:    dnsAliases,
 				MattermostEnv: envVarMap,
 			},
 		)
@@ -149,7 +206,7 @@ var installationDeleteCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		installationID, _ := command.Flags().GetString("installation")
 
@@ -162,6 +219,99 @@ var installationDeleteCmd = &cobra.Command{
 	},
 }
 
+var installationHibernateCmd = &cobra.Command{
+	Use:   "hibernate",
+	Short: "Put an installation into hibernation.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+
+		installation, err := client.HibernateInstallation(installationID)
+		if err != nil {
+			return errors.Wrap(err, "failed to hibernate installation")
+		}
+
+		return printJSON(installation)
+	},
+}
+
+var installationWakeupCmd = &cobra.Command{
+	Use:   "wakeup",
+	Short: "Wake up an installation from hibernation.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+
+		installation, err := client.WakeupInstallation(installationID)
+		if err != nil {
+			return errors.Wrap(err, "failed to wake up installation")
+		}
+
+		return printJSON(installation)
+	},
+}
+
+var installationMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move an installation to another cluster.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+		clusterID, _ := command.Flags().GetString("cluster")
+
+		installation, err := client.MigrateInstallation(installationID, &model.MigrateInstallationRequest{
+			ClusterID: clusterID,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to migrate installation")
+		}
+
+		return printJSON(installation)
+	},
+}
+
+var installationScheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Show which cluster an installation would be scheduled onto, without creating it.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		size, _ := command.Flags().GetString("size")
+		affinity, _ := command.Flags().GetString("affinity")
+		nodePool, _ := command.Flags().GetString("node-pool")
+		zone, _ := command.Flags().GetString("zone")
+		schedulingStrategy, _ := command.Flags().GetString("scheduling-strategy")
+
+		decision, err := client.ScheduleInstallation(&model.ScheduleInstallationRequest{
+			Size:               size,
+			Affinity:           affinity,
+			NodePool:           nodePool,
+			Zone:               zone,
+			SchedulingStrategy: schedulingStrategy,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to schedule installation")
+		}
+
+		return printJSON(decision)
+	},
+}
+
 var installationGetCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Get a particular installation.",
@@ -169,7 +319,7 @@ var installationGetCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		installationID, _ := command.Flags().GetString("installation")
 		includeGroupConfig, _ := command.Flags().GetBool("include-group-config")
@@ -202,10 +352,17 @@ var installationListCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		owner, _ := command.Flags().GetString("owner")
 		group, _ := command.Flags().GetString("group")
+		states, _ := command.Flags().GetStringArray("state")
+		dns, _ := command.Flags().GetString("dns")
+		version, _ := command.Flags().GetString("version")
+		database, _ := command.Flags().GetString("database")
+		filestore, _ := command.Flags().GetString("filestore")
+		affinity, _ := command.Flags().GetString("affinity")
+		size, _ := command.Flags().GetString("size")
 		includeGroupConfig, _ := command.Flags().GetBool("include-group-config")
 		includeGroupConfigOverrides, _ := command.Flags().GetBool("include-group-config-overrides")
 		page, _ := command.Flags().GetInt("page")
@@ -214,6 +371,13 @@ var installationListCmd = &cobra.Command{
 		installations, err := client.GetInstallations(&model.GetInstallationsRequest{
 			OwnerID:                     owner,
 			GroupID:                     group,
+			States:                      states,
+			DNS:                         dns,
+			Version:                     version,
+			Database:                    database,
+			Filestore:                   filestore,
+			Affinity:                    affinity,
+			Size:                        size,
 			IncludeGroupConfig:          includeGroupConfig,
 			IncludeGroupConfigOverrides: includeGroupConfigOverrides,
 			Page:                        page,
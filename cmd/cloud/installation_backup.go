@@ -0,0 +1,139 @@
+package main
+
+import (
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	installationBackupCreateCmd.Flags().String("installation", "", "The id of the installation to be backed up.")
+	installationBackupCreateCmd.MarkFlagRequired("installation")
+
+	installationBackupGetCmd.Flags().String("installation", "", "The id of the installation that was backed up.")
+	installationBackupGetCmd.Flags().String("backup", "", "The id of the installation backup to be fetched.")
+	installationBackupGetCmd.MarkFlagRequired("installation")
+	installationBackupGetCmd.MarkFlagRequired("backup")
+
+	installationBackupListCmd.Flags().String("installation", "", "The id of the installation whose backups will be listed.")
+	installationBackupListCmd.Flags().Int("page", 0, "The page of installation backups to fetch, starting at 0.")
+	installationBackupListCmd.Flags().Int("per-page", 100, "The number of installation backups to fetch per page.")
+	installationBackupListCmd.Flags().Bool("include-deleted", false, "Whether to include deleted installation backups.")
+	installationBackupListCmd.MarkFlagRequired("installation")
+
+	installationBackupRestoreCmd.Flags().String("installation", "", "The id of the installation that was backed up.")
+	installationBackupRestoreCmd.Flags().String("backup", "", "The id of the installation backup to restore.")
+	installationBackupRestoreCmd.Flags().String("owner", "", "An opaque identifier describing the owner of the restored installation.")
+	installationBackupRestoreCmd.Flags().String("dns", "", "The URL at which the restored Mattermost server will be available.")
+	installationBackupRestoreCmd.MarkFlagRequired("installation")
+	installationBackupRestoreCmd.MarkFlagRequired("backup")
+	installationBackupRestoreCmd.MarkFlagRequired("owner")
+	installationBackupRestoreCmd.MarkFlagRequired("dns")
+
+	installationBackupCmd.AddCommand(installationBackupCreateCmd)
+	installationBackupCmd.AddCommand(installationBackupGetCmd)
+	installationBackupCmd.AddCommand(installationBackupListCmd)
+	installationBackupCmd.AddCommand(installationBackupRestoreCmd)
+}
+
+var installationBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Manipulate installation backups managed by the provisioning server.",
+}
+
+var installationBackupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Request a backup of an installation's database and filestore.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+
+		backup, err := client.CreateInstallationBackup(installationID)
+		if err != nil {
+			return errors.Wrap(err, "failed to request installation backup")
+		}
+
+		return printJSON(backup)
+	},
+}
+
+var installationBackupGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get a particular installation backup.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+		backupID, _ := command.Flags().GetString("backup")
+
+		backup, err := client.GetInstallationBackup(installationID, backupID)
+		if err != nil {
+			return errors.Wrap(err, "failed to query installation backup")
+		}
+		if backup == nil {
+			return nil
+		}
+
+		return printJSON(backup)
+	},
+}
+
+var installationBackupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List backups of an installation.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		includeDeleted, _ := command.Flags().GetBool("include-deleted")
+
+		backups, err := client.GetInstallationBackups(installationID, &model.GetInstallationBackupsRequest{
+			Page:           page,
+			PerPage:        perPage,
+			IncludeDeleted: includeDeleted,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query installation backups")
+		}
+
+		return printJSON(backups)
+	},
+}
+
+var installationBackupRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Create a new installation from an installation backup.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+		backupID, _ := command.Flags().GetString("backup")
+		ownerID, _ := command.Flags().GetString("owner")
+		dns, _ := command.Flags().GetString("dns")
+
+		installation, err := client.RestoreInstallationBackup(installationID, backupID, &model.RestoreInstallationBackupRequest{
+			OwnerID: ownerID,
+			DNS:     dns,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to restore installation backup")
+		}
+
+		return printJSON(installation)
+	},
+}
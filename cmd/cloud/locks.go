@@ -0,0 +1,76 @@
+package main
+
+import (
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	locksCmd.PersistentFlags().String("server", "http://localhost:8075", "The provisioning server whose API will be queried.")
+
+	locksListCmd.Flags().String("resource-type", "", "The resource type by which to filter locks.")
+	locksListCmd.Flags().String("locker", "", "The locker ID, such as a server instance ID, by which to filter locks.")
+
+	locksReleaseCmd.Flags().String("resource-type", "", "The type of the locked resource.")
+	locksReleaseCmd.Flags().String("resource", "", "The ID of the locked resource.")
+	locksReleaseCmd.MarkFlagRequired("resource-type")
+	locksReleaseCmd.MarkFlagRequired("resource")
+
+	locksCmd.AddCommand(locksListCmd)
+	locksCmd.AddCommand(locksReleaseCmd)
+}
+
+var locksCmd = &cobra.Command{
+	Use:     "locks",
+	Aliases: []string{"lock"},
+	Short:   "Inspect and release locks held on provisioner resources.",
+}
+
+var locksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the locks currently held on resources.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		resourceType, _ := command.Flags().GetString("resource-type")
+		locker, _ := command.Flags().GetString("locker")
+		locks, err := client.GetLocks(&model.GetLocksRequest{
+			ResourceType:   resourceType,
+			LockAcquiredBy: locker,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query locks")
+		}
+
+		err = printJSON(locks)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var locksReleaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Forcibly release the lock held on a resource.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		resourceType, _ := command.Flags().GetString("resource-type")
+		resourceID, _ := command.Flags().GetString("resource")
+		err := client.ReleaseLock(resourceType, resourceID)
+		if err != nil {
+			return errors.Wrap(err, "failed to release lock")
+		}
+
+		return nil
+	},
+}
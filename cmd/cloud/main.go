@@ -26,6 +26,10 @@ func init() {
 	rootCmd.AddCommand(groupCmd)
 	rootCmd.AddCommand(schemaCmd)
 	rootCmd.AddCommand(webhookCmd)
+	rootCmd.AddCommand(eventCmd)
+	rootCmd.AddCommand(apiKeyCmd)
+	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(locksCmd)
 	rootCmd.AddCommand(completionCmd)
 }
 
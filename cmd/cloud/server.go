@@ -9,15 +9,18 @@ import (
 	"os/exec"
 	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	sdkAWS "github.com/aws/aws-sdk-go/aws"
 	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/health"
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
 	"github.com/mattermost/mattermost-cloud/internal/provisioner"
-	"github.com/mattermost/mattermost-cloud/internal/store"
 	"github.com/mattermost/mattermost-cloud/internal/supervisor"
 	toolsAWS "github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/internal/tools/exechelper"
 	"github.com/mattermost/mattermost-cloud/internal/tools/utils"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
@@ -39,9 +42,37 @@ func init() {
 	serverCmd.PersistentFlags().Bool("group-supervisor", false, "Whether this server will run an installation group supervisor or not.")
 	serverCmd.PersistentFlags().Bool("installation-supervisor", true, "Whether this server will run an installation supervisor or not.")
 	serverCmd.PersistentFlags().Bool("cluster-installation-supervisor", true, "Whether this server will run a cluster installation supervisor or not.")
+	serverCmd.PersistentFlags().Bool("installation-backup-supervisor", true, "Whether this server will run an installation backup supervisor or not.")
+	serverCmd.PersistentFlags().Bool("webhook-delivery-supervisor", true, "Whether this server will run a webhook delivery supervisor or not.")
+	serverCmd.PersistentFlags().Int("cluster-supervisor-workers", 1, "The number of clusters the cluster supervisor works on concurrently.")
+	serverCmd.PersistentFlags().Int("group-supervisor-workers", 1, "The number of groups the installation group supervisor works on concurrently.")
+	serverCmd.PersistentFlags().Int("installation-supervisor-workers", 1, "The number of installations the installation supervisor works on concurrently.")
+	serverCmd.PersistentFlags().Int("cluster-installation-supervisor-workers", 1, "The number of cluster installations the cluster installation supervisor works on concurrently.")
+	serverCmd.PersistentFlags().Int("installation-backup-supervisor-workers", 1, "The number of installation backups the installation backup supervisor works on concurrently.")
+	serverCmd.PersistentFlags().Int("webhook-delivery-supervisor-workers", 1, "The number of webhook deliveries the webhook delivery supervisor works on concurrently.")
+	serverCmd.PersistentFlags().Int("webhook-delivery-retention", 168, "The age in hours after which delivered webhook deliveries are deleted, or 0 to keep them.")
+	serverCmd.PersistentFlags().Int("max-concurrent-commands", 8, "The maximum number of external commands such as kops, terraform and helm to run concurrently, or 0 for no limit.")
+	serverCmd.PersistentFlags().Bool("lock-reaper", true, "Whether this server will release stale locks left behind by stopped servers or not.")
+	serverCmd.PersistentFlags().Int("lock-ttl", 600, "The age in seconds after which a lock held by a stopped server is considered stale.")
+	serverCmd.PersistentFlags().Bool("capacity-manager", false, "Whether this server will add capacity for installations waiting for a compatible cluster and remove the empty clusters it added or not. Only the elected leader manages capacity.")
+	serverCmd.PersistentFlags().String("capacity-strategy", supervisor.CapacityStrategyGrow, "How to add capacity: 'grow' an existing cluster when possible, or always 'create' a new cluster.")
+	serverCmd.PersistentFlags().String("capacity-size-ladder", "SizeAlef500,SizeAlef1000,SizeAlef5000,SizeAlef10000", "The cluster sizes, from smallest to largest, through which clusters grow one step at a time. Use commas to separate multiple sizes.")
+	serverCmd.PersistentFlags().Int64("capacity-node-pool-step", 1, "The number of nodes added to a node pool when growing it.")
+	serverCmd.PersistentFlags().Int64("capacity-node-pool-max", 10, "The number of nodes beyond which a node pool does not grow.")
+	serverCmd.PersistentFlags().String("capacity-cluster-size", model.SizeAlef500, "The size of clusters created to add capacity.")
+	serverCmd.PersistentFlags().String("capacity-cluster-zones", "us-east-1a", "The zones of clusters created to add capacity. Use commas to separate multiple zones.")
+	serverCmd.PersistentFlags().Int("capacity-max-clusters", 10, "The number of clusters beyond which no cluster is created to add capacity.")
+	serverCmd.PersistentFlags().Int("capacity-min-clusters", 1, "The number of clusters below which empty clusters are kept.")
+	serverCmd.PersistentFlags().Int("capacity-scale-down-grace", 3600, "The time in seconds a cluster added by the capacity manager must stay empty before it is deleted, or 0 to keep empty clusters.")
+	serverCmd.PersistentFlags().Int("capacity-reservation-timeout", 600, "The time in seconds a cluster added by the capacity manager stays reserved for the installation it was added for once stable. No more capacity is added for an installation still waiting after that.")
+	serverCmd.PersistentFlags().Bool("partition-work", false, "Whether to partition supervisor work by resource between running servers rather than having every server compete for every resource.")
 	serverCmd.PersistentFlags().String("state-store", "dev.cloud.mattermost.com", "The S3 bucket used to store cluster state.")
+	serverCmd.PersistentFlags().Bool("require-api-key", false, "Whether API requests must be authenticated with an API key even before any API key is created. Once an API key is active, API requests must always be authenticated.")
 	serverCmd.PersistentFlags().Int("poll", 30, "The interval in seconds to poll for background work.")
+	serverCmd.PersistentFlags().Int("shutdown-timeout", 300, "The time in seconds to wait for in-progress background work to finish when shutting down.")
+	serverCmd.PersistentFlags().String("cluster-sizes-file", "", "The path to a JSON file defining custom cluster sizes supported in addition to the built-in sizes.")
 	serverCmd.PersistentFlags().Int("cluster-resource-threshold", 80, "The percent threshold where new installations won't be scheduled on a multi-tenant cluster.")
+	serverCmd.PersistentFlags().String("scheduling-strategy", model.SchedulingStrategyFirstFit, "How to choose between the clusters an installation fits on, unless the installation specifies its own: 'first-fit', 'bin-pack' or 'spread'.")
 	serverCmd.PersistentFlags().Bool("use-existing-aws-resources", true, "Whether to use existing AWS resources (VPCs, subnets, etc.) or not.")
 	serverCmd.PersistentFlags().Bool("keep-database-data", true, "Whether to preserve database data after installation deletion or not.")
 	serverCmd.PersistentFlags().Bool("keep-filestore-data", true, "Whether to preserve filestore data after installation deletion or not.")
@@ -72,28 +103,72 @@ var serverCmd = &cobra.Command{
 			return err
 		}
 
-		currentVersion, err := sqlStore.GetCurrentVersion()
+		currentVersion, err := sqlStore.CheckSchemaVersion()
 		if err != nil {
 			return err
 		}
-		serverVersion := store.LatestVersion()
-
-		// Require the schema to be at least the server version, and also the same major
-		// version.
-		if currentVersion.LT(serverVersion) || currentVersion.Major != serverVersion.Major {
-			return errors.Errorf("server requires at least schema %s, current is %s", serverVersion, currentVersion)
-		}
 
 		clusterResourceThreshold, _ := command.Flags().GetInt("cluster-resource-threshold")
 		if clusterResourceThreshold < 10 || clusterResourceThreshold > 100 {
 			return fmt.Errorf("cluster-resource-threshold (%d) must be set between 10 and 100", clusterResourceThreshold)
 		}
 
+		schedulingStrategy, _ := command.Flags().GetString("scheduling-strategy")
+		if !model.IsSupportedSchedulingStrategy(schedulingStrategy) {
+			return errors.Errorf("unsupported scheduling-strategy %s", schedulingStrategy)
+		}
+
+		var configClusterSizes []*model.ClusterSize
+		clusterSizesFile, _ := command.Flags().GetString("cluster-sizes-file")
+		if clusterSizesFile != "" {
+			configClusterSizes, err = readClusterSizesFile(clusterSizesFile)
+			if err != nil {
+				return err
+			}
+		}
+
+		lockReaper, _ := command.Flags().GetBool("lock-reaper")
+		lockTTL, _ := command.Flags().GetInt("lock-ttl")
+		if lockTTL <= 0 {
+			return errors.Errorf("lock-ttl (%d) must be positive", lockTTL)
+		}
+
+		capacityManager, _ := command.Flags().GetBool("capacity-manager")
+		partitionWork, _ := command.Flags().GetBool("partition-work")
+
+		shutdownTimeout, _ := command.Flags().GetInt("shutdown-timeout")
+		if shutdownTimeout < 0 {
+			return errors.Errorf("shutdown-timeout (%d) must not be negative", shutdownTimeout)
+		}
+
 		clusterSupervisor, _ := command.Flags().GetBool("cluster-supervisor")
 		groupSupervisor, _ := command.Flags().GetBool("group-supervisor")
 		installationSupervisor, _ := command.Flags().GetBool("installation-supervisor")
 		clusterInstallationSupervisor, _ := command.Flags().GetBool("cluster-installation-supervisor")
-		if !clusterSupervisor && !installationSupervisor && !clusterInstallationSupervisor && !groupSupervisor {
+		installationBackupSupervisor, _ := command.Flags().GetBool("installation-backup-supervisor")
+		webhookDeliverySupervisor, _ := command.Flags().GetBool("webhook-delivery-supervisor")
+		supervisorWorkers := map[string]int{}
+		for _, name := range []string{"cluster", "group", "installation", "cluster-installation", "installation-backup", "webhook-delivery"} {
+			flag := fmt.Sprintf("%s-supervisor-workers", name)
+			workers, _ := command.Flags().GetInt(flag)
+			if workers < 1 {
+				return errors.Errorf("%s (%d) must be at least 1", flag, workers)
+			}
+			supervisorWorkers[name] = workers
+		}
+
+		webhookDeliveryRetention, _ := command.Flags().GetInt("webhook-delivery-retention")
+		if webhookDeliveryRetention < 0 {
+			return errors.Errorf("webhook-delivery-retention (%d) must not be negative", webhookDeliveryRetention)
+		}
+
+		maxConcurrentCommands, _ := command.Flags().GetInt("max-concurrent-commands")
+		if maxConcurrentCommands < 0 {
+			return errors.Errorf("max-concurrent-commands (%d) must not be negative", maxConcurrentCommands)
+		}
+		exechelper.SetMaxConcurrent(maxConcurrentCommands)
+
+		if !clusterSupervisor && !installationSupervisor && !clusterInstallationSupervisor && !groupSupervisor && !installationBackupSupervisor && !webhookDeliverySupervisor {
 			logger.Warn("Server will be running with no supervisors. Only API functionality will work.")
 		}
 
@@ -101,6 +176,7 @@ var serverCmd = &cobra.Command{
 		keepDatabaseData, _ := command.Flags().GetBool("keep-database-data")
 		keepFilestoreData, _ := command.Flags().GetBool("keep-filestore-data")
 		useExistingResources, _ := command.Flags().GetBool("use-existing-aws-resources")
+		requireAPIKey, _ := command.Flags().GetBool("require-api-key")
 
 		wd, err := os.Getwd()
 		if err != nil {
@@ -113,13 +189,24 @@ var serverCmd = &cobra.Command{
 			"group-supervisor":                groupSupervisor,
 			"installation-supervisor":         installationSupervisor,
 			"cluster-installation-supervisor": clusterInstallationSupervisor,
+			"installation-backup-supervisor":  installationBackupSupervisor,
+			"webhook-delivery-supervisor":     webhookDeliverySupervisor,
+			"supervisor-workers":              supervisorWorkers,
+			"webhook-delivery-retention":      webhookDeliveryRetention,
+			"max-concurrent-commands":         maxConcurrentCommands,
+			"lock-reaper":                     lockReaper,
+			"lock-ttl":                        lockTTL,
+			"partition-work":                  partitionWork,
 			"store-version":                   currentVersion,
 			"state-store":                     s3StateStore,
 			"working-directory":               wd,
 			"cluster-resource-threshold":      clusterResourceThreshold,
+			"scheduling-strategy":             schedulingStrategy,
 			"use-existing-aws-resources":      useExistingResources,
 			"keep-database-data":              keepDatabaseData,
 			"keep-filestore-data":             keepFilestoreData,
+			"require-api-key":                 requireAPIKey,
+			"shutdown-timeout":                shutdownTimeout,
 			"debug":                           debug,
 		}).Info("Starting Mattermost Provisioning Server")
 
@@ -129,6 +216,9 @@ var serverCmd = &cobra.Command{
 		if !useExistingResources {
 			logger.Warn("[DEV] Server is configured to not use cluster VPC claim functionality")
 		}
+		if !requireAPIKey {
+			logger.Warn("[DEV] Server is configured to accept API requests without an API key until one is created")
+		}
 
 		// best-effort attempt to tag the VPC with a human's identity for dev purposes
 		owner := getHumanReadableID()
@@ -151,30 +241,110 @@ var serverCmd = &cobra.Command{
 			logger,
 		)
 
+		// When partitioning work, each supervisor only partitions its resources
+		// with the other servers partitioning the work of the same supervisor,
+		// as recorded with their heartbeats.
+		var partitionedSupervisors []string
+		partition := func(name string, s supervisor.Partitionable) {
+			if !partitionWork {
+				return
+			}
+			s.SetPartitioner(supervisor.NewInstancePartitioner(sqlStore, instanceID, name, logger))
+			partitionedSupervisors = append(partitionedSupervisors, name)
+		}
+
 		var multiDoer supervisor.MultiDoer
 		if clusterSupervisor {
-			multiDoer = append(multiDoer, supervisor.NewClusterSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, logger))
+			s := supervisor.NewClusterSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, logger)
+			s.SetWorkers(supervisorWorkers["cluster"])
+			partition("cluster", s)
+			multiDoer = append(multiDoer, s)
 		}
 		if groupSupervisor {
-			multiDoer = append(multiDoer, supervisor.NewGroupSupervisor(sqlStore, instanceID, logger))
+			s := supervisor.NewGroupSupervisor(sqlStore, instanceID, logger)
+			s.SetWorkers(supervisorWorkers["group"])
+			partition("group", s)
+			multiDoer = append(multiDoer, s)
 		}
 		if installationSupervisor {
-			multiDoer = append(multiDoer, supervisor.NewInstallationSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, clusterResourceThreshold, keepDatabaseData, keepFilestoreData, resourceUtil, logger))
+			s := supervisor.NewInstallationSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, clusterResourceThreshold, keepDatabaseData, keepFilestoreData, resourceUtil, logger)
+			s.SetWorkers(supervisorWorkers["installation"])
+			partition("installation", s)
+			s.SetSchedulingStrategy(schedulingStrategy)
+			multiDoer = append(multiDoer, s)
 		}
 		if clusterInstallationSupervisor {
-			multiDoer = append(multiDoer, supervisor.NewClusterInstallationSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, logger))
+			s := supervisor.NewClusterInstallationSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, logger)
+			s.SetWorkers(supervisorWorkers["cluster-installation"])
+			partition("cluster-installation", s)
+			multiDoer = append(multiDoer, s)
+		}
+		if installationBackupSupervisor {
+			s := supervisor.NewInstallationBackupSupervisor(sqlStore, awsClient, instanceID, logger)
+			s.SetWorkers(supervisorWorkers["installation-backup"])
+			partition("installation-backup", s)
+			multiDoer = append(multiDoer, s)
+		}
+		// The webhook delivery supervisor runs last so that webhooks queued by
+		// the other supervisors are delivered in the same work cycle.
+		if webhookDeliverySupervisor {
+			s := supervisor.NewWebhookDeliverySupervisor(sqlStore, instanceID, logger)
+			s.SetWorkers(supervisorWorkers["webhook-delivery"])
+			s.SetRetention(time.Duration(webhookDeliveryRetention) * time.Hour)
+			partition("webhook-delivery", s)
+			multiDoer = append(multiDoer, s)
 		}
 
 		// Setup the supervisor to effect any requested changes. It is wrapped in a
-		// scheduler to trigger it periodically in addition to being poked by the API
-		// layer.
+		// scheduler to trigger it periodically, in addition to supervising the
+		// resources enqueued by the API layer as they change.
 		poll, _ := command.Flags().GetInt("poll")
 		if poll == 0 {
 			logger.WithField("poll", poll).Info("Scheduler is disabled")
 		}
 
+		// Record heartbeats on a separate schedule from the supervisors, which
+		// may be busy for a long time, so that other servers can tell the locks
+		// of this instance apart from those left behind by stopped servers.
+		instanceHeartbeat := supervisor.NewInstanceHeartbeat(sqlStore, instanceID, logger)
+		instanceHeartbeat.SetPartitionedSupervisors(partitionedSupervisors)
+		instanceHeartbeat.Do()
+		heartbeatScheduler := supervisor.NewScheduler(instanceHeartbeat, supervisor.InstanceHeartbeatInterval)
+
+		// Refresh the custom cluster sizes on a separate schedule from the
+		// supervisors, so that sizes created through other servers can be used
+		// even when polling is disabled.
+		clusterSizeLoader := supervisor.NewClusterSizeLoader(sqlStore, configClusterSizes, logger)
+		clusterSizeLoader.Do()
+		clusterSizeScheduler := supervisor.NewScheduler(clusterSizeLoader, supervisor.ClusterSizeRefreshInterval)
+
+		// Elect a leader among the running servers to perform work that must
+		// not run on several servers at once, such as reaping stale locks.
+		leaderElection := supervisor.NewLeaderElection(sqlStore, instanceID, logger)
+		leaderElection.Do()
+		leaderElectionScheduler := supervisor.NewScheduler(leaderElection, supervisor.LeaderLeaseRenewInterval)
+
+		var capacityManagerScheduler *supervisor.Scheduler
+		if capacityManager {
+			policy, err := capacityPolicy(command)
+			if err != nil {
+				return err
+			}
+			capacityManagerScheduler = supervisor.NewScheduler(
+				leaderElection.Only(supervisor.NewCapacityManager(sqlStore, policy, instanceID, logger)),
+				time.Duration(poll)*time.Second,
+			)
+		}
+
+		var lockReaperScheduler *supervisor.Scheduler
+		if lockReaper {
+			lockReaperScheduler = supervisor.NewScheduler(
+				leaderElection.Only(supervisor.NewLockReaper(sqlStore, instanceID, time.Duration(lockTTL)*time.Second, logger)),
+				time.Duration(lockTTL)*time.Second/2,
+			)
+		}
+
 		supervisor := supervisor.NewScheduler(multiDoer, time.Duration(poll)*time.Second)
-		defer supervisor.Close()
 
 		router := mux.NewRouter()
 
@@ -183,8 +353,31 @@ var serverCmd = &cobra.Command{
 			Supervisor:  supervisor,
 			Provisioner: kopsProvisioner,
 			Logger:      logger,
+
+			RequireAPIKey: requireAPIKey,
+
+			ClusterResourceThreshold: clusterResourceThreshold,
+			SchedulingStrategy:       schedulingStrategy,
 		})
 
+		err = metrics.RegisterResourceCollector(sqlStore, logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to register resource metrics")
+		}
+		router.Handle("/metrics", metrics.Handler())
+
+		// The liveness check only covers the store, without which the server
+		// cannot function, while the readiness check covers every dependency.
+		storeCheck := health.StoreCheck(sqlStore)
+		router.Handle("/healthz", health.Handler([]health.Check{storeCheck}, logger))
+		router.Handle("/readyz", health.Handler([]health.Check{
+			storeCheck,
+			health.AWSCheck(awsClient),
+			health.BinaryCheck("kops", "version"),
+			health.BinaryCheck("terraform", "version"),
+			health.BinaryCheck("helm", "version", "--client", "--short"),
+		}, logger))
+
 		listen, _ := command.Flags().GetString("listen")
 		srv := &http.Server{
 			Addr:           listen,
@@ -205,17 +398,63 @@ var serverCmd = &cobra.Command{
 		}()
 
 		c := make(chan os.Signal, 1)
-		// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C)
-		// SIGKILL, SIGQUIT or SIGTERM (Ctrl+/) will not be caught.
-		signal.Notify(c, os.Interrupt)
+		// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C) or SIGTERM,
+		// as sent by Kubernetes. SIGKILL and SIGQUIT will not be caught.
+		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 		// Block until we receive our signal.
-		<-c
-		logger.Info("Shutting down")
+		sig := <-c
+		logger.WithField("signal", sig.String()).Info("Shutting down")
 
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
-		srv.Shutdown(ctx)
+		err = srv.Shutdown(ctx)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to gracefully shut down the API server")
+		}
+
+		// Stop scheduling supervisor work, and give any in-progress transitions
+		// a chance to finish before releasing the locks held by this instance.
+		logger.WithField("shutdown-timeout", shutdownTimeout).Info("Waiting for in-progress supervisor work to finish")
+		err = supervisor.Shutdown(time.Duration(shutdownTimeout) * time.Second)
+		workStopped := err == nil
+		if err != nil {
+			logger.WithError(err).Warn("Supervisor work did not finish before the shutdown timeout")
+		}
+
+		if capacityManagerScheduler != nil {
+			capacityManagerScheduler.Close()
+		}
+		if lockReaperScheduler != nil {
+			lockReaperScheduler.Close()
+		}
+
+		clusterSizeScheduler.Close()
+		leaderElectionScheduler.Close()
+		err = leaderElection.Resign()
+		if err != nil {
+			logger.WithError(err).Warn("Failed to release leader lease")
+		}
+
+		// Locks held by work that is still running must not be released from
+		// under it. Once this instance stops sending heartbeats, the lock reaper
+		// of another instance releases them after the lock TTL instead.
+		if workStopped {
+			released, err := sqlStore.ReleaseAllLocks(instanceID)
+			if err != nil {
+				logger.WithError(err).Error("Failed to release locks held by this instance")
+			} else {
+				logger.WithField("released", released).Info("Released locks held by this instance")
+			}
+		} else {
+			logger.Warn("Leaving locks held by in-progress supervisor work to the lock reaper")
+		}
+
+		heartbeatScheduler.Close()
+		err = sqlStore.DeleteInstance(instanceID)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to delete instance heartbeat")
+		}
 
 		return nil
 	},
@@ -256,3 +495,61 @@ func getHumanReadableID() string {
 
 	return strings.TrimSpace(string(output))
 }
+
+// readClusterSizesFile reads the custom cluster sizes defined in the given
+// JSON file.
+func readClusterSizesFile(path string) ([]*model.ClusterSize, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open cluster sizes file")
+	}
+	defer file.Close()
+
+	clusterSizes, err := model.ClusterSizesFromReader(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode cluster sizes file %s", path)
+	}
+	err = model.ValidateClusterSizes(clusterSizes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid cluster sizes file %s", path)
+	}
+
+	return clusterSizes, nil
+}
+
+// capacityPolicy builds the capacity policy configured by the server flags.
+// It must be called once custom cluster sizes have been loaded.
+func capacityPolicy(command *cobra.Command) (supervisor.CapacityPolicy, error) {
+	strategy, _ := command.Flags().GetString("capacity-strategy")
+	sizeLadder, _ := command.Flags().GetString("capacity-size-ladder")
+	nodePoolStep, _ := command.Flags().GetInt64("capacity-node-pool-step")
+	nodePoolMax, _ := command.Flags().GetInt64("capacity-node-pool-max")
+	clusterSize, _ := command.Flags().GetString("capacity-cluster-size")
+	clusterZones, _ := command.Flags().GetString("capacity-cluster-zones")
+	maxClusters, _ := command.Flags().GetInt("capacity-max-clusters")
+	minClusters, _ := command.Flags().GetInt("capacity-min-clusters")
+	scaleDownGrace, _ := command.Flags().GetInt("capacity-scale-down-grace")
+	reservationTimeout, _ := command.Flags().GetInt("capacity-reservation-timeout")
+
+	policy := supervisor.CapacityPolicy{
+		Strategy:             strategy,
+		NodePoolStep:         nodePoolStep,
+		NodePoolMaxCount:     nodePoolMax,
+		ClusterSize:          clusterSize,
+		ClusterZones:         strings.Split(clusterZones, ","),
+		MaxClusters:          maxClusters,
+		MinClusters:          minClusters,
+		ScaleDownGracePeriod: time.Duration(scaleDownGrace) * time.Second,
+		ReservationTimeout:   time.Duration(reservationTimeout) * time.Second,
+	}
+	if sizeLadder != "" {
+		policy.SizeLadder = strings.Split(sizeLadder, ",")
+	}
+
+	err := policy.Validate()
+	if err != nil {
+		return supervisor.CapacityPolicy{}, errors.Wrap(err, "invalid capacity policy")
+	}
+
+	return policy, nil
+}
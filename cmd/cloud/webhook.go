@@ -11,6 +11,10 @@ func init() {
 
 	webhookCreateCmd.Flags().String("owner", "", "An opaque identifier describing the owner of the webhook.")
 	webhookCreateCmd.Flags().String("url", "", "The callback URL of the webhook.")
+	webhookCreateCmd.Flags().String("secret", "", "An optional secret used to sign the webhook requests.")
+	webhookCreateCmd.Flags().StringArray("type", []string{}, "The resource type the webhook subscribes to. Use the flag multiple times to subscribe to several types. Defaults to all types.")
+	webhookCreateCmd.Flags().StringArray("new-state", []string{}, "The new state the webhook subscribes to. Use the flag multiple times to subscribe to several states. Defaults to all states.")
+	webhookCreateCmd.Flags().String("installation-owner", "", "If set, only installation payloads of installations with this owner are sent to the webhook.")
 	webhookCreateCmd.MarkFlagRequired("owner")
 	webhookCreateCmd.MarkFlagRequired("url")
 
@@ -25,10 +29,23 @@ func init() {
 	webhookDeleteCmd.Flags().String("webhook", "", "The id of the webhook to be deleted.")
 	webhookDeleteCmd.MarkFlagRequired("webhook")
 
+	webhookDeliveriesCmd.Flags().String("webhook", "", "The id of the webhook whose deliveries are to be fetched.")
+	webhookDeliveriesCmd.Flags().StringArray("state", []string{}, "The state by which to filter deliveries. Use the flag multiple times to match any of several states.")
+	webhookDeliveriesCmd.Flags().Int("page", 0, "The page of deliveries to fetch, starting at 0.")
+	webhookDeliveriesCmd.Flags().Int("per-page", 100, "The number of deliveries to fetch per page.")
+	webhookDeliveriesCmd.MarkFlagRequired("webhook")
+
+	webhookReplayCmd.Flags().String("webhook", "", "The id of the webhook the delivery belongs to.")
+	webhookReplayCmd.Flags().String("delivery", "", "The id of the failed delivery to be replayed.")
+	webhookReplayCmd.MarkFlagRequired("webhook")
+	webhookReplayCmd.MarkFlagRequired("delivery")
+
 	webhookCmd.AddCommand(webhookCreateCmd)
 	webhookCmd.AddCommand(webhookGetCmd)
 	webhookCmd.AddCommand(webhookListCmd)
 	webhookCmd.AddCommand(webhookDeleteCmd)
+	webhookCmd.AddCommand(webhookDeliveriesCmd)
+	webhookCmd.AddCommand(webhookReplayCmd)
 }
 
 var webhookCmd = &cobra.Command{
@@ -43,14 +60,22 @@ var webhookCreateCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		ownerID, _ := command.Flags().GetString("owner")
 		url, _ := command.Flags().GetString("url")
+		secret, _ := command.Flags().GetString("secret")
+		types, _ := command.Flags().GetStringArray("type")
+		newStates, _ := command.Flags().GetStringArray("new-state")
+		installationOwnerID, _ := command.Flags().GetString("installation-owner")
 
 		webhook, err := client.CreateWebhook(&model.CreateWebhookRequest{
-			OwnerID: ownerID,
-			URL:     url,
+			OwnerID:             ownerID,
+			URL:                 url,
+			Secret:              secret,
+			Types:               types,
+			NewStates:           newStates,
+			InstallationOwnerID: installationOwnerID,
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to create webhook")
@@ -72,7 +97,7 @@ var webhookGetCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		webhookID, _ := command.Flags().GetString("webhook")
 		webhook, err := client.GetWebhook(webhookID)
@@ -99,7 +124,7 @@ var webhookListCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		owner, _ := command.Flags().GetString("owner")
 		page, _ := command.Flags().GetInt("page")
@@ -131,7 +156,7 @@ var webhookDeleteCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(serverAddress)
 
 		webhookID, _ := command.Flags().GetString("webhook")
 
@@ -143,3 +168,60 @@ var webhookDeleteCmd = &cobra.Command{
 		return nil
 	},
 }
+
+var webhookDeliveriesCmd = &cobra.Command{
+	Use:   "deliveries",
+	Short: "List the deliveries of a webhook.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		webhookID, _ := command.Flags().GetString("webhook")
+		states, _ := command.Flags().GetStringArray("state")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		deliveries, err := client.GetWebhookDeliveries(webhookID, &model.GetWebhookDeliveriesRequest{
+			States:  states,
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query webhook deliveries")
+		}
+
+		err = printJSON(deliveries)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var webhookReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Queue a failed webhook delivery for delivery again.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(serverAddress)
+
+		webhookID, _ := command.Flags().GetString("webhook")
+		deliveryID, _ := command.Flags().GetString("delivery")
+
+		delivery, err := client.ReplayWebhookDelivery(webhookID, deliveryID)
+		if err != nil {
+			return errors.Wrap(err, "failed to replay webhook delivery")
+		}
+
+		err = printJSON(delivery)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -5,10 +5,16 @@ import "github.com/gorilla/mux"
 // Register registers the API endpoints on the given router.
 func Register(rootRouter *mux.Router, context *Context) {
 	apiRouter := rootRouter.PathPrefix("/api").Subrouter()
+	apiRouter.Use(audit(context))
+	apiRouter.Use(authenticate(context))
 
 	initCluster(apiRouter, context)
+	initClusterSize(apiRouter, context)
 	initInstallation(apiRouter, context)
 	initClusterInstallation(apiRouter, context)
 	initGroup(apiRouter, context)
 	initWebhook(apiRouter, context)
+	initEvent(apiRouter, context)
+	initAudit(apiRouter, context)
+	initLocks(apiRouter, context)
 }
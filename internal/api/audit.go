@@ -0,0 +1,278 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// auditedMethods are the request methods recorded in the audit log.
+var auditedMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodDelete: true,
+}
+
+// auditResourceVars are the route variables that may identify the resource
+// acted upon by a request, most specific first.
+var auditResourceVars = []string{
+	"backup",
+	"delivery",
+	"cluster_installation",
+	"installation",
+	"cluster",
+	"group",
+	"webhook",
+	"resource_id",
+}
+
+// sensitiveAuditFields are the lowercased JSON field names whose values are
+// redacted from audited request bodies. Value covers the values of Mattermost
+// environment variables.
+var sensitiveAuditFields = map[string]bool{
+	"license":  true,
+	"password": true,
+	"secret":   true,
+	"token":    true,
+	"value":    true,
+}
+
+// maxAuditBodySize is the maximum number of bytes of a request or response
+// body kept for the audit log.
+const maxAuditBodySize = 64 * 1024
+
+const redactedAuditValue = "[REDACTED]"
+
+// initAudit registers audit endpoints on the given router.
+func initAudit(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	auditRouter := apiRouter.PathPrefix("/audit").Subrouter()
+	auditRouter.Use(authorize(resourceAudit))
+	auditRouter.Handle("", addContext(handleGetAuditRecords)).Methods("GET")
+}
+
+// handleGetAuditRecords responds to GET /api/audit, returning the specified
+// page of audited requests.
+func handleGetAuditRecords(c *Context, w http.ResponseWriter, r *http.Request) {
+	apiKeyID := r.URL.Query().Get("api_key_id")
+	resourceID := r.URL.Query().Get("resource_id")
+
+	page, perPage, _, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	filter := &model.AuditRecordFilter{
+		APIKeyID:   apiKeyID,
+		ResourceID: resourceID,
+		Page:       page,
+		PerPage:    perPage,
+	}
+
+	auditRecords, err := c.Store.GetAuditRecords(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query audit records")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if auditRecords == nil {
+		auditRecords = []*model.AuditRecord{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, auditRecords)
+}
+
+type requestIDContextKey struct{}
+
+type auditRecordContextKey struct{}
+
+// requestIDFromRequest returns the ID assigned to the request by the audit
+// middleware, if any.
+func requestIDFromRequest(r *http.Request) string {
+	requestID, _ := r.Context().Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// auditRecordFromRequest returns the audit record of the request, if the
+// request is audited.
+func auditRecordFromRequest(r *http.Request) *model.AuditRecord {
+	auditRecord, _ := r.Context().Value(auditRecordContextKey{}).(*model.AuditRecord)
+	return auditRecord
+}
+
+// audit returns middleware that records requests using an audited method,
+// and their outcome, in the audit log and observes the metrics of every
+// request. It must run ahead of the authenticate and authorize middleware so
+// that rejected requests are recorded and observed as well.
+func audit(c *Context) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			requestID := model.NewID()
+			r = r.WithContext(context.WithValue(r.Context(), requestIDContextKey{}, requestID))
+
+			writer := &responseWriter{ResponseWriter: w}
+			if auditedMethods[r.Method] {
+				logger := c.Logger.WithFields(log.Fields{
+					"path":    r.URL.Path,
+					"request": requestID,
+				})
+				handleAudited(c.Store, logger, next, writer, r)
+			} else {
+				next.ServeHTTP(writer, r)
+			}
+
+			metrics.ObserveAPIRequest(routeTemplate(r), r.Method, writer.StatusCode(), time.Since(start))
+		})
+	}
+}
+
+// handleAudited invokes the given handler and records the request and its
+// outcome in the audit log.
+func handleAudited(store Store, logger log.FieldLogger, handler http.Handler, w *responseWriter, r *http.Request) {
+	var requestBody []byte
+	if r.Body != nil {
+		var err error
+		requestBody, err = ioutil.ReadAll(r.Body)
+		if err != nil {
+			logger.WithError(err).Warn("failed to read request body for audit")
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	resourceID := auditResourceID(r)
+
+	if resourceID == "" {
+		// Requests creating a resource only learn its ID from the response.
+		w.body = &bytes.Buffer{}
+	}
+
+	// The API key is recorded by the authenticate middleware once resolved.
+	auditRecord := &model.AuditRecord{
+		RequestID:  requestIDFromRequest(r),
+		RemoteAddr: r.RemoteAddr,
+		Method:     r.Method,
+		Route:      routeTemplate(r),
+		Path:       r.URL.Path,
+	}
+	handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), auditRecordContextKey{}, auditRecord)))
+
+	statusCode := w.StatusCode()
+	if resourceID == "" && statusCode >= 200 && statusCode < 300 {
+		resourceID = resourceIDFromResponse(w.body.Bytes())
+	}
+
+	auditRecord.ResourceID = resourceID
+	auditRecord.RequestBody = sanitizeAuditBody(requestBody)
+	auditRecord.StatusCode = statusCode
+
+	err := store.CreateAuditRecord(auditRecord)
+	if err != nil {
+		logger.WithError(err).Error("failed to record audit record")
+	}
+}
+
+// auditResourceID returns the ID of the resource named by the request route,
+// if any.
+func auditResourceID(r *http.Request) string {
+	vars := mux.Vars(r)
+	for _, name := range auditResourceVars {
+		if id := vars[name]; id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
+
+// resourceIDFromResponse returns the ID of the resource encoded in the given
+// response body, if any.
+func resourceIDFromResponse(body []byte) string {
+	var resource struct {
+		ID string
+	}
+
+	err := json.Unmarshal(body, &resource)
+	if err != nil {
+		return ""
+	}
+
+	return resource.ID
+}
+
+// sanitizeAuditBody redacts sensitive values from the given request body,
+// truncating it if necessary. Bodies that are not valid JSON are omitted.
+func sanitizeAuditBody(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+
+	var data interface{}
+	err := json.Unmarshal(body, &data)
+	if err != nil {
+		return "[INVALID JSON]"
+	}
+
+	if args, ok := data.([]interface{}); ok {
+		data = redactAuditArgs(args)
+	}
+
+	sanitized, err := json.Marshal(redactAuditValue(data))
+	if err != nil {
+		return "[INVALID JSON]"
+	}
+	if len(sanitized) > maxAuditBodySize {
+		return string(sanitized[:maxAuditBodySize]) + "[TRUNCATED]"
+	}
+
+	return string(sanitized)
+}
+
+// redactAuditArgs redacts all but the first of the given positional command
+// arguments, such as those of a Mattermost CLI command, as they may carry
+// sensitive values with no field name to identify them. Only the subcommand
+// is kept.
+func redactAuditArgs(args []interface{}) []interface{} {
+	for i := 1; i < len(args); i++ {
+		args[i] = redactedAuditValue
+	}
+
+	return args
+}
+
+// redactAuditValue recursively replaces the values of sensitive fields.
+func redactAuditValue(data interface{}) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		for key, fieldValue := range value {
+			if sensitiveAuditFields[strings.ToLower(key)] {
+				value[key] = redactedAuditValue
+				continue
+			}
+			value[key] = redactAuditValue(fieldValue)
+		}
+	case []interface{}:
+		for i, element := range value {
+			value[i] = redactAuditValue(element)
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,234 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("invalid paging", func(t *testing.T) {
+		resp, err := http.Get(fmt.Sprintf("%s/api/audit?page=invalid", ts.URL))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("no audit records", func(t *testing.T) {
+		auditRecords, err := client.GetAuditRecords(&model.GetAuditRecordsRequest{
+			Page:    0,
+			PerPage: 10,
+		})
+		require.NoError(t, err)
+		require.Empty(t, auditRecords)
+	})
+
+	installation, err := client.CreateInstallation(&model.CreateInstallationRequest{
+		OwnerID: "owner1",
+		DNS:     "audit.example.com",
+		License: "this_is_my_license",
+		MattermostEnv: model.EnvVarMap{
+			"MM_SECRET_SETTING": {Value: "secret_value"},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = client.GetInstallation(installation.ID, &model.GetInstallationRequest{})
+	require.NoError(t, err)
+
+	err = client.DeleteInstallation(installation.ID)
+	require.NoError(t, err)
+
+	resp, err := http.Post(fmt.Sprintf("%s/api/installations", ts.URL), "application/json", strings.NewReader("{invalid"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	t.Run("mutating requests are recorded", func(t *testing.T) {
+		auditRecords, err := client.GetAuditRecords(&model.GetAuditRecordsRequest{
+			Page:    0,
+			PerPage: 10,
+		})
+		require.NoError(t, err)
+		require.Len(t, auditRecords, 3)
+
+		create := auditRecords[0]
+		require.NotEmpty(t, create.RequestID)
+		require.Empty(t, create.APIKeyID)
+		require.Equal(t, http.MethodPost, create.Method)
+		require.Equal(t, "/api/installations", create.Route)
+		require.Equal(t, installation.ID, create.ResourceID)
+		require.Equal(t, http.StatusAccepted, create.StatusCode)
+		require.Contains(t, create.RequestBody, "audit.example.com")
+		require.Contains(t, create.RequestBody, "MM_SECRET_SETTING")
+		require.NotContains(t, create.RequestBody, "this_is_my_license")
+		require.NotContains(t, create.RequestBody, "secret_value")
+
+		remove := auditRecords[1]
+		require.Equal(t, http.MethodDelete, remove.Method)
+		require.Equal(t, "/api/installation/{installation:[A-Za-z0-9]{26}}", remove.Route)
+		require.Equal(t, "/api/installation/"+installation.ID, remove.Path)
+		require.Equal(t, installation.ID, remove.ResourceID)
+		require.Equal(t, http.StatusAccepted, remove.StatusCode)
+
+		invalid := auditRecords[2]
+		require.Empty(t, invalid.ResourceID)
+		require.Equal(t, "[INVALID JSON]", invalid.RequestBody)
+		require.Equal(t, http.StatusBadRequest, invalid.StatusCode)
+	})
+
+	t.Run("filter by resource", func(t *testing.T) {
+		auditRecords, err := client.GetAuditRecords(&model.GetAuditRecordsRequest{
+			ResourceID: installation.ID,
+			Page:       0,
+			PerPage:    10,
+		})
+		require.NoError(t, err)
+		require.Len(t, auditRecords, 2)
+	})
+}
+
+func TestAuditAPIKey(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:         sqlStore,
+		Supervisor:    &mockSupervisor{},
+		Logger:        logger,
+		RequireAPIKey: true,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	key, err := model.NewAPIKeySecret()
+	require.NoError(t, err)
+	apiKey := &model.APIKey{
+		KeyHash: model.HashAPIKey(key),
+		Scopes:  []string{model.APIKeyScopeClusterAdmin},
+	}
+	err = sqlStore.CreateAPIKey(apiKey)
+	require.NoError(t, err)
+
+	client := model.NewClientWithHeaders(ts.URL, map[string]string{model.APIKeyHeader: key})
+
+	_, err = client.CreateGroup(&model.CreateGroupRequest{Name: "group1"})
+	require.NoError(t, err)
+
+	auditRecords, err := client.GetAuditRecords(&model.GetAuditRecordsRequest{
+		APIKeyID: apiKey.ID,
+		Page:     0,
+		PerPage:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, auditRecords, 1)
+	require.Equal(t, apiKey.ID, auditRecords[0].APIKeyID)
+	require.Equal(t, "/api/groups", auditRecords[0].Route)
+}
+
+func TestAuditRejectedRequests(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:         sqlStore,
+		Supervisor:    &mockSupervisor{},
+		Logger:        logger,
+		RequireAPIKey: true,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	adminKey, err := model.NewAPIKeySecret()
+	require.NoError(t, err)
+	adminAPIKey := &model.APIKey{
+		KeyHash: model.HashAPIKey(adminKey),
+		Scopes:  []string{model.APIKeyScopeClusterAdmin},
+	}
+	err = sqlStore.CreateAPIKey(adminAPIKey)
+	require.NoError(t, err)
+
+	readOnlyKey, err := model.NewAPIKeySecret()
+	require.NoError(t, err)
+	readOnlyAPIKey := &model.APIKey{
+		KeyHash: model.HashAPIKey(readOnlyKey),
+	}
+	err = sqlStore.CreateAPIKey(readOnlyAPIKey)
+	require.NoError(t, err)
+
+	_, err = model.NewClient(ts.URL).CreateGroup(&model.CreateGroupRequest{Name: "group1"})
+	require.EqualError(t, err, "failed with status code 401")
+
+	_, err = model.NewClientWithHeaders(ts.URL, map[string]string{model.APIKeyHeader: readOnlyKey}).CreateGroup(&model.CreateGroupRequest{Name: "group1"})
+	require.EqualError(t, err, "failed with status code 403")
+
+	client := model.NewClientWithHeaders(ts.URL, map[string]string{model.APIKeyHeader: adminKey})
+	auditRecords, err := client.GetAuditRecords(&model.GetAuditRecordsRequest{
+		Page:    0,
+		PerPage: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, auditRecords, 2)
+
+	unauthenticated := auditRecords[0]
+	require.Empty(t, unauthenticated.APIKeyID)
+	require.Equal(t, "/api/groups", unauthenticated.Route)
+	require.Equal(t, http.StatusUnauthorized, unauthenticated.StatusCode)
+
+	unauthorized := auditRecords[1]
+	require.Equal(t, readOnlyAPIKey.ID, unauthorized.APIKeyID)
+	require.Equal(t, "/api/groups", unauthorized.Route)
+	require.Equal(t, http.StatusForbidden, unauthorized.StatusCode)
+}
+
+func TestAuditMattermostCLI(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:       sqlStore,
+		Supervisor:  &mockSupervisor{},
+		Provisioner: &mockProvisioner{},
+		Logger:      logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	subcommand := model.ClusterInstallationMattermostCLISubcommand{"config", "set", "SqlSettings.DataSource", "secret_data_source"}
+	_, err := client.RunMattermostCLICommandOnClusterInstallation(model.NewID(), subcommand)
+	require.EqualError(t, err, "failed with status code 404")
+
+	auditRecords, err := client.GetAuditRecords(&model.GetAuditRecordsRequest{
+		Page:    0,
+		PerPage: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, auditRecords, 1)
+	require.Equal(t, `["config","[REDACTED]","[REDACTED]","[REDACTED]"]`, auditRecords[0].RequestBody)
+}
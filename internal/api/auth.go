@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// The resources an API key can be authorized against.
+const (
+	resourceCluster      = "cluster"
+	resourceInstallation = "installation"
+	resourceGroup        = "group"
+	resourceWebhook      = "webhook"
+	resourceEvent        = "event"
+	resourceAudit        = "audit"
+	resourceLock         = "lock"
+)
+
+// ownedResources are the resources that belong to an owner, and so are the
+// only resources accessible to API keys limited to an owner.
+var ownedResources = map[string]bool{
+	resourceInstallation: true,
+	resourceWebhook:      true,
+}
+
+type apiKeyContextKey struct{}
+
+// apiKeyFromRequest returns the API key the request was authenticated with,
+// if any.
+func apiKeyFromRequest(r *http.Request) *model.APIKey {
+	apiKey, _ := r.Context().Value(apiKeyContextKey{}).(*model.APIKey)
+	return apiKey
+}
+
+// parseAPIKey returns the API key presented with the request, either in the
+// API key header or as a bearer token.
+func parseAPIKey(r *http.Request) string {
+	if key := r.Header.Get(model.APIKeyHeader); key != "" {
+		return key
+	}
+
+	authorization := r.Header.Get("Authorization")
+	if strings.HasPrefix(authorization, "Bearer ") {
+		return strings.TrimPrefix(authorization, "Bearer ")
+	}
+
+	return ""
+}
+
+// authenticate returns middleware that resolves the API key presented with a
+// request. Requests without an API key are rejected when the context requires
+// one or once any API key is active, so that the limits of an API key cannot
+// be bypassed by leaving it out. Requests with an unknown or revoked API key
+// are always rejected.
+func authenticate(c *Context) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := parseAPIKey(r)
+			if key == "" {
+				if c.RequireAPIKey {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				hasActiveAPIKeys, err := c.Store.HasActiveAPIKeys()
+				if err != nil {
+					c.Logger.WithError(err).Error("failed to query API keys")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				if hasActiveAPIKeys {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			apiKey, err := c.Store.GetAPIKeyByHash(model.HashAPIKey(key))
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to query API key")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if apiKey == nil || apiKey.IsDeleted() {
+				c.Logger.WithField("path", r.URL.Path).Warn("rejected request with invalid API key")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			if auditRecord := auditRecordFromRequest(r); auditRecord != nil {
+				auditRecord.APIKeyID = apiKey.ID
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, apiKey)))
+		})
+	}
+}
+
+// authorize returns middleware that rejects requests whose API key does not
+// permit the request method on the given resource. Unauthenticated requests
+// are left to the authenticate middleware.
+func authorize(resource string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey := apiKeyFromRequest(r)
+			if apiKey != nil && !isAuthorized(apiKey, resource, r.Method) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isAuthorized returns whether the API key permits the given request method
+// on the given resource.
+func isAuthorized(apiKey *model.APIKey, resource, method string) bool {
+	if apiKey.OwnerID != "" && !ownedResources[resource] {
+		return false
+	}
+	if method == http.MethodGet {
+		return true
+	}
+	if apiKey.HasScope(model.APIKeyScopeClusterAdmin) {
+		return true
+	}
+	if apiKey.HasScope(model.APIKeyScopeInstallationAdmin) {
+		return resource == resourceInstallation || resource == resourceGroup || resource == resourceWebhook
+	}
+
+	return false
+}
+
+// authorizeInstallationOwner returns middleware that hides installations from
+// API keys limited to a different owner.
+func authorizeInstallationOwner(c *Context) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey := apiKeyFromRequest(r)
+			if apiKey == nil || apiKey.OwnerID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			installation, err := c.Store.GetInstallation(mux.Vars(r)["installation"], false, false)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to query installation")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if installation == nil || installation.OwnerID != apiKey.OwnerID {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authorizeWebhookOwner returns middleware that hides webhooks from API keys
+// limited to a different owner.
+func authorizeWebhookOwner(c *Context) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey := apiKeyFromRequest(r)
+			if apiKey == nil || apiKey.OwnerID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			webhook, err := c.Store.GetWebhook(mux.Vars(r)["webhook"])
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to query webhook")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if webhook == nil || webhook.OwnerID != apiKey.OwnerID {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:         sqlStore,
+		Supervisor:    &mockSupervisor{},
+		Logger:        logger,
+		RequireAPIKey: true,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	createAPIKey := func(t *testing.T, ownerID string, scopes ...string) *model.Client {
+		t.Helper()
+		key, err := model.NewAPIKeySecret()
+		require.NoError(t, err)
+
+		err = sqlStore.CreateAPIKey(&model.APIKey{
+			KeyHash: model.HashAPIKey(key),
+			Scopes:  scopes,
+			OwnerID: ownerID,
+		})
+		require.NoError(t, err)
+
+		return model.NewClientWithHeaders(ts.URL, map[string]string{model.APIKeyHeader: key})
+	}
+
+	createInstallationRequest := func(ownerID, dns string) *model.CreateInstallationRequest {
+		return &model.CreateInstallationRequest{
+			OwnerID: ownerID,
+			DNS:     dns,
+		}
+	}
+
+	readOnlyClient := createAPIKey(t, "", model.APIKeyScopeReadOnly)
+	installationAdminClient := createAPIKey(t, "", model.APIKeyScopeInstallationAdmin)
+	clusterAdminClient := createAPIKey(t, "", model.APIKeyScopeClusterAdmin)
+	ownerClient := createAPIKey(t, "owner1", model.APIKeyScopeInstallationAdmin)
+
+	t.Run("missing API key", func(t *testing.T) {
+		_, err := model.NewClient(ts.URL).GetClusters(&model.GetClustersRequest{PerPage: 10})
+		require.EqualError(t, err, "failed with status code 401")
+	})
+
+	t.Run("unknown API key", func(t *testing.T) {
+		client := model.NewClientWithHeaders(ts.URL, map[string]string{model.APIKeyHeader: "unknown"})
+		_, err := client.GetClusters(&model.GetClustersRequest{PerPage: 10})
+		require.EqualError(t, err, "failed with status code 401")
+	})
+
+	t.Run("revoked API key", func(t *testing.T) {
+		key, err := model.NewAPIKeySecret()
+		require.NoError(t, err)
+		apiKey := &model.APIKey{
+			KeyHash: model.HashAPIKey(key),
+			Scopes:  []string{model.APIKeyScopeClusterAdmin},
+		}
+		err = sqlStore.CreateAPIKey(apiKey)
+		require.NoError(t, err)
+		err = sqlStore.DeleteAPIKey(apiKey.ID)
+		require.NoError(t, err)
+
+		client := model.NewClientWithHeaders(ts.URL, map[string]string{model.APIKeyHeader: key})
+		_, err = client.GetClusters(&model.GetClustersRequest{PerPage: 10})
+		require.EqualError(t, err, "failed with status code 401")
+	})
+
+	t.Run("bearer token", func(t *testing.T) {
+		key, err := model.NewAPIKeySecret()
+		require.NoError(t, err)
+		err = sqlStore.CreateAPIKey(&model.APIKey{
+			KeyHash: model.HashAPIKey(key),
+			Scopes:  []string{model.APIKeyScopeReadOnly},
+		})
+		require.NoError(t, err)
+
+		client := model.NewClientWithHeaders(ts.URL, map[string]string{"Authorization": "Bearer " + key})
+		_, err = client.GetClusters(&model.GetClustersRequest{PerPage: 10})
+		require.NoError(t, err)
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		_, err := readOnlyClient.GetClusters(&model.GetClustersRequest{PerPage: 10})
+		require.NoError(t, err)
+		_, err = readOnlyClient.GetEvents(&model.GetEventsRequest{PerPage: 10})
+		require.NoError(t, err)
+
+		_, err = readOnlyClient.CreateInstallation(createInstallationRequest("owner1", "readonly.example.com"))
+		require.EqualError(t, err, "failed with status code 403")
+		err = readOnlyClient.DeleteCluster(model.NewID())
+		require.EqualError(t, err, "failed with status code 403")
+		_, err = readOnlyClient.ScheduleInstallation(&model.ScheduleInstallationRequest{})
+		require.EqualError(t, err, "failed with status code 403")
+	})
+
+	t.Run("installation-admin", func(t *testing.T) {
+		_, err := installationAdminClient.CreateInstallation(createInstallationRequest("owner2", "admin.example.com"))
+		require.NoError(t, err)
+
+		err = installationAdminClient.DeleteCluster(model.NewID())
+		require.EqualError(t, err, "failed with status code 403")
+		_, err = installationAdminClient.ScheduleInstallation(&model.ScheduleInstallationRequest{})
+		require.EqualError(t, err, "failed with status code 403")
+	})
+
+	t.Run("cluster-admin", func(t *testing.T) {
+		err := clusterAdminClient.DeleteCluster(model.NewID())
+		require.EqualError(t, err, "failed with status code 404")
+		_, err = clusterAdminClient.ScheduleInstallation(&model.ScheduleInstallationRequest{
+			SchedulingStrategy: model.SchedulingStrategySpread,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("owner limited", func(t *testing.T) {
+		installation, err := ownerClient.CreateInstallation(createInstallationRequest("owner1", "owner1.example.com"))
+		require.NoError(t, err)
+
+		_, err = ownerClient.CreateInstallation(createInstallationRequest("owner2", "owner2.example.com"))
+		require.EqualError(t, err, "failed with status code 403")
+
+		installations, err := ownerClient.GetInstallations(&model.GetInstallationsRequest{PerPage: 10})
+		require.NoError(t, err)
+		require.Equal(t, []*model.Installation{installation}, installations)
+
+		_, err = ownerClient.GetInstallations(&model.GetInstallationsRequest{OwnerID: "owner2", PerPage: 10})
+		require.EqualError(t, err, "failed with status code 403")
+
+		installations, err = clusterAdminClient.GetInstallations(&model.GetInstallationsRequest{OwnerID: "owner2", PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, installations, 1)
+
+		otherInstallation, err := ownerClient.GetInstallation(installations[0].ID, nil)
+		require.NoError(t, err)
+		require.Nil(t, otherInstallation)
+
+		err = ownerClient.DeleteInstallation(installations[0].ID)
+		require.EqualError(t, err, "failed with status code 404")
+
+		_, err = ownerClient.GetClusters(&model.GetClustersRequest{PerPage: 10})
+		require.EqualError(t, err, "failed with status code 403")
+		_, err = ownerClient.GetEvents(&model.GetEventsRequest{PerPage: 10})
+		require.EqualError(t, err, "failed with status code 403")
+		_, err = ownerClient.ScheduleInstallation(&model.ScheduleInstallationRequest{})
+		require.EqualError(t, err, "failed with status code 403")
+	})
+
+	t.Run("owner limited webhooks", func(t *testing.T) {
+		webhook, err := ownerClient.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID: "owner1",
+			URL:     "https://owner1.example.com",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{model.TypeInstallation}, webhook.Types)
+		require.Equal(t, "owner1", webhook.InstallationOwnerID)
+
+		_, err = ownerClient.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID: "owner1",
+			URL:     "https://clusters.example.com",
+			Types:   []string{model.TypeCluster},
+		})
+		require.EqualError(t, err, "failed with status code 403")
+
+		_, err = ownerClient.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID:             "owner1",
+			URL:                 "https://owner2.example.com",
+			InstallationOwnerID: "owner2",
+		})
+		require.EqualError(t, err, "failed with status code 403")
+
+		otherWebhook, err := clusterAdminClient.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID: "owner2",
+			URL:     "https://other.example.com",
+		})
+		require.NoError(t, err)
+
+		webhooks, err := ownerClient.GetWebhooks(&model.GetWebhooksRequest{PerPage: 10})
+		require.NoError(t, err)
+		require.Equal(t, []*model.Webhook{webhook}, webhooks)
+
+		fetchedWebhook, err := ownerClient.GetWebhook(otherWebhook.ID)
+		require.NoError(t, err)
+		require.Nil(t, fetchedWebhook)
+	})
+}
+
+func TestAPIKeyOptional(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	t.Run("missing API key", func(t *testing.T) {
+		_, err := model.NewClient(ts.URL).GetClusters(&model.GetClustersRequest{PerPage: 10})
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown API key", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/clusters", nil)
+		require.NoError(t, err)
+		req.Header.Set(model.APIKeyHeader, "unknown")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("missing API key once an API key is active", func(t *testing.T) {
+		key, err := model.NewAPIKeySecret()
+		require.NoError(t, err)
+		apiKey := &model.APIKey{
+			KeyHash: model.HashAPIKey(key),
+			Scopes:  []string{model.APIKeyScopeInstallationAdmin},
+			OwnerID: "owner1",
+		}
+		err = sqlStore.CreateAPIKey(apiKey)
+		require.NoError(t, err)
+
+		_, err = model.NewClient(ts.URL).GetClusters(&model.GetClustersRequest{PerPage: 10})
+		require.EqualError(t, err, "failed with status code 401")
+
+		err = sqlStore.DeleteAPIKey(apiKey.ID)
+		require.NoError(t, err)
+
+		_, err = model.NewClient(ts.URL).GetClusters(&model.GetClustersRequest{PerPage: 10})
+		require.NoError(t, err)
+	})
+}
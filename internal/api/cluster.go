@@ -16,15 +16,18 @@ func initCluster(apiRouter *mux.Router, context *Context) {
 	}
 
 	clustersRouter := apiRouter.PathPrefix("/clusters").Subrouter()
+	clustersRouter.Use(authorize(resourceCluster))
 	clustersRouter.Handle("", addContext(handleGetClusters)).Methods("GET")
 	clustersRouter.Handle("", addContext(handleCreateCluster)).Methods("POST")
 
 	clusterRouter := apiRouter.PathPrefix("/cluster/{cluster:[A-Za-z0-9]{26}}").Subrouter()
+	clusterRouter.Use(authorize(resourceCluster))
 	clusterRouter.Handle("", addContext(handleGetCluster)).Methods("GET")
 	clusterRouter.Handle("", addContext(handleRetryCreateCluster)).Methods("POST")
 	clusterRouter.Handle("", addContext(handleUpdateClusterConfiguration)).Methods("PUT")
 	clusterRouter.Handle("/provision", addContext(handleProvisionCluster)).Methods("POST")
 	clusterRouter.Handle("/kubernetes/{version}", addContext(handleUpgradeKubernetes)).Methods("PUT")
+	clusterRouter.Handle("/size", addContext(handleResizeCluster)).Methods("PUT")
 	clusterRouter.Handle("/utilities", addContext(handleGetAllUtilityMetadata)).Methods("GET")
 	clusterRouter.Handle("", addContext(handleDeleteCluster)).Methods("DELETE")
 }
@@ -38,10 +41,21 @@ func handleGetClusters(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	allowInstallations, err := parseOptionalBool(r.URL, "allow_installations")
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse filter parameters")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
 	filter := &model.ClusterFilter{
-		Page:           page,
-		PerPage:        perPage,
-		IncludeDeleted: includeDeleted,
+		States:             r.URL.Query()["state"],
+		Size:               r.URL.Query().Get("size"),
+		Version:            r.URL.Query().Get("version"),
+		AllowInstallations: allowInstallations,
+		Page:               page,
+		PerPage:            perPage,
+		IncludeDeleted:     includeDeleted,
 	}
 
 	clusters, err := c.Store.GetClusters(filter)
@@ -105,8 +119,9 @@ func handleCreateCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = cluster.SetProvisionerMetadata(model.KopsMetadata{
-		Version: createClusterRequest.Version,
-		AMI:     createClusterRequest.KopsAMI,
+		Version:        createClusterRequest.Version,
+		AMI:            createClusterRequest.KopsAMI,
+		InstanceGroups: createClusterRequest.InstanceGroups,
 	})
 
 	if err != nil {
@@ -134,7 +149,7 @@ func handleCreateCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 		c.Logger.WithError(err).Error("Unable to process and send webhooks")
 	}
 
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeCluster, cluster.ID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
@@ -175,6 +190,7 @@ func handleRetryCreateCluster(c *Context, w http.ResponseWriter, r *http.Request
 			Timestamp: time.Now().UnixNano(),
 		}
 		cluster.State = newState
+		cluster.ResetAttempts()
 
 		err := c.Store.UpdateCluster(cluster)
 		if err != nil {
@@ -191,7 +207,7 @@ func handleRetryCreateCluster(c *Context, w http.ResponseWriter, r *http.Request
 
 	// Notify even if we didn't make changes, to expedite even the no-op operations above.
 	unlockOnce()
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeCluster, clusterID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
@@ -242,6 +258,7 @@ func handleProvisionCluster(c *Context, w http.ResponseWriter, r *http.Request)
 			Timestamp: time.Now().UnixNano(),
 		}
 		cluster.State = newState
+		cluster.ResetAttempts()
 
 		err := c.Store.UpdateCluster(cluster)
 		if err != nil {
@@ -258,7 +275,7 @@ func handleProvisionCluster(c *Context, w http.ResponseWriter, r *http.Request)
 
 	// Notify even if we didn't make changes, to expedite even the no-op operations above.
 	unlockOnce()
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeCluster, clusterID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
@@ -386,6 +403,7 @@ func handleUpgradeKubernetes(c *Context, w http.ResponseWriter, r *http.Request)
 			Timestamp: time.Now().UnixNano(),
 		}
 		cluster.State = newState
+		cluster.ResetAttempts()
 
 		err := c.Store.UpdateCluster(cluster)
 		if err != nil {
@@ -401,11 +419,96 @@ func handleUpgradeKubernetes(c *Context, w http.ResponseWriter, r *http.Request)
 	}
 
 	unlockOnce()
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeCluster, clusterID)
 
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// handleResizeCluster responds to PUT /api/cluster/{cluster}/size, resizing
+// the cluster's kops instance groups to the given size.
+func handleResizeCluster(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clusterID := vars["cluster"]
+	c.Logger = c.Logger.WithField("cluster", clusterID)
+
+	resizeClusterRequest, err := model.NewResizeClusterRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	cluster, status, unlockOnce := lockCluster(c, clusterID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	newState := model.ClusterStateResizeRequested
+
+	if !cluster.ValidTransitionState(newState) {
+		c.Logger.Warnf("unable to resize cluster while in state %s", cluster.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	kopsMetadata, err := model.NewKopsMetadata(cluster.ProvisionerMetadata)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse existing provisioner metadata")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// The cluster size is only updated once the resize has succeeded.
+	var resizeSize string
+	if resizeClusterRequest.Size != cluster.Size {
+		resizeSize = resizeClusterRequest.Size
+	}
+
+	if cluster.State != newState || kopsMetadata.ResizeSize != resizeSize || len(resizeClusterRequest.InstanceGroups) > 0 {
+		kopsMetadata.ResizeSize = resizeSize
+		kopsMetadata.MergeInstanceGroups(resizeClusterRequest.InstanceGroups)
+		err = cluster.SetProvisionerMetadata(kopsMetadata)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to set provisioner metadata")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		webhookPayload := &model.WebhookPayload{
+			Type:      model.TypeCluster,
+			ID:        cluster.ID,
+			NewState:  newState,
+			OldState:  cluster.State,
+			Timestamp: time.Now().UnixNano(),
+		}
+		cluster.State = newState
+		cluster.ResetAttempts()
+
+		err := c.Store.UpdateCluster(cluster)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to mark cluster for resize")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if webhookPayload.OldState != newState {
+			err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+			if err != nil {
+				c.Logger.WithError(err).Error("Unable to process and send webhooks")
+			}
+		}
+	}
+
+	unlockOnce()
+	c.Supervisor.Enqueue(model.LockResourceTypeCluster, clusterID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, cluster)
+}
+
 // handleDeleteCluster responds to DELETE /api/cluster/{cluster}, beginning the process of
 // deleting the cluster.
 func handleDeleteCluster(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -454,6 +557,7 @@ func handleDeleteCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 			Timestamp: time.Now().UnixNano(),
 		}
 		cluster.State = newState
+		cluster.ResetAttempts()
 
 		err := c.Store.UpdateCluster(cluster)
 		if err != nil {
@@ -469,7 +573,7 @@ func handleDeleteCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	unlockOnce()
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeCluster, clusterID)
 
 	w.WriteHeader(http.StatusAccepted)
 }
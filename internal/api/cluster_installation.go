@@ -14,9 +14,11 @@ func initClusterInstallation(apiRouter *mux.Router, context *Context) {
 	}
 
 	clusterInstallationsRouter := apiRouter.PathPrefix("/cluster_installations").Subrouter()
+	clusterInstallationsRouter.Use(authorize(resourceCluster))
 	clusterInstallationsRouter.Handle("", addContext(handleGetClusterInstallations)).Methods("GET")
 
 	clusterInstallationRouter := apiRouter.PathPrefix("/cluster_installation/{cluster_installation:[A-Za-z0-9]{26}}").Subrouter()
+	clusterInstallationRouter.Use(authorize(resourceCluster))
 	clusterInstallationRouter.Handle("", addContext(handleGetClusterInstallation)).Methods("GET")
 	clusterInstallationRouter.Handle("/config", addContext(handleGetClusterInstallationConfig)).Methods("GET")
 	clusterInstallationRouter.Handle("/config", addContext(handleSetClusterInstallationConfig)).Methods("PUT")
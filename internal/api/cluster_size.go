@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// initClusterSize registers custom cluster size endpoints on the given router.
+func initClusterSize(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	clusterSizesRouter := apiRouter.PathPrefix("/cluster/sizes").Subrouter()
+	clusterSizesRouter.Use(authorize(resourceCluster))
+	clusterSizesRouter.Handle("", addContext(handleGetClusterSizes)).Methods("GET")
+	clusterSizesRouter.Handle("", addContext(handleCreateClusterSize)).Methods("POST")
+	clusterSizesRouter.Handle("/{name}", addContext(handleDeleteClusterSize)).Methods("DELETE")
+}
+
+// handleGetClusterSizes responds to GET /api/cluster/sizes, returning the
+// custom cluster sizes supported in addition to the built-in sizes.
+func handleGetClusterSizes(c *Context, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, model.GetCustomClusterSizes())
+}
+
+// handleCreateClusterSize responds to POST /api/cluster/sizes, recording a new
+// custom cluster size.
+func handleCreateClusterSize(c *Context, w http.ResponseWriter, r *http.Request) {
+	clusterSize, err := model.NewClusterSizeFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.Logger = c.Logger.WithField("cluster-size", clusterSize.Name)
+
+	if model.GetCustomClusterSize(clusterSize.Name) != nil {
+		c.Logger.Error("cluster size already exists")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = c.Store.CreateClusterSize(clusterSize)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to create cluster size")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Other servers pick up the new size the next time they refresh theirs.
+	model.AddCustomClusterSize(clusterSize)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, clusterSize)
+}
+
+// handleDeleteClusterSize responds to DELETE /api/cluster/sizes/{name},
+// removing a custom cluster size that no cluster uses. Sizes defined in the
+// configuration file of a server cannot be deleted.
+func handleDeleteClusterSize(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+	c.Logger = c.Logger.WithField("cluster-size", name)
+
+	clusterSize, err := c.Store.GetClusterSize(name)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query cluster size")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if clusterSize == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	for _, size := range model.ClusterSizeVariants(name) {
+		clusters, err := c.Store.GetClusters(&model.ClusterFilter{
+			Size:    size,
+			PerPage: 1,
+		})
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query clusters")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if len(clusters) != 0 {
+			c.Logger.Errorf("unable to delete cluster size while cluster %s uses it", clusters[0].ID)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+
+	err = c.Store.DeleteClusterSize(name)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to delete cluster size")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	model.RemoveCustomClusterSize(name)
+
+	w.WriteHeader(http.StatusOK)
+}
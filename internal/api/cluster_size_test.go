@@ -0,0 +1,116 @@
+package api_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterSizes(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer model.SetCustomClusterSizes(nil)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("no custom sizes", func(t *testing.T) {
+		clusterSizes, err := client.GetClusterSizes()
+		require.NoError(t, err)
+		require.Empty(t, clusterSizes)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		resp, err := http.Post(fmt.Sprintf("%s/api/cluster/sizes", ts.URL), "application/json", bytes.NewReader([]byte("{")))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("invalid size", func(t *testing.T) {
+		_, err := client.CreateClusterSize(&model.ClusterSize{
+			Name:               model.SizeAlef1000,
+			NodeCount:          2,
+			NodeInstanceType:   "m5.large",
+			MasterInstanceType: "t3.medium",
+		})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("unsupported size before creation", func(t *testing.T) {
+		_, err := client.CreateCluster(&model.CreateClusterRequest{Size: "SizeCustom"})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	clusterSize, err := client.CreateClusterSize(&model.ClusterSize{
+		Name:               "SizeCustom",
+		NodeCount:          4,
+		NodeInstanceType:   "m5.2xlarge",
+		MasterInstanceType: "t3.large",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "SizeCustom", clusterSize.Name)
+	require.Equal(t, int64(1), clusterSize.MasterCount)
+	require.NotZero(t, clusterSize.CreateAt)
+
+	t.Run("duplicate size", func(t *testing.T) {
+		_, err := client.CreateClusterSize(&model.ClusterSize{
+			Name:               "SizeCustom",
+			NodeCount:          2,
+			NodeInstanceType:   "m5.large",
+			MasterInstanceType: "t3.medium",
+		})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("get custom sizes", func(t *testing.T) {
+		clusterSizes, err := client.GetClusterSizes()
+		require.NoError(t, err)
+		require.Equal(t, []*model.ClusterSize{clusterSize}, clusterSizes)
+	})
+
+	t.Run("delete unknown size", func(t *testing.T) {
+		err := client.DeleteClusterSize("SizeUnknown")
+		require.EqualError(t, err, "failed with status code 404")
+	})
+
+	t.Run("delete size in use", func(t *testing.T) {
+		cluster, err := client.CreateCluster(&model.CreateClusterRequest{Size: "SizeCustom-HA3"})
+		require.NoError(t, err)
+		require.Equal(t, "SizeCustom-HA3", cluster.Size)
+
+		err = client.DeleteClusterSize("SizeCustom")
+		require.EqualError(t, err, "failed with status code 403")
+
+		err = sqlStore.DeleteCluster(cluster.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("delete size", func(t *testing.T) {
+		err := client.DeleteClusterSize("SizeCustom")
+		require.NoError(t, err)
+
+		clusterSizes, err := client.GetClusterSizes()
+		require.NoError(t, err)
+		require.Empty(t, clusterSizes)
+
+		_, err = client.CreateCluster(&model.CreateClusterRequest{Size: "SizeCustom"})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+}
@@ -325,6 +325,37 @@ func TestCreateCluster(t *testing.T) {
 		require.Equal(t, model.ClusterStateCreationRequested, cluster.State)
 		// TODO: more fields...
 	})
+
+	t.Run("invalid instance group", func(t *testing.T) {
+		_, err := client.CreateCluster(&model.CreateClusterRequest{
+			Provider:       model.ProviderAWS,
+			Size:           model.SizeAlef500,
+			Zones:          []string{"zone"},
+			InstanceGroups: []model.KopsInstanceGroup{{Name: "nodes", MachineType: "r5.large", MinCount: 1, MaxCount: 1}},
+		})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("with instance groups", func(t *testing.T) {
+		instanceGroups := []model.KopsInstanceGroup{
+			{Name: "memory", MachineType: "r5.large", MinCount: 1, MaxCount: 2},
+			{Name: "spot", MachineType: "m5.large", MinCount: 0, MaxCount: 4, MaxPrice: "0.05"},
+		}
+		cluster, err := client.CreateCluster(&model.CreateClusterRequest{
+			Provider:       model.ProviderAWS,
+			Size:           model.SizeAlef500,
+			Zones:          []string{"zone"},
+			InstanceGroups: instanceGroups,
+		})
+		require.NoError(t, err)
+
+		cluster, err = client.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		kopsMetadata, err := model.NewKopsMetadata(cluster.ProvisionerMetadata)
+		require.NoError(t, err)
+		require.Equal(t, instanceGroups, kopsMetadata.InstanceGroups)
+		require.True(t, cluster.HasNodePool("memory"))
+	})
 }
 
 func TestRetryCreateCluster(t *testing.T) {
@@ -650,6 +681,136 @@ func TestUpgradeCluster(t *testing.T) {
 	})
 }
 
+func TestResizeCluster(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	cluster1, err := client.CreateCluster(&model.CreateClusterRequest{
+		Provider: model.ProviderAWS,
+		Size:     model.SizeAlef500,
+		Zones:    []string{"zone"},
+	})
+	require.NoError(t, err)
+
+	resizeRequest := &model.ResizeClusterRequest{Size: model.SizeAlef1000}
+
+	t.Run("unknown cluster", func(t *testing.T) {
+		_, err := client.ResizeCluster(model.NewID(), resizeRequest)
+		require.EqualError(t, err, "failed with status code 404")
+	})
+
+	t.Run("invalid size", func(t *testing.T) {
+		_, err := client.ResizeCluster(cluster1.ID, &model.ResizeClusterRequest{Size: "invalid"})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("while locked", func(t *testing.T) {
+		cluster1.State = model.ClusterStateStable
+		err = sqlStore.UpdateCluster(cluster1)
+		require.NoError(t, err)
+
+		lockerID := model.NewID()
+
+		locked, err := sqlStore.LockCluster(cluster1.ID, lockerID)
+		require.NoError(t, err)
+		require.True(t, locked)
+		defer func() {
+			unlocked, err := sqlStore.UnlockCluster(cluster1.ID, lockerID, false)
+			require.NoError(t, err)
+			require.True(t, unlocked)
+		}()
+
+		_, err = client.ResizeCluster(cluster1.ID, resizeRequest)
+		require.EqualError(t, err, "failed with status code 409")
+	})
+
+	t.Run("while stable", func(t *testing.T) {
+		cluster1.State = model.ClusterStateStable
+		err = sqlStore.UpdateCluster(cluster1)
+		require.NoError(t, err)
+
+		cluster, err := client.ResizeCluster(cluster1.ID, resizeRequest)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateResizeRequested, cluster.State)
+		require.Equal(t, model.SizeAlef500, cluster.Size)
+
+		cluster1, err = client.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateResizeRequested, cluster1.State)
+		require.Equal(t, model.SizeAlef500, cluster1.Size)
+		kopsMetadata, err := model.NewKopsMetadata(cluster1.ProvisionerMetadata)
+		require.NoError(t, err)
+		require.Equal(t, model.SizeAlef1000, kopsMetadata.ResizeSize)
+	})
+
+	t.Run("after resize failed", func(t *testing.T) {
+		cluster1.State = model.ClusterStateResizeFailed
+		err = sqlStore.UpdateCluster(cluster1)
+		require.NoError(t, err)
+
+		_, err = client.ResizeCluster(cluster1.ID, &model.ResizeClusterRequest{Size: model.SizeAlef5000})
+		require.NoError(t, err)
+
+		cluster1, err = client.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateResizeRequested, cluster1.State)
+		require.Equal(t, model.SizeAlef500, cluster1.Size)
+		kopsMetadata, err := model.NewKopsMetadata(cluster1.ProvisionerMetadata)
+		require.NoError(t, err)
+		require.Equal(t, model.SizeAlef5000, kopsMetadata.ResizeSize)
+	})
+
+	t.Run("with instance groups", func(t *testing.T) {
+		_, err = client.ResizeCluster(cluster1.ID, &model.ResizeClusterRequest{
+			Size:           model.SizeAlef5000,
+			InstanceGroups: []model.KopsInstanceGroup{{Name: "memory", MachineType: "r5.large", MinCount: 1, MaxCount: 2}},
+		})
+		require.NoError(t, err)
+
+		_, err = client.ResizeCluster(cluster1.ID, &model.ResizeClusterRequest{
+			Size:           model.SizeAlef5000,
+			InstanceGroups: []model.KopsInstanceGroup{{Name: "memory", MachineType: "r5.xlarge", MinCount: 2, MaxCount: 2}},
+		})
+		require.NoError(t, err)
+
+		cluster1, err = client.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateResizeRequested, cluster1.State)
+		kopsMetadata, err := model.NewKopsMetadata(cluster1.ProvisionerMetadata)
+		require.NoError(t, err)
+		require.Equal(t, []model.KopsInstanceGroup{{Name: "memory", MachineType: "r5.xlarge", MinCount: 2, MaxCount: 2}}, kopsMetadata.InstanceGroups)
+	})
+
+	t.Run("while upgrading", func(t *testing.T) {
+		cluster1.State = model.ClusterStateUpgradeRequested
+		err = sqlStore.UpdateCluster(cluster1)
+		require.NoError(t, err)
+
+		_, err = client.ResizeCluster(cluster1.ID, resizeRequest)
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("while deleting", func(t *testing.T) {
+		cluster1.State = model.ClusterStateDeletionRequested
+		err = sqlStore.UpdateCluster(cluster1)
+		require.NoError(t, err)
+
+		_, err = client.ResizeCluster(cluster1.ID, resizeRequest)
+		require.EqualError(t, err, "failed with status code 400")
+	})
+}
+
 func TestDeleteCluster(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := store.MakeTestSQLStore(t, logger)
@@ -744,6 +905,25 @@ func TestDeleteCluster(t *testing.T) {
 		}
 	})
 
+	t.Run("resets failed attempts", func(t *testing.T) {
+		cluster1.State = model.ClusterStateDeletionFailed
+		cluster1.Attempts = 3
+		cluster1.LastError = "failed to delete cluster"
+		cluster1.NextAttemptAt = store.GetMillis() + 60000
+		err = sqlStore.UpdateCluster(cluster1)
+		require.NoError(t, err)
+
+		err = client.DeleteCluster(cluster1.ID)
+		require.NoError(t, err)
+
+		cluster1, err = client.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateDeletionRequested, cluster1.State)
+		require.Equal(t, 0, cluster1.Attempts)
+		require.Empty(t, cluster1.LastError)
+		require.Equal(t, int64(0), cluster1.NextAttemptAt)
+	})
+
 	t.Run("from a valid, unlocked state, but not empty of cluster installations", func(t *testing.T) {
 		for _, state := range states {
 			t.Run(state, func(t *testing.T) {
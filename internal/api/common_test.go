@@ -12,9 +12,13 @@ func (s *mockSupervisor) Do() error {
 	return nil
 }
 
+func (s *mockSupervisor) Enqueue(resourceType, resourceID string) {
+}
+
 type mockProvisioner struct {
 	Output       []byte
 	CommandError error
+	Resources    *k8s.ClusterResources
 }
 
 func (s *mockProvisioner) ExecMattermostCLI(*model.Cluster, *model.ClusterInstallation, ...string) ([]byte, error) {
@@ -25,8 +29,8 @@ func (s *mockProvisioner) ExecMattermostCLI(*model.Cluster, *model.ClusterInstal
 	return s.Output, s.CommandError
 }
 
-func (s *mockProvisioner) GetClusterResources(*model.Cluster, bool) (*k8s.ClusterResources, error) {
-	return nil, nil
+func (s *mockProvisioner) GetClusterResources(*model.Cluster, string, bool) (*k8s.ClusterResources, error) {
+	return s.Resources, nil
 }
 
 func sToP(s string) *string {
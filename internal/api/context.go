@@ -8,7 +8,7 @@ import (
 
 // Supervisor describes the interface to notify the background jobs of an actionable change.
 type Supervisor interface {
-	Do() error
+	Enqueue(resourceType, resourceID string)
 }
 
 // Store describes the interface required to persist changes made via API requests.
@@ -21,6 +21,10 @@ type Store interface {
 	UnlockCluster(clusterID, lockerID string, force bool) (bool, error)
 	DeleteCluster(clusterID string) error
 
+	CreateClusterSize(clusterSize *model.ClusterSize) error
+	GetClusterSize(name string) (*model.ClusterSize, error)
+	DeleteClusterSize(name string) error
+
 	CreateInstallation(installation *model.Installation) error
 	GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error)
 	GetInstallations(filter *model.InstallationFilter, includeGroupConfig, includeGroupConfigOverrides bool) ([]*model.Installation, error)
@@ -29,6 +33,10 @@ type Store interface {
 	UnlockInstallation(installationID, lockerID string, force bool) (bool, error)
 	DeleteInstallation(installationID string) error
 
+	CreateInstallationBackup(backup *model.InstallationBackup) error
+	GetInstallationBackup(backupID string) (*model.InstallationBackup, error)
+	GetInstallationBackups(filter *model.InstallationBackupFilter) ([]*model.InstallationBackup, error)
+
 	GetClusterInstallation(clusterInstallationID string) (*model.ClusterInstallation, error)
 	GetClusterInstallations(filter *model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error)
 
@@ -43,13 +51,28 @@ type Store interface {
 	CreateWebhook(webhook *model.Webhook) error
 	GetWebhook(webhookID string) (*model.Webhook, error)
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	GetWebhookDelivery(deliveryID string) (*model.WebhookDelivery, error)
+	GetWebhookDeliveries(filter *model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	UpdateWebhookDelivery(delivery *model.WebhookDelivery) error
 	DeleteWebhook(webhookID string) error
+
+	GetEvents(filter *model.EventFilter) ([]*model.Event, error)
+
+	GetAPIKeyByHash(keyHash string) (*model.APIKey, error)
+	HasActiveAPIKeys() (bool, error)
+
+	CreateAuditRecord(auditRecord *model.AuditRecord) error
+	GetAuditRecords(filter *model.AuditRecordFilter) ([]*model.AuditRecord, error)
+
+	GetLocks(filter *model.LockFilter) ([]*model.Lock, error)
+	UnlockResource(resourceType, resourceID, lockerID string, force bool) (bool, error)
 }
 
 // Provisioner describes the interface required to communicate with the Kubernetes cluster.
 type Provisioner interface {
 	ExecMattermostCLI(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation, args ...string) ([]byte, error)
-	GetClusterResources(*model.Cluster, bool) (*k8s.ClusterResources, error)
+	GetClusterResources(*model.Cluster, string, bool) (*k8s.ClusterResources, error)
 }
 
 // Context provides the API with all necessary data and interfaces for responding to requests.
@@ -61,6 +84,17 @@ type Context struct {
 	Provisioner Provisioner
 	RequestID   string
 	Logger      logrus.FieldLogger
+
+	// RequireAPIKey rejects requests that do not present an API key, even
+	// before any API key was created.
+	RequireAPIKey bool
+	// APIKey is the API key the request was authenticated with, if any.
+	APIKey *model.APIKey
+
+	// ClusterResourceThreshold and SchedulingStrategy mirror the installation
+	// supervisor configuration when explaining scheduling decisions.
+	ClusterResourceThreshold int
+	SchedulingStrategy       string
 }
 
 // Clone creates a shallow copy of context, allowing clones to apply per-request changes.
@@ -70,5 +104,28 @@ func (c *Context) Clone() *Context {
 		Supervisor:  c.Supervisor,
 		Provisioner: c.Provisioner,
 		Logger:      c.Logger,
+
+		RequireAPIKey: c.RequireAPIKey,
+
+		ClusterResourceThreshold: c.ClusterResourceThreshold,
+		SchedulingStrategy:       c.SchedulingStrategy,
+	}
+}
+
+// restrictedOwnerID returns the owner the request is limited to by its API
+// key, or an empty string if the request is not limited to an owner.
+func (c *Context) restrictedOwnerID() string {
+	if c.APIKey == nil {
+		return ""
 	}
+
+	return c.APIKey.OwnerID
+}
+
+// isOwnerAllowed returns whether the request may act on resources of the
+// given owner.
+func (c *Context) isOwnerAllowed(ownerID string) bool {
+	restrictedOwnerID := c.restrictedOwnerID()
+
+	return restrictedOwnerID == "" || restrictedOwnerID == ownerID
 }
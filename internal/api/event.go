@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// initEvent registers event endpoints on the given router.
+func initEvent(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	eventsRouter := apiRouter.PathPrefix("/events").Subrouter()
+	eventsRouter.Use(authorize(resourceEvent))
+	eventsRouter.Handle("", addContext(handleGetEvents)).Methods("GET")
+}
+
+// handleGetEvents responds to GET /api/events, returning the specified page of
+// state change events.
+func handleGetEvents(c *Context, w http.ResponseWriter, r *http.Request) {
+	resourceType := r.URL.Query().Get("resource_type")
+	resourceID := r.URL.Query().Get("resource_id")
+
+	page, perPage, _, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	filter := &model.EventFilter{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Page:         page,
+		PerPage:      perPage,
+	}
+
+	events, err := c.Store.GetEvents(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query events")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if events == nil {
+		events = []*model.Event{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, events)
+}
@@ -0,0 +1,109 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEvents(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("invalid paging", func(t *testing.T) {
+		resp, err := http.Get(fmt.Sprintf("%s/api/events?page=invalid", ts.URL))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("no events", func(t *testing.T) {
+		events, err := client.GetEvents(&model.GetEventsRequest{
+			Page:    0,
+			PerPage: 10,
+		})
+		require.NoError(t, err)
+		require.Empty(t, events)
+	})
+
+	event1 := &model.Event{
+		ResourceType: model.TypeCluster,
+		ResourceID:   model.NewID(),
+		OldState:     model.ClusterStateCreationRequested,
+		NewState:     model.ClusterStateStable,
+		Timestamp:    1,
+		InstanceID:   "instance",
+	}
+	err := sqlStore.CreateEvent(event1)
+	require.NoError(t, err)
+
+	event2 := &model.Event{
+		ResourceType: model.TypeInstallation,
+		ResourceID:   model.NewID(),
+		OldState:     model.InstallationStateCreationRequested,
+		NewState:     model.InstallationStateCreationFailed,
+		Timestamp:    2,
+		InstanceID:   "instance",
+		ErrorMessage: "failed",
+	}
+	err = sqlStore.CreateEvent(event2)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Description string
+		Request     *model.GetEventsRequest
+		Expected    []*model.Event
+	}{
+		{
+			"all",
+			&model.GetEventsRequest{Page: 0, PerPage: 10},
+			[]*model.Event{event1, event2},
+		},
+		{
+			"paged",
+			&model.GetEventsRequest{Page: 1, PerPage: 1},
+			[]*model.Event{event2},
+		},
+		{
+			"resource type",
+			&model.GetEventsRequest{ResourceType: model.TypeCluster, Page: 0, PerPage: 10},
+			[]*model.Event{event1},
+		},
+		{
+			"resource id",
+			&model.GetEventsRequest{ResourceID: event2.ResourceID, Page: 0, PerPage: 10},
+			[]*model.Event{event2},
+		},
+		{
+			"unknown resource id",
+			&model.GetEventsRequest{ResourceID: model.NewID(), Page: 0, PerPage: 10},
+			[]*model.Event{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Description, func(t *testing.T) {
+			events, err := client.GetEvents(testCase.Request)
+			require.NoError(t, err)
+			require.Equal(t, testCase.Expected, events)
+		})
+	}
+}
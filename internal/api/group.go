@@ -14,10 +14,12 @@ func initGroup(apiRouter *mux.Router, context *Context) {
 	}
 
 	groupsRouter := apiRouter.PathPrefix("/groups").Subrouter()
+	groupsRouter.Use(authorize(resourceGroup))
 	groupsRouter.Handle("", addContext(handleGetGroups)).Methods("GET")
 	groupsRouter.Handle("", addContext(handleCreateGroup)).Methods("POST")
 
 	groupRouter := apiRouter.PathPrefix("/group/{group:[A-Za-z0-9]{26}}").Subrouter()
+	groupRouter.Use(authorize(resourceGroup))
 	groupRouter.Handle("", addContext(handleGetGroup)).Methods("GET")
 	groupRouter.Handle("", addContext(handleUpdateGroup)).Methods("PUT")
 	groupRouter.Handle("", addContext(handleDeleteGroup)).Methods("DELETE")
@@ -55,6 +57,9 @@ func handleGetGroups(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	filter := &model.GroupFilter{
+		Name:           r.URL.Query().Get("name"),
+		Version:        r.URL.Query().Get("version"),
+		Image:          r.URL.Query().Get("image"),
 		Page:           page,
 		PerPage:        perPage,
 		IncludeDeleted: includeDeleted,
@@ -100,7 +105,7 @@ func handleCreateGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeGroup, group.ID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -136,7 +141,7 @@ func handleUpdateGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeGroup, groupID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -182,7 +187,7 @@ func handleDeleteGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeGroup, groupID)
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -182,6 +182,27 @@ func TestGetGroups(t *testing.T) {
 					},
 					[]*model.Group{group3, group4},
 				},
+
+				{
+					"filter by name",
+					&model.GetGroupsRequest{
+						Name:    "GROUP2",
+						Page:    0,
+						PerPage: 10,
+					},
+					[]*model.Group{group2},
+				},
+
+				{
+					"filter by version, include deleted",
+					&model.GetGroupsRequest{
+						Version:        "version4",
+						Page:           0,
+						PerPage:        10,
+						IncludeDeleted: true,
+					},
+					[]*model.Group{group4},
+				},
 			}
 
 			for _, testCase := range testCases {
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"bytes"
 	"net/http"
 
+	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-cloud/model"
 	log "github.com/sirupsen/logrus"
 )
@@ -16,7 +18,11 @@ type contextHandler struct {
 
 func (h contextHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	context := h.context.Clone()
-	context.RequestID = model.NewID()
+	context.RequestID = requestIDFromRequest(r)
+	if context.RequestID == "" {
+		context.RequestID = model.NewID()
+	}
+	context.APIKey = apiKeyFromRequest(r)
 	context.Logger = context.Logger.WithFields(log.Fields{
 		"path":    r.URL.Path,
 		"request": context.RequestID,
@@ -31,3 +37,53 @@ func newContextHandler(context *Context, handler contextHandlerFunc) *contextHan
 		handler: handler,
 	}
 }
+
+// responseWriter records the status code of a response and, when body is
+// set, the start of the response body.
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       *bytes.Buffer
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	if w.body != nil && w.body.Len() < maxAuditBodySize {
+		w.body.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// StatusCode returns the status code of the response written so far.
+func (w *responseWriter) StatusCode() int {
+	if w.statusCode == 0 {
+		return http.StatusOK
+	}
+
+	return w.statusCode
+}
+
+// routeTemplate returns the path template of the route matched by the request.
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return r.URL.Path
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return r.URL.Path
+	}
+
+	return template
+}
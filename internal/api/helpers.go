@@ -35,6 +35,20 @@ func parseBool(u *url.URL, name string, defaultValue bool) (bool, error) {
 	return value, nil
 }
 
+func parseOptionalBool(u *url.URL, name string) (*bool, error) {
+	valueStr := u.Query().Get(name)
+	if valueStr == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s as boolean", name)
+	}
+
+	return &value, nil
+}
+
 func parsePaging(u *url.URL) (int, int, bool, error) {
 	page, err := parseInt(u, "page", 0)
 	if err != nil {
@@ -5,8 +5,10 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/scheduling"
 	"github.com/mattermost/mattermost-cloud/internal/webhook"
 	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
 )
 
 // initInstallation registers installation endpoints on the given router.
@@ -15,14 +17,30 @@ func initInstallation(apiRouter *mux.Router, context *Context) {
 		return newContextHandler(context, handler)
 	}
 
+	// Scheduling explains the state and load of every cluster, so it is
+	// authorized against clusters rather than installations.
+	scheduleRouter := apiRouter.PathPrefix("/installations/schedule").Subrouter()
+	scheduleRouter.Use(authorize(resourceCluster))
+	scheduleRouter.Handle("", addContext(handleScheduleInstallation)).Methods("POST")
+
 	installationsRouter := apiRouter.PathPrefix("/installations").Subrouter()
+	installationsRouter.Use(authorize(resourceInstallation))
 	installationsRouter.Handle("", addContext(handleGetInstallations)).Methods("GET")
 	installationsRouter.Handle("", addContext(handleCreateInstallation)).Methods("POST")
 
 	installationRouter := apiRouter.PathPrefix("/installation/{installation:[A-Za-z0-9]{26}}").Subrouter()
+	installationRouter.Use(authorize(resourceInstallation))
+	installationRouter.Use(authorizeInstallationOwner(context))
 	installationRouter.Handle("", addContext(handleGetInstallation)).Methods("GET")
 	installationRouter.Handle("", addContext(handleRetryCreateInstallation)).Methods("POST")
 	installationRouter.Handle("/mattermost", addContext(handleUpdateInstallation)).Methods("PUT")
+	installationRouter.Handle("/hibernate", addContext(handleHibernateInstallation)).Methods("POST")
+	installationRouter.Handle("/wakeup", addContext(handleWakeupInstallation)).Methods("POST")
+	installationRouter.Handle("/migrate", addContext(handleMigrateInstallation)).Methods("POST")
+	installationRouter.Handle("/backups", addContext(handleGetInstallationBackups)).Methods("GET")
+	installationRouter.Handle("/backups", addContext(handleCreateInstallationBackup)).Methods("POST")
+	installationRouter.Handle("/backup/{backup:[A-Za-z0-9]{26}}", addContext(handleGetInstallationBackup)).Methods("GET")
+	installationRouter.Handle("/backup/{backup:[A-Za-z0-9]{26}}/restore", addContext(handleRestoreInstallationBackup)).Methods("POST")
 	installationRouter.Handle("/group/{group}", addContext(handleJoinGroup)).Methods("PUT")
 	installationRouter.Handle("/group", addContext(handleLeaveGroup)).Methods("DELETE")
 	installationRouter.Handle("", addContext(handleDeleteInstallation)).Methods("DELETE")
@@ -62,6 +80,22 @@ func handleGetInstallations(c *Context, w http.ResponseWriter, r *http.Request)
 	var err error
 	owner := r.URL.Query().Get("owner")
 	group := r.URL.Query().Get("group")
+	states := r.URL.Query()["state"]
+	dns := r.URL.Query().Get("dns")
+	version := r.URL.Query().Get("version")
+	database := r.URL.Query().Get("database")
+	filestore := r.URL.Query().Get("filestore")
+	affinity := r.URL.Query().Get("affinity")
+	size := r.URL.Query().Get("size")
+
+	if owner == "" {
+		owner = c.restrictedOwnerID()
+	}
+	if !c.isOwnerAllowed(owner) {
+		c.Logger.Warnf("API key may not list resources of owner %s", owner)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
 
 	page, perPage, includeDeleted, err := parsePaging(r.URL)
 	if err != nil {
@@ -80,6 +114,13 @@ func handleGetInstallations(c *Context, w http.ResponseWriter, r *http.Request)
 	filter := &model.InstallationFilter{
 		OwnerID:        owner,
 		GroupID:        group,
+		States:         states,
+		DNS:            dns,
+		Version:        version,
+		Database:       database,
+		Filestore:      filestore,
+		Affinity:       affinity,
+		Size:           size,
 		Page:           page,
 		PerPage:        perPage,
 		IncludeDeleted: includeDeleted,
@@ -110,6 +151,12 @@ func handleCreateInstallation(c *Context, w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if !c.isOwnerAllowed(createInstallationRequest.OwnerID) {
+		c.Logger.Warnf("API key may not create resources for owner %s", createInstallationRequest.OwnerID)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
 	if len(createInstallationRequest.GroupID) != 0 {
 		group, err := c.Store.GetGroup(createInstallationRequest.GroupID)
 		if err != nil {
@@ -124,21 +171,30 @@ func handleCreateInstallation(c *Context, w http.ResponseWriter, r *http.Request
 	}
 
 	installation := model.Installation{
-		OwnerID:       createInstallationRequest.OwnerID,
-		GroupID:       &createInstallationRequest.GroupID,
-		Version:       createInstallationRequest.Version,
-		Image:         createInstallationRequest.Image,
-		DNS:           createInstallationRequest.DNS,
-		Database:      createInstallationRequest.Database,
-		Filestore:     createInstallationRequest.Filestore,
-		License:       createInstallationRequest.License,
-		Size:          createInstallationRequest.Size,
-		Affinity:      createInstallationRequest.Affinity,
-		MattermostEnv: createInstallationRequest.MattermostEnv,
-		State:         model.InstallationStateCreationRequested,
+		OwnerID:            createInstallationRequest.OwnerID,
+		GroupID:            &createInstallationRequest.GroupID,
+		Version:            createInstallationRequest.Version,
+		Image:              createInstallationRequest.Image,
+		DNS:                createInstallationRequest.DNS,
+		DNSAliases:         createInstallationRequest.DNSAliases,
+		Database:           createInstallationRequest.Database,
+		Filestore:          createInstallationRequest.Filestore,
+		License:            createInstallationRequest.License,
+		Size:               createInstallationRequest.Size,
+		Affinity:           createInstallationRequest.Affinity,
+		NodePool:           createInstallationRequest.NodePool,
+		Zone:               createInstallationRequest.Zone,
+		SchedulingStrategy: createInstallationRequest.SchedulingStrategy,
+		MattermostEnv:      createInstallationRequest.MattermostEnv,
+		State:              model.InstallationStateCreationRequested,
 	}
 
 	err = c.Store.CreateInstallation(&installation)
+	if errors.Cause(err) == model.ErrInstallationDNSInUse {
+		c.Logger.WithError(err).Error("failed to create installation")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to create installation")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -151,19 +207,55 @@ func handleCreateInstallation(c *Context, w http.ResponseWriter, r *http.Request
 		NewState:  model.InstallationStateCreationRequested,
 		OldState:  "n/a",
 		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"OwnerID": installation.OwnerID},
 	}
 	err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 	if err != nil {
 		c.Logger.WithError(err).Error("Unable to process and send webhooks")
 	}
 
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeInstallation, installation.ID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
 	outputJSON(c, w, installation)
 }
 
+// handleScheduleInstallation responds to POST /api/installations/schedule,
+// explaining which cluster an installation with the given requirements would
+// be scheduled onto and why each of the other clusters was passed over. No
+// installation is created.
+func handleScheduleInstallation(c *Context, w http.ResponseWriter, r *http.Request) {
+	scheduleInstallationRequest, err := model.NewScheduleInstallationRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	clusters, err := c.Store.GetClusters(&model.ClusterFilter{
+		PerPage:        model.AllPerPage,
+		IncludeDeleted: false,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query clusters")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	scheduler := scheduling.NewScheduler(c.Store, c.Provisioner, c.ClusterResourceThreshold, c.SchedulingStrategy, c.Logger)
+	decision, err := scheduler.Schedule(scheduleInstallationRequest.Installation(), clusters)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to schedule installation")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, decision)
+}
+
 // handleRetryCreateInstallation responds to POST /api/installation/{installation}, retrying a
 // previously failed creation.
 //
@@ -196,8 +288,10 @@ func handleRetryCreateInstallation(c *Context, w http.ResponseWriter, r *http.Re
 			NewState:  newState,
 			OldState:  installation.State,
 			Timestamp: time.Now().UnixNano(),
+			ExtraData: map[string]string{"OwnerID": installation.OwnerID},
 		}
 		installation.State = newState
+		installation.ResetAttempts()
 
 		err := c.Store.UpdateInstallation(installation)
 		if err != nil {
@@ -214,7 +308,7 @@ func handleRetryCreateInstallation(c *Context, w http.ResponseWriter, r *http.Re
 
 	// Notify even if we didn't make changes, to expedite even the no-op operations above.
 	unlockOnce()
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeInstallation, installationID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
@@ -252,8 +346,14 @@ func handleUpdateInstallation(c *Context, w http.ResponseWriter, r *http.Request
 
 	if patchInstallationRequest.Apply(installation) {
 		installation.State = newState
+		installation.ResetAttempts()
 
 		err = c.Store.UpdateInstallation(installation)
+		if errors.Cause(err) == model.ErrInstallationDNSInUse {
+			c.Logger.WithError(err).Error("failed to update installation")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			c.Logger.WithError(err).Error("failed to update installation")
 			w.WriteHeader(http.StatusInternalServerError)
@@ -266,7 +366,118 @@ func handleUpdateInstallation(c *Context, w http.ResponseWriter, r *http.Request
 			NewState:  newState,
 			OldState:  installation.State,
 			Timestamp: time.Now().UnixNano(),
+			ExtraData: map[string]string{"OwnerID": installation.OwnerID},
+		}
+		err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+		if err != nil {
+			c.Logger.WithError(err).Error("Unable to process and send webhooks")
+		}
+	}
+
+	unlockOnce()
+	c.Supervisor.Enqueue(model.LockResourceTypeInstallation, installationID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, installation)
+}
+
+// handleHibernateInstallation responds to POST /api/installation/{installation}/hibernate,
+// beginning the process of putting the installation into hibernation.
+func handleHibernateInstallation(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	installation, status, unlockOnce := lockInstallation(c, installationID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	newState := model.InstallationStateHibernationRequested
+
+	if !installation.ValidTransitionState(newState) {
+		c.Logger.Warnf("unable to hibernate installation while in state %s", installation.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if installation.State != newState {
+		webhookPayload := &model.WebhookPayload{
+			Type:      model.TypeInstallation,
+			ID:        installation.ID,
+			NewState:  newState,
+			OldState:  installation.State,
+			Timestamp: time.Now().UnixNano(),
+			ExtraData: map[string]string{"OwnerID": installation.OwnerID},
+		}
+		installation.State = newState
+		installation.ResetAttempts()
+
+		err := c.Store.UpdateInstallation(installation)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to mark installation for hibernation")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+		if err != nil {
+			c.Logger.WithError(err).Error("Unable to process and send webhooks")
+		}
+	}
+
+	unlockOnce()
+	c.Supervisor.Enqueue(model.LockResourceTypeInstallation, installationID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, installation)
+}
+
+// handleWakeupInstallation responds to POST /api/installation/{installation}/wakeup,
+// beginning the process of waking up a hibernating installation.
+func handleWakeupInstallation(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	installation, status, unlockOnce := lockInstallation(c, installationID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	newState := model.InstallationStateWakeUpRequested
+
+	if !installation.ValidTransitionState(newState) {
+		c.Logger.Warnf("unable to wake up installation while in state %s", installation.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if installation.State != newState {
+		webhookPayload := &model.WebhookPayload{
+			Type:      model.TypeInstallation,
+			ID:        installation.ID,
+			NewState:  newState,
+			OldState:  installation.State,
+			Timestamp: time.Now().UnixNano(),
+			ExtraData: map[string]string{"OwnerID": installation.OwnerID},
+		}
+		installation.State = newState
+		installation.ResetAttempts()
+
+		err := c.Store.UpdateInstallation(installation)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to mark installation for wake up")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
 		}
+
 		err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
 		if err != nil {
 			c.Logger.WithError(err).Error("Unable to process and send webhooks")
@@ -274,7 +485,117 @@ func handleUpdateInstallation(c *Context, w http.ResponseWriter, r *http.Request
 	}
 
 	unlockOnce()
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeInstallation, installationID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, installation)
+}
+
+// handleMigrateInstallation responds to POST /api/installation/{installation}/migrate,
+// beginning the process of moving the installation to the requested cluster.
+func handleMigrateInstallation(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	migrateInstallationRequest, err := model.NewMigrateInstallationRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	installation, status, unlockOnce := lockInstallation(c, installationID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	newState := model.InstallationStateMigrationRequested
+
+	if !installation.ValidTransitionState(newState) {
+		c.Logger.Warnf("unable to migrate installation while in state %s", installation.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !installation.IsMigrationSupported() {
+		c.Logger.Warnf("unable to migrate installation with database %s and filestore %s", installation.Database, installation.Filestore)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	targetCluster, err := c.Store.GetCluster(migrateInstallationRequest.ClusterID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query target cluster")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if targetCluster == nil {
+		c.Logger.Warnf("target cluster %s not found", migrateInstallationRequest.ClusterID)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if targetCluster.State != model.ClusterStateStable || !targetCluster.AllowInstallations {
+		c.Logger.Warnf("target cluster %s is not accepting installations", targetCluster.ID)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !targetCluster.HasNodePool(installation.NodePool) {
+		c.Logger.Warnf("target cluster %s has no node pool %s", targetCluster.ID, installation.NodePool)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !targetCluster.InZone(installation.Zone) {
+		c.Logger.Warnf("target cluster %s is not in zone %s", targetCluster.ID, installation.Zone)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	clusterInstallations, err := c.Store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		InstallationID: installation.ID,
+		ClusterID:      targetCluster.ID,
+		PerPage:        model.AllPerPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query cluster installations")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if len(clusterInstallations) > 0 {
+		c.Logger.Warnf("installation is already running on target cluster %s", targetCluster.ID)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallation,
+		ID:        installation.ID,
+		NewState:  newState,
+		OldState:  installation.State,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"OwnerID": installation.OwnerID},
+	}
+	installation.State = newState
+	installation.ResetAttempts()
+	installation.MigrationTargetClusterID = &targetCluster.ID
+
+	err = c.Store.UpdateInstallation(installation)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to mark installation for migration")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	unlockOnce()
+	c.Supervisor.Enqueue(model.LockResourceTypeInstallation, installationID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
@@ -320,7 +641,7 @@ func handleJoinGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	unlockOnce()
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeInstallation, installationID)
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -357,6 +678,7 @@ func handleLeaveGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	if installation.GroupID != nil {
 		installation.State = newState
+		installation.ResetAttempts()
 		installation.GroupID = nil
 		installation.GroupSequence = nil
 
@@ -386,7 +708,7 @@ func handleLeaveGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	unlockOnce()
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeInstallation, installationID)
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -420,8 +742,10 @@ func handleDeleteInstallation(c *Context, w http.ResponseWriter, r *http.Request
 			NewState:  newState,
 			OldState:  installation.State,
 			Timestamp: time.Now().UnixNano(),
+			ExtraData: map[string]string{"OwnerID": installation.OwnerID},
 		}
 		installation.State = newState
+		installation.ResetAttempts()
 
 		err := c.Store.UpdateInstallation(installation)
 		if err != nil {
@@ -437,7 +761,7 @@ func handleDeleteInstallation(c *Context, w http.ResponseWriter, r *http.Request
 	}
 
 	unlockOnce()
-	c.Supervisor.Do()
+	c.Supervisor.Enqueue(model.LockResourceTypeInstallation, installationID)
 
 	w.WriteHeader(http.StatusAccepted)
 }
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/webhook"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+// handleCreateInstallationBackup responds to POST /api/installation/{installation}/backups,
+// requesting a new backup of the installation's database and filestore.
+func handleCreateInstallationBackup(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	installation, status, unlockOnce := lockInstallation(c, installationID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if !installation.IsBackupSupported() {
+		c.Logger.Warnf("backups are not supported for installations with database %s and filestore %s", installation.Database, installation.Filestore)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !installation.IsBackupAllowed() {
+		c.Logger.Warnf("unable to backup installation while in state %s", installation.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	backup := model.InstallationBackup{
+		InstallationID: installation.ID,
+		State:          model.InstallationBackupStateBackupRequested,
+	}
+
+	err := c.Store.CreateInstallationBackup(&backup)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to create installation backup")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallationBackup,
+		ID:        backup.ID,
+		NewState:  model.InstallationBackupStateBackupRequested,
+		OldState:  "n/a",
+		Timestamp: time.Now().UnixNano(),
+	}
+	err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	unlockOnce()
+	c.Supervisor.Enqueue(model.LockResourceTypeInstallationBackup, backup.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, backup)
+}
+
+// handleGetInstallationBackups responds to GET /api/installation/{installation}/backups,
+// returning the specified page of backups of the installation.
+func handleGetInstallationBackups(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	page, perPage, includeDeleted, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	filter := &model.InstallationBackupFilter{
+		InstallationID: installationID,
+		Page:           page,
+		PerPage:        perPage,
+		IncludeDeleted: includeDeleted,
+	}
+
+	backups, err := c.Store.GetInstallationBackups(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation backups")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if backups == nil {
+		backups = []*model.InstallationBackup{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, backups)
+}
+
+// handleGetInstallationBackup responds to GET /api/installation/{installation}/backup/{backup},
+// returning the installation backup in question.
+func handleGetInstallationBackup(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	backupID := vars["backup"]
+	c.Logger = c.Logger.WithField("installation", installationID).WithField("backup", backupID)
+
+	backup, err := c.Store.GetInstallationBackup(backupID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation backup")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if backup == nil || backup.InstallationID != installationID {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, backup)
+}
+
+// handleRestoreInstallationBackup responds to POST /api/installation/{installation}/backup/{backup}/restore,
+// creating a new installation from the given installation backup.
+func handleRestoreInstallationBackup(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	backupID := vars["backup"]
+	c.Logger = c.Logger.WithField("installation", installationID).WithField("backup", backupID)
+
+	restoreRequest, err := model.NewRestoreInstallationBackupRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !c.isOwnerAllowed(restoreRequest.OwnerID) {
+		c.Logger.Warnf("API key may not create resources for owner %s", restoreRequest.OwnerID)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	backup, err := c.Store.GetInstallationBackup(backupID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation backup")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if backup == nil || backup.InstallationID != installationID {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if !backup.IsRestorable() {
+		c.Logger.Warnf("unable to restore installation backup while in state %s", backup.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// The restored installation copies the effective configuration of the
+	// source installation, but never joins its group.
+	source, err := c.Store.GetInstallation(installationID, true, false)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if source == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	installation := model.Installation{
+		OwnerID:              restoreRequest.OwnerID,
+		Version:              source.Version,
+		Image:                source.Image,
+		DNS:                  restoreRequest.DNS,
+		Database:             source.Database,
+		Filestore:            source.Filestore,
+		License:              source.License,
+		Size:                 source.Size,
+		Affinity:             source.Affinity,
+		NodePool:             source.NodePool,
+		Zone:                 source.Zone,
+		SchedulingStrategy:   source.SchedulingStrategy,
+		MattermostEnv:        source.MattermostEnv,
+		RestoredFromBackupID: &backup.ID,
+		State:                model.InstallationStateCreationRequested,
+	}
+
+	err = c.Store.CreateInstallation(&installation)
+	if errors.Cause(err) == model.ErrInstallationDNSInUse {
+		c.Logger.WithError(err).Error("failed to create installation")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to create installation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallation,
+		ID:        installation.ID,
+		NewState:  model.InstallationStateCreationRequested,
+		OldState:  "n/a",
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"OwnerID": installation.OwnerID},
+	}
+	err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	c.Supervisor.Enqueue(model.LockResourceTypeInstallation, installation.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, installation)
+}
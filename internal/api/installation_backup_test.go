@@ -0,0 +1,179 @@
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationBackups(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	operatorInstallation, err := client.CreateInstallation(&model.CreateInstallationRequest{
+		OwnerID: "owner",
+		DNS:     "operator.example.com",
+	})
+	require.NoError(t, err)
+
+	awsInstallation, err := client.CreateInstallation(&model.CreateInstallationRequest{
+		OwnerID:   "owner",
+		Version:   "version",
+		DNS:       "aws.example.com",
+		Size:      "1000users",
+		Database:  model.InstallationDatabaseAwsRDS,
+		Filestore: model.InstallationFilestoreAwsS3,
+		MattermostEnv: model.EnvVarMap{
+			"KEY": model.EnvVar{Value: "value"},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("unknown installation", func(t *testing.T) {
+		backup, err := client.CreateInstallationBackup(model.NewID())
+		require.EqualError(t, err, "failed with status code 404")
+		require.Nil(t, backup)
+	})
+
+	t.Run("unsupported database and filestore", func(t *testing.T) {
+		operatorInstallation.State = model.InstallationStateStable
+		err = sqlStore.UpdateInstallation(operatorInstallation)
+		require.NoError(t, err)
+
+		backup, err := client.CreateInstallationBackup(operatorInstallation.ID)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, backup)
+	})
+
+	t.Run("while creating", func(t *testing.T) {
+		backup, err := client.CreateInstallationBackup(awsInstallation.ID)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, backup)
+	})
+
+	t.Run("while locked", func(t *testing.T) {
+		awsInstallation.State = model.InstallationStateStable
+		err = sqlStore.UpdateInstallation(awsInstallation)
+		require.NoError(t, err)
+
+		lockerID := model.NewID()
+
+		locked, err := sqlStore.LockInstallation(awsInstallation.ID, lockerID)
+		require.NoError(t, err)
+		require.True(t, locked)
+		defer func() {
+			unlocked, err := sqlStore.UnlockInstallation(awsInstallation.ID, lockerID, false)
+			require.NoError(t, err)
+			require.True(t, unlocked)
+		}()
+
+		backup, err := client.CreateInstallationBackup(awsInstallation.ID)
+		require.EqualError(t, err, "failed with status code 409")
+		require.Nil(t, backup)
+	})
+
+	var backup *model.InstallationBackup
+
+	t.Run("while stable", func(t *testing.T) {
+		backup, err = client.CreateInstallationBackup(awsInstallation.ID)
+		require.NoError(t, err)
+		require.Equal(t, awsInstallation.ID, backup.InstallationID)
+		require.Equal(t, model.InstallationBackupStateBackupRequested, backup.State)
+	})
+
+	t.Run("get backup", func(t *testing.T) {
+		fetchedBackup, err := client.GetInstallationBackup(awsInstallation.ID, backup.ID)
+		require.NoError(t, err)
+		require.Equal(t, backup, fetchedBackup)
+
+		fetchedBackup, err = client.GetInstallationBackup(operatorInstallation.ID, backup.ID)
+		require.NoError(t, err)
+		require.Nil(t, fetchedBackup)
+
+		fetchedBackup, err = client.GetInstallationBackup(awsInstallation.ID, model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, fetchedBackup)
+	})
+
+	t.Run("list backups", func(t *testing.T) {
+		backups, err := client.GetInstallationBackups(awsInstallation.ID, &model.GetInstallationBackupsRequest{
+			Page:    0,
+			PerPage: 10,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationBackup{backup}, backups)
+
+		backups, err = client.GetInstallationBackups(operatorInstallation.ID, &model.GetInstallationBackupsRequest{
+			Page:    0,
+			PerPage: 10,
+		})
+		require.NoError(t, err)
+		require.Empty(t, backups)
+	})
+
+	restoreRequest := &model.RestoreInstallationBackupRequest{
+		OwnerID: "owner2",
+		DNS:     "restored.example.com",
+	}
+
+	t.Run("restore invalid request", func(t *testing.T) {
+		installation, err := client.RestoreInstallationBackup(awsInstallation.ID, backup.ID, &model.RestoreInstallationBackupRequest{
+			OwnerID: "owner2",
+		})
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installation)
+	})
+
+	t.Run("restore unknown backup", func(t *testing.T) {
+		installation, err := client.RestoreInstallationBackup(awsInstallation.ID, model.NewID(), restoreRequest)
+		require.EqualError(t, err, "failed with status code 404")
+		require.Nil(t, installation)
+	})
+
+	t.Run("restore incomplete backup", func(t *testing.T) {
+		installation, err := client.RestoreInstallationBackup(awsInstallation.ID, backup.ID, restoreRequest)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installation)
+	})
+
+	t.Run("restore succeeded backup", func(t *testing.T) {
+		backup.State = model.InstallationBackupStateBackupSucceeded
+		err = sqlStore.UpdateInstallationBackup(backup)
+		require.NoError(t, err)
+
+		installation, err := client.RestoreInstallationBackup(awsInstallation.ID, backup.ID, restoreRequest)
+		require.NoError(t, err)
+		require.NotEqual(t, awsInstallation.ID, installation.ID)
+		require.Equal(t, "owner2", installation.OwnerID)
+		require.Equal(t, "restored.example.com", installation.DNS)
+		require.Equal(t, awsInstallation.Version, installation.Version)
+		require.Equal(t, awsInstallation.Size, installation.Size)
+		require.Equal(t, awsInstallation.Database, installation.Database)
+		require.Equal(t, awsInstallation.Filestore, installation.Filestore)
+		require.Equal(t, awsInstallation.MattermostEnv, installation.MattermostEnv)
+		require.Equal(t, model.InstallationStateCreationRequested, installation.State)
+		require.NotNil(t, installation.RestoredFromBackupID)
+		require.Equal(t, backup.ID, *installation.RestoredFromBackupID)
+
+		installation, err = client.GetInstallation(installation.ID, nil)
+		require.NoError(t, err)
+		require.Equal(t, backup.ID, *installation.RestoredFromBackupID)
+	})
+}
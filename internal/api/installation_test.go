@@ -12,6 +12,7 @@ import (
 	"github.com/mattermost/mattermost-cloud/internal/api"
 	"github.com/mattermost/mattermost-cloud/internal/store"
 	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/internal/tools/k8s"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/stretchr/testify/require"
 )
@@ -175,6 +176,28 @@ func TestGetInstallations(t *testing.T) {
 				require.NoError(t, err)
 				require.Equal(t, installation4, installation)
 			})
+
+			t.Run("failing transition attempts", func(t *testing.T) {
+				installation, err := sqlStore.GetInstallation(installation3.ID, false, false)
+				require.NoError(t, err)
+				installation.Attempts = 2
+				installation.LastError = "failed to create DNS record"
+				installation.NextAttemptAt = store.GetMillis() + 60000
+				err = sqlStore.UpdateInstallationAttempts(installation)
+				require.NoError(t, err)
+
+				fetched, err := client.GetInstallation(installation3.ID, nil)
+				require.NoError(t, err)
+				require.Equal(t, 2, fetched.Attempts)
+				require.Equal(t, "failed to create DNS record", fetched.LastError)
+				require.Equal(t, installation.NextAttemptAt, fetched.NextAttemptAt)
+
+				installation.Attempts = 0
+				installation.LastError = ""
+				installation.NextAttemptAt = 0
+				err = sqlStore.UpdateInstallationAttempts(installation)
+				require.NoError(t, err)
+			})
 		})
 
 		t.Run("get installations", func(t *testing.T) {
@@ -232,6 +255,27 @@ func TestGetInstallations(t *testing.T) {
 					},
 					[]*model.Installation{installation1, installation3},
 				},
+				{
+					"filter by dns",
+					&model.GetInstallationsRequest{
+						Page:           0,
+						PerPage:        100,
+						DNS:            "dns2",
+						IncludeDeleted: false,
+					},
+					[]*model.Installation{installation2},
+				},
+				{
+					"filter by size and version",
+					&model.GetInstallationsRequest{
+						Page:           0,
+						PerPage:        100,
+						Size:           "1000users",
+						Version:        "version",
+						IncludeDeleted: true,
+					},
+					[]*model.Installation{installation1},
+				},
 			}
 
 			for _, testCase := range testCases {
@@ -373,6 +417,93 @@ func TestCreateInstallation(t *testing.T) {
 	})
 }
 
+func TestScheduleInstallation(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Provisioner: &mockProvisioner{Resources: &k8s.ClusterResources{
+			MilliTotalCPU:    100000000,
+			MilliUsedCPU:     10000000,
+			MilliTotalMemory: 100000000000000000,
+			MilliUsedMemory:  10000000000000000,
+		}},
+		Logger:                   logger,
+		ClusterResourceThreshold: 80,
+		SchedulingStrategy:       model.SchedulingStrategyFirstFit,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("invalid payload", func(t *testing.T) {
+		resp, err := http.Post(fmt.Sprintf("%s/api/installations/schedule", ts.URL), "application/json", bytes.NewReader([]byte("invalid")))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("invalid zone", func(t *testing.T) {
+		_, err := client.ScheduleInstallation(&model.ScheduleInstallationRequest{Zone: "east"})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("no clusters", func(t *testing.T) {
+		decision, err := client.ScheduleInstallation(&model.ScheduleInstallationRequest{})
+		require.NoError(t, err)
+		require.Equal(t, model.SchedulingStrategyFirstFit, decision.Strategy)
+		require.Empty(t, decision.ClusterID)
+		require.Empty(t, decision.Candidates)
+	})
+
+	cluster1 := &model.Cluster{
+		State:              model.ClusterStateStable,
+		AllowInstallations: true,
+	}
+	err := cluster1.SetProviderMetadata(model.AWSMetadata{Zones: []string{"us-east-1a"}})
+	require.NoError(t, err)
+	err = sqlStore.CreateCluster(cluster1)
+	require.NoError(t, err)
+
+	cluster2 := &model.Cluster{
+		State:              model.ClusterStateCreationRequested,
+		AllowInstallations: true,
+	}
+	err = sqlStore.CreateCluster(cluster2)
+	require.NoError(t, err)
+
+	t.Run("schedules onto the stable cluster", func(t *testing.T) {
+		decision, err := client.ScheduleInstallation(&model.ScheduleInstallationRequest{
+			SchedulingStrategy: model.SchedulingStrategySpread,
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.SchedulingStrategySpread, decision.Strategy)
+		require.Equal(t, cluster1.ID, decision.ClusterID)
+		require.Len(t, decision.Candidates, 2)
+		require.True(t, decision.Candidates[0].Fits)
+		require.Equal(t, 10, decision.Candidates[0].CPUPercent)
+		require.Equal(t, cluster2.ID, decision.Candidates[1].ClusterID)
+		require.False(t, decision.Candidates[1].Fits)
+		require.Equal(t, "cluster is not stable (currently creation-requested)", decision.Candidates[1].Reason)
+	})
+
+	t.Run("explains rejections", func(t *testing.T) {
+		decision, err := client.ScheduleInstallation(&model.ScheduleInstallationRequest{
+			Zone: "us-east-1b",
+		})
+		require.NoError(t, err)
+		require.Empty(t, decision.ClusterID)
+		require.Len(t, decision.Candidates, 2)
+		for _, candidate := range decision.Candidates {
+			require.False(t, candidate.Fits)
+			require.NotEmpty(t, candidate.Reason)
+		}
+	})
+}
+
 func TestRetryCreateInstallation(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := store.MakeTestSQLStore(t, logger)
@@ -624,6 +755,69 @@ func TestUpdateInstallation(t *testing.T) {
 		require.Equal(t, installationReponse, installation1)
 	})
 
+	t.Run("dns and aliases", func(t *testing.T) {
+		installation1.State = model.InstallationStateStable
+		err = sqlStore.UpdateInstallation(installation1)
+		require.NoError(t, err)
+
+		upgradeRequest := &model.PatchInstallationRequest{
+			DNS:        sToP("new-dns.example.com"),
+			DNSAliases: []string{"alias1.example.com", "alias2.example.com"},
+		}
+		installationReponse, err := client.UpdateInstallation(installation1.ID, upgradeRequest)
+		require.NoError(t, err)
+
+		installation1, err = client.GetInstallation(installation1.ID, nil)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationStateUpdateRequested, installation1.State)
+		require.Equal(t, "new-dns.example.com", installation1.DNS)
+		require.Equal(t, []string{"alias1.example.com", "alias2.example.com"}, installation1.DNSAliases)
+		require.Equal(t, installationReponse, installation1)
+	})
+
+	t.Run("invalid dns alias", func(t *testing.T) {
+		installation1.State = model.InstallationStateStable
+		err = sqlStore.UpdateInstallation(installation1)
+		require.NoError(t, err)
+
+		upgradeRequest := &model.PatchInstallationRequest{
+			DNSAliases: []string{""},
+		}
+		installationReponse, err := client.UpdateInstallation(installation1.ID, upgradeRequest)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installationReponse)
+	})
+
+	t.Run("dns alias used by another installation", func(t *testing.T) {
+		installation1.State = model.InstallationStateStable
+		err = sqlStore.UpdateInstallation(installation1)
+		require.NoError(t, err)
+
+		installation2 := &model.Installation{
+			OwnerID: model.NewID(),
+			DNS:     "taken.example.com",
+			State:   model.InstallationStateStable,
+		}
+		err = sqlStore.CreateInstallation(installation2)
+		require.NoError(t, err)
+
+		upgradeRequest := &model.PatchInstallationRequest{
+			DNSAliases: []string{"Taken.example.com"},
+		}
+		installationReponse, err := client.UpdateInstallation(installation1.ID, upgradeRequest)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installationReponse)
+	})
+
+	t.Run("create with dns used as another installation alias", func(t *testing.T) {
+		installation, err := client.CreateInstallation(&model.CreateInstallationRequest{
+			OwnerID: model.NewID(),
+			DNS:     "alias1.example.com",
+		})
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installation)
+	})
+
 	t.Run("to version with embedded slash", func(t *testing.T) {
 		installation1.State = model.InstallationStateStable
 		err = sqlStore.UpdateInstallation(installation1)
@@ -644,6 +838,289 @@ func TestUpdateInstallation(t *testing.T) {
 	})
 }
 
+func TestHibernateInstallation(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	installation1, err := client.CreateInstallation(&model.CreateInstallationRequest{
+		OwnerID:  "owner",
+		Version:  "version",
+		DNS:      "dns.example.com",
+		Affinity: model.InstallationAffinityIsolated,
+	})
+	require.NoError(t, err)
+
+	t.Run("unknown installation", func(t *testing.T) {
+		installationResponse, err := client.HibernateInstallation(model.NewID())
+		require.EqualError(t, err, "failed with status code 404")
+		require.Nil(t, installationResponse)
+	})
+
+	t.Run("while locked", func(t *testing.T) {
+		installation1.State = model.InstallationStateStable
+		err = sqlStore.UpdateInstallation(installation1)
+		require.NoError(t, err)
+
+		lockerID := model.NewID()
+
+		locked, err := sqlStore.LockInstallation(installation1.ID, lockerID)
+		require.NoError(t, err)
+		require.True(t, locked)
+		defer func() {
+			unlocked, err := sqlStore.UnlockInstallation(installation1.ID, lockerID, false)
+			require.NoError(t, err)
+			require.True(t, unlocked)
+		}()
+
+		installationResponse, err := client.HibernateInstallation(installation1.ID)
+		require.EqualError(t, err, "failed with status code 409")
+		require.Nil(t, installationResponse)
+	})
+
+	t.Run("while creating", func(t *testing.T) {
+		installation1.State = model.InstallationStateCreationRequested
+		err = sqlStore.UpdateInstallation(installation1)
+		require.NoError(t, err)
+
+		installationResponse, err := client.HibernateInstallation(installation1.ID)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installationResponse)
+	})
+
+	t.Run("while stable", func(t *testing.T) {
+		installation1.State = model.InstallationStateStable
+		installation1.Attempts = 3
+		installation1.LastError = "failed to update cluster installation"
+		installation1.NextAttemptAt = store.GetMillis() + 60000
+		err = sqlStore.UpdateInstallation(installation1)
+		require.NoError(t, err)
+
+		installationResponse, err := client.HibernateInstallation(installation1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationStateHibernationRequested, installationResponse.State)
+
+		installation1, err = client.GetInstallation(installation1.ID, nil)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationStateHibernationRequested, installation1.State)
+		require.Equal(t, 0, installation1.Attempts)
+		require.Empty(t, installation1.LastError)
+		require.Equal(t, int64(0), installation1.NextAttemptAt)
+	})
+
+	t.Run("while hibernating", func(t *testing.T) {
+		installation1.State = model.InstallationStateHibernating
+		err = sqlStore.UpdateInstallation(installation1)
+		require.NoError(t, err)
+
+		installationResponse, err := client.HibernateInstallation(installation1.ID)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installationResponse)
+	})
+}
+
+func TestWakeupInstallation(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	installation1, err := client.CreateInstallation(&model.CreateInstallationRequest{
+		OwnerID:  "owner",
+		Version:  "version",
+		DNS:      "dns.example.com",
+		Affinity: model.InstallationAffinityIsolated,
+	})
+	require.NoError(t, err)
+
+	t.Run("unknown installation", func(t *testing.T) {
+		installationResponse, err := client.WakeupInstallation(model.NewID())
+		require.EqualError(t, err, "failed with status code 404")
+		require.Nil(t, installationResponse)
+	})
+
+	t.Run("while stable", func(t *testing.T) {
+		installation1.State = model.InstallationStateStable
+		err = sqlStore.UpdateInstallation(installation1)
+		require.NoError(t, err)
+
+		installationResponse, err := client.WakeupInstallation(installation1.ID)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installationResponse)
+	})
+
+	t.Run("while hibernating", func(t *testing.T) {
+		installation1.State = model.InstallationStateHibernating
+		err = sqlStore.UpdateInstallation(installation1)
+		require.NoError(t, err)
+
+		installationResponse, err := client.WakeupInstallation(installation1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationStateWakeUpRequested, installationResponse.State)
+
+		installation1, err = client.GetInstallation(installation1.ID, nil)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationStateWakeUpRequested, installation1.State)
+	})
+}
+
+func TestMigrateInstallation(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	sourceCluster := &model.Cluster{State: model.ClusterStateStable, AllowInstallations: true}
+	err := sqlStore.CreateCluster(sourceCluster)
+	require.NoError(t, err)
+
+	targetCluster := &model.Cluster{State: model.ClusterStateStable, AllowInstallations: true}
+	err = sqlStore.CreateCluster(targetCluster)
+	require.NoError(t, err)
+
+	installation1, err := client.CreateInstallation(&model.CreateInstallationRequest{
+		OwnerID:   "owner",
+		Version:   "version",
+		DNS:       "dns.example.com",
+		Affinity:  model.InstallationAffinityIsolated,
+		Database:  model.InstallationDatabaseAwsRDS,
+		Filestore: model.InstallationFilestoreAwsS3,
+	})
+	require.NoError(t, err)
+
+	err = sqlStore.CreateClusterInstallation(&model.ClusterInstallation{
+		ClusterID:      sourceCluster.ID,
+		InstallationID: installation1.ID,
+		Namespace:      installation1.ID,
+		State:          model.ClusterInstallationStateStable,
+	})
+	require.NoError(t, err)
+
+	migrateRequest := &model.MigrateInstallationRequest{ClusterID: targetCluster.ID}
+
+	t.Run("unknown installation", func(t *testing.T) {
+		installationResponse, err := client.MigrateInstallation(model.NewID(), migrateRequest)
+		require.EqualError(t, err, "failed with status code 404")
+		require.Nil(t, installationResponse)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		installationResponse, err := client.MigrateInstallation(installation1.ID, &model.MigrateInstallationRequest{})
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installationResponse)
+	})
+
+	t.Run("while creating", func(t *testing.T) {
+		installationResponse, err := client.MigrateInstallation(installation1.ID, migrateRequest)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installationResponse)
+	})
+
+	t.Run("unknown target cluster", func(t *testing.T) {
+		installation1.State = model.InstallationStateStable
+		err = sqlStore.UpdateInstallation(installation1)
+		require.NoError(t, err)
+
+		installationResponse, err := client.MigrateInstallation(installation1.ID, &model.MigrateInstallationRequest{ClusterID: model.NewID()})
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installationResponse)
+	})
+
+	t.Run("to the current cluster", func(t *testing.T) {
+		installationResponse, err := client.MigrateInstallation(installation1.ID, &model.MigrateInstallationRequest{ClusterID: sourceCluster.ID})
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installationResponse)
+	})
+
+	t.Run("target cluster not allowing installations", func(t *testing.T) {
+		targetCluster.AllowInstallations = false
+		err = sqlStore.UpdateCluster(targetCluster)
+		require.NoError(t, err)
+		defer func() {
+			targetCluster.AllowInstallations = true
+			err = sqlStore.UpdateCluster(targetCluster)
+			require.NoError(t, err)
+		}()
+
+		installationResponse, err := client.MigrateInstallation(installation1.ID, migrateRequest)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installationResponse)
+	})
+
+	t.Run("target cluster lacking node pool", func(t *testing.T) {
+		installation1.NodePool = "memory"
+		err = sqlStore.UpdateInstallation(installation1)
+		require.NoError(t, err)
+		defer func() {
+			installation1.NodePool = ""
+			err = sqlStore.UpdateInstallation(installation1)
+			require.NoError(t, err)
+		}()
+
+		installationResponse, err := client.MigrateInstallation(installation1.ID, migrateRequest)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installationResponse)
+	})
+
+	t.Run("while stable", func(t *testing.T) {
+		installationResponse, err := client.MigrateInstallation(installation1.ID, migrateRequest)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationStateMigrationRequested, installationResponse.State)
+		require.Equal(t, targetCluster.ID, *installationResponse.MigrationTargetClusterID)
+
+		installation1, err = client.GetInstallation(installation1.ID, nil)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationStateMigrationRequested, installation1.State)
+		require.Equal(t, targetCluster.ID, *installation1.MigrationTargetClusterID)
+	})
+
+	t.Run("unsupported installation", func(t *testing.T) {
+		installation2, err := client.CreateInstallation(&model.CreateInstallationRequest{
+			OwnerID:  "owner",
+			Version:  "version",
+			DNS:      "dns2.example.com",
+			Affinity: model.InstallationAffinityIsolated,
+		})
+		require.NoError(t, err)
+
+		installation2.State = model.InstallationStateStable
+		err = sqlStore.UpdateInstallation(installation2)
+		require.NoError(t, err)
+
+		installationResponse, err := client.MigrateInstallation(installation2.ID, migrateRequest)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, installationResponse)
+	})
+}
+
 func TestJoinGroup(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := store.MakeTestSQLStore(t, logger)
@@ -907,6 +1384,10 @@ func TestDeleteInstallation(t *testing.T) {
 			model.InstallationStateUpdateRequested,
 			model.InstallationStateUpdateInProgress,
 			model.InstallationStateUpdateFailed,
+			model.InstallationStateHibernationRequested,
+			model.InstallationStateHibernating,
+			model.InstallationStateWakeUpRequested,
+			model.InstallationStateMigrationFailed,
 			model.InstallationStateDeletionRequested,
 			model.InstallationStateDeletionInProgress,
 			model.InstallationStateDeletionFinalCleanup,
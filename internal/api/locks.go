@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// initLocks registers lock endpoints on the given router.
+func initLocks(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	locksRouter := apiRouter.PathPrefix("/locks").Subrouter()
+	locksRouter.Use(authorize(resourceLock))
+	locksRouter.Handle("", addContext(handleGetLocks)).Methods("GET")
+
+	lockRouter := apiRouter.PathPrefix("/lock/{resource_type}/{resource_id:[A-Za-z0-9]{26}}").Subrouter()
+	lockRouter.Use(authorize(resourceLock))
+	lockRouter.Handle("", addContext(handleReleaseLock)).Methods("DELETE")
+}
+
+// handleGetLocks responds to GET /api/locks, returning the locks currently
+// held on resources.
+func handleGetLocks(c *Context, w http.ResponseWriter, r *http.Request) {
+	resourceType := r.URL.Query().Get("resource_type")
+	if resourceType != "" && !model.IsValidLockResourceType(resourceType) {
+		c.Logger.Errorf("unsupported lock resource type %s", resourceType)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	filter := &model.LockFilter{
+		ResourceType:   resourceType,
+		LockAcquiredBy: r.URL.Query().Get("locker"),
+	}
+
+	locks, err := c.Store.GetLocks(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query locks")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if locks == nil {
+		locks = []*model.Lock{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, locks)
+}
+
+// handleReleaseLock responds to DELETE /api/lock/{resource_type}/{resource_id},
+// forcibly releasing the lock held on the resource.
+func handleReleaseLock(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	resourceType := vars["resource_type"]
+	resourceID := vars["resource_id"]
+	c.Logger = c.Logger.WithField("resource-type", resourceType).WithField("resource", resourceID)
+
+	if !model.IsValidLockResourceType(resourceType) {
+		c.Logger.Errorf("unsupported lock resource type %s", resourceType)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	unlocked, err := c.Store.UnlockResource(resourceType, resourceID, "", true)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to release lock")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if !unlocked {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	c.Logger.Warn("Lock forcibly released")
+	c.Supervisor.Enqueue(resourceType, resourceID)
+
+	w.WriteHeader(http.StatusOK)
+}
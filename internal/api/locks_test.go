@@ -0,0 +1,93 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocks(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("no locks", func(t *testing.T) {
+		locks, err := client.GetLocks(&model.GetLocksRequest{})
+		require.NoError(t, err)
+		require.Empty(t, locks)
+	})
+
+	t.Run("invalid resource type", func(t *testing.T) {
+		resp, err := http.Get(fmt.Sprintf("%s/api/locks?resource_type=invalid", ts.URL))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		err = client.ReleaseLock("invalid", model.NewID())
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	cluster := &model.Cluster{}
+	err := sqlStore.CreateCluster(cluster)
+	require.NoError(t, err)
+	locked, err := sqlStore.LockCluster(cluster.ID, "instance1")
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	installation := &model.Installation{}
+	err = sqlStore.CreateInstallation(installation)
+	require.NoError(t, err)
+	locked, err = sqlStore.LockInstallation(installation.ID, "instance2")
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	t.Run("get locks", func(t *testing.T) {
+		locks, err := client.GetLocks(&model.GetLocksRequest{})
+		require.NoError(t, err)
+		require.Len(t, locks, 2)
+
+		locks, err = client.GetLocks(&model.GetLocksRequest{ResourceType: model.LockResourceTypeCluster})
+		require.NoError(t, err)
+		require.Len(t, locks, 1)
+		require.Equal(t, cluster.ID, locks[0].ResourceID)
+		require.Equal(t, "instance1", locks[0].LockAcquiredBy)
+
+		locks, err = client.GetLocks(&model.GetLocksRequest{LockAcquiredBy: "instance2"})
+		require.NoError(t, err)
+		require.Len(t, locks, 1)
+		require.Equal(t, installation.ID, locks[0].ResourceID)
+	})
+
+	t.Run("release lock", func(t *testing.T) {
+		err := client.ReleaseLock(model.LockResourceTypeCluster, cluster.ID)
+		require.NoError(t, err)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Nil(t, cluster.LockAcquiredBy)
+
+		err = client.ReleaseLock(model.LockResourceTypeCluster, cluster.ID)
+		require.EqualError(t, err, "failed with status code 404")
+
+		locks, err := client.GetLocks(&model.GetLocksRequest{})
+		require.NoError(t, err)
+		require.Len(t, locks, 1)
+	})
+}
@@ -96,4 +96,64 @@ func TestGetClustersRequestApplyToURL(t *testing.T) {
 
 		require.Equal(t, "include_deleted=true&page=10&per_page=123", u.RawQuery)
 	})
+
+	t.Run("filters", func(t *testing.T) {
+		u, err := url.Parse("http://localhost:8075")
+		require.NoError(t, err)
+
+		allowInstallations := false
+		getClustersRequest := &model.GetClustersRequest{
+			States:             []string{model.ClusterStateStable, model.ClusterStateCreationFailed},
+			Size:               model.SizeAlef500,
+			Version:            "1.15.0",
+			AllowInstallations: &allowInstallations,
+			Page:               0,
+			PerPage:            10,
+		}
+		getClustersRequest.ApplyToURL(u)
+
+		require.Equal(t, "allow_installations=false&page=0&per_page=10&size=SizeAlef500&state=stable&state=creation-failed&version=1.15.0", u.RawQuery)
+	})
+}
+
+func TestGetInstallationsRequestApplyToURL(t *testing.T) {
+	t.Run("filters", func(t *testing.T) {
+		u, err := url.Parse("http://localhost:8075")
+		require.NoError(t, err)
+
+		getInstallationsRequest := &model.GetInstallationsRequest{
+			OwnerID:                     "owner",
+			States:                      []string{model.InstallationStateStable, model.InstallationStateUpdateFailed},
+			DNS:                         "example",
+			Version:                     "5.22.0",
+			Database:                    model.InstallationDatabaseAwsRDS,
+			Filestore:                   model.InstallationFilestoreAwsS3,
+			Affinity:                    model.InstallationAffinityIsolated,
+			Size:                        "1000users",
+			IncludeGroupConfig:          true,
+			IncludeGroupConfigOverrides: true,
+			PerPage:                     10,
+		}
+		getInstallationsRequest.ApplyToURL(u)
+
+		require.Equal(t, "affinity=isolated&database=aws-rds&dns=example&filestore=aws-s3&group=&owner=owner&page=0&per_page=10&size=1000users&state=stable&state=update-failed&version=5.22.0", u.RawQuery)
+	})
+}
+
+func TestGetGroupsRequestApplyToURL(t *testing.T) {
+	t.Run("filters", func(t *testing.T) {
+		u, err := url.Parse("http://localhost:8075")
+		require.NoError(t, err)
+
+		getGroupsRequest := &model.GetGroupsRequest{
+			Name:    "group",
+			Version: "5.22.0",
+			Image:   "mattermost/mattermost-enterprise-edition",
+			Page:    0,
+			PerPage: 10,
+		}
+		getGroupsRequest.ApplyToURL(u)
+
+		require.Equal(t, "image=mattermost%2Fmattermost-enterprise-edition&name=group&page=0&per_page=10&version=5.22.0", u.RawQuery)
+	})
 }
@@ -14,12 +14,17 @@ func initWebhook(apiRouter *mux.Router, context *Context) {
 	}
 
 	webhooksRouter := apiRouter.PathPrefix("/webhooks").Subrouter()
+	webhooksRouter.Use(authorize(resourceWebhook))
 	webhooksRouter.Handle("", addContext(handleGetWebhooks)).Methods("GET")
 	webhooksRouter.Handle("", addContext(handleCreateWebhook)).Methods("POST")
 
 	webhookRouter := apiRouter.PathPrefix("/webhook/{webhook:[A-Za-z0-9]{26}}").Subrouter()
+	webhookRouter.Use(authorize(resourceWebhook))
+	webhookRouter.Use(authorizeWebhookOwner(context))
 	webhookRouter.Handle("", addContext(handleGetWebhook)).Methods("GET")
 	webhookRouter.Handle("", addContext(handleDeleteWebhook)).Methods("DELETE")
+	webhookRouter.Handle("/deliveries", addContext(handleGetWebhookDeliveries)).Methods("GET")
+	webhookRouter.Handle("/deliveries/{delivery:[A-Za-z0-9]{26}}/replay", addContext(handleReplayWebhookDelivery)).Methods("POST")
 }
 
 // handleCreateWebhook responds to POST /api/webhooks, creating a new webhook.
@@ -31,9 +36,37 @@ func handleCreateWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !c.isOwnerAllowed(createWebhookRequest.OwnerID) {
+		c.Logger.Warnf("API key may not create resources for owner %s", createWebhookRequest.OwnerID)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	// Webhooks created with an API key limited to an owner may only receive
+	// the payloads of that owner's installations.
+	if restrictedOwnerID := c.restrictedOwnerID(); restrictedOwnerID != "" {
+		if createWebhookRequest.InstallationOwnerID == "" {
+			createWebhookRequest.InstallationOwnerID = restrictedOwnerID
+		}
+		if len(createWebhookRequest.Types) == 0 {
+			createWebhookRequest.Types = []string{model.TypeInstallation}
+		}
+		if createWebhookRequest.InstallationOwnerID != restrictedOwnerID ||
+			len(createWebhookRequest.Types) != 1 || createWebhookRequest.Types[0] != model.TypeInstallation {
+			c.Logger.Warn("API key may only subscribe to installations of its owner")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+
 	webhook := model.Webhook{
 		OwnerID: createWebhookRequest.OwnerID,
 		URL:     createWebhookRequest.URL,
+		Secret:  createWebhookRequest.Secret,
+
+		Types:               createWebhookRequest.Types,
+		NewStates:           createWebhookRequest.NewStates,
+		InstallationOwnerID: createWebhookRequest.InstallationOwnerID,
 	}
 
 	err = c.Store.CreateWebhook(&webhook)
@@ -75,6 +108,15 @@ func handleGetWebhooks(c *Context, w http.ResponseWriter, r *http.Request) {
 	var err error
 	owner := r.URL.Query().Get("owner")
 
+	if owner == "" {
+		owner = c.restrictedOwnerID()
+	}
+	if !c.isOwnerAllowed(owner) {
+		c.Logger.Warnf("API key may not list resources of owner %s", owner)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
 	page, perPage, includeDeleted, err := parsePaging(r.URL)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to parse paging parameters")
@@ -135,3 +177,109 @@ func handleDeleteWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// handleGetWebhookDeliveries responds to GET /api/webhook/{webhook}/deliveries,
+// returning the specified page of deliveries of the webhook.
+func handleGetWebhookDeliveries(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID := vars["webhook"]
+	c.Logger = c.Logger.WithField("webhook", webhookID)
+
+	page, perPage, _, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := c.Store.GetWebhook(webhookID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if webhook == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	filter := &model.WebhookDeliveryFilter{
+		WebhookID: webhookID,
+		States:    r.URL.Query()["state"],
+		Page:      page,
+		PerPage:   perPage,
+	}
+
+	deliveries, err := c.Store.GetWebhookDeliveries(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook deliveries")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*model.WebhookDelivery{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, deliveries)
+}
+
+// handleReplayWebhookDelivery responds to POST
+// /api/webhook/{webhook}/deliveries/{delivery}/replay, queueing a failed
+// webhook delivery for delivery again.
+func handleReplayWebhookDelivery(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID := vars["webhook"]
+	deliveryID := vars["delivery"]
+	c.Logger = c.Logger.WithField("webhook", webhookID).WithField("delivery", deliveryID)
+
+	webhook, err := c.Store.GetWebhook(webhookID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if webhook == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if webhook.IsDeleted() {
+		c.Logger.Warn("unable to replay delivery to a deleted webhook")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := c.Store.GetWebhookDelivery(deliveryID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook delivery")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if delivery == nil || delivery.WebhookID != webhookID {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if !delivery.IsReplayable() {
+		c.Logger.Warnf("unable to replay webhook delivery in state %s", delivery.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	delivery.State = model.WebhookDeliveryStatePending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = 0
+
+	err = c.Store.UpdateWebhookDelivery(delivery)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to update webhook delivery")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	c.Supervisor.Enqueue(model.LockResourceTypeWebhookDelivery, deliveryID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, delivery)
+}
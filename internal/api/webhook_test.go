@@ -77,6 +77,20 @@ func TestCreateWebhook(t *testing.T) {
 		require.NotEqual(t, 0, webhook.CreateAt)
 		require.EqualValues(t, 0, webhook.DeleteAt)
 	})
+
+	t.Run("valid with secret", func(t *testing.T) {
+		webhook, err := client.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID: "owner",
+			URL:     "https://signedurl.com",
+			Secret:  "secret",
+		})
+		require.NoError(t, err)
+		require.Empty(t, webhook.Secret)
+
+		storedWebhook, err := sqlStore.GetWebhook(webhook.ID)
+		require.NoError(t, err)
+		require.Equal(t, "secret", storedWebhook.Secret)
+	})
 }
 
 func TestGetWebhooks(t *testing.T) {
@@ -303,3 +317,107 @@ func TestDeleteWebhook(t *testing.T) {
 		require.True(t, webhook.IsDeleted())
 	})
 }
+
+func TestWebhookDeliveries(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	webhook, err := client.CreateWebhook(&model.CreateWebhookRequest{
+		OwnerID: "owner",
+		URL:     "https://validurl.com",
+	})
+	require.NoError(t, err)
+
+	deliveredDelivery := &model.WebhookDelivery{
+		WebhookID: webhook.ID,
+		Payload:   `{"id":"1"}`,
+		State:     model.WebhookDeliveryStateDelivered,
+		Attempts:  1,
+	}
+	err = sqlStore.CreateWebhookDelivery(deliveredDelivery)
+	require.NoError(t, err)
+
+	time.Sleep(1 * time.Millisecond)
+
+	failedDelivery := &model.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		Payload:       `{"id":"2"}`,
+		State:         model.WebhookDeliveryStateFailed,
+		Attempts:      10,
+		NextAttemptAt: 100,
+		LastError:     "webhook receiver responded with status code 500",
+	}
+	err = sqlStore.CreateWebhookDelivery(failedDelivery)
+	require.NoError(t, err)
+
+	t.Run("unknown webhook", func(t *testing.T) {
+		deliveries, err := client.GetWebhookDeliveries(model.NewID(), &model.GetWebhookDeliveriesRequest{PerPage: 10})
+		require.EqualError(t, err, "failed with status code 404")
+		require.Nil(t, deliveries)
+	})
+
+	t.Run("invalid paging", func(t *testing.T) {
+		resp, err := http.Get(fmt.Sprintf("%s/api/webhook/%s/deliveries?page=invalid", ts.URL, webhook.ID))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("all deliveries", func(t *testing.T) {
+		deliveries, err := client.GetWebhookDeliveries(webhook.ID, &model.GetWebhookDeliveriesRequest{PerPage: 10})
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{deliveredDelivery, failedDelivery}, deliveries)
+	})
+
+	t.Run("dead-letter list", func(t *testing.T) {
+		deliveries, err := client.GetWebhookDeliveries(webhook.ID, &model.GetWebhookDeliveriesRequest{
+			States:  []string{model.WebhookDeliveryStateFailed},
+			PerPage: 10,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{failedDelivery}, deliveries)
+	})
+
+	t.Run("replay unknown delivery", func(t *testing.T) {
+		delivery, err := client.ReplayWebhookDelivery(webhook.ID, model.NewID())
+		require.EqualError(t, err, "failed with status code 404")
+		require.Nil(t, delivery)
+	})
+
+	t.Run("replay delivered delivery", func(t *testing.T) {
+		delivery, err := client.ReplayWebhookDelivery(webhook.ID, deliveredDelivery.ID)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, delivery)
+	})
+
+	t.Run("replay failed delivery", func(t *testing.T) {
+		delivery, err := client.ReplayWebhookDelivery(webhook.ID, failedDelivery.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.WebhookDeliveryStatePending, delivery.State)
+		require.Equal(t, 0, delivery.Attempts)
+		require.Equal(t, int64(0), delivery.NextAttemptAt)
+
+		delivery, err = sqlStore.GetWebhookDelivery(failedDelivery.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.WebhookDeliveryStatePending, delivery.State)
+	})
+
+	t.Run("replay delivery to deleted webhook", func(t *testing.T) {
+		err := client.DeleteWebhook(webhook.ID)
+		require.NoError(t, err)
+
+		delivery, err := client.ReplayWebhookDelivery(webhook.ID, failedDelivery.ID)
+		require.EqualError(t, err, "failed with status code 400")
+		require.Nil(t, delivery)
+	})
+}
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+)
+
+// binaryVersionTimeout is the maximum time allowed for a binary to report its version.
+const binaryVersionTimeout = 10 * time.Second
+
+// schemaStore describes the interface required to check the store.
+type schemaStore interface {
+	CheckSchemaVersion() (semver.Version, error)
+}
+
+// sessionChecker describes the interface required to check the AWS session.
+type sessionChecker interface {
+	CheckSession() error
+}
+
+// StoreCheck returns a check that the store is reachable and that its schema
+// is compatible with the server.
+func StoreCheck(store schemaStore) Check {
+	return Check{
+		Name: "store",
+		Run: func() (string, error) {
+			currentVersion, err := store.CheckSchemaVersion()
+			if err != nil {
+				return "", err
+			}
+
+			return currentVersion.String(), nil
+		},
+	}
+}
+
+// AWSCheck returns a check that an AWS session with credentials is available.
+func AWSCheck(client sessionChecker) Check {
+	return Check{
+		Name: "aws",
+		Run: func() (string, error) {
+			return "", client.CheckSession()
+		},
+	}
+}
+
+// BinaryCheck returns a check that the named binary is installed on the PATH,
+// reporting the first line output when invoked with the given version arguments.
+func BinaryCheck(name string, versionArgs ...string) Check {
+	return Check{
+		Name: name,
+		Run: func() (string, error) {
+			path, err := exec.LookPath(name)
+			if err != nil {
+				return "", errors.Wrapf(err, "failed to find %s installed on the PATH", name)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), binaryVersionTimeout)
+			defer cancel()
+
+			output, err := exec.CommandContext(ctx, path, versionArgs...).Output()
+			if err != nil {
+				return "", errors.Wrapf(err, "failed to invoke %s version", name)
+			}
+
+			return strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0]), nil
+		},
+	}
+}
@@ -0,0 +1,80 @@
+// Package health checks the dependencies of the provisioning server, reporting
+// the outcome over HTTP for use by load balancers and Kubernetes probes.
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// Check is a named check of a single dependency of the provisioning server.
+type Check struct {
+	Name string
+	// Run checks the dependency, returning its version if known.
+	Run func() (string, error)
+}
+
+// Run runs the given checks concurrently, reporting the outcome of each in order.
+func Run(checks []Check) *model.HealthReport {
+	report := &model.HealthReport{
+		Status: model.HealthStatusOK,
+		Checks: make([]*model.HealthCheckResult, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+
+			result := &model.HealthCheckResult{
+				Name:   check.Name,
+				Status: model.HealthStatusOK,
+			}
+
+			version, err := check.Run()
+			result.Version = version
+			if err != nil {
+				result.Status = model.HealthStatusFailed
+				result.Error = err.Error()
+			}
+
+			report.Checks[i] = result
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != model.HealthStatusOK {
+			report.Status = model.HealthStatusFailed
+		}
+	}
+
+	return report
+}
+
+// Handler returns an http.Handler responding with a report of the given
+// checks, using status code 503 if any of them fail.
+func Handler(checks []Check, logger log.FieldLogger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := Run(checks)
+
+		statusCode := http.StatusOK
+		if !report.IsHealthy() {
+			logger.WithField("path", r.URL.Path).Warn("Health checks failed")
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+
+		err := json.NewEncoder(w).Encode(report)
+		if err != nil {
+			logger.WithError(err).Error("failed to encode health report")
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package health_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/mattermost/mattermost-cloud/internal/health"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSchemaStore struct {
+	err error
+}
+
+func (s *mockSchemaStore) CheckSchemaVersion() (semver.Version, error) {
+	return semver.MustParse("1.2.3"), s.err
+}
+
+type mockSessionChecker struct {
+	err error
+}
+
+func (c *mockSessionChecker) CheckSession() error {
+	return c.err
+}
+
+func TestRun(t *testing.T) {
+	t.Run("no checks", func(t *testing.T) {
+		report := health.Run(nil)
+		require.True(t, report.IsHealthy())
+		require.Empty(t, report.Checks)
+	})
+
+	t.Run("passing checks", func(t *testing.T) {
+		report := health.Run([]health.Check{
+			health.StoreCheck(&mockSchemaStore{}),
+			health.AWSCheck(&mockSessionChecker{}),
+		})
+		require.True(t, report.IsHealthy())
+		require.Equal(t, []*model.HealthCheckResult{
+			{Name: "store", Status: model.HealthStatusOK, Version: "1.2.3"},
+			{Name: "aws", Status: model.HealthStatusOK},
+		}, report.Checks)
+	})
+
+	t.Run("failing check", func(t *testing.T) {
+		report := health.Run([]health.Check{
+			health.StoreCheck(&mockSchemaStore{}),
+			health.AWSCheck(&mockSessionChecker{err: errors.New("no credentials")}),
+		})
+		require.False(t, report.IsHealthy())
+		require.Equal(t, model.HealthStatusOK, report.Checks[0].Status)
+		require.Equal(t, model.HealthStatusFailed, report.Checks[1].Status)
+		require.Equal(t, "no credentials", report.Checks[1].Error)
+	})
+}
+
+func TestBinaryCheck(t *testing.T) {
+	t.Run("installed", func(t *testing.T) {
+		report := health.Run([]health.Check{health.BinaryCheck("go", "version")})
+		require.True(t, report.IsHealthy())
+		require.Contains(t, report.Checks[0].Version, "go version")
+	})
+
+	t.Run("not installed", func(t *testing.T) {
+		report := health.Run([]health.Check{health.BinaryCheck("not-a-real-binary", "version")})
+		require.False(t, report.IsHealthy())
+		require.Contains(t, report.Checks[0].Error, "failed to find not-a-real-binary installed on the PATH")
+	})
+}
+
+func TestHandler(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	getReport := func(t *testing.T, checks []health.Check) (int, *model.HealthReport) {
+		ts := httptest.NewServer(health.Handler(checks, logger))
+		defer ts.Close()
+
+		resp, err := http.Get(ts.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var report model.HealthReport
+		err = json.NewDecoder(resp.Body).Decode(&report)
+		require.NoError(t, err)
+
+		return resp.StatusCode, &report
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		statusCode, report := getReport(t, []health.Check{health.StoreCheck(&mockSchemaStore{})})
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, model.HealthStatusOK, report.Status)
+	})
+
+	t.Run("unhealthy", func(t *testing.T) {
+		statusCode, report := getReport(t, []health.Check{health.StoreCheck(&mockSchemaStore{err: errors.New("unreachable")})})
+		require.Equal(t, http.StatusServiceUnavailable, statusCode)
+		require.Equal(t, model.HealthStatusFailed, report.Status)
+		require.Equal(t, "unreachable", report.Checks[0].Error)
+	})
+}
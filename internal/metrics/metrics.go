@@ -0,0 +1,143 @@
+// Package metrics collects Prometheus metrics describing the provisioning
+// server, its supervisors and the external commands they invoke.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "cloud"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	apiRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "api",
+			Name:      "requests_total",
+			Help:      "The number of API requests handled, by route, method and status code.",
+		},
+		[]string{"route", "method", "status"},
+	)
+
+	apiRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "api",
+			Name:      "request_duration_seconds",
+			Help:      "The time taken to handle API requests, by route and method.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"route", "method"},
+	)
+
+	supervisorDoDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "supervisor",
+			Name:      "do_duration_seconds",
+			Help:      "The time taken by a supervisor to work through its pending resources.",
+			Buckets:   []float64{0.1, 0.5, 1, 5, 15, 30, 60, 300, 900, 1800, 3600},
+		},
+		[]string{"supervisor"},
+	)
+
+	lockAcquisitionFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "store",
+			Name:      "lock_acquisition_failures_total",
+			Help:      "The number of failed attempts to lock rows, by table.",
+		},
+		[]string{"table"},
+	)
+
+	commandDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "command",
+			Name:      "duration_seconds",
+			Help:      "The time taken by invocations of external commands such as kops, terraform and helm.",
+			Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800},
+		},
+		[]string{"command", "subcommand"},
+	)
+
+	commandFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "command",
+			Name:      "failures_total",
+			Help:      "The number of failed invocations of external commands such as kops, terraform and helm.",
+		},
+		[]string{"command", "subcommand"},
+	)
+
+	leader = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "instance",
+			Name:      "leader",
+			Help:      "Whether this instance currently holds the leader lease.",
+		},
+	)
+)
+
+func init() {
+	registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		apiRequestsTotal,
+		apiRequestDuration,
+		supervisorDoDuration,
+		lockAcquisitionFailuresTotal,
+		commandDuration,
+		commandFailuresTotal,
+		leader,
+	)
+}
+
+// Handler returns an http.Handler exposing the collected metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveAPIRequest records an API request handled in the given duration.
+func ObserveAPIRequest(route, method string, statusCode int, duration time.Duration) {
+	apiRequestsTotal.WithLabelValues(route, method, strconv.Itoa(statusCode)).Inc()
+	apiRequestDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+// ObserveSupervisorDo records the time taken by the given supervisor since
+// start. It is intended to be deferred at the start of a supervisor's Do.
+func ObserveSupervisorDo(supervisor string, start time.Time) {
+	supervisorDoDuration.WithLabelValues(supervisor).Observe(time.Since(start).Seconds())
+}
+
+// IncLockAcquisitionFailures records a failed attempt to lock rows in the given table.
+func IncLockAcquisitionFailures(table string) {
+	lockAcquisitionFailuresTotal.WithLabelValues(table).Inc()
+}
+
+// ObserveCommand records an invocation of an external command, and whether it failed.
+func ObserveCommand(command, subcommand string, duration time.Duration, failed bool) {
+	commandDuration.WithLabelValues(command, subcommand).Observe(duration.Seconds())
+	if failed {
+		commandFailuresTotal.WithLabelValues(command, subcommand).Inc()
+	}
+}
+
+// SetLeader records whether this instance currently holds the leader lease.
+func SetLeader(isLeader bool) {
+	if isLeader {
+		leader.Set(1)
+	} else {
+		leader.Set(0)
+	}
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type mockResourceStore struct {
+	clusterStateCounts map[string]int64
+	err                error
+}
+
+func (s *mockResourceStore) GetClusterStateCounts() (map[string]int64, error) {
+	return s.clusterStateCounts, nil
+}
+
+func (s *mockResourceStore) GetInstallationStateCounts() (map[string]int64, error) {
+	return map[string]int64{model.InstallationStateStable: 3}, nil
+}
+
+func (s *mockResourceStore) GetClusterInstallationStateCounts() (map[string]int64, error) {
+	return nil, s.err
+}
+
+func TestObserveAPIRequest(t *testing.T) {
+	ObserveAPIRequest("/api/test", "POST", 202, time.Second)
+	ObserveAPIRequest("/api/test", "POST", 202, time.Second)
+	ObserveAPIRequest("/api/test", "POST", 400, time.Second)
+
+	require.Equal(t, float64(2), testutil.ToFloat64(apiRequestsTotal.WithLabelValues("/api/test", "POST", "202")))
+	require.Equal(t, float64(1), testutil.ToFloat64(apiRequestsTotal.WithLabelValues("/api/test", "POST", "400")))
+}
+
+func TestObserveCommand(t *testing.T) {
+	ObserveCommand("kops", "create", time.Second, false)
+	ObserveCommand("kops", "create", time.Second, true)
+
+	require.Equal(t, float64(1), testutil.ToFloat64(commandFailuresTotal.WithLabelValues("kops", "create")))
+}
+
+func TestIncLockAcquisitionFailures(t *testing.T) {
+	IncLockAcquisitionFailures("Test")
+
+	require.Equal(t, float64(1), testutil.ToFloat64(lockAcquisitionFailuresTotal.WithLabelValues("Test")))
+}
+
+func TestSetLeader(t *testing.T) {
+	SetLeader(true)
+	require.Equal(t, float64(1), testutil.ToFloat64(leader))
+
+	SetLeader(false)
+	require.Equal(t, float64(0), testutil.ToFloat64(leader))
+}
+
+func TestResourceCollector(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	t.Run("counts", func(t *testing.T) {
+		collector := &resourceCollector{
+			store: &mockResourceStore{
+				clusterStateCounts: map[string]int64{
+					model.ClusterStateStable:                2,
+					model.ClusterStateProvisioningRequested: 1,
+				},
+			},
+			logger: logger,
+		}
+
+		require.Equal(t, 3, testutil.CollectAndCount(collector))
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		testRegistry := prometheus.NewRegistry()
+		testRegistry.MustRegister(&resourceCollector{
+			store:  &mockResourceStore{err: errors.New("failed")},
+			logger: logger,
+		})
+
+		_, err := testRegistry.Gather()
+		require.Error(t, err)
+	})
+}
+
+func TestHandler(t *testing.T) {
+	ObserveSupervisorDo("test", time.Now())
+
+	ts := httptest.NewServer(Handler())
+	defer ts.Close()
+
+	resp, err := ts.Client().Get(ts.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `cloud_supervisor_do_duration_seconds_count{supervisor="test"} 1`)
+}
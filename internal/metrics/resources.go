@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+var resourcesDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "resources"),
+	"The number of resources that have not been deleted, by type and state.",
+	[]string{"type", "state"},
+	nil,
+)
+
+// resourceStore describes the interface required to count resources by state.
+type resourceStore interface {
+	GetClusterStateCounts() (map[string]int64, error)
+	GetInstallationStateCounts() (map[string]int64, error)
+	GetClusterInstallationStateCounts() (map[string]int64, error)
+}
+
+// resourceCollector reports the number of resources in each state, queried
+// from the store whenever metrics are collected.
+type resourceCollector struct {
+	store  resourceStore
+	logger log.FieldLogger
+}
+
+// RegisterResourceCollector registers a collector reporting the number of
+// clusters, installations and cluster installations in each state.
+func RegisterResourceCollector(store resourceStore, logger log.FieldLogger) error {
+	return registry.Register(&resourceCollector{
+		store:  store,
+		logger: logger,
+	})
+}
+
+// Describe implements prometheus.Collector.
+func (c *resourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- resourcesDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *resourceCollector) Collect(ch chan<- prometheus.Metric) {
+	counters := []struct {
+		resourceType string
+		getCounts    func() (map[string]int64, error)
+	}{
+		{model.TypeCluster, c.store.GetClusterStateCounts},
+		{model.TypeInstallation, c.store.GetInstallationStateCounts},
+		{model.TypeClusterInstallation, c.store.GetClusterInstallationStateCounts},
+	}
+
+	for _, counter := range counters {
+		counts, err := counter.getCounts()
+		if err != nil {
+			c.logger.WithError(err).WithField("type", counter.resourceType).Error("Failed to count resources by state")
+			ch <- prometheus.NewInvalidMetric(resourcesDesc, err)
+			continue
+		}
+
+		for state, count := range counts {
+			ch <- prometheus.MustNewConstMetric(resourcesDesc, prometheus.GaugeValue, float64(count), counter.resourceType, state)
+		}
+	}
+}
@@ -1,11 +1,9 @@
 package provisioner
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"time"
 
 	"github.com/pkg/errors"
@@ -47,7 +45,7 @@ func installHelm(kops *kops.Cmd, repos map[string]string, logger log.FieldLogger
 	logger.Infof("Waiting up to %d seconds for helm to become ready...", wait)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(wait)*time.Second)
 	defer cancel()
-	err = waitForHelmRunning(ctx, kops.GetKubeConfigPath())
+	err = waitForHelmRunning(ctx, kops.GetKubeConfigPath(), logger)
 	if err != nil {
 		return errors.Wrap(err, "helm didn't start as expected, or we couldn't detect it")
 	}
@@ -74,13 +72,16 @@ func (d *helmDeployment) Create() error {
 }
 
 // waitForHelmRunning is used to check when Helm is ready to install charts.
-func waitForHelmRunning(ctx context.Context, configPath string) error {
+func waitForHelmRunning(ctx context.Context, configPath string, logger log.FieldLogger) error {
+	helmClient, err := helm.New(logger)
+	if err != nil {
+		return errors.Wrap(err, "unable to create helm wrapper")
+	}
+	defer helmClient.Close()
+
 	for {
-		cmd := exec.Command("helm", "ls", "--kubeconfig", configPath)
-		var out bytes.Buffer
-		cmd.Stderr = &out
-		cmd.Run()
-		if out.String() == "" {
+		_, err = helmClient.List(configPath, true)
+		if err == nil {
 			return nil
 		}
 		select {
@@ -218,24 +219,14 @@ type HelmListOutput struct {
 }
 
 func (d *helmDeployment) List() (*HelmListOutput, error) {
-	arguments := []string{
-		"list",
-		"--kubeconfig", d.kops.GetKubeConfigPath(),
-		"--output", "json",
+	helmClient, err := helm.New(d.logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create helm wrapper")
 	}
+	defer helmClient.Close()
 
-	// TODO: Not using helm client here due to requirement for raw output
-	cmd := exec.Command("helm", arguments...)
-
-	logger := d.logger.WithFields(log.Fields{
-		"cmd": cmd.Path,
-	})
-
-	rawOutput, err := cmd.Output()
+	rawOutput, err := helmClient.List(d.kops.GetKubeConfigPath(), false)
 	if err != nil {
-		if len(rawOutput) > 0 {
-			logger.Debugf("Helm output was:\n%s\n", string(rawOutput))
-		}
 		return nil, err
 	}
 
@@ -3,6 +3,7 @@ package provisioner
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -127,6 +128,11 @@ func (provisioner *KopsProvisioner) CreateCluster(cluster *model.Cluster, awsCli
 		return errors.Wrap(err, "unable to create kops cluster")
 	}
 
+	err = kops.EnsureInstanceGroups(kopsMetadata.Name, kopsMetadata.InstanceGroups)
+	if err != nil {
+		return errors.Wrap(err, "unable to create additional instance groups")
+	}
+
 	terraformClient, err := terraform.New(kops.GetOutputDirectory(), provisioner.s3StateStore, logger)
 	if err != nil {
 		return err
@@ -441,6 +447,167 @@ func (provisioner *KopsProvisioner) UpgradeCluster(cluster *model.Cluster) error
 	return nil
 }
 
+// ResizeCluster changes the instance types and node count of a cluster's kops
+// instance groups to match the requested resize size, or the cluster size if
+// none was requested, then rolls the change out. The cluster size is updated
+// once the resize has succeeded.
+func (provisioner *KopsProvisioner) ResizeCluster(cluster *model.Cluster) error {
+	kopsMetadata, err := model.NewKopsMetadata(cluster.ProvisionerMetadata)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse provisioner metadata")
+	}
+
+	size := cluster.Size
+	if kopsMetadata.ResizeSize != "" {
+		size = kopsMetadata.ResizeSize
+	}
+
+	clusterSize, err := kops.GetSize(size)
+	if err != nil {
+		return err
+	}
+	nodeCount, err := strconv.ParseInt(clusterSize.NodeCount, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid node count for size %s", size)
+	}
+	masterCount, err := strconv.ParseInt(clusterSize.MasterCount, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid master count for size %s", size)
+	}
+
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	kops, err := kops.New(provisioner.s3StateStore, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create kops wrapper")
+	}
+	defer kops.Close()
+
+	instanceGroups, err := kops.GetInstanceGroups(kopsMetadata.Name)
+	if err != nil {
+		return err
+	}
+
+	// Each master runs in its own instance group, so the number of masters
+	// cannot be changed by resizing instance groups.
+	masterGroups, nodeGroups := splitInstanceGroupsByRole(instanceGroups, kopsMetadata)
+	if int64(len(masterGroups)) != masterCount {
+		return errors.Errorf("cannot change the master count from %d to %d", len(masterGroups), masterCount)
+	}
+	if len(nodeGroups) == 0 {
+		return errors.New("found no node instance groups to resize")
+	}
+
+	logger.WithField("size", size).Info("Resizing cluster")
+
+	for _, instanceGroup := range masterGroups {
+		if instanceGroup.MachineType() == clusterSize.MasterSize {
+			continue
+		}
+		instanceGroup.SetMachineType(clusterSize.MasterSize)
+		err = kops.ReplaceInstanceGroup(instanceGroup)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Spread the nodes across the node instance groups, which are usually one
+	// per zone.
+	for i, instanceGroup := range nodeGroups {
+		count := nodeCount / int64(len(nodeGroups))
+		if int64(i) < nodeCount%int64(len(nodeGroups)) {
+			count++
+		}
+		instanceGroup.SetMachineType(clusterSize.NodeSize)
+		instanceGroup.SetSize(count, count)
+		err = kops.ReplaceInstanceGroup(instanceGroup)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = kops.EnsureInstanceGroups(kopsMetadata.Name, kopsMetadata.InstanceGroups)
+	if err != nil {
+		return errors.Wrap(err, "unable to update additional instance groups")
+	}
+
+	err = kops.UpdateCluster(kopsMetadata.Name, kops.GetOutputDirectory())
+	if err != nil {
+		return err
+	}
+
+	terraformClient, err := terraform.New(kops.GetOutputDirectory(), provisioner.s3StateStore, logger)
+	if err != nil {
+		return err
+	}
+	defer terraformClient.Close()
+
+	err = terraformClient.Init(kopsMetadata.Name)
+	if err != nil {
+		return err
+	}
+
+	err = terraformClient.Plan()
+	if err != nil {
+		return err
+	}
+	err = terraformClient.Apply()
+	if err != nil {
+		return err
+	}
+
+	err = kops.RollingUpdateCluster(kopsMetadata.Name)
+	if err != nil {
+		return err
+	}
+
+	wait := 1000
+	logger.Infof("Waiting up to %d seconds for k8s cluster to become ready...", wait)
+	err = kops.WaitForKubernetesReadiness(kopsMetadata.Name, wait)
+	if err != nil {
+		// Run non-silent validate one more time to log final cluster state
+		// and return original timeout error.
+		kops.ValidateCluster(kopsMetadata.Name, false)
+		return err
+	}
+
+	kopsMetadata.MasterInstanceType = clusterSize.MasterSize
+	kopsMetadata.MasterCount = masterCount
+	kopsMetadata.NodeInstanceType = clusterSize.NodeSize
+	kopsMetadata.NodeMinCount = nodeCount
+	kopsMetadata.NodeMaxCount = nodeCount
+	kopsMetadata.ResizeSize = ""
+	err = cluster.SetProvisionerMetadata(kopsMetadata)
+	if err != nil {
+		return err
+	}
+	cluster.Size = size
+
+	logger.Info("Successfully resized cluster")
+
+	return nil
+}
+
+// splitInstanceGroupsByRole returns the master and default node instance
+// groups from the given list, ignoring the additional instance groups declared
+// in the kops metadata and groups with any other role such as bastions.
+func splitInstanceGroupsByRole(instanceGroups []kops.InstanceGroup, kopsMetadata *model.KopsMetadata) ([]kops.InstanceGroup, []kops.InstanceGroup) {
+	var masterGroups, nodeGroups []kops.InstanceGroup
+	for _, instanceGroup := range instanceGroups {
+		switch instanceGroup.Role() {
+		case kops.InstanceGroupRoleMaster:
+			masterGroups = append(masterGroups, instanceGroup)
+		case kops.InstanceGroupRoleNode:
+			if kopsMetadata.GetInstanceGroup(instanceGroup.Name()) != nil {
+				continue
+			}
+			nodeGroups = append(nodeGroups, instanceGroup)
+		}
+	}
+
+	return masterGroups, nodeGroups
+}
+
 // DeleteCluster deletes a previously created cluster using kops and terraform.
 func (provisioner *KopsProvisioner) DeleteCluster(cluster *model.Cluster, awsClient aws.AWS) error {
 	kopsMetadata, err := model.NewKopsMetadata(cluster.ProvisionerMetadata)
@@ -526,8 +693,10 @@ func (provisioner *KopsProvisioner) DeleteCluster(cluster *model.Cluster, awsCli
 	return nil
 }
 
-// GetClusterResources returns a snapshot of resources of a given cluster.
-func (provisioner *KopsProvisioner) GetClusterResources(cluster *model.Cluster, onlySchedulable bool) (*k8s.ClusterResources, error) {
+// GetClusterResources returns a snapshot of resources of a given cluster. If a
+// node pool is given, only the nodes of that node pool and the pods running on
+// or pinned to them are counted.
+func (provisioner *KopsProvisioner) GetClusterResources(cluster *model.Cluster, nodePool string, onlySchedulable bool) (*k8s.ClusterResources, error) {
 	logger := provisioner.logger.WithField("cluster", cluster.ID)
 
 	kops, err := kops.New(provisioner.s3StateStore, logger)
@@ -551,18 +720,14 @@ func (provisioner *KopsProvisioner) GetClusterResources(cluster *model.Cluster,
 		return nil, errors.Wrap(err, "failed to construct k8s client")
 	}
 
-	allPods, err := k8sClient.Clientset.CoreV1().Pods("").List(metav1.ListOptions{})
-	if err != nil {
-		return nil, err
-	}
-	usedCPU, usedMemory := k8s.CalculateTotalPodMilliResourceRequests(allPods)
-
 	var totalCPU, totalMemory int64
-	nodes, err := k8sClient.Clientset.CoreV1().Nodes().List(metav1.ListOptions{})
+	nodes, err := k8sClient.Clientset.CoreV1().Nodes().List(makeNodePoolListOptions(nodePool))
 	if err != nil {
 		return nil, err
 	}
+	nodeNames := make(map[string]bool)
 	for _, node := range nodes.Items {
+		nodeNames[node.GetName()] = true
 		var skipNode bool
 
 		if onlySchedulable {
@@ -590,6 +755,15 @@ func (provisioner *KopsProvisioner) GetClusterResources(cluster *model.Cluster,
 		}
 	}
 
+	allPods, err := k8sClient.Clientset.CoreV1().Pods("").List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if nodePool != "" {
+		allPods = filterNodePoolPods(allPods, nodeNames, nodePool)
+	}
+	usedCPU, usedMemory := k8s.CalculateTotalPodMilliResourceRequests(allPods)
+
 	return &k8s.ClusterResources{
 		MilliTotalCPU:    totalCPU,
 		MilliUsedCPU:     usedCPU,
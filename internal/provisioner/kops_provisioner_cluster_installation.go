@@ -1,6 +1,7 @@
 package provisioner
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -15,9 +16,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes/scheme"
 )
 
+// hibernationConfigMapKey is the key of the hibernation config map holding the
+// saved cluster installation resource.
+const hibernationConfigMapKey = "clusterinstallation"
+
 // CreateClusterInstallation creates a Mattermost installation within the given cluster.
 func (provisioner *KopsProvisioner) CreateClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, awsClient aws.AWS) error {
 	logger := provisioner.logger.WithFields(log.Fields{
@@ -77,6 +83,7 @@ func (provisioner *KopsProvisioner) CreateClusterInstallation(cluster *model.Clu
 			IngressName:            installation.DNS,
 			UseServiceLoadBalancer: true,
 			MattermostEnv:          installation.MattermostEnv.ToEnvList(),
+			NodeSelector:           makeNodeSelector(installation),
 			ServiceAnnotations: map[string]string{
 				"service.beta.kubernetes.io/aws-load-balancer-backend-protocol":        "tcp",
 				"service.beta.kubernetes.io/aws-load-balancer-ssl-cert":                *certificateSummary.CertificateArn,
@@ -176,7 +183,16 @@ func (provisioner *KopsProvisioner) UpdateClusterInstallation(cluster *model.Clu
 
 	name := makeClusterInstallationName(clusterInstallation)
 
+	// A hibernated cluster installation has no resource; updating it restores
+	// the one saved when it went into hibernation. This is how an installation
+	// wakes up, and how a failed migration restarts its source cluster
+	// installations.
+	hibernated := false
 	cr, err := k8sClient.MattermostClientset.MattermostV1alpha1().ClusterInstallations(clusterInstallation.Namespace).Get(name, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		cr, err = getHibernatedClusterInstallation(k8sClient, clusterInstallation.Namespace, name)
+		hibernated = true
+	}
 	if err != nil {
 		return errors.Wrapf(err, "failed to get cluster installation %s", clusterInstallation.ID)
 	}
@@ -194,6 +210,25 @@ func (provisioner *KopsProvisioner) UpdateClusterInstallation(cluster *model.Clu
 	}
 	cr.Spec.Image = installation.Image
 
+	if cr.Spec.Size != installation.Size {
+		logger.Infof("Resizing cluster installation from %s to %s", cr.Spec.Size, installation.Size)
+
+		// Clear the sized values so that the operator derives them from the
+		// new size.
+		cr.Spec.Size = installation.Size
+		cr.Spec.Replicas = 0
+		cr.Spec.Resources = corev1.ResourceRequirements{}
+		cr.Spec.Minio.Replicas = 0
+		cr.Spec.Minio.Resources = corev1.ResourceRequirements{}
+		cr.Spec.Database.Replicas = 0
+		cr.Spec.Database.Resources = corev1.ResourceRequirements{}
+	}
+
+	if cr.Spec.IngressName != installation.DNS {
+		logger.Infof("Updating cluster installation ingress from %s to %s", cr.Spec.IngressName, installation.DNS)
+	}
+	cr.Spec.IngressName = installation.DNS
+
 	cr.Spec.MattermostLicenseSecret = ""
 	secretName := fmt.Sprintf("%s-license", name)
 	if installation.License != "" {
@@ -219,6 +254,22 @@ func (provisioner *KopsProvisioner) UpdateClusterInstallation(cluster *model.Clu
 	}
 
 	cr.Spec.MattermostEnv = installation.MattermostEnv.ToEnvList()
+	cr.Spec.NodeSelector = makeNodeSelector(installation)
+
+	if hibernated {
+		_, err = k8sClient.MattermostClientset.MattermostV1alpha1().ClusterInstallations(clusterInstallation.Namespace).Create(cr)
+		if err != nil {
+			return errors.Wrapf(err, "failed to restore cluster installation %s", clusterInstallation.ID)
+		}
+
+		err = k8sClient.Clientset.CoreV1().ConfigMaps(clusterInstallation.Namespace).Delete(makeHibernationConfigMapName(name), nil)
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete the hibernation config map of cluster installation %s", clusterInstallation.ID)
+		}
+
+		logger.Info("Restored cluster installation from hibernation")
+		return nil
+	}
 
 	_, err = k8sClient.MattermostClientset.MattermostV1alpha1().ClusterInstallations(clusterInstallation.Namespace).Update(cr)
 	if err != nil {
@@ -230,6 +281,164 @@ func (provisioner *KopsProvisioner) UpdateClusterInstallation(cluster *model.Clu
 	return nil
 }
 
+// HibernateClusterInstallation scales the Mattermost app of the cluster
+// installation down to zero pods while leaving all other resources in place.
+// It is safe to call repeatedly, and returns true once no Mattermost pods
+// remain.
+//
+// The operator keeps the Mattermost deployment at the replica count of the
+// cluster installation resource, which cannot be zero. The resource is
+// therefore saved to a config map and deleted, orphaning the deployment,
+// database and filestore it manages, before the deployment is scaled down.
+// Waking up restores the resource, which adopts them again.
+func (provisioner *KopsProvisioner) HibernateClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) (bool, error) {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":      clusterInstallation.ClusterID,
+		"installation": clusterInstallation.InstallationID,
+	})
+
+	kops, err := kops.New(provisioner.s3StateStore, logger)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create kops wrapper")
+	}
+	defer kops.Close()
+
+	kopsMetadata, err := model.NewKopsMetadata(cluster.ProvisionerMetadata)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to parse provisioner metadata")
+	}
+
+	err = kops.ExportKubecfg(kopsMetadata.Name)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to export kubecfg")
+	}
+
+	k8sClient, err := k8s.New(kops.GetKubeConfigPath(), logger)
+	if err != nil {
+		return false, err
+	}
+
+	name := makeClusterInstallationName(clusterInstallation)
+	clusterInstallationClient := k8sClient.MattermostClientset.MattermostV1alpha1().ClusterInstallations(clusterInstallation.Namespace)
+
+	cr, err := clusterInstallationClient.Get(name, metav1.GetOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "failed to get cluster installation %s", clusterInstallation.ID)
+	}
+	if err == nil {
+		// The operator still reconciles the deployment until the resource is
+		// gone, so wait for the deletion to complete before scaling down.
+		if cr.DeletionTimestamp == nil {
+			err = saveHibernatedClusterInstallation(k8sClient, cr)
+			if err != nil {
+				return false, errors.Wrapf(err, "failed to save cluster installation %s", clusterInstallation.ID)
+			}
+
+			orphan := metav1.DeletePropagationOrphan
+			err = clusterInstallationClient.Delete(name, &metav1.DeleteOptions{PropagationPolicy: &orphan})
+			if err != nil && !k8sErrors.IsNotFound(err) {
+				return false, errors.Wrapf(err, "failed to delete cluster installation %s", clusterInstallation.ID)
+			}
+			logger.Info("Deleted cluster installation resource for hibernation")
+		}
+
+		return false, nil
+	}
+
+	deployments := k8sClient.Clientset.AppsV1().Deployments(clusterInstallation.Namespace)
+	deployment, err := deployments.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get deployment %s/%s", clusterInstallation.Namespace, name)
+	}
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 0 {
+		replicas := int32(0)
+		deployment.Spec.Replicas = &replicas
+		_, err = deployments.Update(deployment)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to scale down deployment %s/%s", clusterInstallation.Namespace, name)
+		}
+		logger.Info("Scaled down Mattermost deployment")
+	}
+
+	pods, err := k8sClient.Clientset.CoreV1().Pods(clusterInstallation.Namespace).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(mmv1alpha1.ClusterInstallationLabels(name)).String(),
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to list pods of cluster installation %s", clusterInstallation.ID)
+	}
+	if len(pods.Items) > 0 {
+		logger.Debugf("Waiting for %d Mattermost pods to terminate", len(pods.Items))
+		return false, nil
+	}
+
+	logger.Info("Hibernated cluster installation")
+
+	return true, nil
+}
+
+// makeHibernationConfigMapName returns the name of the config map holding the
+// cluster installation resource of a hibernating cluster installation.
+func makeHibernationConfigMapName(name string) string {
+	return fmt.Sprintf("%s-hibernation", name)
+}
+
+// saveHibernatedClusterInstallation saves the given cluster installation
+// resource to a config map, so that it can be restored when waking up.
+func saveHibernatedClusterInstallation(k8sClient *k8s.KubeClient, cr *mmv1alpha1.ClusterInstallation) error {
+	saved := &mmv1alpha1.ClusterInstallation{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ClusterInstallation",
+			APIVersion: "mattermost.com/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        cr.Name,
+			Namespace:   cr.Namespace,
+			Labels:      cr.Labels,
+			Annotations: cr.Annotations,
+		},
+		Spec: cr.Spec,
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode cluster installation")
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      makeHibernationConfigMapName(cr.Name),
+			Namespace: cr.Namespace,
+		},
+		Data: map[string]string{
+			hibernationConfigMapKey: string(data),
+		},
+	}
+
+	configMaps := k8sClient.Clientset.CoreV1().ConfigMaps(cr.Namespace)
+	_, err = configMaps.Create(configMap)
+	if k8sErrors.IsAlreadyExists(err) {
+		_, err = configMaps.Update(configMap)
+	}
+
+	return err
+}
+
+// getHibernatedClusterInstallation returns the cluster installation resource
+// saved when the cluster installation went into hibernation.
+func getHibernatedClusterInstallation(k8sClient *k8s.KubeClient, namespace, name string) (*mmv1alpha1.ClusterInstallation, error) {
+	configMap, err := k8sClient.Clientset.CoreV1().ConfigMaps(namespace).Get(makeHibernationConfigMapName(name), metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get hibernation config map")
+	}
+
+	var cr mmv1alpha1.ClusterInstallation
+	err = json.Unmarshal([]byte(configMap.Data[hibernationConfigMapKey]), &cr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode hibernated cluster installation")
+	}
+
+	return &cr, nil
+}
+
 // DeleteClusterInstallation deletes a Mattermost installation within the given cluster.
 func (provisioner *KopsProvisioner) DeleteClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error {
 	logger := provisioner.logger.WithFields(log.Fields{
@@ -6,10 +6,12 @@ import (
 	"time"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/k8s"
+	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/internal/tools/terraform"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -46,6 +48,39 @@ func translateMattermostVersion(version string) string {
 	return version
 }
 
+// makeNodeSelector returns the node selector pinning the pods of an
+// installation to its node pool, or nil to let them run on any node.
+func makeNodeSelector(installation *model.Installation) map[string]string {
+	if installation.NodePool == "" {
+		return nil
+	}
+
+	return map[string]string{kops.InstanceGroupLabel: installation.NodePool}
+}
+
+// makeNodePoolListOptions returns the options listing the nodes of the given
+// node pool, or every node if no node pool is given.
+func makeNodePoolListOptions(nodePool string) metav1.ListOptions {
+	if nodePool == "" {
+		return metav1.ListOptions{}
+	}
+
+	return metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", kops.InstanceGroupLabel, nodePool)}
+}
+
+// filterNodePoolPods returns the pods running on the given nodes of a node
+// pool, along with the pods pinned to the node pool that are still pending.
+func filterNodePoolPods(pods *corev1.PodList, nodeNames map[string]bool, nodePool string) *corev1.PodList {
+	filtered := &corev1.PodList{}
+	for _, pod := range pods.Items {
+		if nodeNames[pod.Spec.NodeName] || (pod.Spec.NodeName == "" && pod.Spec.NodeSelector[kops.InstanceGroupLabel] == nodePool) {
+			filtered.Items = append(filtered.Items, pod)
+		}
+	}
+
+	return filtered
+}
+
 func makeClusterInstallationName(clusterInstallation *model.ClusterInstallation) string {
 	// TODO: Once https://mattermost.atlassian.net/browse/MM-15467 is fixed, we can use the
 	// full namespace as part of the name. For now, truncate to keep within the existing limit
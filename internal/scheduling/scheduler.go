@@ -0,0 +1,221 @@
+// Package scheduling chooses the cluster an installation is scheduled onto.
+//
+// Every cluster is first checked for whether the installation fits on it at
+// all, taking into account the state of the cluster, the node pool and zone
+// the installation is pinned to, isolation and the cluster resource
+// threshold. A Strategy then chooses among the clusters that fit. With the
+// first-fit strategy, the clusters are checked in order until the installation
+// fits on one.
+package scheduling
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Store abstracts the database operations required to schedule installations.
+type Store interface {
+	GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error)
+	GetClusterInstallations(filter *model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error)
+}
+
+// Provisioner abstracts the provisioning operations required to schedule
+// installations.
+type Provisioner interface {
+	GetClusterResources(cluster *model.Cluster, nodePool string, onlySchedulable bool) (*k8s.ClusterResources, error)
+}
+
+// Scheduler chooses the cluster an installation is scheduled onto.
+type Scheduler struct {
+	store                    Store
+	provisioner              Provisioner
+	clusterResourceThreshold int
+	defaultStrategy          string
+	logger                   log.FieldLogger
+}
+
+// NewScheduler creates a new Scheduler, using the given strategy for
+// installations that do not specify their own.
+func NewScheduler(store Store, provisioner Provisioner, clusterResourceThreshold int, defaultStrategy string, logger log.FieldLogger) *Scheduler {
+	return &Scheduler{
+		store:                    store,
+		provisioner:              provisioner,
+		clusterResourceThreshold: clusterResourceThreshold,
+		defaultStrategy:          defaultStrategy,
+		logger:                   logger,
+	}
+}
+
+// strategy returns the name and implementation of the scheduling strategy of
+// the installation.
+func (s *Scheduler) strategy(installation *model.Installation) (string, Strategy, error) {
+	strategyName := installation.SchedulingStrategy
+	if strategyName == "" {
+		strategyName = s.defaultStrategy
+	}
+	strategy := GetStrategy(strategyName)
+	if strategy == nil {
+		return "", nil, errors.Errorf("unsupported scheduling strategy %s", strategyName)
+	}
+
+	return strategyName, strategy, nil
+}
+
+// IsFirstFit returns whether the installation is scheduled with the first-fit
+// strategy, in which case it can be scheduled onto the first of the clusters
+// it fits on without evaluating the others.
+func (s *Scheduler) IsFirstFit(installation *model.Installation) bool {
+	_, strategy, err := s.strategy(installation)
+	if err != nil {
+		return false
+	}
+	_, ok := strategy.(firstFit)
+
+	return ok
+}
+
+// Schedule considers each of the given clusters for the installation, and
+// returns the clusters in order of preference. With the first-fit strategy,
+// the clusters after the first one the installation fits on are not evaluated.
+// No changes are made.
+func (s *Scheduler) Schedule(installation *model.Installation, clusters []*model.Cluster) (*model.SchedulingDecision, error) {
+	strategyName, strategy, err := s.strategy(installation)
+	if err != nil {
+		return nil, err
+	}
+	_, isFirstFit := strategy.(firstFit)
+
+	decision := &model.SchedulingDecision{
+		Strategy: strategyName,
+	}
+	var fits bool
+	for _, cluster := range clusters {
+		if fits && isFirstFit {
+			decision.Candidates = append(decision.Candidates, &model.SchedulingCandidate{
+				ClusterID: cluster.ID,
+				Reason:    "not evaluated: the installation fits on an earlier cluster",
+			})
+			continue
+		}
+		candidate := s.Evaluate(cluster, installation)
+		fits = fits || candidate.Fits
+		decision.Candidates = append(decision.Candidates, candidate)
+	}
+
+	sort.SliceStable(decision.Candidates, func(i, j int) bool {
+		a, b := decision.Candidates[i], decision.Candidates[j]
+		if a.Fits != b.Fits {
+			return a.Fits
+		}
+		return a.Fits && strategy.Prefer(a, b)
+	})
+
+	if len(decision.Candidates) > 0 && decision.Candidates[0].Fits {
+		decision.ClusterID = decision.Candidates[0].ClusterID
+	}
+
+	return decision, nil
+}
+
+// Evaluate returns whether the installation fits on the given cluster, and
+// why not otherwise.
+func (s *Scheduler) Evaluate(cluster *model.Cluster, installation *model.Installation) *model.SchedulingCandidate {
+	candidate := &model.SchedulingCandidate{
+		ClusterID: cluster.ID,
+	}
+	reject := func(format string, args ...interface{}) *model.SchedulingCandidate {
+		candidate.Reason = fmt.Sprintf(format, args...)
+		return candidate
+	}
+
+	if cluster.State != model.ClusterStateStable {
+		return reject("cluster is not stable (currently %s)", cluster.State)
+	}
+	if !cluster.AllowInstallations {
+		return reject("cluster does not allow new installations")
+	}
+	if !cluster.HasNodePool(installation.NodePool) {
+		return reject("cluster has no node pool %s", installation.NodePool)
+	}
+	if !cluster.InZone(installation.Zone) {
+		return reject("cluster is not in zone %s", installation.Zone)
+	}
+
+	existingClusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:   model.AllPerPage,
+		ClusterID: cluster.ID,
+	})
+	if err != nil {
+		s.logger.WithError(err).Warnf("Failed to query cluster installations of cluster %s", cluster.ID)
+		return reject("failed to query cluster installations")
+	}
+
+	////////////////////////////////////////////////////////////////////////////
+	//                              MULTI-TENANCY                             //
+	////////////////////////////////////////////////////////////////////////////
+	// Current model:                                                         //
+	// - isolation=true  | 1 cluster installations                            //
+	// - isolation=false | X cluster installations, where "X" is as many as   //
+	//                     will fit with the given CPU and Memory threshold.  //
+	////////////////////////////////////////////////////////////////////////////
+	if installation.Affinity == model.InstallationAffinityIsolated {
+		if len(existingClusterInstallations) > 0 {
+			return reject("cluster already has %d installations", len(existingClusterInstallations))
+		}
+	} else if len(existingClusterInstallations) == 1 {
+		// This should be the only scenario where we need to check if the
+		// cluster installation running requires isolation or not.
+		existingInstallation, err := s.store.GetInstallation(existingClusterInstallations[0].InstallationID, true, false)
+		if err != nil {
+			s.logger.WithError(err).Warn("Unable to find installation")
+			return reject("failed to query existing installation")
+		}
+		if existingInstallation != nil && existingInstallation.Affinity == model.InstallationAffinityIsolated {
+			return reject("cluster already has an isolated installation %s", existingInstallation.ID)
+		}
+	}
+
+	size, err := mmv1alpha1.GetClusterSize(installation.Size)
+	if err != nil {
+		return reject("invalid installation size %s", installation.Size)
+	}
+
+	// An installation pinned to a node pool only uses the resources of that
+	// node pool, while other installations may run on any node.
+	clusterResources, err := s.provisioner.GetClusterResources(cluster, installation.NodePool, true)
+	if err != nil {
+		s.logger.WithError(err).Warnf("Failed to get resources of cluster %s", cluster.ID)
+		return reject("failed to get cluster resources")
+	}
+	if clusterResources == nil {
+		return reject("cluster resources are unknown")
+	}
+	if clusterResources.MilliTotalCPU == 0 || clusterResources.MilliTotalMemory == 0 {
+		if installation.NodePool != "" {
+			return reject("node pool %s has no schedulable nodes", installation.NodePool)
+		}
+		return reject("cluster has no schedulable nodes")
+	}
+
+	candidate.CPUPercent = clusterResources.CalculateCPUPercentUsed(size.CalculateCPUMilliRequirement(
+		installation.InternalDatabase(),
+		installation.InternalFilestore(),
+	))
+	candidate.MemoryPercent = clusterResources.CalculateMemoryPercentUsed(size.CalculateMemoryMilliRequirement(
+		installation.InternalDatabase(),
+		installation.InternalFilestore(),
+	))
+	if candidate.CPUPercent > s.clusterResourceThreshold || candidate.MemoryPercent > s.clusterResourceThreshold {
+		return reject("cluster would exceed the cluster load threshold (%d%%): CPU=%d%%, Memory=%d%%", s.clusterResourceThreshold, candidate.CPUPercent, candidate.MemoryPercent)
+	}
+
+	candidate.Fits = true
+
+	return candidate
+}
@@ -0,0 +1,305 @@
+package scheduling_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/scheduling"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/internal/tools/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStore struct {
+	Installations        map[string]*model.Installation
+	ClusterInstallations map[string][]*model.ClusterInstallation
+}
+
+func (s *mockStore) GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error) {
+	return s.Installations[installationID], nil
+}
+
+func (s *mockStore) GetClusterInstallations(filter *model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error) {
+	return s.ClusterInstallations[filter.ClusterID], nil
+}
+
+// mockProvisioner reports each cluster, or each node pool, as loaded by the
+// given percentage of both CPU and memory, and records the clusters it was
+// asked about. Node pools without a load have no nodes.
+type mockProvisioner struct {
+	Load         map[string]int64
+	NodePoolLoad map[string]int64
+	Evaluated    []string
+}
+
+func (p *mockProvisioner) GetClusterResources(cluster *model.Cluster, nodePool string, onlySchedulable bool) (*k8s.ClusterResources, error) {
+	p.Evaluated = append(p.Evaluated, cluster.ID)
+	load, ok := p.Load[cluster.ID]
+	if !ok {
+		return nil, errors.New("no resources")
+	}
+	if nodePool != "" {
+		load, ok = p.NodePoolLoad[nodePool]
+		if !ok {
+			return &k8s.ClusterResources{}, nil
+		}
+	}
+
+	return &k8s.ClusterResources{
+		MilliTotalCPU:    100000000,
+		MilliUsedCPU:     load * 1000000,
+		MilliTotalMemory: 100000000000000000,
+		MilliUsedMemory:  load * 1000000000000000,
+	}, nil
+}
+
+func newCluster(t *testing.T, id string) *model.Cluster {
+	cluster := &model.Cluster{
+		ID:                 id,
+		State:              model.ClusterStateStable,
+		AllowInstallations: true,
+	}
+	err := cluster.SetProviderMetadata(model.AWSMetadata{Zones: []string{"us-east-1a"}})
+	require.NoError(t, err)
+
+	return cluster
+}
+
+func newInstallation() *model.Installation {
+	return &model.Installation{
+		ID:        model.NewID(),
+		Size:      "100users",
+		Affinity:  model.InstallationAffinityMultiTenant,
+		Database:  model.InstallationDatabaseAwsRDS,
+		Filestore: model.InstallationFilestoreAwsS3,
+	}
+}
+
+func candidateIDs(decision *model.SchedulingDecision) []string {
+	var ids []string
+	for _, candidate := range decision.Candidates {
+		ids = append(ids, candidate.ClusterID)
+	}
+
+	return ids
+}
+
+func TestSchedulerStrategies(t *testing.T) {
+	clusters := []*model.Cluster{
+		newCluster(t, "medium"),
+		newCluster(t, "low"),
+		newCluster(t, "full"),
+		newCluster(t, "high"),
+	}
+	store := &mockStore{}
+	provisioner := &mockProvisioner{Load: map[string]int64{
+		"medium": 40,
+		"low":    10,
+		"full":   90,
+		"high":   70,
+	}}
+
+	testCases := []struct {
+		strategy string
+		expected []string
+	}{
+		{model.SchedulingStrategyBinPack, []string{"high", "medium", "low", "full"}},
+		{model.SchedulingStrategySpread, []string{"low", "medium", "high", "full"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.strategy, func(t *testing.T) {
+			scheduler := scheduling.NewScheduler(store, provisioner, 80, tc.strategy, testlib.MakeLogger(t))
+
+			decision, err := scheduler.Schedule(newInstallation(), clusters)
+			require.NoError(t, err)
+			assert.Equal(t, tc.strategy, decision.Strategy)
+			assert.Equal(t, tc.expected[0], decision.ClusterID)
+			assert.Equal(t, tc.expected, candidateIDs(decision))
+
+			full := decision.Candidates[len(decision.Candidates)-1]
+			assert.False(t, full.Fits)
+			assert.Contains(t, full.Reason, "threshold")
+		})
+	}
+
+	t.Run(model.SchedulingStrategyFirstFit, func(t *testing.T) {
+		provisioner := &mockProvisioner{Load: provisioner.Load}
+		scheduler := scheduling.NewScheduler(store, provisioner, 80, model.SchedulingStrategyFirstFit, testlib.MakeLogger(t))
+
+		decision, err := scheduler.Schedule(newInstallation(), clusters)
+		require.NoError(t, err)
+		assert.Equal(t, model.SchedulingStrategyFirstFit, decision.Strategy)
+		assert.Equal(t, "medium", decision.ClusterID)
+		assert.Equal(t, []string{"medium", "low", "full", "high"}, candidateIDs(decision))
+
+		// The clusters after the first one that fits are not evaluated.
+		assert.Equal(t, []string{"medium"}, provisioner.Evaluated)
+		for _, candidate := range decision.Candidates[1:] {
+			assert.False(t, candidate.Fits)
+			assert.Contains(t, candidate.Reason, "not evaluated")
+		}
+	})
+
+	t.Run("installation strategy overrides the default", func(t *testing.T) {
+		scheduler := scheduling.NewScheduler(store, provisioner, 80, model.SchedulingStrategyFirstFit, testlib.MakeLogger(t))
+
+		installation := newInstallation()
+		installation.SchedulingStrategy = model.SchedulingStrategySpread
+
+		decision, err := scheduler.Schedule(installation, clusters)
+		require.NoError(t, err)
+		assert.Equal(t, model.SchedulingStrategySpread, decision.Strategy)
+		assert.Equal(t, "low", decision.ClusterID)
+	})
+
+	t.Run("is first fit", func(t *testing.T) {
+		scheduler := scheduling.NewScheduler(store, provisioner, 80, model.SchedulingStrategyFirstFit, testlib.MakeLogger(t))
+
+		installation := newInstallation()
+		assert.True(t, scheduler.IsFirstFit(installation))
+
+		installation.SchedulingStrategy = model.SchedulingStrategyBinPack
+		assert.False(t, scheduler.IsFirstFit(installation))
+	})
+
+	t.Run("unsupported strategy", func(t *testing.T) {
+		scheduler := scheduling.NewScheduler(store, provisioner, 80, "random", testlib.MakeLogger(t))
+
+		decision, err := scheduler.Schedule(newInstallation(), clusters)
+		require.Error(t, err)
+		require.Nil(t, decision)
+	})
+}
+
+func TestSchedulerNoClusterFits(t *testing.T) {
+	scheduler := scheduling.NewScheduler(&mockStore{}, &mockProvisioner{}, 80, model.SchedulingStrategyFirstFit, testlib.MakeLogger(t))
+
+	decision, err := scheduler.Schedule(newInstallation(), []*model.Cluster{newCluster(t, "unknown")})
+	require.NoError(t, err)
+	assert.Empty(t, decision.ClusterID)
+	require.Len(t, decision.Candidates, 1)
+	assert.False(t, decision.Candidates[0].Fits)
+	assert.Equal(t, "failed to get cluster resources", decision.Candidates[0].Reason)
+}
+
+func TestSchedulerEvaluate(t *testing.T) {
+	isolated := newInstallation()
+	isolated.Affinity = model.InstallationAffinityIsolated
+
+	store := &mockStore{
+		Installations: map[string]*model.Installation{
+			isolated.ID: isolated,
+		},
+		ClusterInstallations: map[string][]*model.ClusterInstallation{
+			"isolated": {{ClusterID: "isolated", InstallationID: isolated.ID}},
+		},
+	}
+	provisioner := &mockProvisioner{
+		Load: map[string]int64{
+			"cluster":  10,
+			"isolated": 10,
+		},
+		NodePoolLoad: map[string]int64{
+			"memory": 50,
+		},
+	}
+	scheduler := scheduling.NewScheduler(store, provisioner, 80, model.SchedulingStrategyFirstFit, testlib.MakeLogger(t))
+
+	withNodePools := func(t *testing.T, cluster *model.Cluster) {
+		err := cluster.SetProvisionerMetadata(model.KopsMetadata{
+			InstanceGroups: []model.KopsInstanceGroup{{Name: "memory"}, {Name: "spot"}},
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("fits", func(t *testing.T) {
+		candidate := scheduler.Evaluate(newCluster(t, "cluster"), newInstallation())
+		assert.True(t, candidate.Fits)
+		assert.Empty(t, candidate.Reason)
+		assert.Equal(t, 10, candidate.CPUPercent)
+		assert.Equal(t, 10, candidate.MemoryPercent)
+	})
+
+	t.Run("fits in node pool", func(t *testing.T) {
+		cluster := newCluster(t, "cluster")
+		withNodePools(t, cluster)
+		installation := newInstallation()
+		installation.NodePool = "memory"
+
+		candidate := scheduler.Evaluate(cluster, installation)
+		assert.True(t, candidate.Fits)
+		assert.Equal(t, 50, candidate.CPUPercent)
+		assert.Equal(t, 50, candidate.MemoryPercent)
+	})
+
+	t.Run("node pool without nodes", func(t *testing.T) {
+		cluster := newCluster(t, "cluster")
+		withNodePools(t, cluster)
+		installation := newInstallation()
+		installation.NodePool = "spot"
+
+		candidate := scheduler.Evaluate(cluster, installation)
+		assert.False(t, candidate.Fits)
+		assert.Equal(t, "node pool spot has no schedulable nodes", candidate.Reason)
+	})
+
+	testCases := []struct {
+		description  string
+		cluster      func(*model.Cluster)
+		installation func(*model.Installation)
+		reason       string
+	}{
+		{
+			"unstable cluster",
+			func(c *model.Cluster) { c.State = model.ClusterStateUpgradeRequested },
+			func(i *model.Installation) {},
+			"cluster is not stable (currently upgrade-requested)",
+		},
+		{
+			"installations not allowed",
+			func(c *model.Cluster) { c.AllowInstallations = false },
+			func(i *model.Installation) {},
+			"cluster does not allow new installations",
+		},
+		{
+			"missing node pool",
+			func(c *model.Cluster) {},
+			func(i *model.Installation) { i.NodePool = "memory" },
+			"cluster has no node pool memory",
+		},
+		{
+			"other zone",
+			func(c *model.Cluster) {},
+			func(i *model.Installation) { i.Zone = "us-east-1b" },
+			"cluster is not in zone us-east-1b",
+		},
+		{
+			"isolated installation on a used cluster",
+			func(c *model.Cluster) { c.ID = "isolated" },
+			func(i *model.Installation) { i.Affinity = model.InstallationAffinityIsolated },
+			"cluster already has 1 installations",
+		},
+		{
+			"cluster used by an isolated installation",
+			func(c *model.Cluster) { c.ID = "isolated" },
+			func(i *model.Installation) {},
+			"cluster already has an isolated installation " + isolated.ID,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cluster := newCluster(t, "cluster")
+			tc.cluster(cluster)
+			installation := newInstallation()
+			tc.installation(installation)
+
+			candidate := scheduler.Evaluate(cluster, installation)
+			assert.False(t, candidate.Fits)
+			assert.Equal(t, tc.reason, candidate.Reason)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package scheduling
+
+import (
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// Strategy chooses among the clusters an installation fits on.
+type Strategy interface {
+	// Prefer returns true if the installation should rather be scheduled onto
+	// the first candidate than onto the second. Candidates are otherwise
+	// kept in the order of the clusters, from oldest to newest.
+	Prefer(a, b *model.SchedulingCandidate) bool
+}
+
+// strategies maps the supported scheduling strategies to their
+// implementation.
+var strategies = map[string]Strategy{
+	model.SchedulingStrategyFirstFit: firstFit{},
+	model.SchedulingStrategyBinPack:  binPack{},
+	model.SchedulingStrategySpread:   spread{},
+}
+
+// GetStrategy returns the implementation of the named scheduling strategy, or
+// nil if the strategy is not supported.
+func GetStrategy(name string) Strategy {
+	return strategies[name]
+}
+
+// firstFit prefers the oldest cluster.
+type firstFit struct{}
+
+func (firstFit) Prefer(a, b *model.SchedulingCandidate) bool {
+	return false
+}
+
+// binPack prefers the most loaded cluster.
+type binPack struct{}
+
+func (binPack) Prefer(a, b *model.SchedulingCandidate) bool {
+	return load(a) > load(b)
+}
+
+// spread prefers the least loaded cluster.
+type spread struct{}
+
+func (spread) Prefer(a, b *model.SchedulingCandidate) bool {
+	return load(a) < load(b)
+}
+
+// load returns the expected load of the cluster of the candidate, which is
+// the highest of its CPU and memory load.
+func load(candidate *model.SchedulingCandidate) int {
+	if candidate.CPUPercent > candidate.MemoryPercent {
+		return candidate.CPUPercent
+	}
+
+	return candidate.MemoryPercent
+}
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+var apiKeySelect sq.SelectBuilder
+
+func init() {
+	apiKeySelect = sq.
+		Select(
+			"ID", "Description", "KeyHash", "ScopesRaw", "OwnerID",
+			"CreateAt", "DeleteAt",
+		).
+		From("APIKey")
+}
+
+type rawAPIKey struct {
+	*model.APIKey
+	ScopesRaw []byte
+}
+
+type rawAPIKeys []*rawAPIKey
+
+func (r *rawAPIKey) toAPIKey() (*model.APIKey, error) {
+	// We only need to set values that are converted from a raw database format.
+	r.APIKey.Scopes = nil
+	if r.ScopesRaw != nil {
+		err := json.Unmarshal(r.ScopesRaw, &r.APIKey.Scopes)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal Scopes")
+		}
+	}
+
+	return r.APIKey, nil
+}
+
+func (rs *rawAPIKeys) toAPIKeys() ([]*model.APIKey, error) {
+	var apiKeys []*model.APIKey
+	for _, rawAPIKey := range *rs {
+		apiKey, err := rawAPIKey.toAPIKey()
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	return apiKeys, nil
+}
+
+// GetAPIKey fetches the given API key by id.
+func (sqlStore *SQLStore) GetAPIKey(id string) (*model.APIKey, error) {
+	var rawAPIKey rawAPIKey
+	err := sqlStore.getBuilder(sqlStore.db, &rawAPIKey,
+		apiKeySelect.Where("ID = ?", id),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get API key by id")
+	}
+
+	return rawAPIKey.toAPIKey()
+}
+
+// GetAPIKeyByHash fetches the API key with the given key hash.
+func (sqlStore *SQLStore) GetAPIKeyByHash(keyHash string) (*model.APIKey, error) {
+	var rawAPIKey rawAPIKey
+	err := sqlStore.getBuilder(sqlStore.db, &rawAPIKey,
+		apiKeySelect.Where("KeyHash = ?", keyHash),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get API key by hash")
+	}
+
+	return rawAPIKey.toAPIKey()
+}
+
+// GetAPIKeys fetches the given page of created API keys. The first page is 0.
+func (sqlStore *SQLStore) GetAPIKeys(filter *model.APIKeyFilter) ([]*model.APIKey, error) {
+	builder := apiKeySelect.
+		OrderBy("CreateAt ASC")
+
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	if filter.OwnerID != "" {
+		builder = builder.Where("OwnerID = ?", filter.OwnerID)
+	}
+	if !filter.IncludeDeleted {
+		builder = builder.Where("DeleteAt = 0")
+	}
+
+	var rawAPIKeys rawAPIKeys
+	err := sqlStore.selectBuilder(sqlStore.db, &rawAPIKeys, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for API keys")
+	}
+
+	return rawAPIKeys.toAPIKeys()
+}
+
+// HasActiveAPIKeys returns whether any API key has been created and not yet
+// revoked.
+func (sqlStore *SQLStore) HasActiveAPIKeys() (bool, error) {
+	var result countResult
+	err := sqlStore.selectBuilder(sqlStore.db, &result, sq.
+		Select("Count (*)").
+		From("APIKey").
+		Where("DeleteAt = 0"),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to count API keys")
+	}
+	count, err := result.value()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// CreateAPIKey records the given API key to the database, assigning it a
+// unique ID.
+func (sqlStore *SQLStore) CreateAPIKey(apiKey *model.APIKey) error {
+	apiKey.ID = model.NewID()
+	apiKey.CreateAt = GetMillis()
+
+	scopesJSON, err := json.Marshal(apiKey.Scopes)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal Scopes")
+	}
+
+	_, err = sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("APIKey").
+		SetMap(map[string]interface{}{
+			"ID":          apiKey.ID,
+			"Description": apiKey.Description,
+			"KeyHash":     apiKey.KeyHash,
+			"ScopesRaw":   string(scopesJSON),
+			"OwnerID":     apiKey.OwnerID,
+			"CreateAt":    apiKey.CreateAt,
+			"DeleteAt":    0,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create API key")
+	}
+
+	return nil
+}
+
+// DeleteAPIKey marks the given API key as deleted, revoking it, but does not
+// remove the record from the database.
+func (sqlStore *SQLStore) DeleteAPIKey(id string) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("APIKey").
+		Set("DeleteAt", GetMillis()).
+		Where("ID = ?", id).
+		Where("DeleteAt = 0"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to mark API key as deleted")
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeys(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	t.Run("get unknown API key", func(t *testing.T) {
+		apiKey, err := sqlStore.GetAPIKey("unknown")
+		require.NoError(t, err)
+		require.Nil(t, apiKey)
+
+		apiKey, err = sqlStore.GetAPIKeyByHash("unknown")
+		require.NoError(t, err)
+		require.Nil(t, apiKey)
+	})
+
+	t.Run("no active API keys", func(t *testing.T) {
+		hasActiveAPIKeys, err := sqlStore.HasActiveAPIKeys()
+		require.NoError(t, err)
+		require.False(t, hasActiveAPIKeys)
+	})
+
+	apiKey1 := &model.APIKey{
+		Description: "admin",
+		KeyHash:     model.HashAPIKey("key1"),
+		Scopes:      []string{model.APIKeyScopeClusterAdmin},
+	}
+	err := sqlStore.CreateAPIKey(apiKey1)
+	require.NoError(t, err)
+
+	time.Sleep(1 * time.Millisecond)
+
+	apiKey2 := &model.APIKey{
+		Description: "billing",
+		KeyHash:     model.HashAPIKey("key2"),
+		Scopes:      []string{model.APIKeyScopeReadOnly, model.APIKeyScopeInstallationAdmin},
+		OwnerID:     "owner",
+	}
+	err = sqlStore.CreateAPIKey(apiKey2)
+	require.NoError(t, err)
+
+	t.Run("active API keys", func(t *testing.T) {
+		hasActiveAPIKeys, err := sqlStore.HasActiveAPIKeys()
+		require.NoError(t, err)
+		require.True(t, hasActiveAPIKeys)
+	})
+
+	t.Run("duplicate key hash", func(t *testing.T) {
+		err := sqlStore.CreateAPIKey(&model.APIKey{
+			KeyHash: model.HashAPIKey("key1"),
+			Scopes:  []string{model.APIKeyScopeReadOnly},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("get API keys", func(t *testing.T) {
+		apiKey, err := sqlStore.GetAPIKey(apiKey1.ID)
+		require.NoError(t, err)
+		require.Equal(t, apiKey1, apiKey)
+
+		apiKey, err = sqlStore.GetAPIKeyByHash(model.HashAPIKey("key2"))
+		require.NoError(t, err)
+		require.Equal(t, apiKey2, apiKey)
+
+		apiKeys, err := sqlStore.GetAPIKeys(&model.APIKeyFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.APIKey{apiKey1, apiKey2}, apiKeys)
+
+		apiKeys, err = sqlStore.GetAPIKeys(&model.APIKeyFilter{OwnerID: "owner", PerPage: 10})
+		require.NoError(t, err)
+		require.Equal(t, []*model.APIKey{apiKey2}, apiKeys)
+
+		apiKeys, err = sqlStore.GetAPIKeys(&model.APIKeyFilter{Page: 1, PerPage: 1})
+		require.NoError(t, err)
+		require.Equal(t, []*model.APIKey{apiKey2}, apiKeys)
+	})
+
+	t.Run("delete API key", func(t *testing.T) {
+		err := sqlStore.DeleteAPIKey(apiKey1.ID)
+		require.NoError(t, err)
+
+		apiKey, err := sqlStore.GetAPIKey(apiKey1.ID)
+		require.NoError(t, err)
+		require.True(t, apiKey.IsDeleted())
+
+		apiKeys, err := sqlStore.GetAPIKeys(&model.APIKeyFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.APIKey{apiKey2}, apiKeys)
+
+		apiKeys, err = sqlStore.GetAPIKeys(&model.APIKeyFilter{PerPage: model.AllPerPage, IncludeDeleted: true})
+		require.NoError(t, err)
+		require.Len(t, apiKeys, 2)
+	})
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+var auditRecordSelect sq.SelectBuilder
+
+func init() {
+	auditRecordSelect = sq.
+		Select(
+			"ID", "RequestID", "APIKeyID", "RemoteAddr", "Method", "Route",
+			"Path", "ResourceID", "RequestBody", "StatusCode", "CreateAt",
+		).
+		From("AuditRecord")
+}
+
+// GetAuditRecords fetches the given page of audit records. The first page is 0.
+func (sqlStore *SQLStore) GetAuditRecords(filter *model.AuditRecordFilter) ([]*model.AuditRecord, error) {
+	builder := auditRecordSelect.
+		OrderBy("CreateAt ASC")
+
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	if filter.APIKeyID != "" {
+		builder = builder.Where("APIKeyID = ?", filter.APIKeyID)
+	}
+	if filter.ResourceID != "" {
+		builder = builder.Where("ResourceID = ?", filter.ResourceID)
+	}
+
+	var auditRecords []*model.AuditRecord
+	err := sqlStore.selectBuilder(sqlStore.db, &auditRecords, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for audit records")
+	}
+
+	return auditRecords, nil
+}
+
+// CreateAuditRecord records the given audit record to the database, assigning it a unique ID.
+func (sqlStore *SQLStore) CreateAuditRecord(auditRecord *model.AuditRecord) error {
+	auditRecord.ID = model.NewID()
+	auditRecord.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("AuditRecord").
+		SetMap(map[string]interface{}{
+			"ID":          auditRecord.ID,
+			"RequestID":   auditRecord.RequestID,
+			"APIKeyID":    auditRecord.APIKeyID,
+			"RemoteAddr":  auditRecord.RemoteAddr,
+			"Method":      auditRecord.Method,
+			"Route":       auditRecord.Route,
+			"Path":        auditRecord.Path,
+			"ResourceID":  auditRecord.ResourceID,
+			"RequestBody": auditRecord.RequestBody,
+			"StatusCode":  auditRecord.StatusCode,
+			"CreateAt":    auditRecord.CreateAt,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create audit record")
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRecords(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	apiKeyID := model.NewID()
+	clusterID := model.NewID()
+
+	auditRecord1 := &model.AuditRecord{
+		RequestID:   model.NewID(),
+		APIKeyID:    apiKeyID,
+		RemoteAddr:  "127.0.0.1:1234",
+		Method:      "POST",
+		Route:       "/api/clusters",
+		Path:        "/api/clusters",
+		ResourceID:  clusterID,
+		RequestBody: `{"Provider":"aws"}`,
+		StatusCode:  202,
+	}
+	auditRecord2 := &model.AuditRecord{
+		RequestID:  model.NewID(),
+		RemoteAddr: "127.0.0.1:1234",
+		Method:     "DELETE",
+		Route:      "/api/cluster/{cluster}",
+		Path:       "/api/cluster/" + clusterID,
+		ResourceID: clusterID,
+		StatusCode: 202,
+	}
+	auditRecord3 := &model.AuditRecord{
+		RequestID:  model.NewID(),
+		APIKeyID:   apiKeyID,
+		RemoteAddr: "127.0.0.1:1234",
+		Method:     "POST",
+		Route:      "/api/installations",
+		Path:       "/api/installations",
+		StatusCode: 400,
+	}
+
+	for _, auditRecord := range []*model.AuditRecord{auditRecord1, auditRecord2, auditRecord3} {
+		err := sqlStore.CreateAuditRecord(auditRecord)
+		require.NoError(t, err)
+		require.NotEmpty(t, auditRecord.ID)
+		require.NotZero(t, auditRecord.CreateAt)
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	testCases := []struct {
+		Description string
+		Filter      *model.AuditRecordFilter
+		Expected    []*model.AuditRecord
+	}{
+		{
+			"all",
+			&model.AuditRecordFilter{PerPage: model.AllPerPage},
+			[]*model.AuditRecord{auditRecord1, auditRecord2, auditRecord3},
+		},
+		{
+			"page 0, perPage 2",
+			&model.AuditRecordFilter{Page: 0, PerPage: 2},
+			[]*model.AuditRecord{auditRecord1, auditRecord2},
+		},
+		{
+			"page 1, perPage 2",
+			&model.AuditRecordFilter{Page: 1, PerPage: 2},
+			[]*model.AuditRecord{auditRecord3},
+		},
+		{
+			"api key",
+			&model.AuditRecordFilter{APIKeyID: apiKeyID, PerPage: 10},
+			[]*model.AuditRecord{auditRecord1, auditRecord3},
+		},
+		{
+			"resource id",
+			&model.AuditRecordFilter{ResourceID: clusterID, PerPage: 10},
+			[]*model.AuditRecord{auditRecord1, auditRecord2},
+		},
+		{
+			"unknown resource",
+			&model.AuditRecordFilter{ResourceID: model.NewID(), PerPage: 10},
+			nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Description, func(t *testing.T) {
+			actual, err := sqlStore.GetAuditRecords(testCase.Filter)
+			require.NoError(t, err)
+			require.Equal(t, testCase.Expected, actual)
+		})
+	}
+}
@@ -16,6 +16,7 @@ func init() {
 			"ID", "Provider", "Provisioner", "ProviderMetadata", "ProvisionerMetadata",
 			"Version", "Size", "State", "AllowInstallations", "CreateAt", "DeleteAt",
 			"LockAcquiredBy", "LockAcquiredAt", "UtilityMetadata",
+			"Attempts", "LastError", "NextAttemptAt",
 		).
 		From("Cluster")
 }
@@ -39,6 +40,7 @@ func (sqlStore *SQLStore) GetUnlockedClustersPendingWork() ([]*model.Cluster, er
 		Where(sq.Eq{
 			"State": model.AllClusterStatesPendingWork,
 		}).
+		Where("NextAttemptAt <= ?", GetMillis()).
 		Where("LockAcquiredAt = 0").
 		OrderBy("CreateAt ASC")
 
@@ -72,6 +74,18 @@ func (sqlStore *SQLStore) GetClusters(filter *model.ClusterFilter) ([]*model.Clu
 			Offset(uint64(filter.Page * filter.PerPage))
 	}
 
+	if len(filter.States) > 0 {
+		builder = builder.Where(sq.Eq{"State": filter.States})
+	}
+	if filter.Size != "" {
+		builder = builder.Where("Size = ?", filter.Size)
+	}
+	if filter.Version != "" {
+		builder = builder.Where("Version = ?", filter.Version)
+	}
+	if filter.AllowInstallations != nil {
+		builder = builder.Where("AllowInstallations = ?", *filter.AllowInstallations)
+	}
 	if !filter.IncludeDeleted {
 		builder = builder.Where("DeleteAt = 0")
 	}
@@ -130,6 +144,9 @@ func (sqlStore *SQLStore) UpdateCluster(cluster *model.Cluster) error {
 			"State":               cluster.State,
 			"AllowInstallations":  cluster.AllowInstallations,
 			"UtilityMetadata":     cluster.UtilityMetadata,
+			"Attempts":            cluster.Attempts,
+			"LastError":           cluster.LastError,
+			"NextAttemptAt":       cluster.NextAttemptAt,
 		}).
 		Where("ID = ?", cluster.ID),
 	)
@@ -140,6 +157,25 @@ func (sqlStore *SQLStore) UpdateCluster(cluster *model.Cluster) error {
 	return nil
 }
 
+// UpdateClusterAttempts updates the given cluster's record of failed attempts
+// to transition it out of its current state.
+func (sqlStore *SQLStore) UpdateClusterAttempts(cluster *model.Cluster) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Cluster").
+		SetMap(map[string]interface{}{
+			"Attempts":      cluster.Attempts,
+			"LastError":     cluster.LastError,
+			"NextAttemptAt": cluster.NextAttemptAt,
+		}).
+		Where("ID = ?", cluster.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update cluster attempts")
+	}
+
+	return nil
+}
+
 // DeleteCluster marks the given cluster as deleted, but does not remove the record from the
 // database.
 func (sqlStore *SQLStore) DeleteCluster(id string) error {
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+var clusterSizeSelect sq.SelectBuilder
+
+func init() {
+	clusterSizeSelect = sq.
+		Select(
+			"Name", "NodeCount", "NodeInstanceType", "MasterCount",
+			"MasterInstanceType", "CreateAt",
+		).
+		From("ClusterSize")
+}
+
+// GetClusterSize fetches the given custom cluster size by name.
+func (sqlStore *SQLStore) GetClusterSize(name string) (*model.ClusterSize, error) {
+	var clusterSize model.ClusterSize
+	err := sqlStore.getBuilder(sqlStore.db, &clusterSize,
+		clusterSizeSelect.Where("Name = ?", name),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster size by name")
+	}
+
+	return &clusterSize, nil
+}
+
+// GetClusterSizes fetches all custom cluster sizes, sorted by name.
+func (sqlStore *SQLStore) GetClusterSizes() ([]*model.ClusterSize, error) {
+	var clusterSizes []*model.ClusterSize
+	err := sqlStore.selectBuilder(sqlStore.db, &clusterSizes,
+		clusterSizeSelect.OrderBy("Name ASC"),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for cluster sizes")
+	}
+
+	return clusterSizes, nil
+}
+
+// CreateClusterSize records the given custom cluster size to the database.
+func (sqlStore *SQLStore) CreateClusterSize(clusterSize *model.ClusterSize) error {
+	clusterSize.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("ClusterSize").
+		SetMap(map[string]interface{}{
+			"Name":               clusterSize.Name,
+			"NodeCount":          clusterSize.NodeCount,
+			"NodeInstanceType":   clusterSize.NodeInstanceType,
+			"MasterCount":        clusterSize.MasterCount,
+			"MasterInstanceType": clusterSize.MasterInstanceType,
+			"CreateAt":           clusterSize.CreateAt,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create cluster size")
+	}
+
+	return nil
+}
+
+// DeleteClusterSize removes the given custom cluster size from the database.
+func (sqlStore *SQLStore) DeleteClusterSize(name string) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Delete("ClusterSize").
+		Where("Name = ?", name),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete cluster size")
+	}
+
+	return nil
+}
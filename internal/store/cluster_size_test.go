@@ -0,0 +1,83 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterSizes(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	t.Run("get unknown cluster size", func(t *testing.T) {
+		clusterSize, err := sqlStore.GetClusterSize("unknown")
+		require.NoError(t, err)
+		require.Nil(t, clusterSize)
+	})
+
+	t.Run("get no cluster sizes", func(t *testing.T) {
+		clusterSizes, err := sqlStore.GetClusterSizes()
+		require.NoError(t, err)
+		require.Empty(t, clusterSizes)
+	})
+
+	clusterSize1 := &model.ClusterSize{
+		Name:               "SizeLarge",
+		NodeCount:          10,
+		NodeInstanceType:   "m5.2xlarge",
+		MasterCount:        3,
+		MasterInstanceType: "t3.large",
+	}
+	err := sqlStore.CreateClusterSize(clusterSize1)
+	require.NoError(t, err)
+	require.NotZero(t, clusterSize1.CreateAt)
+
+	clusterSize2 := &model.ClusterSize{
+		Name:               "SizeCompute",
+		NodeCount:          4,
+		NodeInstanceType:   "c5.xlarge",
+		MasterCount:        1,
+		MasterInstanceType: "t3.medium",
+	}
+	err = sqlStore.CreateClusterSize(clusterSize2)
+	require.NoError(t, err)
+
+	t.Run("duplicate name", func(t *testing.T) {
+		err := sqlStore.CreateClusterSize(&model.ClusterSize{
+			Name:               "SizeLarge",
+			NodeCount:          1,
+			NodeInstanceType:   "m5.large",
+			MasterCount:        1,
+			MasterInstanceType: "t3.medium",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("get cluster size", func(t *testing.T) {
+		clusterSize, err := sqlStore.GetClusterSize(clusterSize1.Name)
+		require.NoError(t, err)
+		require.Equal(t, clusterSize1, clusterSize)
+	})
+
+	t.Run("get cluster sizes", func(t *testing.T) {
+		clusterSizes, err := sqlStore.GetClusterSizes()
+		require.NoError(t, err)
+		require.Equal(t, []*model.ClusterSize{clusterSize2, clusterSize1}, clusterSizes)
+	})
+
+	t.Run("delete cluster size", func(t *testing.T) {
+		err := sqlStore.DeleteClusterSize(clusterSize2.Name)
+		require.NoError(t, err)
+
+		clusterSize, err := sqlStore.GetClusterSize(clusterSize2.Name)
+		require.NoError(t, err)
+		require.Nil(t, clusterSize)
+
+		clusterSizes, err := sqlStore.GetClusterSizes()
+		require.NoError(t, err)
+		require.Equal(t, []*model.ClusterSize{clusterSize1}, clusterSizes)
+	})
+}
@@ -84,6 +84,23 @@ func TestClusters(t *testing.T) {
 		actualClusters, err = sqlStore.GetClusters(&model.ClusterFilter{PerPage: model.AllPerPage, IncludeDeleted: true})
 		require.NoError(t, err)
 		require.Equal(t, []*model.Cluster{cluster1, cluster2}, actualClusters)
+
+		actualClusters, err = sqlStore.GetClusters(&model.ClusterFilter{PerPage: model.AllPerPage, States: []string{model.ClusterStateStable, model.ClusterStateDeleted}})
+		require.NoError(t, err)
+		require.Equal(t, []*model.Cluster{cluster2}, actualClusters)
+
+		actualClusters, err = sqlStore.GetClusters(&model.ClusterFilter{PerPage: model.AllPerPage, Size: model.SizeAlef500})
+		require.NoError(t, err)
+		require.Equal(t, []*model.Cluster{cluster1, cluster2}, actualClusters)
+
+		actualClusters, err = sqlStore.GetClusters(&model.ClusterFilter{PerPage: model.AllPerPage, Version: "1.15.0"})
+		require.NoError(t, err)
+		require.Empty(t, actualClusters)
+
+		allowInstallations := false
+		actualClusters, err = sqlStore.GetClusters(&model.ClusterFilter{PerPage: model.AllPerPage, AllowInstallations: &allowInstallations})
+		require.NoError(t, err)
+		require.Equal(t, []*model.Cluster{cluster1}, actualClusters)
 	})
 
 	t.Run("update clusters", func(t *testing.T) {
@@ -294,6 +311,45 @@ func TestGetUnlockedClustersPendingWork(t *testing.T) {
 	require.Empty(t, clusters)
 }
 
+func TestUpdateClusterAttempts(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	cluster := &model.Cluster{
+		State: model.ClusterStateCreationRequested,
+	}
+	err := sqlStore.CreateCluster(cluster)
+	require.NoError(t, err)
+
+	cluster.Attempts = 3
+	cluster.LastError = "failed"
+	cluster.NextAttemptAt = GetMillis() + time.Hour.Milliseconds()
+	cluster.State = model.ClusterStateStable
+
+	err = sqlStore.UpdateClusterAttempts(cluster)
+	require.NoError(t, err)
+
+	storedCluster, err := sqlStore.GetCluster(cluster.ID)
+	require.NoError(t, err)
+	require.Equal(t, 3, storedCluster.Attempts)
+	require.Equal(t, "failed", storedCluster.LastError)
+	require.Equal(t, cluster.NextAttemptAt, storedCluster.NextAttemptAt)
+	require.Equal(t, model.ClusterStateCreationRequested, storedCluster.State)
+
+	// Clusters backing off are not pending work until the next attempt is due.
+	clusters, err := sqlStore.GetUnlockedClustersPendingWork()
+	require.NoError(t, err)
+	require.Empty(t, clusters)
+
+	cluster.NextAttemptAt = GetMillis() - 1
+	err = sqlStore.UpdateClusterAttempts(cluster)
+	require.NoError(t, err)
+
+	clusters, err = sqlStore.GetUnlockedClustersPendingWork()
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+}
+
 func TestLockCluster(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := MakeTestSQLStore(t, logger)
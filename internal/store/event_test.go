@@ -0,0 +1,89 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvents(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	clusterID := model.NewID()
+	installationID := model.NewID()
+
+	event1 := &model.Event{
+		ResourceType: model.TypeCluster,
+		ResourceID:   clusterID,
+		OldState:     model.ClusterStateCreationRequested,
+		NewState:     model.ClusterStateStable,
+		Timestamp:    1,
+		InstanceID:   "instance1",
+	}
+	event2 := &model.Event{
+		ResourceType: model.TypeInstallation,
+		ResourceID:   installationID,
+		OldState:     model.InstallationStateCreationRequested,
+		NewState:     model.InstallationStateCreationFailed,
+		Timestamp:    2,
+		InstanceID:   "instance2",
+		ErrorMessage: "failed to create",
+	}
+	event3 := &model.Event{
+		ResourceType: model.TypeInstallation,
+		ResourceID:   installationID,
+		OldState:     model.InstallationStateCreationFailed,
+		NewState:     model.InstallationStateDeletionRequested,
+		Timestamp:    3,
+		InstanceID:   "instance1",
+	}
+
+	for _, event := range []*model.Event{event1, event2, event3} {
+		err := sqlStore.CreateEvent(event)
+		require.NoError(t, err)
+		require.NotEmpty(t, event.ID)
+	}
+
+	testCases := []struct {
+		Description string
+		Filter      *model.EventFilter
+		Expected    []*model.Event
+	}{
+		{
+			"all",
+			&model.EventFilter{PerPage: model.AllPerPage},
+			[]*model.Event{event1, event2, event3},
+		},
+		{
+			"page 1, perPage 2",
+			&model.EventFilter{Page: 1, PerPage: 2},
+			[]*model.Event{event3},
+		},
+		{
+			"resource type",
+			&model.EventFilter{ResourceType: model.TypeCluster, PerPage: 10},
+			[]*model.Event{event1},
+		},
+		{
+			"resource id",
+			&model.EventFilter{ResourceID: installationID, PerPage: 10},
+			[]*model.Event{event2, event3},
+		},
+		{
+			"unknown resource",
+			&model.EventFilter{ResourceID: model.NewID(), PerPage: 10},
+			nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Description, func(t *testing.T) {
+			actual, err := sqlStore.GetEvents(testCase.Filter)
+			require.NoError(t, err)
+			require.Equal(t, testCase.Expected, actual)
+		})
+	}
+}
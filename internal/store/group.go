@@ -3,6 +3,7 @@ package store
 import (
 	"database/sql"
 	"reflect"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/mattermost/mattermost-cloud/model"
@@ -204,6 +205,15 @@ func (sqlStore *SQLStore) GetGroups(filter *model.GroupFilter) ([]*model.Group,
 			Offset(uint64(filter.Page * filter.PerPage))
 	}
 
+	if filter.Name != "" {
+		builder = builder.Where(`LOWER(Name) LIKE ? ESCAPE '\'`, containsPattern(strings.ToLower(filter.Name)))
+	}
+	if filter.Version != "" {
+		builder = builder.Where("Version = ?", filter.Version)
+	}
+	if filter.Image != "" {
+		builder = builder.Where("Image = ?", filter.Image)
+	}
 	if !filter.IncludeDeleted {
 		builder = builder.Where("DeleteAt = 0")
 	}
@@ -72,9 +72,10 @@ func TestGroups(t *testing.T) {
 	time.Sleep(1 * time.Millisecond)
 
 	group2 := &model.Group{
-		Name:        "name2",
+		Name:        "Name2",
 		Description: "description2",
 		Version:     "version2",
+		Image:       "image2",
 	}
 
 	err = sqlStore.CreateGroup(group2)
@@ -165,6 +166,38 @@ func TestGroups(t *testing.T) {
 			},
 			[]*model.Group{group1, group2, group3, group4},
 		},
+		{
+			"filter by partial name, case-insensitively",
+			&model.GroupFilter{
+				Name:    "NAME2",
+				PerPage: 10,
+			},
+			[]*model.Group{group2},
+		},
+		{
+			"filter by name matches wildcards literally",
+			&model.GroupFilter{
+				Name:    "name_",
+				PerPage: 10,
+			},
+			nil,
+		},
+		{
+			"filter by version",
+			&model.GroupFilter{
+				Version: "version3",
+				PerPage: 10,
+			},
+			[]*model.Group{group3},
+		},
+		{
+			"filter by image",
+			&model.GroupFilter{
+				Image:   "image2",
+				PerPage: 10,
+			},
+			[]*model.Group{group2},
+		},
 	}
 
 	for _, testCase := range testCases {
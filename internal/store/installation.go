@@ -3,8 +3,10 @@ package store
 import (
 	"database/sql"
 	"encoding/json"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 )
@@ -15,9 +17,12 @@ func init() {
 	installationSelect = sq.
 		Select(
 			"ID", "OwnerID", "Version", "Image", "DNS", "Database", "Filestore", "Size",
-			"Affinity", "GroupID", "GroupSequence", "State", "License",
-			"MattermostEnvRaw", "CreateAt", "DeleteAt", "LockAcquiredBy",
-			"LockAcquiredAt",
+			"Affinity", "NodePool", "Zone", "SchedulingStrategy", "GroupID", "GroupSequence", "State", "License",
+			"MattermostEnvRaw", "DNSAliasesRaw", "DNSRecordsRaw",
+			"RestoredFromBackupID", "RestoreFilestoreCopyMarker", "RestoreFilestoreCopied",
+			"MigrationTargetClusterID",
+			"CreateAt", "DeleteAt", "LockAcquiredBy", "LockAcquiredAt",
+			"Attempts", "LastError", "NextAttemptAt",
 		).
 		From("Installation")
 }
@@ -25,6 +30,8 @@ func init() {
 type rawInstallation struct {
 	*model.Installation
 	MattermostEnvRaw []byte
+	DNSAliasesRaw    []byte
+	DNSRecordsRaw    []byte
 }
 
 type rawInstallations []*rawInstallation
@@ -41,6 +48,23 @@ func (r *rawInstallation) toInstallation() (*model.Installation, error) {
 	}
 
 	r.Installation.MattermostEnv = *mattermostEnv
+
+	r.Installation.DNSAliases = nil
+	if r.DNSAliasesRaw != nil {
+		err = json.Unmarshal(r.DNSAliasesRaw, &r.Installation.DNSAliases)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal DNSAliases")
+		}
+	}
+
+	r.Installation.DNSRecords = nil
+	if r.DNSRecordsRaw != nil {
+		err = json.Unmarshal(r.DNSRecordsRaw, &r.Installation.DNSRecords)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal DNSRecords")
+		}
+	}
+
 	return r.Installation, nil
 }
 
@@ -105,6 +129,27 @@ func (sqlStore *SQLStore) GetInstallations(filter *model.InstallationFilter, inc
 	if filter.GroupID != "" {
 		builder = builder.Where("GroupID = ?", filter.GroupID)
 	}
+	if len(filter.States) > 0 {
+		builder = builder.Where(sq.Eq{"State": filter.States})
+	}
+	if filter.DNS != "" {
+		builder = builder.Where(`LOWER(DNS) LIKE ? ESCAPE '\'`, containsPattern(strings.ToLower(filter.DNS)))
+	}
+	if filter.Version != "" {
+		builder = builder.Where("Version = ?", filter.Version)
+	}
+	if filter.Database != "" {
+		builder = builder.Where("Database = ?", filter.Database)
+	}
+	if filter.Filestore != "" {
+		builder = builder.Where("Filestore = ?", filter.Filestore)
+	}
+	if filter.Affinity != "" {
+		builder = builder.Where("Affinity = ?", filter.Affinity)
+	}
+	if filter.Size != "" {
+		builder = builder.Where("Size = ?", filter.Size)
+	}
 	if !filter.IncludeDeleted {
 		builder = builder.Where("DeleteAt = 0")
 	}
@@ -143,6 +188,7 @@ func (sqlStore *SQLStore) GetUnlockedInstallationsPendingWork() ([]*model.Instal
 		Where(sq.Eq{
 			"State": model.AllInstallationStatesPendingWork,
 		}).
+		Where("NextAttemptAt <= ?", GetMillis()).
 		Where("LockAcquiredAt = 0").
 		OrderBy("CreateAt ASC")
 
@@ -191,35 +237,61 @@ func (sqlStore *SQLStore) CreateInstallation(installation *model.Installation) e
 	if err != nil {
 		errors.Wrap(err, "unable to marshal MattermostEnv")
 	}
-
-	_, err = sqlStore.execBuilder(sqlStore.db, sq.
-		Insert("Installation").
-		SetMap(map[string]interface{}{
-			"ID":               installation.ID,
-			"OwnerID":          installation.OwnerID,
-			"GroupID":          installation.GroupID,
-			"GroupSequence":    nil,
-			"Version":          installation.Version,
-			"Image":            installation.Image,
-			"DNS":              installation.DNS,
-			"Database":         installation.Database,
-			"Filestore":        installation.Filestore,
-			"Size":             installation.Size,
-			"Affinity":         installation.Affinity,
-			"State":            installation.State,
-			"CreateAt":         installation.CreateAt,
-			"License":          installation.License,
-			"MattermostEnvRaw": []byte(envJSON),
-			"DeleteAt":         0,
-			"LockAcquiredBy":   nil,
-			"LockAcquiredAt":   0,
-		}),
-	)
+	dnsAliasesJSON, err := json.Marshal(installation.DNSAliases)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal DNSAliases")
+	}
+	dnsRecordsJSON, err := json.Marshal(installation.DNSRecords)
 	if err != nil {
-		return errors.Wrap(err, "failed to create installation")
+		return errors.Wrap(err, "unable to marshal DNSRecords")
 	}
 
-	return nil
+	return sqlStore.transaction(func(tx *sqlx.Tx) error {
+		err = sqlStore.replaceInstallationDNSNames(tx, installation)
+		if err != nil {
+			return err
+		}
+
+		_, err = sqlStore.execBuilder(tx, sq.
+			Insert("Installation").
+			SetMap(map[string]interface{}{
+				"ID":                         installation.ID,
+				"OwnerID":                    installation.OwnerID,
+				"GroupID":                    installation.GroupID,
+				"GroupSequence":              nil,
+				"Version":                    installation.Version,
+				"Image":                      installation.Image,
+				"DNS":                        installation.DNS,
+				"Database":                   installation.Database,
+				"Filestore":                  installation.Filestore,
+				"Size":                       installation.Size,
+				"Affinity":                   installation.Affinity,
+				"NodePool":                   installation.NodePool,
+				"Zone":                       installation.Zone,
+				"SchedulingStrategy":         installation.SchedulingStrategy,
+				"State":                      installation.State,
+				"CreateAt":                   installation.CreateAt,
+				"License":                    installation.License,
+				"MattermostEnvRaw":           []byte(envJSON),
+				"DNSAliasesRaw":              string(dnsAliasesJSON),
+				"DNSRecordsRaw":              string(dnsRecordsJSON),
+				"RestoredFromBackupID":       installation.RestoredFromBackupID,
+				"RestoreFilestoreCopyMarker": installation.RestoreFilestoreCopyMarker,
+				"RestoreFilestoreCopied":     installation.RestoreFilestoreCopied,
+				"MigrationTargetClusterID":   installation.MigrationTargetClusterID,
+				"DeleteAt":                   0,
+				"LockAcquiredBy":             nil,
+				"LockAcquiredAt":             0,
+			}),
+		)
+		if isUniqueConstraintViolation(err) {
+			return errors.Wrap(model.ErrInstallationDNSInUse, "failed to create installation")
+		} else if err != nil {
+			return errors.Wrap(err, "failed to create installation")
+		}
+
+		return nil
+	})
 }
 
 // UpdateInstallation updates the given installation in the database.
@@ -231,31 +303,52 @@ func (sqlStore *SQLStore) UpdateInstallation(installation *model.Installation) e
 	if err != nil {
 		return errors.Wrap(err, "unable to marshal MattermostEnv")
 	}
-
-	_, err = sqlStore.execBuilder(sqlStore.db, sq.
-		Update("Installation").
-		SetMap(map[string]interface{}{
-			"OwnerID":          installation.OwnerID,
-			"GroupID":          installation.GroupID,
-			"GroupSequence":    installation.GroupSequence,
-			"Version":          installation.Version,
-			"Image":            installation.Image,
-			"DNS":              installation.DNS,
-			"Database":         installation.Database,
-			"Filestore":        installation.Filestore,
-			"Size":             installation.Size,
-			"Affinity":         installation.Affinity,
-			"License":          installation.License,
-			"MattermostEnvRaw": []byte(envJSON),
-			"State":            installation.State,
-		}).
-		Where("ID = ?", installation.ID),
-	)
+	dnsAliasesJSON, err := json.Marshal(installation.DNSAliases)
 	if err != nil {
-		return errors.Wrap(err, "failed to update installation")
+		return errors.Wrap(err, "unable to marshal DNSAliases")
 	}
 
-	return nil
+	return sqlStore.transaction(func(tx *sqlx.Tx) error {
+		err = sqlStore.replaceInstallationDNSNames(tx, installation)
+		if err != nil {
+			return err
+		}
+
+		_, err = sqlStore.execBuilder(tx, sq.
+			Update("Installation").
+			SetMap(map[string]interface{}{
+				"OwnerID":                  installation.OwnerID,
+				"GroupID":                  installation.GroupID,
+				"GroupSequence":            installation.GroupSequence,
+				"Version":                  installation.Version,
+				"Image":                    installation.Image,
+				"DNS":                      installation.DNS,
+				"Database":                 installation.Database,
+				"Filestore":                installation.Filestore,
+				"Size":                     installation.Size,
+				"Affinity":                 installation.Affinity,
+				"NodePool":                 installation.NodePool,
+				"Zone":                     installation.Zone,
+				"SchedulingStrategy":       installation.SchedulingStrategy,
+				"License":                  installation.License,
+				"MattermostEnvRaw":         []byte(envJSON),
+				"DNSAliasesRaw":            string(dnsAliasesJSON),
+				"MigrationTargetClusterID": installation.MigrationTargetClusterID,
+				"State":                    installation.State,
+				"Attempts":                 installation.Attempts,
+				"LastError":                installation.LastError,
+				"NextAttemptAt":            installation.NextAttemptAt,
+			}).
+			Where("ID = ?", installation.ID),
+		)
+		if isUniqueConstraintViolation(err) {
+			return errors.Wrap(model.ErrInstallationDNSInUse, "failed to update installation")
+		} else if err != nil {
+			return errors.Wrap(err, "failed to update installation")
+		}
+
+		return nil
+	})
 }
 
 // UpdateInstallationGroupSequence updates the given installation GroupSequence
@@ -280,6 +373,47 @@ func (sqlStore *SQLStore) UpdateInstallationGroupSequence(installation *model.In
 	return nil
 }
 
+// UpdateInstallationDNSRecords updates the given installation to record which
+// public DNS names are currently configured for it, releasing the DNS names
+// whose records are no longer configured.
+func (sqlStore *SQLStore) UpdateInstallationDNSRecords(installation *model.Installation) error {
+	dnsRecordsJSON, err := json.Marshal(installation.DNSRecords)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal DNSRecords")
+	}
+
+	return sqlStore.transaction(func(tx *sqlx.Tx) error {
+		_, err = sqlStore.execBuilder(tx, sq.
+			Update("Installation").
+			SetMap(map[string]interface{}{
+				"DNSRecordsRaw": string(dnsRecordsJSON),
+			}).
+			Where("ID = ?", installation.ID),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to update installation DNS records")
+		}
+
+		// Reserve the DNS names as currently stored rather than those of the
+		// given installation, which may have been changed since it was read.
+		var rawInstallation rawInstallation
+		err = sqlStore.getBuilder(tx, &rawInstallation,
+			installationSelect.Where("ID = ?", installation.ID),
+		)
+		if err == sql.ErrNoRows {
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "failed to get installation by id")
+		}
+		current, err := rawInstallation.toInstallation()
+		if err != nil {
+			return err
+		}
+
+		return sqlStore.replaceInstallationDNSNames(tx, current)
+	})
+}
+
 // UpdateInstallationState updates the given installation to a new state.
 func (sqlStore *SQLStore) UpdateInstallationState(installation *model.Installation) error {
 	_, err := sqlStore.execBuilder(sqlStore.db, sq.
@@ -296,18 +430,73 @@ func (sqlStore *SQLStore) UpdateInstallationState(installation *model.Installati
 	return nil
 }
 
-// DeleteInstallation marks the given installation as deleted, but does not remove the record from the
-// database.
-func (sqlStore *SQLStore) DeleteInstallation(id string) error {
+// UpdateInstallationSize updates the given installation to a new size.
+func (sqlStore *SQLStore) UpdateInstallationSize(installation *model.Installation) error {
 	_, err := sqlStore.execBuilder(sqlStore.db, sq.
 		Update("Installation").
-		Set("DeleteAt", GetMillis()).
-		Where("ID = ?", id).
-		Where("DeleteAt = 0"),
+		SetMap(map[string]interface{}{
+			"Size": installation.Size,
+		}).
+		Where("ID = ?", installation.ID),
 	)
 	if err != nil {
-		return errors.Wrap(err, "failed to mark installation as deleted")
+		return errors.Wrap(err, "failed to update installation size")
 	}
 
 	return nil
 }
+
+// UpdateInstallationRestoreProgress updates the given installation's record of
+// how much of the backup filestore has been restored.
+func (sqlStore *SQLStore) UpdateInstallationRestoreProgress(installation *model.Installation) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Installation").
+		SetMap(map[string]interface{}{
+			"RestoreFilestoreCopyMarker": installation.RestoreFilestoreCopyMarker,
+			"RestoreFilestoreCopied":     installation.RestoreFilestoreCopied,
+		}).
+		Where("ID = ?", installation.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update installation restore progress")
+	}
+
+	return nil
+}
+
+// UpdateInstallationAttempts updates the given installation's record of failed
+// attempts to transition it out of its current state.
+func (sqlStore *SQLStore) UpdateInstallationAttempts(installation *model.Installation) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Installation").
+		SetMap(map[string]interface{}{
+			"Attempts":      installation.Attempts,
+			"LastError":     installation.LastError,
+			"NextAttemptAt": installation.NextAttemptAt,
+		}).
+		Where("ID = ?", installation.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update installation attempts")
+	}
+
+	return nil
+}
+
+// DeleteInstallation marks the given installation as deleted, but does not remove the record from the
+// database.
+func (sqlStore *SQLStore) DeleteInstallation(id string) error {
+	return sqlStore.transaction(func(tx *sqlx.Tx) error {
+		_, err := sqlStore.execBuilder(tx, sq.
+			Update("Installation").
+			Set("DeleteAt", GetMillis()).
+			Where("ID = ?", id).
+			Where("DeleteAt = 0"),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to mark installation as deleted")
+		}
+
+		return sqlStore.deleteInstallationDNSNames(tx, id)
+	})
+}
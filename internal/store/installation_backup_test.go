@@ -0,0 +1,128 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationBackups(t *testing.T) {
+	t.Run("get unknown installation backup", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		backup, err := sqlStore.GetInstallationBackup("unknown")
+		require.NoError(t, err)
+		require.Nil(t, backup)
+	})
+
+	t.Run("create, get and update installation backups", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		installationID1 := model.NewID()
+		installationID2 := model.NewID()
+
+		backup1 := &model.InstallationBackup{
+			InstallationID: installationID1,
+			State:          model.InstallationBackupStateBackupRequested,
+		}
+		err := sqlStore.CreateInstallationBackup(backup1)
+		require.NoError(t, err)
+
+		time.Sleep(1 * time.Millisecond)
+
+		backup2 := &model.InstallationBackup{
+			InstallationID: installationID2,
+			State:          model.InstallationBackupStateBackupSucceeded,
+		}
+		err = sqlStore.CreateInstallationBackup(backup2)
+		require.NoError(t, err)
+
+		actualBackup1, err := sqlStore.GetInstallationBackup(backup1.ID)
+		require.NoError(t, err)
+		require.Equal(t, backup1, actualBackup1)
+
+		actualBackups, err := sqlStore.GetInstallationBackups(&model.InstallationBackupFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationBackup{backup1, backup2}, actualBackups)
+
+		actualBackups, err = sqlStore.GetInstallationBackups(&model.InstallationBackupFilter{InstallationID: installationID2, PerPage: 10})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationBackup{backup2}, actualBackups)
+
+		actualBackups, err = sqlStore.GetInstallationBackups(&model.InstallationBackupFilter{Page: 1, PerPage: 1})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationBackup{backup2}, actualBackups)
+
+		backup1.State = model.InstallationBackupStateBackupInProgress
+		backup1.DatabaseSnapshotID = "snapshot-id"
+		backup1.FilestoreBucket = "bucket"
+		backup1.FilestorePrefix = "prefix"
+		backup1.FilestoreCopyMarker = "data/users/file.png"
+		backup1.FilestoreCopied = true
+		err = sqlStore.UpdateInstallationBackup(backup1)
+		require.NoError(t, err)
+
+		actualBackup1, err = sqlStore.GetInstallationBackup(backup1.ID)
+		require.NoError(t, err)
+		require.Equal(t, backup1, actualBackup1)
+	})
+
+	t.Run("get and lock backups pending work", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		requestedBackup := &model.InstallationBackup{
+			InstallationID: model.NewID(),
+			State:          model.InstallationBackupStateBackupRequested,
+		}
+		err := sqlStore.CreateInstallationBackup(requestedBackup)
+		require.NoError(t, err)
+
+		time.Sleep(1 * time.Millisecond)
+
+		inProgressBackup := &model.InstallationBackup{
+			InstallationID: model.NewID(),
+			State:          model.InstallationBackupStateBackupInProgress,
+		}
+		err = sqlStore.CreateInstallationBackup(inProgressBackup)
+		require.NoError(t, err)
+
+		succeededBackup := &model.InstallationBackup{
+			InstallationID: model.NewID(),
+			State:          model.InstallationBackupStateBackupSucceeded,
+		}
+		err = sqlStore.CreateInstallationBackup(succeededBackup)
+		require.NoError(t, err)
+
+		backups, err := sqlStore.GetUnlockedInstallationBackupsPendingWork()
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationBackup{requestedBackup, inProgressBackup}, backups)
+
+		lockerID := model.NewID()
+
+		locked, err := sqlStore.LockInstallationBackup(requestedBackup.ID, lockerID)
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		locked, err = sqlStore.LockInstallationBackup(requestedBackup.ID, model.NewID())
+		require.NoError(t, err)
+		require.False(t, locked)
+
+		backups, err = sqlStore.GetUnlockedInstallationBackupsPendingWork()
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationBackup{inProgressBackup}, backups)
+
+		unlocked, err := sqlStore.UnlockInstallationBackup(requestedBackup.ID, lockerID, false)
+		require.NoError(t, err)
+		require.True(t, unlocked)
+
+		backups, err = sqlStore.GetUnlockedInstallationBackupsPendingWork()
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationBackup{requestedBackup, inProgressBackup}, backups)
+	})
+}
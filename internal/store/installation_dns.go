@@ -0,0 +1,109 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+// replaceInstallationDNSNames records the DNS names reserved by the given
+// installation in place of the ones previously recorded for it. The names are
+// unique across installations, so model.ErrInstallationDNSInUse is returned if
+// another installation already uses one of them.
+//
+// An installation reserves its DNS name and aliases, as well as the DNS
+// records still configured for it, so that a name is only released once its
+// record has been deleted.
+func (sqlStore *SQLStore) replaceInstallationDNSNames(tx *sqlx.Tx, installation *model.Installation) error {
+	dnsRecords, err := sqlStore.getInstallationDNSRecords(tx, installation.ID)
+	if err != nil {
+		return err
+	}
+
+	err = sqlStore.deleteInstallationDNSNames(tx, installation.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range reservedDNSNames(installation.DNSNames(), dnsRecords) {
+		_, err = sqlStore.execBuilder(tx, sq.
+			Insert("InstallationDNS").
+			SetMap(map[string]interface{}{
+				"DNSName":        name,
+				"InstallationID": installation.ID,
+			}),
+		)
+		if isUniqueConstraintViolation(err) {
+			return errors.Wrapf(model.ErrInstallationDNSInUse, "failed to record DNS name %s", name)
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to record DNS name %s", name)
+		}
+	}
+
+	return nil
+}
+
+// getInstallationDNSRecords returns the DNS records currently configured for
+// the given installation, if it exists.
+func (sqlStore *SQLStore) getInstallationDNSRecords(q sqlx.Queryer, installationID string) ([]string, error) {
+	var dnsRecordsRaw []byte
+	err := sqlStore.getBuilder(q, &dnsRecordsRaw, sq.
+		Select("DNSRecordsRaw").
+		From("Installation").
+		Where("ID = ?", installationID),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get installation DNS records")
+	}
+	if len(dnsRecordsRaw) == 0 {
+		return nil, nil
+	}
+
+	var dnsRecords []string
+	err = json.Unmarshal(dnsRecordsRaw, &dnsRecords)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal DNSRecords")
+	}
+
+	return dnsRecords, nil
+}
+
+// reservedDNSNames returns the given DNS names lowercased, without blank or
+// duplicate names.
+func reservedDNSNames(nameLists ...[]string) []string {
+	seen := make(map[string]bool)
+	var reserved []string
+	for _, names := range nameLists {
+		for _, name := range names {
+			name = strings.ToLower(name)
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			reserved = append(reserved, name)
+		}
+	}
+
+	return reserved
+}
+
+// deleteInstallationDNSNames releases the DNS names recorded for the given
+// installation.
+func (sqlStore *SQLStore) deleteInstallationDNSNames(e execer, installationID string) error {
+	_, err := sqlStore.execBuilder(e, sq.
+		Delete("InstallationDNS").
+		Where("InstallationID = ?", installationID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete installation DNS names")
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationDNSNames(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	installation1 := &model.Installation{
+		OwnerID:    model.NewID(),
+		DNS:        "dns1.example.com",
+		DNSAliases: []string{"alias1.example.com"},
+		State:      model.InstallationStateCreationRequested,
+	}
+	err := sqlStore.CreateInstallation(installation1)
+	require.NoError(t, err)
+
+	t.Run("create with DNS used as another installation alias", func(t *testing.T) {
+		installation := &model.Installation{
+			OwnerID: model.NewID(),
+			DNS:     "ALIAS1.example.com",
+			State:   model.InstallationStateCreationRequested,
+		}
+		err := sqlStore.CreateInstallation(installation)
+		require.Error(t, err)
+		assert.Equal(t, model.ErrInstallationDNSInUse, errors.Cause(err))
+
+		installations, err := sqlStore.GetInstallations(&model.InstallationFilter{PerPage: model.AllPerPage}, false, false)
+		require.NoError(t, err)
+		assert.Len(t, installations, 1)
+	})
+
+	t.Run("create with alias used as another installation DNS", func(t *testing.T) {
+		installation := &model.Installation{
+			OwnerID:    model.NewID(),
+			DNS:        "dns2.example.com",
+			DNSAliases: []string{"dns1.example.com"},
+			State:      model.InstallationStateCreationRequested,
+		}
+		err := sqlStore.CreateInstallation(installation)
+		require.Error(t, err)
+		assert.Equal(t, model.ErrInstallationDNSInUse, errors.Cause(err))
+	})
+
+	installation2 := &model.Installation{
+		OwnerID: model.NewID(),
+		DNS:     "dns2.example.com",
+		State:   model.InstallationStateCreationRequested,
+	}
+	err = sqlStore.CreateInstallation(installation2)
+	require.NoError(t, err)
+
+	t.Run("update with alias used as another installation alias", func(t *testing.T) {
+		installation2.DNSAliases = []string{"alias1.example.com"}
+		err := sqlStore.UpdateInstallation(installation2)
+		require.Error(t, err)
+		assert.Equal(t, model.ErrInstallationDNSInUse, errors.Cause(err))
+
+		installation, err := sqlStore.GetInstallation(installation2.ID, false, false)
+		require.NoError(t, err)
+		assert.Empty(t, installation.DNSAliases)
+	})
+
+	t.Run("update releases previous names", func(t *testing.T) {
+		installation1.DNSAliases = nil
+		err := sqlStore.UpdateInstallation(installation1)
+		require.NoError(t, err)
+
+		installation2.DNSAliases = []string{"alias1.example.com"}
+		err = sqlStore.UpdateInstallation(installation2)
+		require.NoError(t, err)
+	})
+
+	t.Run("configured DNS records stay reserved until deleted", func(t *testing.T) {
+		installation2.DNSRecords = installation2.DNSNames()
+		err := sqlStore.UpdateInstallationDNSRecords(installation2)
+		require.NoError(t, err)
+
+		installation2.DNS = "dns3.example.com"
+		err = sqlStore.UpdateInstallation(installation2)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID: model.NewID(),
+			DNS:     "DNS2.example.com",
+			State:   model.InstallationStateCreationRequested,
+		}
+		err = sqlStore.CreateInstallation(installation)
+		require.Error(t, err)
+		assert.Equal(t, model.ErrInstallationDNSInUse, errors.Cause(err))
+
+		installation2.DNSRecords = installation2.DNSNames()
+		err = sqlStore.UpdateInstallationDNSRecords(installation2)
+		require.NoError(t, err)
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+	})
+
+	t.Run("delete releases names", func(t *testing.T) {
+		err := sqlStore.DeleteInstallation(installation1.ID)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID: model.NewID(),
+			DNS:     "dns1.example.com",
+			State:   model.InstallationStateCreationRequested,
+		}
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+	})
+}
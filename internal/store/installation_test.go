@@ -52,16 +52,19 @@ func TestInstallations(t *testing.T) {
 	time.Sleep(1 * time.Millisecond)
 
 	installation2 := &model.Installation{
-		OwnerID:   ownerID1,
-		Version:   "version2",
-		Image:     "custom-image",
-		DNS:       "dns2.example.com",
-		Database:  model.InstallationDatabaseMysqlOperator,
-		Filestore: model.InstallationFilestoreMinioOperator,
-		Size:      mmv1alpha1.Size100String,
-		Affinity:  model.InstallationAffinityIsolated,
-		GroupID:   &groupID2,
-		State:     model.InstallationStateStable,
+		OwnerID:            ownerID1,
+		Version:            "version2",
+		Image:              "custom-image",
+		DNS:                "dns2.example.com",
+		Database:           model.InstallationDatabaseMysqlOperator,
+		Filestore:          model.InstallationFilestoreMinioOperator,
+		Size:               mmv1alpha1.Size100String,
+		Affinity:           model.InstallationAffinityIsolated,
+		NodePool:           "memory",
+		Zone:               "us-east-1a",
+		GroupID:            &groupID2,
+		SchedulingStrategy: model.SchedulingStrategySpread,
+		State:              model.InstallationStateStable,
 	}
 
 	err = sqlStore.CreateInstallation(installation2)
@@ -260,6 +263,67 @@ func TestInstallations(t *testing.T) {
 			},
 			[]*model.Installation{installation4},
 		},
+		{
+			"states",
+			&model.InstallationFilter{
+				States:         []string{model.InstallationStateStable, model.InstallationStateDeleted},
+				PerPage:        10,
+				IncludeDeleted: true,
+			},
+			[]*model.Installation{installation2},
+		},
+		{
+			"dns substring",
+			&model.InstallationFilter{
+				DNS:     "dns3",
+				PerPage: 10,
+			},
+			[]*model.Installation{installation3},
+		},
+		{
+			"dns substring ignores case",
+			&model.InstallationFilter{
+				DNS:     "DNS3.Example",
+				PerPage: 10,
+			},
+			[]*model.Installation{installation3},
+		},
+		{
+			"dns substring with wildcards",
+			&model.InstallationFilter{
+				DNS:     "dns_.%",
+				PerPage: 10,
+			},
+			nil,
+		},
+		{
+			"version",
+			&model.InstallationFilter{
+				Version:        "version",
+				PerPage:        10,
+				IncludeDeleted: true,
+			},
+			[]*model.Installation{installation1, installation3, installation4},
+		},
+		{
+			"database, filestore, affinity and size",
+			&model.InstallationFilter{
+				Database:  model.InstallationDatabaseMysqlOperator,
+				Filestore: model.InstallationFilestoreMinioOperator,
+				Affinity:  model.InstallationAffinityIsolated,
+				Size:      mmv1alpha1.Size100String,
+				PerPage:   10,
+			},
+			[]*model.Installation{installation1, installation2, installation3},
+		},
+		{
+			"no matching database",
+			&model.InstallationFilter{
+				Database: model.InstallationDatabaseAwsRDS,
+				PerPage:  10,
+			},
+			nil,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -699,6 +763,155 @@ func TestUpdateInstallationState(t *testing.T) {
 	assert.NotEqual(t, storedInstallation.Version, installation1.Version)
 }
 
+func TestUpdateInstallationSize(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	installation1 := &model.Installation{
+		OwnerID:   model.NewID(),
+		Version:   "version",
+		DNS:       "dns3.example.com",
+		Database:  model.InstallationDatabaseMysqlOperator,
+		Filestore: model.InstallationFilestoreMinioOperator,
+		Size:      mmv1alpha1.Size1000String,
+		Affinity:  model.InstallationAffinityIsolated,
+		State:     model.InstallationStateUpdateRequested,
+	}
+
+	err := sqlStore.CreateInstallation(installation1)
+	require.NoError(t, err)
+
+	installation1.Size = mmv1alpha1.Size100String
+	installation1.Version = "new-version-that-should-not-be-saved"
+
+	err = sqlStore.UpdateInstallationSize(installation1)
+	require.NoError(t, err)
+
+	storedInstallation, err := sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, mmv1alpha1.Size100String, storedInstallation.Size)
+	assert.NotEqual(t, installation1.Version, storedInstallation.Version)
+}
+
+func TestUpdateInstallationAttempts(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	installation1 := &model.Installation{
+		OwnerID:   model.NewID(),
+		Version:   "version",
+		DNS:       "dns4.example.com",
+		Database:  model.InstallationDatabaseMysqlOperator,
+		Filestore: model.InstallationFilestoreMinioOperator,
+		Size:      mmv1alpha1.Size100String,
+		Affinity:  model.InstallationAffinityIsolated,
+		State:     model.InstallationStateCreationRequested,
+	}
+
+	err := sqlStore.CreateInstallation(installation1)
+	require.NoError(t, err)
+
+	installations, err := sqlStore.GetUnlockedInstallationsPendingWork()
+	require.NoError(t, err)
+	require.Len(t, installations, 1)
+
+	installation1.Attempts = 2
+	installation1.LastError = "failed"
+	installation1.NextAttemptAt = GetMillis() + time.Hour.Milliseconds()
+	installation1.Version = "new-version-that-should-not-be-saved"
+
+	err = sqlStore.UpdateInstallationAttempts(installation1)
+	require.NoError(t, err)
+
+	storedInstallation, err := sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, storedInstallation.Attempts)
+	assert.Equal(t, "failed", storedInstallation.LastError)
+	assert.Equal(t, installation1.NextAttemptAt, storedInstallation.NextAttemptAt)
+	assert.Equal(t, "version", storedInstallation.Version)
+
+	// Installations backing off are not pending work until the next attempt is due.
+	installations, err = sqlStore.GetUnlockedInstallationsPendingWork()
+	require.NoError(t, err)
+	require.Empty(t, installations)
+
+	installation1.NextAttemptAt = GetMillis() - 1
+	err = sqlStore.UpdateInstallationAttempts(installation1)
+	require.NoError(t, err)
+
+	installations, err = sqlStore.GetUnlockedInstallationsPendingWork()
+	require.NoError(t, err)
+	require.Len(t, installations, 1)
+}
+
+func TestUpdateInstallationRestoreProgress(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	installation1 := &model.Installation{
+		OwnerID:   model.NewID(),
+		Version:   "version",
+		DNS:       "dns5.example.com",
+		Database:  model.InstallationDatabaseAwsRDS,
+		Filestore: model.InstallationFilestoreAwsS3,
+		Size:      mmv1alpha1.Size100String,
+		Affinity:  model.InstallationAffinityIsolated,
+		State:     model.InstallationStateCreationPreProvisioning,
+	}
+
+	err := sqlStore.CreateInstallation(installation1)
+	require.NoError(t, err)
+
+	installation1.RestoreFilestoreCopyMarker = "backup/file1"
+	installation1.RestoreFilestoreCopied = true
+	installation1.Version = "new-version-that-should-not-be-saved"
+
+	err = sqlStore.UpdateInstallationRestoreProgress(installation1)
+	require.NoError(t, err)
+
+	storedInstallation, err := sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, "backup/file1", storedInstallation.RestoreFilestoreCopyMarker)
+	assert.True(t, storedInstallation.RestoreFilestoreCopied)
+	assert.Equal(t, "version", storedInstallation.Version)
+}
+
+func TestUpdateInstallationDNSRecords(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	installation1 := &model.Installation{
+		OwnerID:    model.NewID(),
+		Version:    "version",
+		DNS:        "dns.example.com",
+		DNSAliases: []string{"alias1.example.com", "alias2.example.com"},
+		Database:   model.InstallationDatabaseMysqlOperator,
+		Filestore:  model.InstallationFilestoreMinioOperator,
+		Size:       mmv1alpha1.Size100String,
+		Affinity:   model.InstallationAffinityIsolated,
+		State:      model.InstallationStateCreationRequested,
+	}
+
+	err := sqlStore.CreateInstallation(installation1)
+	require.NoError(t, err)
+
+	storedInstallation, err := sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, installation1.DNSAliases, storedInstallation.DNSAliases)
+	assert.Nil(t, storedInstallation.DNSRecords)
+
+	installation1.DNSRecords = installation1.DNSNames()
+	installation1.DNSAliases = []string{"alias-that-should-not-be-saved.example.com"}
+
+	err = sqlStore.UpdateInstallationDNSRecords(installation1)
+	require.NoError(t, err)
+
+	storedInstallation, err = sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, installation1.DNSRecords, storedInstallation.DNSRecords)
+	assert.Equal(t, []string{"alias1.example.com", "alias2.example.com"}, storedInstallation.DNSAliases)
+}
+
 func TestDeleteInstallation(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := MakeTestSQLStore(t, logger)
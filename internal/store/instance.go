@@ -0,0 +1,139 @@
+package store
+
+import (
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+var instanceSelect sq.SelectBuilder
+
+func init() {
+	instanceSelect = sq.
+		Select("ID", "CreateAt", "LastHeartbeatAt", "PartitionedSupervisorsRaw").
+		From("Instance")
+}
+
+type rawInstance struct {
+	*model.Instance
+	PartitionedSupervisorsRaw []byte
+}
+
+type rawInstances []*rawInstance
+
+func (r *rawInstance) toInstance() (*model.Instance, error) {
+	// We only need to set values that are converted from a raw database format.
+	r.Instance.PartitionedSupervisors = nil
+	if len(r.PartitionedSupervisorsRaw) > 0 {
+		err := json.Unmarshal(r.PartitionedSupervisorsRaw, &r.Instance.PartitionedSupervisors)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal PartitionedSupervisors")
+		}
+	}
+
+	return r.Instance, nil
+}
+
+func (rs *rawInstances) toInstances() ([]*model.Instance, error) {
+	var instances []*model.Instance
+	for _, rawInstance := range *rs {
+		instance, err := rawInstance.toInstance()
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, instance)
+	}
+
+	return instances, nil
+}
+
+// GetInstances fetches every provisioning server instance that has recorded a heartbeat.
+func (sqlStore *SQLStore) GetInstances() ([]*model.Instance, error) {
+	var rawInstances rawInstances
+	err := sqlStore.selectBuilder(sqlStore.db, &rawInstances, instanceSelect.OrderBy("CreateAt ASC"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for instances")
+	}
+
+	return rawInstances.toInstances()
+}
+
+// HeartbeatInstance records that the given provisioning server instance is alive, along with
+// the supervisors whose work it partitions, creating it if necessary.
+func (sqlStore *SQLStore) HeartbeatInstance(instance *model.Instance) error {
+	now := GetMillis()
+
+	partitionedSupervisorsJSON, err := json.Marshal(instance.PartitionedSupervisors)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal PartitionedSupervisors")
+	}
+
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Instance").
+		SetMap(map[string]interface{}{
+			"LastHeartbeatAt":           now,
+			"PartitionedSupervisorsRaw": string(partitionedSupervisorsJSON),
+		}).
+		Where("ID = ?", instance.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update instance heartbeat")
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to count rows affected")
+	}
+	if count > 0 {
+		return nil
+	}
+
+	_, err = sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("Instance").
+		SetMap(map[string]interface{}{
+			"ID":                        instance.ID,
+			"CreateAt":                  now,
+			"LastHeartbeatAt":           now,
+			"PartitionedSupervisorsRaw": string(partitionedSupervisorsJSON),
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create instance")
+	}
+
+	return nil
+}
+
+// DeleteInstance removes the given provisioning server instance.
+func (sqlStore *SQLStore) DeleteInstance(instanceID string) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Delete("Instance").
+		Where("ID = ?", instanceID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete instance")
+	}
+
+	return nil
+}
+
+// DeleteInstancesHeartbeatBefore removes the provisioning server instances whose last heartbeat
+// was before the given time in milliseconds, returning the number removed.
+func (sqlStore *SQLStore) DeleteInstancesHeartbeatBefore(cutoff int64) (int64, error) {
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Delete("Instance").
+		Where("LastHeartbeatAt < ?", cutoff),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete instances")
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count rows affected")
+	}
+
+	return count, nil
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstances(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	instances, err := sqlStore.GetInstances()
+	require.NoError(t, err)
+	require.Empty(t, instances)
+
+	err = sqlStore.HeartbeatInstance(&model.Instance{ID: "instance1"})
+	require.NoError(t, err)
+
+	instances, err = sqlStore.GetInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	require.Equal(t, "instance1", instances[0].ID)
+	require.Empty(t, instances[0].PartitionedSupervisors)
+	require.NotZero(t, instances[0].CreateAt)
+	require.Equal(t, instances[0].CreateAt, instances[0].LastHeartbeatAt)
+	createAt := instances[0].CreateAt
+
+	time.Sleep(2 * time.Millisecond)
+
+	err = sqlStore.HeartbeatInstance(&model.Instance{ID: "instance1", PartitionedSupervisors: []string{"cluster"}})
+	require.NoError(t, err)
+	err = sqlStore.HeartbeatInstance(&model.Instance{ID: "instance2", PartitionedSupervisors: []string{"installation"}})
+	require.NoError(t, err)
+
+	instances, err = sqlStore.GetInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 2)
+	require.Equal(t, "instance1", instances[0].ID)
+	require.Equal(t, createAt, instances[0].CreateAt)
+	require.Greater(t, instances[0].LastHeartbeatAt, createAt)
+	require.Equal(t, []string{"cluster"}, instances[0].PartitionedSupervisors)
+	require.Equal(t, "instance2", instances[1].ID)
+	require.Equal(t, []string{"installation"}, instances[1].PartitionedSupervisors)
+
+	err = sqlStore.DeleteInstance("instance1")
+	require.NoError(t, err)
+
+	instances, err = sqlStore.GetInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	require.Equal(t, "instance2", instances[0].ID)
+
+	deleted, err := sqlStore.DeleteInstancesHeartbeatBefore(instances[0].LastHeartbeatAt)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, deleted)
+
+	deleted, err = sqlStore.DeleteInstancesHeartbeatBefore(instances[0].LastHeartbeatAt + 1)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, deleted)
+
+	instances, err = sqlStore.GetInstances()
+	require.NoError(t, err)
+	require.Empty(t, instances)
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+var leaseSelect sq.SelectBuilder
+
+func init() {
+	leaseSelect = sq.
+		Select("Name", "HolderID", "AcquiredAt", "ExpiresAt").
+		From("Lease")
+}
+
+// GetLease fetches the given lease by name.
+func (sqlStore *SQLStore) GetLease(name string) (*model.Lease, error) {
+	var lease model.Lease
+	err := sqlStore.getBuilder(sqlStore.db, &lease, leaseSelect.Where("Name = ?", name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get lease by name")
+	}
+
+	return &lease, nil
+}
+
+// AcquireLease acquires or renews the given lease for the holder, returning whether the holder
+// now holds the lease. A lease held by another holder can only be acquired once it has expired.
+func (sqlStore *SQLStore) AcquireLease(name, holderID string, duration time.Duration) (bool, error) {
+	now := GetMillis()
+	expiresAt := now + duration.Milliseconds()
+
+	// Renew the lease if already held by the holder.
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Lease").
+		Set("ExpiresAt", expiresAt).
+		Where("Name = ?", name).
+		Where("HolderID = ?", holderID),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to renew lease")
+	}
+	if acquired, err := hasAffectedRows(result); err != nil || acquired {
+		return acquired, err
+	}
+
+	// Take over the lease if it has expired.
+	result, err = sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Lease").
+		SetMap(map[string]interface{}{
+			"HolderID":   holderID,
+			"AcquiredAt": now,
+			"ExpiresAt":  expiresAt,
+		}).
+		Where("Name = ?", name).
+		Where("ExpiresAt < ?", now),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to take over lease")
+	}
+	if acquired, err := hasAffectedRows(result); err != nil || acquired {
+		return acquired, err
+	}
+
+	// Create the lease if it does not yet exist.
+	result, err = sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("Lease").
+		SetMap(map[string]interface{}{
+			"Name":       name,
+			"HolderID":   holderID,
+			"AcquiredAt": now,
+			"ExpiresAt":  expiresAt,
+		}).
+		Suffix("ON CONFLICT (Name) DO NOTHING"),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create lease")
+	}
+
+	return hasAffectedRows(result)
+}
+
+// ReleaseLease releases the given lease if held by the holder.
+func (sqlStore *SQLStore) ReleaseLease(name, holderID string) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Delete("Lease").
+		Where("Name = ?", name).
+		Where("HolderID = ?", holderID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to release lease")
+	}
+
+	return nil
+}
+
+// hasAffectedRows returns whether the given result affected any rows.
+func hasAffectedRows(result sql.Result) (bool, error) {
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to count rows affected")
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeases(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	lease, err := sqlStore.GetLease("leader")
+	require.NoError(t, err)
+	require.Nil(t, lease)
+
+	acquired, err := sqlStore.AcquireLease("leader", "instance1", time.Hour)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	lease, err = sqlStore.GetLease("leader")
+	require.NoError(t, err)
+	require.Equal(t, "instance1", lease.HolderID)
+	require.NotZero(t, lease.AcquiredAt)
+	require.Greater(t, lease.ExpiresAt, lease.AcquiredAt)
+	acquiredAt := lease.AcquiredAt
+
+	t.Run("held by another instance", func(t *testing.T) {
+		acquired, err := sqlStore.AcquireLease("leader", "instance2", time.Hour)
+		require.NoError(t, err)
+		require.False(t, acquired)
+	})
+
+	t.Run("separate lease", func(t *testing.T) {
+		acquired, err := sqlStore.AcquireLease("other", "instance2", time.Hour)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	})
+
+	t.Run("renew", func(t *testing.T) {
+		time.Sleep(2 * time.Millisecond)
+
+		acquired, err := sqlStore.AcquireLease("leader", "instance1", time.Millisecond)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		lease, err := sqlStore.GetLease("leader")
+		require.NoError(t, err)
+		require.Equal(t, "instance1", lease.HolderID)
+		require.Equal(t, acquiredAt, lease.AcquiredAt)
+	})
+
+	t.Run("take over expired lease", func(t *testing.T) {
+		time.Sleep(5 * time.Millisecond)
+
+		acquired, err := sqlStore.AcquireLease("leader", "instance2", time.Hour)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		lease, err := sqlStore.GetLease("leader")
+		require.NoError(t, err)
+		require.Equal(t, "instance2", lease.HolderID)
+		require.Greater(t, lease.AcquiredAt, acquiredAt)
+	})
+
+	t.Run("release", func(t *testing.T) {
+		err := sqlStore.ReleaseLease("leader", "instance1")
+		require.NoError(t, err)
+
+		lease, err := sqlStore.GetLease("leader")
+		require.NoError(t, err)
+		require.Equal(t, "instance2", lease.HolderID)
+
+		err = sqlStore.ReleaseLease("leader", "instance2")
+		require.NoError(t, err)
+
+		lease, err = sqlStore.GetLease("leader")
+		require.NoError(t, err)
+		require.Nil(t, lease)
+
+		acquired, err := sqlStore.AcquireLease("leader", "instance1", time.Hour)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	})
+}
@@ -1,7 +1,11 @@
 package store
 
 import (
+	"sort"
+
 	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
+	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 )
 
@@ -19,16 +23,20 @@ func (sqlStore *SQLStore) lockRows(table string, ids []string, lockerID string)
 		}),
 	)
 	if err != nil {
+		metrics.IncLockAcquisitionFailures(table)
 		return false, errors.Wrapf(err, "failed to lock %d rows in %s", len(ids), table)
 	}
 	count, err := result.RowsAffected()
 	if err != nil {
+		metrics.IncLockAcquisitionFailures(table)
 		return false, errors.Wrap(err, "failed to count rows affected")
 	}
 
 	locked := false
 	if count > 0 {
 		locked = true
+	} else {
+		metrics.IncLockAcquisitionFailures(table)
 	}
 
 	if count > 0 && int(count) < len(ids) {
@@ -80,3 +88,117 @@ func (sqlStore *SQLStore) unlockRows(table string, ids []string, lockerID string
 
 	return unlocked, nil
 }
+
+// lockableTables maps the resource types that may be locked to their tables.
+var lockableTables = []struct {
+	resourceType string
+	table        string
+}{
+	{model.LockResourceTypeCluster, "Cluster"},
+	{model.LockResourceTypeInstallation, "Installation"},
+	{model.LockResourceTypeClusterInstallation, "ClusterInstallation"},
+	{model.LockResourceTypeGroup, `"Group"`},
+	{model.LockResourceTypeInstallationBackup, "InstallationBackup"},
+	{model.LockResourceTypeWebhookDelivery, "WebhookDelivery"},
+}
+
+// lockableTable returns the table storing resources of the given type.
+func lockableTable(resourceType string) (string, error) {
+	for _, lockable := range lockableTables {
+		if lockable.resourceType == resourceType {
+			return lockable.table, nil
+		}
+	}
+
+	return "", errors.Errorf("unsupported lock resource type %s", resourceType)
+}
+
+// GetLocks fetches the locks currently held on resources, oldest first.
+func (sqlStore *SQLStore) GetLocks(filter *model.LockFilter) ([]*model.Lock, error) {
+	locks := []*model.Lock{}
+	for _, lockable := range lockableTables {
+		if filter.ResourceType != "" && filter.ResourceType != lockable.resourceType {
+			continue
+		}
+
+		builder := sq.
+			Select("ID", "LockAcquiredBy", "LockAcquiredAt").
+			From(lockable.table).
+			Where("LockAcquiredAt <> 0")
+
+		if filter.LockAcquiredBy != "" {
+			builder = builder.Where("LockAcquiredBy = ?", filter.LockAcquiredBy)
+		}
+		if filter.AcquiredBefore != 0 {
+			builder = builder.Where("LockAcquiredAt < ?", filter.AcquiredBefore)
+		}
+
+		var rows []struct {
+			ID             string
+			LockAcquiredBy *string
+			LockAcquiredAt int64
+		}
+		err := sqlStore.selectBuilder(sqlStore.db, &rows, builder)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to query for locks in %s", lockable.table)
+		}
+
+		for _, row := range rows {
+			lock := &model.Lock{
+				ResourceType:   lockable.resourceType,
+				ResourceID:     row.ID,
+				LockAcquiredAt: row.LockAcquiredAt,
+			}
+			if row.LockAcquiredBy != nil {
+				lock.LockAcquiredBy = *row.LockAcquiredBy
+			}
+			locks = append(locks, lock)
+		}
+	}
+
+	sort.SliceStable(locks, func(i, j int) bool {
+		return locks[i].LockAcquiredAt < locks[j].LockAcquiredAt
+	})
+
+	return locks, nil
+}
+
+// UnlockResource releases a lock held on the given resource, requiring it to be held by the
+// given locker unless forced.
+func (sqlStore *SQLStore) UnlockResource(resourceType, resourceID, lockerID string, force bool) (bool, error) {
+	table, err := lockableTable(resourceType)
+	if err != nil {
+		return false, err
+	}
+
+	return sqlStore.unlockRows(table, []string{resourceID}, lockerID, force)
+}
+
+// ReleaseAllLocks releases every lock held by the given locker, returning the number of rows
+// unlocked.
+func (sqlStore *SQLStore) ReleaseAllLocks(lockerID string) (int64, error) {
+	var released int64
+	for _, lockable := range lockableTables {
+		result, err := sqlStore.execBuilder(sqlStore.db, sq.
+			Update(lockable.table).
+			SetMap(map[string]interface{}{
+				"LockAcquiredBy": nil,
+				"LockAcquiredAt": 0,
+			}).
+			Where(sq.Eq{
+				"LockAcquiredBy": lockerID,
+			}),
+		)
+		if err != nil {
+			return released, errors.Wrapf(err, "failed to release locks in %s", lockable.table)
+		}
+
+		count, err := result.RowsAffected()
+		if err != nil {
+			return released, errors.Wrap(err, "failed to count rows affected")
+		}
+		released += count
+	}
+
+	return released, nil
+}
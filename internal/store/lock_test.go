@@ -0,0 +1,163 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseAllLocks(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	lockerID := model.NewID()
+	otherLockerID := model.NewID()
+
+	cluster := &model.Cluster{}
+	err := sqlStore.CreateCluster(cluster)
+	require.NoError(t, err)
+
+	installation := &model.Installation{}
+	err = sqlStore.CreateInstallation(installation)
+	require.NoError(t, err)
+
+	group := &model.Group{}
+	err = sqlStore.CreateGroup(group)
+	require.NoError(t, err)
+
+	otherInstallation := &model.Installation{}
+	err = sqlStore.CreateInstallation(otherInstallation)
+	require.NoError(t, err)
+
+	locked, err := sqlStore.LockCluster(cluster.ID, lockerID)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	locked, err = sqlStore.LockInstallation(installation.ID, lockerID)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	locked, err = sqlStore.LockGroup(group.ID, lockerID)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	locked, err = sqlStore.LockInstallation(otherInstallation.ID, otherLockerID)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	released, err := sqlStore.ReleaseAllLocks(lockerID)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, released)
+
+	cluster, err = sqlStore.GetCluster(cluster.ID)
+	require.NoError(t, err)
+	require.Nil(t, cluster.LockAcquiredBy)
+	require.EqualValues(t, 0, cluster.LockAcquiredAt)
+
+	installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+	require.NoError(t, err)
+	require.Nil(t, installation.LockAcquiredBy)
+
+	group, err = sqlStore.GetGroup(group.ID)
+	require.NoError(t, err)
+	require.Nil(t, group.LockAcquiredBy)
+
+	otherInstallation, err = sqlStore.GetInstallation(otherInstallation.ID, false, false)
+	require.NoError(t, err)
+	require.Equal(t, otherLockerID, *otherInstallation.LockAcquiredBy)
+
+	released, err = sqlStore.ReleaseAllLocks(lockerID)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, released)
+}
+
+func TestGetLocks(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	lockerID := model.NewID()
+	otherLockerID := model.NewID()
+
+	cluster := &model.Cluster{}
+	err := sqlStore.CreateCluster(cluster)
+	require.NoError(t, err)
+
+	installation := &model.Installation{}
+	err = sqlStore.CreateInstallation(installation)
+	require.NoError(t, err)
+
+	unlockedInstallation := &model.Installation{}
+	err = sqlStore.CreateInstallation(unlockedInstallation)
+	require.NoError(t, err)
+
+	locked, err := sqlStore.LockCluster(cluster.ID, lockerID)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	time.Sleep(1 * time.Millisecond)
+
+	locked, err = sqlStore.LockInstallation(installation.ID, otherLockerID)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	t.Run("all", func(t *testing.T) {
+		locks, err := sqlStore.GetLocks(&model.LockFilter{})
+		require.NoError(t, err)
+		require.Len(t, locks, 2)
+		require.Equal(t, model.LockResourceTypeCluster, locks[0].ResourceType)
+		require.Equal(t, cluster.ID, locks[0].ResourceID)
+		require.Equal(t, lockerID, locks[0].LockAcquiredBy)
+		require.NotZero(t, locks[0].LockAcquiredAt)
+		require.Equal(t, model.LockResourceTypeInstallation, locks[1].ResourceType)
+		require.Equal(t, installation.ID, locks[1].ResourceID)
+		require.Equal(t, otherLockerID, locks[1].LockAcquiredBy)
+	})
+
+	t.Run("resource type", func(t *testing.T) {
+		locks, err := sqlStore.GetLocks(&model.LockFilter{ResourceType: model.LockResourceTypeInstallation})
+		require.NoError(t, err)
+		require.Len(t, locks, 1)
+		require.Equal(t, installation.ID, locks[0].ResourceID)
+	})
+
+	t.Run("locker", func(t *testing.T) {
+		locks, err := sqlStore.GetLocks(&model.LockFilter{LockAcquiredBy: lockerID})
+		require.NoError(t, err)
+		require.Len(t, locks, 1)
+		require.Equal(t, cluster.ID, locks[0].ResourceID)
+	})
+
+	t.Run("acquired before", func(t *testing.T) {
+		locks, err := sqlStore.GetLocks(&model.LockFilter{AcquiredBefore: 1})
+		require.NoError(t, err)
+		require.Empty(t, locks)
+
+		locks, err = sqlStore.GetLocks(&model.LockFilter{AcquiredBefore: GetMillis() + 1})
+		require.NoError(t, err)
+		require.Len(t, locks, 2)
+	})
+
+	t.Run("unlock resource", func(t *testing.T) {
+		_, err := sqlStore.UnlockResource("unknown", cluster.ID, lockerID, false)
+		require.EqualError(t, err, "unsupported lock resource type unknown")
+
+		unlocked, err := sqlStore.UnlockResource(model.LockResourceTypeCluster, cluster.ID, otherLockerID, false)
+		require.NoError(t, err)
+		require.False(t, unlocked)
+
+		unlocked, err = sqlStore.UnlockResource(model.LockResourceTypeCluster, cluster.ID, lockerID, false)
+		require.NoError(t, err)
+		require.True(t, unlocked)
+
+		unlocked, err = sqlStore.UnlockResource(model.LockResourceTypeInstallation, installation.ID, "", true)
+		require.NoError(t, err)
+		require.True(t, unlocked)
+
+		locks, err := sqlStore.GetLocks(&model.LockFilter{})
+		require.NoError(t, err)
+		require.Empty(t, locks)
+	})
+}
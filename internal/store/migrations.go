@@ -1,7 +1,12 @@
 package store
 
 import (
+	"encoding/json"
+	"strings"
+
 	"github.com/blang/semver"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
 )
 
 type migration struct {
@@ -893,6 +898,480 @@ var migrations = []migration{
 			}
 		}
 
+		return nil
+	}},
+	{semver.MustParse("0.16.0"), semver.MustParse("0.17.0"), func(e execer) error {
+		// Add InstallationBackup table.
+		// Add RestoredFromBackupID column for installations.
+		_, err := e.Exec(`
+				CREATE TABLE InstallationBackup (
+					ID TEXT PRIMARY KEY,
+					InstallationID TEXT NOT NULL,
+					State TEXT NOT NULL,
+					DatabaseSnapshotID TEXT NOT NULL,
+					FilestoreBucket TEXT NOT NULL,
+					FilestorePrefix TEXT NOT NULL,
+					CreateAt BIGINT NOT NULL,
+					DeleteAt BIGINT NOT NULL,
+					LockAcquiredBy TEXT NULL,
+					LockAcquiredAt BIGINT NOT NULL
+				);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN RestoredFromBackupID TEXT NULL;
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.17.0"), semver.MustParse("0.18.0"), func(e execer) error {
+		// Add MigrationTargetClusterID column for installations.
+		_, err := e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN MigrationTargetClusterID TEXT NULL;
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.18.0"), semver.MustParse("0.19.0"), func(e execer) error {
+		// Add DNS alias and configured DNS record columns for installations.
+		_, err := e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN DNSAliasesRaw TEXT NULL;
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN DNSRecordsRaw TEXT NULL;
+		`)
+		if err != nil {
+			return err
+		}
+
+		// Existing installations have only ever had their primary DNS name
+		// configured.
+		_, err = e.Exec(`
+				UPDATE Installation
+				SET DNSRecordsRaw = '["' || DNS || '"]'
+				WHERE DeleteAt = 0;
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.19.0"), semver.MustParse("0.20.0"), func(e execer) error {
+		// Add the Event table to keep a history of state transitions.
+		_, err := e.Exec(`
+				CREATE TABLE Event (
+					ID TEXT PRIMARY KEY,
+					ResourceType TEXT NOT NULL,
+					ResourceID TEXT NOT NULL,
+					OldState TEXT NOT NULL,
+					NewState TEXT NOT NULL,
+					Timestamp BIGINT NOT NULL,
+					InstanceID TEXT NOT NULL,
+					ErrorMessage TEXT NOT NULL
+				);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				CREATE INDEX Event_ResourceID ON Event (ResourceID);
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.20.0"), semver.MustParse("0.21.0"), func(e execer) error {
+		// Add the WebhookDelivery table to queue outbound webhook payloads.
+		_, err := e.Exec(`
+				CREATE TABLE WebhookDelivery (
+					ID TEXT PRIMARY KEY,
+					WebhookID TEXT NOT NULL,
+					Payload TEXT NOT NULL,
+					State TEXT NOT NULL,
+					Attempts INT NOT NULL,
+					LastAttemptAt BIGINT NOT NULL,
+					NextAttemptAt BIGINT NOT NULL,
+					LastError TEXT NOT NULL,
+					CreateAt BIGINT NOT NULL,
+					LockAcquiredBy TEXT NULL,
+					LockAcquiredAt BIGINT NOT NULL
+				);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				CREATE INDEX WebhookDelivery_WebhookID ON WebhookDelivery (WebhookID);
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.21.0"), semver.MustParse("0.22.0"), func(e execer) error {
+		// Add an optional secret used to sign webhook requests.
+		_, err := e.Exec(`
+				ALTER TABLE Webhooks
+				ADD COLUMN Secret TEXT NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.22.0"), semver.MustParse("0.23.0"), func(e execer) error {
+		// Add webhook subscriptions to limit the payloads sent to a webhook.
+		_, err := e.Exec(`
+				ALTER TABLE Webhooks
+				ADD COLUMN TypesRaw TEXT NULL;
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				ALTER TABLE Webhooks
+				ADD COLUMN NewStatesRaw TEXT NULL;
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				ALTER TABLE Webhooks
+				ADD COLUMN InstallationOwnerID TEXT NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.23.0"), semver.MustParse("0.24.0"), func(e execer) error {
+		// Add the APIKey table used to authenticate API requests.
+		_, err := e.Exec(`
+				CREATE TABLE APIKey (
+					ID TEXT PRIMARY KEY,
+					Description TEXT NOT NULL,
+					KeyHash TEXT NOT NULL,
+					ScopesRaw TEXT NOT NULL,
+					OwnerID TEXT NOT NULL,
+					CreateAt BIGINT NOT NULL,
+					DeleteAt BIGINT NOT NULL
+				);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				CREATE UNIQUE INDEX APIKey_KeyHash ON APIKey (KeyHash);
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.24.0"), semver.MustParse("0.25.0"), func(e execer) error {
+		// Add the AuditRecord table used to record mutating API requests.
+		_, err := e.Exec(`
+				CREATE TABLE AuditRecord (
+					ID TEXT PRIMARY KEY,
+					RequestID TEXT NOT NULL,
+					APIKeyID TEXT NOT NULL,
+					RemoteAddr TEXT NOT NULL,
+					Method TEXT NOT NULL,
+					Route TEXT NOT NULL,
+					Path TEXT NOT NULL,
+					ResourceID TEXT NOT NULL,
+					RequestBody TEXT NOT NULL,
+					StatusCode INT NOT NULL,
+					CreateAt BIGINT NOT NULL
+				);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				CREATE INDEX AuditRecord_ResourceID ON AuditRecord (ResourceID);
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.25.0"), semver.MustParse("0.26.0"), func(e execer) error {
+		// Add the Instance table used to track provisioning server heartbeats.
+		_, err := e.Exec(`
+				CREATE TABLE Instance (
+					ID TEXT PRIMARY KEY,
+					CreateAt BIGINT NOT NULL,
+					LastHeartbeatAt BIGINT NOT NULL
+				);
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.26.0"), semver.MustParse("0.27.0"), func(e execer) error {
+		// Add the Lease table used for leader election between instances.
+		_, err := e.Exec(`
+				CREATE TABLE Lease (
+					Name TEXT PRIMARY KEY,
+					HolderID TEXT NOT NULL,
+					AcquiredAt BIGINT NOT NULL,
+					ExpiresAt BIGINT NOT NULL
+				);
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.27.0"), semver.MustParse("0.28.0"), func(e execer) error {
+		// Track failed transition attempts to back off clusters and
+		// installations that keep failing.
+		_, err := e.Exec(`
+				ALTER TABLE Cluster
+				ADD COLUMN Attempts INT NOT NULL DEFAULT 0;
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				ALTER TABLE Cluster
+				ADD COLUMN LastError TEXT NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				ALTER TABLE Cluster
+				ADD COLUMN NextAttemptAt BIGINT NOT NULL DEFAULT 0;
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN Attempts INT NOT NULL DEFAULT 0;
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN LastError TEXT NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN NextAttemptAt BIGINT NOT NULL DEFAULT 0;
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.28.0"), semver.MustParse("0.29.0"), func(e execer) error {
+		// Add NodePool column for installations.
+		_, err := e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN NodePool TEXT NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.29.0"), semver.MustParse("0.30.0"), func(e execer) error {
+		// Add ClusterSize table for custom cluster sizes.
+		_, err := e.Exec(`
+				CREATE TABLE ClusterSize (
+					Name TEXT PRIMARY KEY,
+					NodeCount BIGINT NOT NULL,
+					NodeInstanceType TEXT NOT NULL,
+					MasterCount BIGINT NOT NULL,
+					MasterInstanceType TEXT NOT NULL,
+					CreateAt BIGINT NOT NULL
+				);
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.30.0"), semver.MustParse("0.31.0"), func(e execer) error {
+		// Add Zone and SchedulingStrategy columns for installations.
+		_, err := e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN Zone TEXT NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN SchedulingStrategy TEXT NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.31.0"), semver.MustParse("0.32.0"), func(e execer) error {
+		// Add FilestoreCopyMarker and FilestoreCopied columns for installation backups.
+		_, err := e.Exec(`
+				ALTER TABLE InstallationBackup
+				ADD COLUMN FilestoreCopyMarker TEXT NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				ALTER TABLE InstallationBackup
+				ADD COLUMN FilestoreCopied BOOLEAN NOT NULL DEFAULT FALSE;
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.32.0"), semver.MustParse("0.33.0"), func(e execer) error {
+		// Add the InstallationDNS table, which keeps the DNS names and aliases
+		// of installations unique across installations.
+		_, err := e.Exec(`
+			CREATE TABLE InstallationDNS (
+				DNSName TEXT PRIMARY KEY,
+				InstallationID TEXT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`CREATE INDEX ix_InstallationDNS_InstallationID ON InstallationDNS (InstallationID);`)
+		if err != nil {
+			return err
+		}
+
+		queryer, ok := e.(sqlx.Queryer)
+		if !ok {
+			return errors.New("unable to query installations to record their DNS names")
+		}
+
+		type installationDNSNames struct {
+			ID            string
+			DNS           string
+			DNSAliasesRaw []byte
+		}
+		var installations []installationDNSNames
+		err = sqlx.Select(queryer, &installations, `SELECT ID, DNS, DNSAliasesRaw FROM Installation WHERE DeleteAt = 0`)
+		if err != nil {
+			return err
+		}
+
+		// Names already shared by several installations are kept by the first
+		// one recorded, while the others must be changed to be updated again.
+		for _, installation := range installations {
+			var dnsAliases []string
+			if len(installation.DNSAliasesRaw) > 0 {
+				err = json.Unmarshal(installation.DNSAliasesRaw, &dnsAliases)
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, name := range append([]string{installation.DNS}, dnsAliases...) {
+				if name == "" {
+					continue
+				}
+				_, err = e.Exec(sqlx.Rebind(sqlx.BindType(e.DriverName()), `
+					INSERT INTO InstallationDNS (DNSName, InstallationID) VALUES (?, ?)
+					ON CONFLICT (DNSName) DO NOTHING;
+				`), strings.ToLower(name), installation.ID)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.33.0"), semver.MustParse("0.34.0"), func(e execer) error {
+		// Add PartitionedSupervisorsRaw column for instances.
+		_, err := e.Exec(`
+				ALTER TABLE Instance
+				ADD COLUMN PartitionedSupervisorsRaw TEXT NOT NULL DEFAULT '[]';
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.34.0"), semver.MustParse("0.35.0"), func(e execer) error {
+		// Add RestoreFilestoreCopyMarker and RestoreFilestoreCopied columns for installations.
+		_, err := e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN RestoreFilestoreCopyMarker TEXT NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN RestoreFilestoreCopied BOOLEAN NOT NULL DEFAULT FALSE;
+		`)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	}},
 }
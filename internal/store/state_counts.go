@@ -0,0 +1,46 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+)
+
+// GetClusterStateCounts returns the number of clusters in each state, excluding deleted clusters.
+func (sqlStore *SQLStore) GetClusterStateCounts() (map[string]int64, error) {
+	return sqlStore.getStateCounts("Cluster")
+}
+
+// GetInstallationStateCounts returns the number of installations in each state, excluding deleted installations.
+func (sqlStore *SQLStore) GetInstallationStateCounts() (map[string]int64, error) {
+	return sqlStore.getStateCounts("Installation")
+}
+
+// GetClusterInstallationStateCounts returns the number of cluster installations in each state, excluding deleted cluster installations.
+func (sqlStore *SQLStore) GetClusterInstallationStateCounts() (map[string]int64, error) {
+	return sqlStore.getStateCounts("ClusterInstallation")
+}
+
+// getStateCounts returns the number of rows in each state in the given table, excluding deleted rows.
+func (sqlStore *SQLStore) getStateCounts(table string) (map[string]int64, error) {
+	var stateCounts []struct {
+		State string
+		Count int64
+	}
+
+	err := sqlStore.selectBuilder(sqlStore.db, &stateCounts, sq.
+		Select("State", "COUNT(*) AS Count").
+		From(table).
+		Where("DeleteAt = 0").
+		GroupBy("State"),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to count rows by state in %s", table)
+	}
+
+	counts := make(map[string]int64, len(stateCounts))
+	for _, stateCount := range stateCounts {
+		counts[stateCount.State] = stateCount.Count
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateCounts(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	counts, err := sqlStore.GetClusterStateCounts()
+	require.NoError(t, err)
+	require.Empty(t, counts)
+
+	for _, state := range []string{model.ClusterStateStable, model.ClusterStateStable, model.ClusterStateCreationRequested} {
+		err = sqlStore.CreateCluster(&model.Cluster{State: state})
+		require.NoError(t, err)
+	}
+
+	deletedCluster := &model.Cluster{State: model.ClusterStateStable}
+	err = sqlStore.CreateCluster(deletedCluster)
+	require.NoError(t, err)
+	err = sqlStore.DeleteCluster(deletedCluster.ID)
+	require.NoError(t, err)
+
+	counts, err = sqlStore.GetClusterStateCounts()
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{
+		model.ClusterStateStable:            2,
+		model.ClusterStateCreationRequested: 1,
+	}, counts)
+
+	err = sqlStore.CreateInstallation(&model.Installation{State: model.InstallationStateStable})
+	require.NoError(t, err)
+
+	counts, err = sqlStore.GetInstallationStateCounts()
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{model.InstallationStateStable: 1}, counts)
+
+	err = sqlStore.CreateClusterInstallation(&model.ClusterInstallation{State: model.ClusterInstallationStateReconciling})
+	require.NoError(t, err)
+
+	counts, err = sqlStore.GetClusterInstallationStateCounts()
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{model.ClusterInstallationStateReconciling: 1}, counts)
+}
@@ -7,13 +7,10 @@ import (
 	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	sqlite3 "github.com/mattn/go-sqlite3"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-
-	// enable the pq driver
-	_ "github.com/lib/pq"
-	// enable the sqlite3 driver
-	_ "github.com/mattn/go-sqlite3"
 )
 
 // SQLStore abstracts access to the database.
@@ -171,3 +168,48 @@ func (sqlStore *SQLStore) execBuilder(e execer, b builder) (sql.Result, error) {
 
 	return sqlStore.exec(e, sql, args...)
 }
+
+// transaction runs the given function within a database transaction, which is
+// committed only if the function succeeds.
+func (sqlStore *SQLStore) transaction(f func(tx *sqlx.Tx) error) error {
+	tx, err := sqlStore.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	err = f(tx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+// isUniqueConstraintViolation returns whether the given error was caused by a
+// write violating a unique index or primary key.
+func isUniqueConstraintViolation(err error) bool {
+	switch err := errors.Cause(err).(type) {
+	case *pq.Error:
+		return err.Code == "23505"
+	case sqlite3.Error:
+		return err.ExtendedCode == sqlite3.ErrConstraintUnique || err.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+
+	return false
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern, using the backslash as
+// the escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern returns the LIKE pattern, to be used with ESCAPE '\',
+// matching the values that contain the given string literally.
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
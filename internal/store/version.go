@@ -32,3 +32,26 @@ func (sqlStore *SQLStore) getCurrentVersion(q queryer) (semver.Version, error) {
 func (sqlStore *SQLStore) setCurrentVersion(e execer, version string) error {
 	return sqlStore.setSystemValue(e, systemDatabaseVersionKey, version)
 }
+
+// CheckSchemaVersion verifies that the database is reachable and that its schema is compatible
+// with this server, returning the current database version.
+func (sqlStore *SQLStore) CheckSchemaVersion() (semver.Version, error) {
+	err := sqlStore.db.Ping()
+	if err != nil {
+		return semver.Version{}, errors.Wrap(err, "failed to connect to the database")
+	}
+
+	currentVersion, err := sqlStore.GetCurrentVersion()
+	if err != nil {
+		return semver.Version{}, err
+	}
+	serverVersion := LatestVersion()
+
+	// Require the schema to be at least the server version, and also the same major
+	// version.
+	if currentVersion.LT(serverVersion) || currentVersion.Major != serverVersion.Major {
+		return currentVersion, errors.Errorf("server requires at least schema %s, current is %s", serverVersion, currentVersion)
+	}
+
+	return currentVersion, nil
+}
@@ -42,3 +42,25 @@ func TestCurrentVersion(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, semver.MustParse("5.0.0"), currentVersion)
 }
+
+func TestCheckSchemaVersion(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := makeUnmigratedTestSQLStore(t, logger)
+
+	_, err := sqlStore.CheckSchemaVersion()
+	require.EqualError(t, err, "server requires at least schema "+LatestVersion().String()+", current is 0.0.0")
+
+	err = sqlStore.Migrate()
+	require.NoError(t, err)
+
+	currentVersion, err := sqlStore.CheckSchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, LatestVersion(), currentVersion)
+
+	err = sqlStore.setCurrentVersion(sqlStore.db, "5.0.0")
+	require.NoError(t, err)
+
+	currentVersion, err = sqlStore.CheckSchemaVersion()
+	require.EqualError(t, err, "server requires at least schema "+LatestVersion().String()+", current is 5.0.0")
+	require.Equal(t, semver.MustParse("5.0.0"), currentVersion)
+}
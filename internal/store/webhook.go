@@ -2,6 +2,7 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/mattermost/mattermost-cloud/model"
@@ -12,13 +13,59 @@ var webhookSelect sq.SelectBuilder
 
 func init() {
 	webhookSelect = sq.
-		Select("ID", "OwnerID", "URL", "CreateAt", "DeleteAt").From("Webhooks")
+		Select(
+			"ID", "OwnerID", "URL", "CreateAt", "DeleteAt", "Secret",
+			"TypesRaw", "NewStatesRaw", "InstallationOwnerID",
+		).
+		From("Webhooks")
+}
+
+type rawWebhook struct {
+	*model.Webhook
+	TypesRaw     []byte
+	NewStatesRaw []byte
+}
+
+type rawWebhooks []*rawWebhook
+
+func (r *rawWebhook) toWebhook() (*model.Webhook, error) {
+	// We only need to set values that are converted from a raw database format.
+	r.Webhook.Types = nil
+	if r.TypesRaw != nil {
+		err := json.Unmarshal(r.TypesRaw, &r.Webhook.Types)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal Types")
+		}
+	}
+
+	r.Webhook.NewStates = nil
+	if r.NewStatesRaw != nil {
+		err := json.Unmarshal(r.NewStatesRaw, &r.Webhook.NewStates)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal NewStates")
+		}
+	}
+
+	return r.Webhook, nil
+}
+
+func (rs *rawWebhooks) toWebhooks() ([]*model.Webhook, error) {
+	var webhooks []*model.Webhook
+	for _, rawWebhook := range *rs {
+		webhook, err := rawWebhook.toWebhook()
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
 }
 
 // GetWebhook fetches the given webhook by id.
 func (sqlStore *SQLStore) GetWebhook(id string) (*model.Webhook, error) {
-	var webhook model.Webhook
-	err := sqlStore.getBuilder(sqlStore.db, &webhook,
+	var rawWebhook rawWebhook
+	err := sqlStore.getBuilder(sqlStore.db, &rawWebhook,
 		webhookSelect.Where("ID = ?", id),
 	)
 	if err == sql.ErrNoRows {
@@ -27,7 +74,7 @@ func (sqlStore *SQLStore) GetWebhook(id string) (*model.Webhook, error) {
 		return nil, errors.Wrap(err, "failed to get webhook by id")
 	}
 
-	return &webhook, nil
+	return rawWebhook.toWebhook()
 }
 
 // GetWebhooks fetches the given page of created webhooks. The first page is 0.
@@ -48,13 +95,13 @@ func (sqlStore *SQLStore) GetWebhooks(filter *model.WebhookFilter) ([]*model.Web
 		builder = builder.Where("DeleteAt = 0")
 	}
 
-	var webhooks []*model.Webhook
-	err := sqlStore.selectBuilder(sqlStore.db, &webhooks, builder)
+	var rawWebhooks rawWebhooks
+	err := sqlStore.selectBuilder(sqlStore.db, &rawWebhooks, builder)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query for webhooks")
 	}
 
-	return webhooks, nil
+	return rawWebhooks.toWebhooks()
 }
 
 // CreateWebhook records the given webhook to the database, assigning it a unique ID.
@@ -62,14 +109,27 @@ func (sqlStore *SQLStore) CreateWebhook(webhook *model.Webhook) error {
 	webhook.ID = model.NewID()
 	webhook.CreateAt = GetMillis()
 
-	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+	typesJSON, err := json.Marshal(webhook.Types)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal Types")
+	}
+	newStatesJSON, err := json.Marshal(webhook.NewStates)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal NewStates")
+	}
+
+	_, err = sqlStore.execBuilder(sqlStore.db, sq.
 		Insert("Webhooks").
 		SetMap(map[string]interface{}{
-			"ID":       webhook.ID,
-			"OwnerID":  webhook.OwnerID,
-			"URL":      webhook.URL,
-			"CreateAt": webhook.CreateAt,
-			"DeleteAt": 0,
+			"ID":                  webhook.ID,
+			"OwnerID":             webhook.OwnerID,
+			"URL":                 webhook.URL,
+			"CreateAt":            webhook.CreateAt,
+			"DeleteAt":            0,
+			"Secret":              webhook.Secret,
+			"TypesRaw":            string(typesJSON),
+			"NewStatesRaw":        string(newStatesJSON),
+			"InstallationOwnerID": webhook.InstallationOwnerID,
 		}),
 	)
 	if err != nil {
@@ -0,0 +1,165 @@
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+var webhookDeliverySelect sq.SelectBuilder
+
+func init() {
+	webhookDeliverySelect = sq.
+		Select(
+			"ID", "WebhookID", "Payload", "State", "Attempts",
+			"LastAttemptAt", "NextAttemptAt", "LastError", "CreateAt",
+			"LockAcquiredBy", "LockAcquiredAt",
+		).
+		From("WebhookDelivery")
+}
+
+// GetWebhookDelivery fetches the given webhook delivery by id.
+func (sqlStore *SQLStore) GetWebhookDelivery(id string) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	err := sqlStore.getBuilder(sqlStore.db, &delivery,
+		webhookDeliverySelect.Where("ID = ?", id),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get webhook delivery by id")
+	}
+
+	return &delivery, nil
+}
+
+// GetWebhookDeliveries fetches the given page of webhook deliveries. The first page is 0.
+func (sqlStore *SQLStore) GetWebhookDeliveries(filter *model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, error) {
+	builder := webhookDeliverySelect.
+		OrderBy("CreateAt ASC")
+
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	if filter.WebhookID != "" {
+		builder = builder.Where("WebhookID = ?", filter.WebhookID)
+	}
+	if len(filter.States) != 0 {
+		builder = builder.Where(sq.Eq{"State": filter.States})
+	}
+
+	var deliveries []*model.WebhookDelivery
+	err := sqlStore.selectBuilder(sqlStore.db, &deliveries, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+// GetUnlockedWebhookDeliveriesPendingWork returns unlocked webhook deliveries
+// in a pending state that are due for their next delivery attempt.
+func (sqlStore *SQLStore) GetUnlockedWebhookDeliveriesPendingWork() ([]*model.WebhookDelivery, error) {
+	builder := webhookDeliverySelect.
+		Where(sq.Eq{
+			"State": model.AllWebhookDeliveryStatesPendingWork,
+		}).
+		Where("NextAttemptAt <= ?", GetMillis()).
+		Where("LockAcquiredAt = 0").
+		OrderBy("CreateAt ASC")
+
+	var deliveries []*model.WebhookDelivery
+	err := sqlStore.selectBuilder(sqlStore.db, &deliveries, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get webhook deliveries pending work")
+	}
+
+	return deliveries, nil
+}
+
+// CreateWebhookDelivery records the given webhook delivery to the database,
+// assigning it a unique ID.
+func (sqlStore *SQLStore) CreateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	delivery.ID = model.NewID()
+	delivery.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("WebhookDelivery").
+		SetMap(map[string]interface{}{
+			"ID":             delivery.ID,
+			"WebhookID":      delivery.WebhookID,
+			"Payload":        delivery.Payload,
+			"State":          delivery.State,
+			"Attempts":       delivery.Attempts,
+			"LastAttemptAt":  delivery.LastAttemptAt,
+			"NextAttemptAt":  delivery.NextAttemptAt,
+			"LastError":      delivery.LastError,
+			"CreateAt":       delivery.CreateAt,
+			"LockAcquiredBy": nil,
+			"LockAcquiredAt": 0,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhook delivery")
+	}
+
+	return nil
+}
+
+// UpdateWebhookDelivery updates the given webhook delivery in the database.
+func (sqlStore *SQLStore) UpdateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("WebhookDelivery").
+		SetMap(map[string]interface{}{
+			"State":         delivery.State,
+			"Attempts":      delivery.Attempts,
+			"LastAttemptAt": delivery.LastAttemptAt,
+			"NextAttemptAt": delivery.NextAttemptAt,
+			"LastError":     delivery.LastError,
+		}).
+		Where("ID = ?", delivery.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update webhook delivery")
+	}
+
+	return nil
+}
+
+// LockWebhookDelivery marks the webhook delivery as locked for exclusive use
+// by the caller.
+func (sqlStore *SQLStore) LockWebhookDelivery(deliveryID, lockerID string) (bool, error) {
+	return sqlStore.lockRows("WebhookDelivery", []string{deliveryID}, lockerID)
+}
+
+// UnlockWebhookDelivery releases a lock previously acquired against a caller.
+func (sqlStore *SQLStore) UnlockWebhookDelivery(deliveryID, lockerID string, force bool) (bool, error) {
+	return sqlStore.unlockRows("WebhookDelivery", []string{deliveryID}, lockerID, force)
+}
+
+// DeleteWebhookDeliveriesDeliveredBefore removes the unlocked webhook deliveries
+// that were delivered before the given time in milliseconds, returning the
+// number removed.
+func (sqlStore *SQLStore) DeleteWebhookDeliveriesDeliveredBefore(cutoff int64) (int64, error) {
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Delete("WebhookDelivery").
+		Where("State = ?", model.WebhookDeliveryStateDelivered).
+		Where("LastAttemptAt < ?", cutoff).
+		Where("LockAcquiredAt = 0"),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete delivered webhook deliveries")
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count rows affected")
+	}
+
+	return count, nil
+}
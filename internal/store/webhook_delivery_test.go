@@ -0,0 +1,178 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	t.Run("get unknown webhook delivery", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		delivery, err := sqlStore.GetWebhookDelivery("unknown")
+		require.NoError(t, err)
+		require.Nil(t, delivery)
+	})
+
+	t.Run("create, get and update webhook deliveries", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		webhookID1 := model.NewID()
+		webhookID2 := model.NewID()
+
+		delivery1 := &model.WebhookDelivery{
+			WebhookID: webhookID1,
+			Payload:   `{"id":"1"}`,
+			State:     model.WebhookDeliveryStatePending,
+		}
+		err := sqlStore.CreateWebhookDelivery(delivery1)
+		require.NoError(t, err)
+
+		time.Sleep(1 * time.Millisecond)
+
+		delivery2 := &model.WebhookDelivery{
+			WebhookID: webhookID2,
+			Payload:   `{"id":"2"}`,
+			State:     model.WebhookDeliveryStateFailed,
+			Attempts:  5,
+			LastError: "failed with status code 500",
+		}
+		err = sqlStore.CreateWebhookDelivery(delivery2)
+		require.NoError(t, err)
+
+		actualDelivery1, err := sqlStore.GetWebhookDelivery(delivery1.ID)
+		require.NoError(t, err)
+		require.Equal(t, delivery1, actualDelivery1)
+
+		actualDeliveries, err := sqlStore.GetWebhookDeliveries(&model.WebhookDeliveryFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{delivery1, delivery2}, actualDeliveries)
+
+		actualDeliveries, err = sqlStore.GetWebhookDeliveries(&model.WebhookDeliveryFilter{WebhookID: webhookID2, PerPage: 10})
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{delivery2}, actualDeliveries)
+
+		actualDeliveries, err = sqlStore.GetWebhookDeliveries(&model.WebhookDeliveryFilter{States: []string{model.WebhookDeliveryStateFailed}, PerPage: 10})
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{delivery2}, actualDeliveries)
+
+		actualDeliveries, err = sqlStore.GetWebhookDeliveries(&model.WebhookDeliveryFilter{Page: 1, PerPage: 1})
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{delivery2}, actualDeliveries)
+
+		delivery1.Attempts = 1
+		delivery1.LastAttemptAt = 100
+		delivery1.NextAttemptAt = 200
+		delivery1.LastError = "connection refused"
+		err = sqlStore.UpdateWebhookDelivery(delivery1)
+		require.NoError(t, err)
+
+		actualDelivery1, err = sqlStore.GetWebhookDelivery(delivery1.ID)
+		require.NoError(t, err)
+		require.Equal(t, delivery1, actualDelivery1)
+	})
+
+	t.Run("get and lock deliveries pending work", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		dueDelivery := &model.WebhookDelivery{
+			WebhookID: model.NewID(),
+			State:     model.WebhookDeliveryStatePending,
+		}
+		err := sqlStore.CreateWebhookDelivery(dueDelivery)
+		require.NoError(t, err)
+
+		notDueDelivery := &model.WebhookDelivery{
+			WebhookID:     model.NewID(),
+			State:         model.WebhookDeliveryStatePending,
+			NextAttemptAt: GetMillis() + time.Hour.Milliseconds(),
+		}
+		err = sqlStore.CreateWebhookDelivery(notDueDelivery)
+		require.NoError(t, err)
+
+		deliveredDelivery := &model.WebhookDelivery{
+			WebhookID: model.NewID(),
+			State:     model.WebhookDeliveryStateDelivered,
+		}
+		err = sqlStore.CreateWebhookDelivery(deliveredDelivery)
+		require.NoError(t, err)
+
+		deliveries, err := sqlStore.GetUnlockedWebhookDeliveriesPendingWork()
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{dueDelivery}, deliveries)
+
+		lockerID := model.NewID()
+
+		locked, err := sqlStore.LockWebhookDelivery(dueDelivery.ID, lockerID)
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		deliveries, err = sqlStore.GetUnlockedWebhookDeliveriesPendingWork()
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+
+		unlocked, err := sqlStore.UnlockWebhookDelivery(dueDelivery.ID, lockerID, false)
+		require.NoError(t, err)
+		require.True(t, unlocked)
+
+		deliveries, err = sqlStore.GetUnlockedWebhookDeliveriesPendingWork()
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{dueDelivery}, deliveries)
+	})
+
+	t.Run("delete delivered deliveries", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		oldDelivery := &model.WebhookDelivery{
+			WebhookID:     model.NewID(),
+			State:         model.WebhookDeliveryStateDelivered,
+			LastAttemptAt: 100,
+		}
+		err := sqlStore.CreateWebhookDelivery(oldDelivery)
+		require.NoError(t, err)
+
+		recentDelivery := &model.WebhookDelivery{
+			WebhookID:     model.NewID(),
+			State:         model.WebhookDeliveryStateDelivered,
+			LastAttemptAt: 300,
+		}
+		err = sqlStore.CreateWebhookDelivery(recentDelivery)
+		require.NoError(t, err)
+
+		failedDelivery := &model.WebhookDelivery{
+			WebhookID:     model.NewID(),
+			State:         model.WebhookDeliveryStateFailed,
+			LastAttemptAt: 100,
+		}
+		err = sqlStore.CreateWebhookDelivery(failedDelivery)
+		require.NoError(t, err)
+
+		pendingDelivery := &model.WebhookDelivery{
+			WebhookID:     model.NewID(),
+			State:         model.WebhookDeliveryStatePending,
+			LastAttemptAt: 100,
+		}
+		err = sqlStore.CreateWebhookDelivery(pendingDelivery)
+		require.NoError(t, err)
+
+		deleted, err := sqlStore.DeleteWebhookDeliveriesDeliveredBefore(200)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, deleted)
+
+		deliveries, err := sqlStore.GetWebhookDeliveries(&model.WebhookDeliveryFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []*model.WebhookDelivery{recentDelivery, failedDelivery, pendingDelivery}, deliveries)
+
+		deleted, err = sqlStore.DeleteWebhookDeliveriesDeliveredBefore(200)
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+	})
+}
@@ -31,6 +31,11 @@ func TestWebhooks(t *testing.T) {
 		webhook2 := &model.Webhook{
 			OwnerID: "owner2",
 			URL:     "https://url2.com",
+			Secret:  "secret",
+
+			Types:               []string{model.TypeInstallation},
+			NewStates:           []string{model.InstallationStateStable},
+			InstallationOwnerID: "owner3",
 		}
 
 		err := sqlStore.CreateWebhook(webhook1)
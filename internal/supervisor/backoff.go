@@ -0,0 +1,42 @@
+package supervisor
+
+import "time"
+
+const (
+	// transitionInitialBackoff is the delay before retrying a resource after
+	// its first failed transition attempt. It doubles with each further failure.
+	transitionInitialBackoff = 30 * time.Second
+	// transitionMaxBackoff caps the delay between retries of a resource whose
+	// transition keeps failing.
+	transitionMaxBackoff = 30 * time.Minute
+)
+
+// transitionBackoff returns the delay before retrying a resource after the
+// given number of consecutive failed transition attempts.
+func transitionBackoff(attempts int) time.Duration {
+	backoff := transitionInitialBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= transitionMaxBackoff {
+			return transitionMaxBackoff
+		}
+	}
+
+	return backoff
+}
+
+// nextTransitionAttempt returns the number of consecutive failed attempts and
+// the time of the next attempt, in milliseconds, following a transition. A
+// transition that logged an error without leaving the current state counts as
+// a failed attempt and is backed off, while any other outcome resets the
+// attempts so that the resource is supervised again as usual.
+func nextTransitionAttempt(attempts int, stateChanged bool, lastError string) (int, int64) {
+	if stateChanged || lastError == "" {
+		return 0, 0
+	}
+
+	attempts++
+	nextAttemptAt := time.Now().Add(transitionBackoff(attempts)).UnixNano() / int64(time.Millisecond)
+
+	return attempts, nextAttemptAt
+}
@@ -0,0 +1,654 @@
+package supervisor
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
+	"github.com/mattermost/mattermost-cloud/internal/webhook"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// CapacityStrategyGrow makes room for waiting installations by growing an
+	// existing cluster, requesting a new cluster only when no cluster can grow.
+	CapacityStrategyGrow = "grow"
+	// CapacityStrategyCreate makes room for waiting installations by always
+	// requesting a new cluster.
+	CapacityStrategyCreate = "create"
+)
+
+// CapacityPolicy configures how the capacity manager adds and removes
+// clusters.
+type CapacityPolicy struct {
+	// Strategy is one of the CapacityStrategy constants.
+	Strategy string
+	// SizeLadder lists the cluster sizes, from smallest to largest, through
+	// which a cluster grows one step at a time. The HA suffix of the size of
+	// the cluster is kept.
+	SizeLadder []string
+	// NodePoolStep is the number of nodes added to an additional instance
+	// group when a cluster grows for an installation pinned to it.
+	NodePoolStep int64
+	// NodePoolMaxCount is the number of nodes up to which an additional
+	// instance group grows.
+	NodePoolMaxCount int64
+	// ClusterSize is the size of new clusters.
+	ClusterSize string
+	// ClusterZones are the zones of new clusters.
+	ClusterZones []string
+	// MaxClusters is the number of clusters beyond which no new cluster is
+	// requested.
+	MaxClusters int
+	// MinClusters is the number of clusters below which empty clusters are
+	// kept.
+	MinClusters int
+	// ScaleDownGracePeriod is how long a cluster must stay empty before it is
+	// deleted, or 0 to keep empty clusters.
+	ScaleDownGracePeriod time.Duration
+	// ReservationTimeout is how long a new cluster stays reserved for the
+	// installation it was requested for once stable. An installation still
+	// waiting after that does not fit a new cluster, and no more capacity is
+	// added for it.
+	ReservationTimeout time.Duration
+}
+
+// Validate validates the values of a capacity policy.
+func (p *CapacityPolicy) Validate() error {
+	if p.Strategy != CapacityStrategyGrow && p.Strategy != CapacityStrategyCreate {
+		return errors.Errorf("unsupported capacity strategy %s", p.Strategy)
+	}
+	for _, size := range p.SizeLadder {
+		if strings.Contains(size, "-HA") || !model.IsSupportedClusterSize(size) {
+			return errors.Errorf("unsupported size %s in size ladder", size)
+		}
+	}
+	if p.NodePoolStep < 1 {
+		return errors.New("node pool step must be 1 or greater")
+	}
+	if p.NodePoolMaxCount < 1 {
+		return errors.New("node pool max count must be 1 or greater")
+	}
+	if !model.IsSupportedClusterSize(p.ClusterSize) {
+		return errors.Errorf("unsupported cluster size %s", p.ClusterSize)
+	}
+	if p.MaxClusters < 1 {
+		return errors.New("max clusters must be 1 or greater")
+	}
+	if p.MinClusters < 0 {
+		return errors.New("min clusters must not be negative")
+	}
+	if p.ScaleDownGracePeriod < 0 {
+		return errors.New("scale down grace period must not be negative")
+	}
+	if p.ReservationTimeout <= 0 {
+		return errors.New("reservation timeout must be greater than zero")
+	}
+
+	return nil
+}
+
+// nextSize returns the size one step up the size ladder from the given size,
+// keeping its HA suffix, or an empty string if the size cannot grow.
+func (p *CapacityPolicy) nextSize(size string) string {
+	for i, step := range p.SizeLadder {
+		if size != step && !strings.HasPrefix(size, step+"-HA") {
+			continue
+		}
+		if i+1 == len(p.SizeLadder) {
+			return ""
+		}
+		return p.SizeLadder[i+1] + strings.TrimPrefix(size, step)
+	}
+
+	return ""
+}
+
+// capacityStore abstracts the database operations required to manage cluster
+// capacity.
+type capacityStore interface {
+	GetClusters(clusterFilter *model.ClusterFilter) ([]*model.Cluster, error)
+	GetCluster(id string) (*model.Cluster, error)
+	CreateCluster(cluster *model.Cluster) error
+	UpdateCluster(cluster *model.Cluster) error
+	LockCluster(clusterID, lockerID string) (bool, error)
+	UnlockCluster(clusterID string, lockerID string, force bool) (bool, error)
+
+	GetInstallations(filter *model.InstallationFilter, includeGroupConfig, includeGroupConfigOverrides bool) ([]*model.Installation, error)
+	GetClusterInstallations(filter *model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error)
+
+	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+}
+
+// CapacityManager adds capacity when installations are waiting for a
+// compatible cluster, and removes the clusters it added once they have stayed
+// empty, according to its policy.
+//
+// Capacity is added one step at a time: nothing more is requested while a
+// cluster is being created or changed, giving waiting installations the chance
+// to be scheduled first. A new cluster is reserved for the installation it was
+// requested for, which is not considered again until the reservation is
+// released or expires. The capacity manager must only run on a single server,
+// such as the leader.
+type CapacityManager struct {
+	store      capacityStore
+	policy     CapacityPolicy
+	instanceID string
+	logger     log.FieldLogger
+
+	// emptySince records when each cluster was first seen without any
+	// cluster installations.
+	emptySince map[string]time.Time
+	// reservedStableSince records when each reserved cluster was first seen
+	// stable.
+	reservedStableSince map[string]time.Time
+}
+
+// NewCapacityManager creates a new CapacityManager.
+func NewCapacityManager(store capacityStore, policy CapacityPolicy, instanceID string, logger log.FieldLogger) *CapacityManager {
+	return &CapacityManager{
+		store:               store,
+		policy:              policy,
+		instanceID:          instanceID,
+		logger:              logger.WithField("capacity-strategy", policy.Strategy),
+		emptySince:          make(map[string]time.Time),
+		reservedStableSince: make(map[string]time.Time),
+	}
+}
+
+// Do adds or removes capacity as required.
+func (m *CapacityManager) Do() error {
+	defer metrics.ObserveSupervisorDo("capacity_manager", time.Now())
+
+	// Deleted clusters are included to remember the installations that did
+	// not fit a new cluster.
+	allClusters, err := m.store.GetClusters(&model.ClusterFilter{
+		PerPage:        model.AllPerPage,
+		IncludeDeleted: true,
+	})
+	if err != nil {
+		m.logger.WithError(err).Warn("Failed to query clusters")
+		return nil
+	}
+
+	waitingInstallations, err := m.store.GetInstallations(&model.InstallationFilter{
+		States:  []string{model.InstallationStateCreationNoCompatibleClusters},
+		PerPage: model.AllPerPage,
+	}, false, false)
+	if err != nil {
+		m.logger.WithError(err).Warn("Failed to query installations waiting for capacity")
+		return nil
+	}
+
+	reservations := m.checkReservations(allClusters, waitingInstallations)
+
+	var clusters []*model.Cluster
+	for _, cluster := range allClusters {
+		if cluster.DeleteAt == 0 {
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	for _, installation := range waitingInstallations {
+		if reservations.waiting[installation.ID] || reservations.unfit[installation.ID] {
+			continue
+		}
+		m.scaleUp(clusters, installation)
+		break
+	}
+
+	m.scaleDown(clusters, reservations.clusters)
+
+	return nil
+}
+
+// capacityReservations describes the clusters reserved for waiting
+// installations.
+type capacityReservations struct {
+	// clusters are the IDs of the clusters still reserved.
+	clusters map[string]bool
+	// waiting are the IDs of the installations waiting for a reserved
+	// cluster.
+	waiting map[string]bool
+	// unfit are the IDs of the installations that did not fit the cluster
+	// reserved for them.
+	unfit map[string]bool
+}
+
+// checkReservations releases the reservations of the clusters whose
+// installation is no longer waiting, or whose creation failed, and expires
+// those that stayed stable for longer than the reservation timeout.
+func (m *CapacityManager) checkReservations(clusters []*model.Cluster, waitingInstallations []*model.Installation) *capacityReservations {
+	now := time.Now()
+	reservations := &capacityReservations{
+		clusters: make(map[string]bool),
+		waiting:  make(map[string]bool),
+		unfit:    make(map[string]bool),
+	}
+	reservedStableSince := make(map[string]time.Time)
+
+	waiting := make(map[string]bool)
+	for _, installation := range waitingInstallations {
+		waiting[installation.ID] = true
+	}
+
+	for _, cluster := range clusters {
+		kopsMetadata, err := model.NewKopsMetadata(cluster.ProvisionerMetadata)
+		if err != nil || kopsMetadata.CapacityReservedFor == "" {
+			continue
+		}
+		installationID := kopsMetadata.CapacityReservedFor
+		if kopsMetadata.CapacityReservationExpired {
+			if waiting[installationID] {
+				reservations.unfit[installationID] = true
+			}
+			continue
+		}
+		if cluster.DeleteAt != 0 {
+			continue
+		}
+		logger := m.logger.WithFields(log.Fields{
+			"cluster":      cluster.ID,
+			"installation": installationID,
+		})
+
+		if !waiting[installationID] || (cluster.State != model.ClusterStateStable && !isPendingWork(cluster.State, model.AllClusterStatesPendingWork)) {
+			logger.Debugf("Releasing cluster in state %s", cluster.State)
+			m.updateReservation(cluster, false, logger)
+			continue
+		}
+
+		if cluster.State == model.ClusterStateStable {
+			since, ok := m.reservedStableSince[cluster.ID]
+			if !ok {
+				since = now
+			}
+			reservedStableSince[cluster.ID] = since
+
+			if now.Sub(since) >= m.policy.ReservationTimeout {
+				logger.Warnf("Installation still waiting %s after the cluster requested for it became stable; no more capacity will be added for it", m.policy.ReservationTimeout)
+				if m.updateReservation(cluster, true, logger) {
+					reservations.unfit[installationID] = true
+					continue
+				}
+			}
+		}
+
+		reservations.clusters[cluster.ID] = true
+		reservations.waiting[installationID] = true
+	}
+
+	m.reservedStableSince = reservedStableSince
+
+	return reservations
+}
+
+// updateReservation releases the reservation of the given cluster, or marks it
+// as expired, returning whether the cluster was updated.
+func (m *CapacityManager) updateReservation(cluster *model.Cluster, expired bool, logger log.FieldLogger) bool {
+	clusterLock := newClusterLock(cluster.ID, m.instanceID, m.store, logger)
+	if !clusterLock.TryLock() {
+		logger.Debug("Failed to lock cluster")
+		return false
+	}
+	defer clusterLock.Unlock()
+
+	cluster, err := m.store.GetCluster(cluster.ID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get cluster")
+		return false
+	}
+	if cluster == nil {
+		return false
+	}
+
+	kopsMetadata, err := model.NewKopsMetadata(cluster.ProvisionerMetadata)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to parse provisioner metadata")
+		return false
+	}
+	if expired {
+		kopsMetadata.CapacityReservationExpired = true
+	} else {
+		kopsMetadata.CapacityReservedFor = ""
+	}
+	err = cluster.SetProvisionerMetadata(kopsMetadata)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to set provisioner metadata")
+		return false
+	}
+
+	err = m.store.UpdateCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to update cluster reservation")
+		return false
+	}
+
+	return true
+}
+
+// scaleUp adds capacity for the given installation waiting for a compatible
+// cluster, unless capacity is already being added.
+func (m *CapacityManager) scaleUp(clusters []*model.Cluster, installation *model.Installation) {
+	logger := m.logger.WithField("installation", installation.ID)
+
+	for _, cluster := range clusters {
+		if cluster.State != model.ClusterStateDeletionRequested && isPendingWork(cluster.State, model.AllClusterStatesPendingWork) {
+			logger.Debugf("Waiting for cluster %s in state %s before adding capacity", cluster.ID, cluster.State)
+			return
+		}
+	}
+
+	// Growing a cluster cannot make room for an isolated installation.
+	if m.policy.Strategy == CapacityStrategyGrow && installation.Affinity != model.InstallationAffinityIsolated {
+		for _, cluster := range clusters {
+			if m.growCluster(cluster, installation, logger) {
+				return
+			}
+		}
+		logger.Debug("No cluster can grow for the installation")
+	}
+
+	m.requestCluster(clusters, installation, logger)
+}
+
+// growCluster attempts to grow the given cluster to make room for the
+// installation, returning whether the cluster was marked for resize.
+func (m *CapacityManager) growCluster(cluster *model.Cluster, installation *model.Installation, logger log.FieldLogger) bool {
+	if cluster.State != model.ClusterStateStable || !cluster.AllowInstallations || !cluster.HasNodePool(installation.NodePool) || !cluster.InZone(installation.Zone) {
+		return false
+	}
+
+	clusterLock := newClusterLock(cluster.ID, m.instanceID, m.store, logger)
+	if !clusterLock.TryLock() {
+		logger.Debugf("Failed to lock cluster %s", cluster.ID)
+		return false
+	}
+	defer clusterLock.Unlock()
+
+	cluster, err := m.store.GetCluster(cluster.ID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get cluster")
+		return false
+	}
+	if cluster == nil || !cluster.ValidTransitionState(model.ClusterStateResizeRequested) {
+		return false
+	}
+
+	kopsMetadata, err := model.NewKopsMetadata(cluster.ProvisionerMetadata)
+	if err != nil {
+		logger.WithError(err).Warnf("Failed to parse provisioner metadata of cluster %s", cluster.ID)
+		return false
+	}
+
+	if installation.NodePool == "" {
+		nextSize := m.policy.nextSize(cluster.Size)
+		if nextSize == "" {
+			return false
+		}
+		logger.Infof("Growing cluster %s from size %s to %s", cluster.ID, cluster.Size, nextSize)
+		// The cluster size is only updated once the resize has succeeded.
+		kopsMetadata.ResizeSize = nextSize
+	} else {
+		instanceGroup := kopsMetadata.GetInstanceGroup(installation.NodePool)
+		if instanceGroup == nil || instanceGroup.MinCount >= m.policy.NodePoolMaxCount {
+			return false
+		}
+
+		minCount := instanceGroup.MinCount + m.policy.NodePoolStep
+		if minCount > m.policy.NodePoolMaxCount {
+			minCount = m.policy.NodePoolMaxCount
+		}
+		logger.Infof("Growing node pool %s of cluster %s from %d to %d nodes", installation.NodePool, cluster.ID, instanceGroup.MinCount, minCount)
+		instanceGroup.MinCount = minCount
+		if instanceGroup.MaxCount < minCount {
+			instanceGroup.MaxCount = minCount
+		}
+	}
+
+	err = cluster.SetProvisionerMetadata(kopsMetadata)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to set provisioner metadata")
+		return false
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeCluster,
+		ID:        cluster.ID,
+		NewState:  model.ClusterStateResizeRequested,
+		OldState:  cluster.State,
+		Timestamp: time.Now().UnixNano(),
+	}
+	cluster.State = model.ClusterStateResizeRequested
+	cluster.ResetAttempts()
+
+	err = m.store.UpdateCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to mark cluster for resize")
+		return false
+	}
+
+	err = webhook.SendToAllWebhooks(m.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	return true
+}
+
+// requestCluster requests a new cluster to make room for the installation,
+// unless the maximum number of clusters has been reached.
+func (m *CapacityManager) requestCluster(clusters []*model.Cluster, installation *model.Installation, logger log.FieldLogger) {
+	if countActiveClusters(clusters) >= m.policy.MaxClusters {
+		logger.Warnf("Unable to add capacity beyond the maximum of %d clusters", m.policy.MaxClusters)
+		return
+	}
+
+	createClusterRequest := &model.CreateClusterRequest{
+		Size:               m.policy.ClusterSize,
+		Zones:              m.policy.ClusterZones,
+		AllowInstallations: true,
+	}
+	if installation.Zone != "" {
+		createClusterRequest.Zones = []string{installation.Zone}
+	}
+
+	// New clusters copy the node pool the installation is pinned to from an
+	// existing cluster declaring it.
+	if installation.NodePool != "" {
+		for _, cluster := range clusters {
+			kopsMetadata, err := model.NewKopsMetadata(cluster.ProvisionerMetadata)
+			if err != nil {
+				continue
+			}
+			if instanceGroup := kopsMetadata.GetInstanceGroup(installation.NodePool); instanceGroup != nil {
+				createClusterRequest.InstanceGroups = []model.KopsInstanceGroup{*instanceGroup}
+				break
+			}
+		}
+		if len(createClusterRequest.InstanceGroups) == 0 {
+			logger.Warnf("Unable to add capacity for node pool %s, which no cluster declares", installation.NodePool)
+			return
+		}
+	}
+
+	createClusterRequest.SetDefaults()
+	err := createClusterRequest.Validate()
+	if err != nil {
+		logger.WithError(err).Error("Invalid request for a new cluster")
+		return
+	}
+
+	cluster := &model.Cluster{
+		Provider:           createClusterRequest.Provider,
+		Provisioner:        "kops",
+		Version:            "0.0.0",
+		Size:               createClusterRequest.Size,
+		AllowInstallations: createClusterRequest.AllowInstallations,
+		State:              model.ClusterStateCreationRequested,
+	}
+	err = cluster.SetProviderMetadata(model.AWSMetadata{
+		Zones: createClusterRequest.Zones,
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to set provider metadata")
+		return
+	}
+	err = cluster.SetUtilityDesiredVersions(createClusterRequest.DesiredUtilityVersions)
+	if err != nil {
+		logger.WithError(err).Error("Failed to set utility metadata")
+		return
+	}
+	err = cluster.SetProvisionerMetadata(model.KopsMetadata{
+		Version:             createClusterRequest.Version,
+		InstanceGroups:      createClusterRequest.InstanceGroups,
+		CapacityManaged:     true,
+		CapacityReservedFor: installation.ID,
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to set provisioner metadata")
+		return
+	}
+
+	err = m.store.CreateCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create cluster")
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeCluster,
+		ID:        cluster.ID,
+		NewState:  model.ClusterStateCreationRequested,
+		OldState:  "n/a",
+		Timestamp: time.Now().UnixNano(),
+	}
+	err = webhook.SendToAllWebhooks(m.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	logger.Infof("Requested new cluster %s of size %s", cluster.ID, cluster.Size)
+}
+
+// scaleDown deletes clusters requested by the capacity manager that have been
+// empty for longer than the grace period, keeping the minimum number of
+// clusters. Clusters created through the API, and reserved clusters, are never
+// deleted.
+func (m *CapacityManager) scaleDown(clusters []*model.Cluster, reservedClusters map[string]bool) {
+	if m.policy.ScaleDownGracePeriod == 0 {
+		return
+	}
+
+	now := time.Now()
+	activeClusters := countActiveClusters(clusters)
+	emptySince := make(map[string]time.Time)
+
+	for _, cluster := range clusters {
+		if cluster.State != model.ClusterStateStable || !cluster.AllowInstallations || !cluster.IsCapacityManaged() || reservedClusters[cluster.ID] {
+			continue
+		}
+		logger := m.logger.WithField("cluster", cluster.ID)
+
+		clusterInstallations, err := m.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+			ClusterID: cluster.ID,
+			PerPage:   model.AllPerPage,
+		})
+		if err != nil {
+			logger.WithError(err).Warn("Failed to query cluster installations")
+			continue
+		}
+		if len(clusterInstallations) > 0 {
+			continue
+		}
+
+		since, ok := m.emptySince[cluster.ID]
+		if !ok {
+			since = now
+		}
+		emptySince[cluster.ID] = since
+
+		if now.Sub(since) < m.policy.ScaleDownGracePeriod || activeClusters <= m.policy.MinClusters {
+			continue
+		}
+		if m.deleteEmptyCluster(cluster, logger) {
+			delete(emptySince, cluster.ID)
+			activeClusters--
+		}
+	}
+
+	m.emptySince = emptySince
+}
+
+// deleteEmptyCluster marks the given cluster for deletion if it still has no
+// cluster installations, returning whether it was marked.
+func (m *CapacityManager) deleteEmptyCluster(cluster *model.Cluster, logger log.FieldLogger) bool {
+	clusterLock := newClusterLock(cluster.ID, m.instanceID, m.store, logger)
+	if !clusterLock.TryLock() {
+		logger.Debug("Failed to lock cluster")
+		return false
+	}
+	defer clusterLock.Unlock()
+
+	// Check again under lock, in case an installation was scheduled onto the
+	// cluster in the meantime.
+	cluster, err := m.store.GetCluster(cluster.ID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get cluster")
+		return false
+	}
+	if cluster == nil || cluster.State != model.ClusterStateStable {
+		return false
+	}
+	clusterInstallations, err := m.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		ClusterID: cluster.ID,
+		PerPage:   model.AllPerPage,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to query cluster installations")
+		return false
+	}
+	if len(clusterInstallations) > 0 {
+		return false
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeCluster,
+		ID:        cluster.ID,
+		NewState:  model.ClusterStateDeletionRequested,
+		OldState:  cluster.State,
+		Timestamp: time.Now().UnixNano(),
+	}
+	cluster.State = model.ClusterStateDeletionRequested
+	cluster.ResetAttempts()
+
+	err = m.store.UpdateCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to mark cluster for deletion")
+		return false
+	}
+
+	err = webhook.SendToAllWebhooks(m.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	logger.Infof("Requested deletion of cluster empty for longer than %s", m.policy.ScaleDownGracePeriod)
+
+	return true
+}
+
+// countActiveClusters returns the number of clusters not being deleted.
+func countActiveClusters(clusters []*model.Cluster) int {
+	var count int
+	for _, cluster := range clusters {
+		if cluster.State != model.ClusterStateDeletionRequested {
+			count++
+		}
+	}
+
+	return count
+}
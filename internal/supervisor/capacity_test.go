@@ -0,0 +1,499 @@
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func testCapacityPolicy() supervisor.CapacityPolicy {
+	return supervisor.CapacityPolicy{
+		Strategy:           supervisor.CapacityStrategyGrow,
+		SizeLadder:         []string{model.SizeAlef500, model.SizeAlef1000},
+		NodePoolStep:       2,
+		NodePoolMaxCount:   5,
+		ClusterSize:        model.SizeAlef500,
+		ClusterZones:       []string{"us-east-1a"},
+		MaxClusters:        3,
+		MinClusters:        1,
+		ReservationTimeout: time.Minute,
+	}
+}
+
+func TestCapacityPolicyValidate(t *testing.T) {
+	var testCases = []struct {
+		description string
+		modify      func(*supervisor.CapacityPolicy)
+		expectError bool
+	}{
+		{"valid", func(p *supervisor.CapacityPolicy) {}, false},
+		{"create strategy", func(p *supervisor.CapacityPolicy) { p.Strategy = supervisor.CapacityStrategyCreate }, false},
+		{"empty size ladder", func(p *supervisor.CapacityPolicy) { p.SizeLadder = nil }, false},
+		{"unknown strategy", func(p *supervisor.CapacityPolicy) { p.Strategy = "shrink" }, true},
+		{"unsupported ladder size", func(p *supervisor.CapacityPolicy) { p.SizeLadder = []string{"SizeUnknown"} }, true},
+		{"HA ladder size", func(p *supervisor.CapacityPolicy) { p.SizeLadder = []string{model.SizeAlef500 + "-HA3"} }, true},
+		{"no node pool step", func(p *supervisor.CapacityPolicy) { p.NodePoolStep = 0 }, true},
+		{"no node pool max count", func(p *supervisor.CapacityPolicy) { p.NodePoolMaxCount = 0 }, true},
+		{"unsupported cluster size", func(p *supervisor.CapacityPolicy) { p.ClusterSize = "SizeUnknown" }, true},
+		{"no max clusters", func(p *supervisor.CapacityPolicy) { p.MaxClusters = 0 }, true},
+		{"negative min clusters", func(p *supervisor.CapacityPolicy) { p.MinClusters = -1 }, true},
+		{"negative grace period", func(p *supervisor.CapacityPolicy) { p.ScaleDownGracePeriod = -time.Second }, true},
+		{"no reservation timeout", func(p *supervisor.CapacityPolicy) { p.ReservationTimeout = 0 }, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			policy := testCapacityPolicy()
+			tc.modify(&policy)
+			if tc.expectError {
+				require.Error(t, policy.Validate())
+			} else {
+				require.NoError(t, policy.Validate())
+			}
+		})
+	}
+}
+
+func TestCapacityManager(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	setup := func(t *testing.T) *store.SQLStore {
+		return store.MakeTestSQLStore(t, logger)
+	}
+
+	createCluster := func(t *testing.T, sqlStore *store.SQLStore, size string, instanceGroups []model.KopsInstanceGroup) *model.Cluster {
+		t.Helper()
+		cluster := &model.Cluster{
+			Provider:           model.ProviderAWS,
+			Provisioner:        "kops",
+			Size:               size,
+			AllowInstallations: true,
+			State:              model.ClusterStateStable,
+		}
+		err := cluster.SetProvisionerMetadata(model.KopsMetadata{InstanceGroups: instanceGroups})
+		require.NoError(t, err)
+		err = sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		return cluster
+	}
+
+	// createManagedCluster creates a cluster as if requested by the capacity manager.
+	createManagedCluster := func(t *testing.T, sqlStore *store.SQLStore) *model.Cluster {
+		t.Helper()
+		cluster := &model.Cluster{
+			Provider:           model.ProviderAWS,
+			Provisioner:        "kops",
+			Size:               model.SizeAlef500,
+			AllowInstallations: true,
+			State:              model.ClusterStateStable,
+		}
+		err := cluster.SetProvisionerMetadata(model.KopsMetadata{CapacityManaged: true})
+		require.NoError(t, err)
+		err = sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		return cluster
+	}
+
+	createWaitingInstallation := func(t *testing.T, sqlStore *store.SQLStore, affinity, nodePool string) *model.Installation {
+		t.Helper()
+		installation := &model.Installation{
+			OwnerID:  model.NewID(),
+			Version:  "version",
+			DNS:      model.NewID() + ".example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: affinity,
+			NodePool: nodePool,
+			State:    model.InstallationStateCreationNoCompatibleClusters,
+		}
+		err := sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		return installation
+	}
+
+	getClusters := func(t *testing.T, sqlStore *store.SQLStore) []*model.Cluster {
+		t.Helper()
+		clusters, err := sqlStore.GetClusters(&model.ClusterFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+
+		return clusters
+	}
+
+	getCluster := func(t *testing.T, sqlStore *store.SQLStore, clusterID string) *model.Cluster {
+		t.Helper()
+		cluster, err := sqlStore.GetCluster(clusterID)
+		require.NoError(t, err)
+		require.NotNil(t, cluster)
+		require.Zero(t, cluster.LockAcquiredAt)
+
+		return cluster
+	}
+
+	// getNewCluster returns the only cluster other than the existing one.
+	getNewCluster := func(t *testing.T, sqlStore *store.SQLStore, existingClusterID string) *model.Cluster {
+		t.Helper()
+		clusters := getClusters(t, sqlStore)
+		require.Len(t, clusters, 2)
+		for _, cluster := range clusters {
+			if cluster.ID != existingClusterID {
+				return cluster
+			}
+		}
+
+		return nil
+	}
+
+	t.Run("no waiting installations", func(t *testing.T) {
+		sqlStore := setup(t)
+		cluster := createCluster(t, sqlStore, model.SizeAlef500, nil)
+
+		manager := supervisor.NewCapacityManager(sqlStore, testCapacityPolicy(), "instance", logger)
+		err := manager.Do()
+		require.NoError(t, err)
+
+		clusters := getClusters(t, sqlStore)
+		require.Len(t, clusters, 1)
+		require.Equal(t, model.ClusterStateStable, getCluster(t, sqlStore, cluster.ID).State)
+	})
+
+	t.Run("grow cluster size", func(t *testing.T) {
+		sqlStore := setup(t)
+		cluster := createCluster(t, sqlStore, model.SizeAlef500+"-HA3", nil)
+		createWaitingInstallation(t, sqlStore, model.InstallationAffinityMultiTenant, "")
+
+		manager := supervisor.NewCapacityManager(sqlStore, testCapacityPolicy(), "instance", logger)
+		err := manager.Do()
+		require.NoError(t, err)
+
+		cluster = getCluster(t, sqlStore, cluster.ID)
+		require.Equal(t, model.ClusterStateResizeRequested, cluster.State)
+		require.Equal(t, model.SizeAlef500+"-HA3", cluster.Size)
+		kopsMetadata, err := model.NewKopsMetadata(cluster.ProvisionerMetadata)
+		require.NoError(t, err)
+		require.Equal(t, model.SizeAlef1000+"-HA3", kopsMetadata.ResizeSize)
+
+		t.Run("wait for resize", func(t *testing.T) {
+			err := manager.Do()
+			require.NoError(t, err)
+
+			require.Len(t, getClusters(t, sqlStore), 1)
+			kopsMetadata, err := model.NewKopsMetadata(getCluster(t, sqlStore, cluster.ID).ProvisionerMetadata)
+			require.NoError(t, err)
+			require.Equal(t, model.SizeAlef1000+"-HA3", kopsMetadata.ResizeSize)
+		})
+
+		t.Run("top of size ladder", func(t *testing.T) {
+			// Complete the resize as the provisioner would.
+			kopsMetadata.ResizeSize = ""
+			err := cluster.SetProvisionerMetadata(kopsMetadata)
+			require.NoError(t, err)
+			cluster.Size = model.SizeAlef1000 + "-HA3"
+			cluster.State = model.ClusterStateStable
+			err = sqlStore.UpdateCluster(cluster)
+			require.NoError(t, err)
+
+			err = manager.Do()
+			require.NoError(t, err)
+
+			require.Equal(t, model.ClusterStateStable, getCluster(t, sqlStore, cluster.ID).State)
+			newCluster := getNewCluster(t, sqlStore, cluster.ID)
+			require.Equal(t, model.ClusterStateCreationRequested, newCluster.State)
+			require.Equal(t, model.SizeAlef500, newCluster.Size)
+			require.True(t, newCluster.AllowInstallations)
+		})
+	})
+
+	t.Run("grow node pool", func(t *testing.T) {
+		sqlStore := setup(t)
+		cluster := createCluster(t, sqlStore, model.SizeAlef500, []model.KopsInstanceGroup{
+			{Name: "memory", MachineType: "r5.xlarge", MinCount: 2, MaxCount: 3},
+		})
+		createWaitingInstallation(t, sqlStore, model.InstallationAffinityMultiTenant, "memory")
+
+		manager := supervisor.NewCapacityManager(sqlStore, testCapacityPolicy(), "instance", logger)
+		err := manager.Do()
+		require.NoError(t, err)
+
+		cluster = getCluster(t, sqlStore, cluster.ID)
+		require.Equal(t, model.ClusterStateResizeRequested, cluster.State)
+		require.Equal(t, model.SizeAlef500, cluster.Size)
+
+		kopsMetadata, err := model.NewKopsMetadata(cluster.ProvisionerMetadata)
+		require.NoError(t, err)
+		require.Equal(t, []model.KopsInstanceGroup{
+			{Name: "memory", MachineType: "r5.xlarge", MinCount: 4, MaxCount: 4},
+		}, kopsMetadata.InstanceGroups)
+
+		t.Run("node pool max count", func(t *testing.T) {
+			cluster.State = model.ClusterStateStable
+			err := sqlStore.UpdateCluster(cluster)
+			require.NoError(t, err)
+
+			err = manager.Do()
+			require.NoError(t, err)
+
+			cluster = getCluster(t, sqlStore, cluster.ID)
+			kopsMetadata, err := model.NewKopsMetadata(cluster.ProvisionerMetadata)
+			require.NoError(t, err)
+			require.Equal(t, int64(5), kopsMetadata.GetInstanceGroup("memory").MinCount)
+			require.Equal(t, int64(5), kopsMetadata.GetInstanceGroup("memory").MaxCount)
+		})
+
+		t.Run("new cluster copies node pool", func(t *testing.T) {
+			cluster.State = model.ClusterStateStable
+			err := sqlStore.UpdateCluster(cluster)
+			require.NoError(t, err)
+
+			err = manager.Do()
+			require.NoError(t, err)
+
+			newCluster := getNewCluster(t, sqlStore, cluster.ID)
+			require.Equal(t, model.ClusterStateCreationRequested, newCluster.State)
+			require.True(t, newCluster.HasNodePool("memory"))
+		})
+	})
+
+	t.Run("isolated installation", func(t *testing.T) {
+		sqlStore := setup(t)
+		cluster := createCluster(t, sqlStore, model.SizeAlef500, nil)
+		createWaitingInstallation(t, sqlStore, model.InstallationAffinityIsolated, "")
+
+		manager := supervisor.NewCapacityManager(sqlStore, testCapacityPolicy(), "instance", logger)
+		err := manager.Do()
+		require.NoError(t, err)
+
+		require.Equal(t, model.ClusterStateStable, getCluster(t, sqlStore, cluster.ID).State)
+		require.Len(t, getClusters(t, sqlStore), 2)
+	})
+
+	t.Run("create strategy", func(t *testing.T) {
+		sqlStore := setup(t)
+		cluster := createCluster(t, sqlStore, model.SizeAlef500, nil)
+		createWaitingInstallation(t, sqlStore, model.InstallationAffinityMultiTenant, "")
+
+		policy := testCapacityPolicy()
+		policy.Strategy = supervisor.CapacityStrategyCreate
+		policy.MaxClusters = 2
+		manager := supervisor.NewCapacityManager(sqlStore, policy, "instance", logger)
+		err := manager.Do()
+		require.NoError(t, err)
+
+		require.Equal(t, model.ClusterStateStable, getCluster(t, sqlStore, cluster.ID).State)
+		require.False(t, getCluster(t, sqlStore, cluster.ID).IsCapacityManaged())
+		newCluster := getNewCluster(t, sqlStore, cluster.ID)
+		require.True(t, newCluster.IsCapacityManaged())
+
+		t.Run("max clusters", func(t *testing.T) {
+			newCluster.State = model.ClusterStateStable
+			err := sqlStore.UpdateCluster(newCluster)
+			require.NoError(t, err)
+
+			err = manager.Do()
+			require.NoError(t, err)
+			require.Len(t, getClusters(t, sqlStore), 2)
+		})
+	})
+
+	t.Run("unknown node pool", func(t *testing.T) {
+		sqlStore := setup(t)
+		createCluster(t, sqlStore, model.SizeAlef500, nil)
+		createWaitingInstallation(t, sqlStore, model.InstallationAffinityMultiTenant, "memory")
+
+		manager := supervisor.NewCapacityManager(sqlStore, testCapacityPolicy(), "instance", logger)
+		err := manager.Do()
+		require.NoError(t, err)
+		require.Len(t, getClusters(t, sqlStore), 1)
+	})
+
+	t.Run("reservations", func(t *testing.T) {
+		sqlStore := setup(t)
+		createCluster(t, sqlStore, model.SizeAlef500, nil)
+		installation1 := createWaitingInstallation(t, sqlStore, model.InstallationAffinityMultiTenant, "")
+
+		policy := testCapacityPolicy()
+		policy.Strategy = supervisor.CapacityStrategyCreate
+		policy.MaxClusters = 5
+		policy.ScaleDownGracePeriod = 10 * time.Millisecond
+		policy.ReservationTimeout = 50 * time.Millisecond
+		manager := supervisor.NewCapacityManager(sqlStore, policy, "instance", logger)
+
+		getReservation := func(t *testing.T, clusterID string) (string, bool) {
+			t.Helper()
+			kopsMetadata, err := model.NewKopsMetadata(getCluster(t, sqlStore, clusterID).ProvisionerMetadata)
+			require.NoError(t, err)
+
+			return kopsMetadata.CapacityReservedFor, kopsMetadata.CapacityReservationExpired
+		}
+
+		// findReservedCluster returns the cluster reserved for the installation.
+		findReservedCluster := func(t *testing.T, installationID string) *model.Cluster {
+			t.Helper()
+			for _, cluster := range getClusters(t, sqlStore) {
+				if reservedFor, _ := getReservation(t, cluster.ID); reservedFor == installationID {
+					return cluster
+				}
+			}
+			require.Fail(t, "no cluster reserved for installation %s", installationID)
+
+			return nil
+		}
+
+		err := manager.Do()
+		require.NoError(t, err)
+		require.Len(t, getClusters(t, sqlStore), 2)
+		cluster1 := findReservedCluster(t, installation1.ID)
+
+		var cluster2 *model.Cluster
+		var installation2 *model.Installation
+
+		t.Run("stable cluster not yet used", func(t *testing.T) {
+			cluster1.State = model.ClusterStateStable
+			err := sqlStore.UpdateCluster(cluster1)
+			require.NoError(t, err)
+
+			err = manager.Do()
+			require.NoError(t, err)
+			require.Len(t, getClusters(t, sqlStore), 2)
+			require.Equal(t, model.ClusterStateStable, getCluster(t, sqlStore, cluster1.ID).State)
+		})
+
+		t.Run("other installation waiting", func(t *testing.T) {
+			installation2 = createWaitingInstallation(t, sqlStore, model.InstallationAffinityMultiTenant, "")
+
+			err := manager.Do()
+			require.NoError(t, err)
+			require.Len(t, getClusters(t, sqlStore), 3)
+			cluster2 = findReservedCluster(t, installation2.ID)
+		})
+
+		t.Run("reservation expired", func(t *testing.T) {
+			time.Sleep(60 * time.Millisecond)
+
+			err := manager.Do()
+			require.NoError(t, err)
+
+			reservedFor, expired := getReservation(t, cluster1.ID)
+			require.Equal(t, installation1.ID, reservedFor)
+			require.True(t, expired)
+		})
+
+		t.Run("reservation released", func(t *testing.T) {
+			installation2.State = model.InstallationStateCreationInProgress
+			err := sqlStore.UpdateInstallationState(installation2)
+			require.NoError(t, err)
+			cluster2.State = model.ClusterStateStable
+			err = sqlStore.UpdateCluster(cluster2)
+			require.NoError(t, err)
+
+			err = manager.Do()
+			require.NoError(t, err)
+			require.Len(t, getClusters(t, sqlStore), 3)
+
+			reservedFor, _ := getReservation(t, cluster2.ID)
+			require.Empty(t, reservedFor)
+		})
+
+		t.Run("no capacity for unfit installation", func(t *testing.T) {
+			time.Sleep(20 * time.Millisecond)
+
+			err := manager.Do()
+			require.NoError(t, err)
+			require.Equal(t, model.ClusterStateDeletionRequested, getCluster(t, sqlStore, cluster1.ID).State)
+
+			err = sqlStore.DeleteCluster(cluster1.ID)
+			require.NoError(t, err)
+
+			err = manager.Do()
+			require.NoError(t, err)
+			for _, cluster := range getClusters(t, sqlStore) {
+				require.NotEqual(t, model.ClusterStateCreationRequested, cluster.State)
+			}
+		})
+	})
+
+	t.Run("scale down", func(t *testing.T) {
+		sqlStore := setup(t)
+		cluster1 := createManagedCluster(t, sqlStore)
+		cluster2 := createManagedCluster(t, sqlStore)
+		cluster3 := createManagedCluster(t, sqlStore)
+		cluster4 := createCluster(t, sqlStore, model.SizeAlef500, nil)
+
+		err := sqlStore.CreateClusterInstallation(&model.ClusterInstallation{
+			ClusterID:      cluster1.ID,
+			InstallationID: model.NewID(),
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		})
+		require.NoError(t, err)
+
+		policy := testCapacityPolicy()
+		policy.ScaleDownGracePeriod = 10 * time.Millisecond
+		manager := supervisor.NewCapacityManager(sqlStore, policy, "instance", logger)
+
+		t.Run("within grace period", func(t *testing.T) {
+			err := manager.Do()
+			require.NoError(t, err)
+
+			require.Equal(t, model.ClusterStateStable, getCluster(t, sqlStore, cluster2.ID).State)
+			require.Equal(t, model.ClusterStateStable, getCluster(t, sqlStore, cluster3.ID).State)
+		})
+
+		t.Run("after grace period", func(t *testing.T) {
+			time.Sleep(20 * time.Millisecond)
+
+			err := manager.Do()
+			require.NoError(t, err)
+
+			require.Equal(t, model.ClusterStateStable, getCluster(t, sqlStore, cluster1.ID).State)
+			require.Equal(t, model.ClusterStateDeletionRequested, getCluster(t, sqlStore, cluster2.ID).State)
+			require.Equal(t, model.ClusterStateDeletionRequested, getCluster(t, sqlStore, cluster3.ID).State)
+			require.Equal(t, model.ClusterStateStable, getCluster(t, sqlStore, cluster4.ID).State)
+		})
+	})
+
+	t.Run("scale down keeps min clusters", func(t *testing.T) {
+		sqlStore := setup(t)
+		cluster1 := createManagedCluster(t, sqlStore)
+		cluster2 := createManagedCluster(t, sqlStore)
+
+		policy := testCapacityPolicy()
+		policy.ScaleDownGracePeriod = 10 * time.Millisecond
+		manager := supervisor.NewCapacityManager(sqlStore, policy, "instance", logger)
+
+		err := manager.Do()
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+		err = manager.Do()
+		require.NoError(t, err)
+
+		// Either cluster may be deleted, but not both.
+		states := []string{
+			getCluster(t, sqlStore, cluster1.ID).State,
+			getCluster(t, sqlStore, cluster2.ID).State,
+		}
+		require.ElementsMatch(t, []string{model.ClusterStateStable, model.ClusterStateDeletionRequested}, states)
+	})
+
+	t.Run("scale down disabled", func(t *testing.T) {
+		sqlStore := setup(t)
+		createManagedCluster(t, sqlStore)
+		cluster := createManagedCluster(t, sqlStore)
+
+		manager := supervisor.NewCapacityManager(sqlStore, testCapacityPolicy(), "instance", logger)
+		err := manager.Do()
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+		err = manager.Do()
+		require.NoError(t, err)
+
+		require.Equal(t, model.ClusterStateStable, getCluster(t, sqlStore, cluster.ID).State)
+	})
+}
@@ -3,6 +3,7 @@ package supervisor
 import (
 	"time"
 
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
 	"github.com/mattermost/mattermost-cloud/internal/webhook"
 	"github.com/mattermost/mattermost-cloud/model"
@@ -15,11 +16,14 @@ type clusterStore interface {
 	GetUnlockedClustersPendingWork() ([]*model.Cluster, error)
 	GetClusters(clusterFilter *model.ClusterFilter) ([]*model.Cluster, error)
 	UpdateCluster(cluster *model.Cluster) error
+	UpdateClusterAttempts(cluster *model.Cluster) error
 	LockCluster(clusterID, lockerID string) (bool, error)
 	UnlockCluster(clusterID string, lockerID string, force bool) (bool, error)
 	DeleteCluster(clusterID string) error
 
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	CreateEvent(event *model.Event) error
 }
 
 // clusterProvisioner abstracts the provisioning operations required by the cluster supervisor.
@@ -28,20 +32,24 @@ type clusterProvisioner interface {
 	CreateCluster(cluster *model.Cluster, aws aws.AWS) error
 	ProvisionCluster(cluster *model.Cluster, aws aws.AWS) error
 	UpgradeCluster(cluster *model.Cluster) error
+	ResizeCluster(cluster *model.Cluster) error
 	DeleteCluster(cluster *model.Cluster, aws aws.AWS) error
 	GetClusterVersion(cluster *model.Cluster) (string, error)
 }
 
 // ClusterSupervisor finds clusters pending work and effects the required changes.
 //
-// The degree of parallelism is controlled by a weighted semaphore, intended to be shared with
-// other clients needing to coordinate background jobs.
+// The number of clusters supervised concurrently is controlled by SetWorkers.
 type ClusterSupervisor struct {
 	store       clusterStore
 	provisioner clusterProvisioner
 	aws         aws.AWS
 	instanceID  string
 	logger      log.FieldLogger
+
+	stopper
+	partitioned
+	workers
 }
 
 // NewClusterSupervisor creates a new ClusterSupervisor.
@@ -57,15 +65,48 @@ func NewClusterSupervisor(store clusterStore, clusterProvisioner clusterProvisio
 
 // Do looks for work to be done on any pending clusters and attempts to schedule the required work.
 func (s *ClusterSupervisor) Do() error {
+	defer metrics.ObserveSupervisorDo("cluster", time.Now())
+
 	clusters, err := s.store.GetUnlockedClustersPendingWork()
 	if err != nil {
 		s.logger.WithError(err).Warn("Failed to query for clusters pending work")
 		return nil
 	}
 
+	pool := s.newWorkerPool()
 	for _, cluster := range clusters {
-		s.Supervise(cluster)
+		if s.isStopped() {
+			s.logger.Info("Supervisor stopped, leaving remaining work for later")
+			break
+		}
+		if !s.owns(cluster.ID) {
+			continue
+		}
+		cluster := cluster
+		pool.Go(func() { s.Supervise(cluster) })
 	}
+	pool.Wait()
+
+	return nil
+}
+
+// DoResource supervises the given cluster immediately if it is pending work,
+// without waiting for any backoff from previously failed attempts.
+func (s *ClusterSupervisor) DoResource(resourceType, resourceID string) error {
+	if resourceType != model.LockResourceTypeCluster || s.isStopped() {
+		return nil
+	}
+
+	cluster, err := s.store.GetCluster(resourceID)
+	if err != nil {
+		s.logger.WithError(err).WithField("cluster", resourceID).Warn("Failed to get cluster")
+		return nil
+	}
+	if cluster == nil || !isPendingWork(cluster.State, model.AllClusterStatesPendingWork) {
+		return nil
+	}
+
+	s.Supervise(cluster)
 
 	return nil
 }
@@ -84,7 +125,8 @@ func (s *ClusterSupervisor) Supervise(cluster *model.Cluster) {
 
 	logger.Debugf("Supervising cluster in state %s", cluster.State)
 
-	newState := s.transitionCluster(cluster, logger)
+	transitionLogger := newTransitionLogger(logger)
+	newState := s.transitionCluster(cluster, transitionLogger)
 
 	cluster, err := s.store.GetCluster(cluster.ID)
 	if err != nil {
@@ -92,6 +134,20 @@ func (s *ClusterSupervisor) Supervise(cluster *model.Cluster) {
 		return
 	}
 
+	lastError := transitionLogger.transitionError(cluster.State, newState)
+	attempts, nextAttemptAt := nextTransitionAttempt(cluster.Attempts, cluster.State != newState, lastError)
+	if attempts != cluster.Attempts || nextAttemptAt != cluster.NextAttemptAt || lastError != cluster.LastError {
+		cluster.Attempts = attempts
+		cluster.LastError = lastError
+		cluster.NextAttemptAt = nextAttemptAt
+		err = s.store.UpdateClusterAttempts(cluster)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to record cluster transition attempt")
+		} else if attempts > 0 {
+			logger.Warnf("Cluster transition failed %d time(s) in a row; retrying in %s", attempts, transitionBackoff(attempts))
+		}
+	}
+
 	if cluster.State == newState {
 		return
 	}
@@ -111,6 +167,7 @@ func (s *ClusterSupervisor) Supervise(cluster *model.Cluster) {
 		OldState:  oldState,
 		Timestamp: time.Now().UnixNano(),
 	}
+	recordEvent(s.store, webhookPayload, s.instanceID, lastError, logger)
 	err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
 	if err != nil {
 		logger.WithError(err).Error("Unable to process and send webhooks")
@@ -128,6 +185,8 @@ func (s *ClusterSupervisor) transitionCluster(cluster *model.Cluster, logger log
 		return s.provisionCluster(cluster, logger)
 	case model.ClusterStateUpgradeRequested:
 		return s.upgradeCluster(cluster, logger)
+	case model.ClusterStateResizeRequested:
+		return s.resizeCluster(cluster, logger)
 	case model.ClusterStateDeletionRequested:
 		return s.deleteCluster(cluster, logger)
 	default:
@@ -234,6 +293,24 @@ func (s *ClusterSupervisor) upgradeCluster(cluster *model.Cluster, logger log.Fi
 	return model.ClusterStateStable
 }
 
+func (s *ClusterSupervisor) resizeCluster(cluster *model.Cluster, logger log.FieldLogger) string {
+	err := s.provisioner.ResizeCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to resize cluster")
+		return model.ClusterStateResizeFailed
+	}
+
+	// Record the new size and instance groups in the database. Log errors,
+	// but do not prevent the resize from finishing cleanly.
+	err = s.store.UpdateCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Warn("failed to record resized cluster")
+	}
+
+	logger.Info("Finished resizing cluster")
+	return model.ClusterStateStable
+}
+
 func (s *ClusterSupervisor) deleteCluster(cluster *model.Cluster, logger log.FieldLogger) string {
 	err := s.provisioner.DeleteCluster(cluster, s.aws)
 	if err != nil {
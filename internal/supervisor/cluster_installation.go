@@ -5,6 +5,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
 	"github.com/mattermost/mattermost-cloud/internal/webhook"
 	"github.com/mattermost/mattermost-cloud/model"
@@ -26,6 +27,8 @@ type clusterInstallationStore interface {
 	DeleteClusterInstallation(clusterInstallationID string) error
 
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	CreateEvent(event *model.Event) error
 }
 
 // provisioner abstracts the provisioning operations required by the cluster installation supervisor.
@@ -38,14 +41,17 @@ type clusterInstallationProvisioner interface {
 
 // ClusterInstallationSupervisor finds cluster installations pending work and effects the required changes.
 //
-// The degree of parallelism is controlled by a weighted semaphore, intended to be shared with
-// other clients needing to coordinate background jobs.
+// The number of cluster installations supervised concurrently is controlled by SetWorkers.
 type ClusterInstallationSupervisor struct {
 	store       clusterInstallationStore
 	provisioner clusterInstallationProvisioner
 	aws         aws.AWS
 	instanceID  string
 	logger      log.FieldLogger
+
+	stopper
+	partitioned
+	workers
 }
 
 // NewClusterInstallationSupervisor creates a new ClusterInstallationSupervisor.
@@ -61,16 +67,48 @@ func NewClusterInstallationSupervisor(store clusterInstallationStore, clusterIns
 
 // Do looks for work to be done on any pending cluster installations and attempts to schedule the required work.
 func (s *ClusterInstallationSupervisor) Do() error {
+	defer metrics.ObserveSupervisorDo("cluster_installation", time.Now())
+
 	clusterInstallations, err := s.store.GetUnlockedClusterInstallationsPendingWork()
 	if err != nil {
 		s.logger.WithError(err).Warn("Failed to query for cluster installations pending work")
 		return nil
 	}
 
+	pool := s.newWorkerPool()
 	for _, clusterInstallation := range clusterInstallations {
-		s.Supervise(clusterInstallation)
+		if s.isStopped() {
+			s.logger.Info("Supervisor stopped, leaving remaining work for later")
+			break
+		}
+		if !s.owns(clusterInstallation.ID) {
+			continue
+		}
+		clusterInstallation := clusterInstallation
+		pool.Go(func() { s.Supervise(clusterInstallation) })
+	}
+	pool.Wait()
+
+	return nil
+}
+
+// DoResource supervises the given cluster installation immediately if it is pending work.
+func (s *ClusterInstallationSupervisor) DoResource(resourceType, resourceID string) error {
+	if resourceType != model.LockResourceTypeClusterInstallation || s.isStopped() {
+		return nil
 	}
 
+	clusterInstallation, err := s.store.GetClusterInstallation(resourceID)
+	if err != nil {
+		s.logger.WithError(err).WithField("cluster-installation", resourceID).Warn("Failed to get cluster installation")
+		return nil
+	}
+	if clusterInstallation == nil || !isPendingWork(clusterInstallation.State, model.AllClusterInstallationStatesPendingWork) {
+		return nil
+	}
+
+	s.Supervise(clusterInstallation)
+
 	return nil
 }
 
@@ -88,7 +126,8 @@ func (s *ClusterInstallationSupervisor) Supervise(clusterInstallation *model.Clu
 
 	logger.Debugf("Supervising cluster installation in state %s", clusterInstallation.State)
 
-	newState := s.transitionClusterInstallation(clusterInstallation, logger)
+	transitionLogger := newTransitionLogger(logger)
+	newState := s.transitionClusterInstallation(clusterInstallation, transitionLogger)
 
 	clusterInstallation, err := s.store.GetClusterInstallation(clusterInstallation.ID)
 	if err != nil {
@@ -116,6 +155,7 @@ func (s *ClusterInstallationSupervisor) Supervise(clusterInstallation *model.Clu
 		Timestamp: time.Now().UnixNano(),
 		ExtraData: map[string]string{"ClusterID": clusterInstallation.ClusterID},
 	}
+	recordEvent(s.store, webhookPayload, s.instanceID, transitionLogger.transitionError(oldState, newState), logger)
 	err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
 	if err != nil {
 		logger.WithError(err).Error("Unable to process and send webhooks")
@@ -0,0 +1,56 @@
+package supervisor
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// ClusterSizeRefreshInterval is the interval at which a provisioning server
+// should refresh the custom cluster sizes it supports.
+const ClusterSizeRefreshInterval = time.Minute
+
+// clusterSizeStore abstracts the database operations required to load custom
+// cluster sizes.
+type clusterSizeStore interface {
+	GetClusterSizes() ([]*model.ClusterSize, error)
+}
+
+// ClusterSizeLoader keeps the custom cluster sizes supported by this
+// provisioning server in sync with those defined by configuration, allowing
+// sizes created through another server to be used here.
+//
+// Sizes recorded in the store take precedence over sizes of the same name
+// loaded from the configuration file.
+type ClusterSizeLoader struct {
+	store       clusterSizeStore
+	configSizes []*model.ClusterSize
+	logger      log.FieldLogger
+}
+
+// NewClusterSizeLoader creates a new ClusterSizeLoader. The given sizes are
+// those loaded from the configuration file, if any.
+func NewClusterSizeLoader(store clusterSizeStore, configSizes []*model.ClusterSize, logger log.FieldLogger) *ClusterSizeLoader {
+	return &ClusterSizeLoader{
+		store:       store,
+		configSizes: configSizes,
+		logger:      logger,
+	}
+}
+
+// Do refreshes the supported custom cluster sizes.
+func (l *ClusterSizeLoader) Do() error {
+	storeSizes, err := l.store.GetClusterSizes()
+	if err != nil {
+		l.logger.WithError(err).Warn("Failed to query for cluster sizes")
+		return nil
+	}
+
+	clusterSizes := make([]*model.ClusterSize, 0, len(l.configSizes)+len(storeSizes))
+	clusterSizes = append(clusterSizes, l.configSizes...)
+	clusterSizes = append(clusterSizes, storeSizes...)
+	model.SetCustomClusterSizes(clusterSizes)
+
+	return nil
+}
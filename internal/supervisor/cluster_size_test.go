@@ -0,0 +1,60 @@
+package supervisor_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterSizeLoader(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer model.SetCustomClusterSizes(nil)
+
+	configSizes := []*model.ClusterSize{
+		{Name: "SizeConfig", NodeCount: 2, NodeInstanceType: "m5.large", MasterCount: 1, MasterInstanceType: "t3.medium"},
+		{Name: "SizeShared", NodeCount: 2, NodeInstanceType: "m5.large", MasterCount: 1, MasterInstanceType: "t3.medium"},
+	}
+	loader := supervisor.NewClusterSizeLoader(sqlStore, configSizes, logger)
+
+	t.Run("config sizes only", func(t *testing.T) {
+		err := loader.Do()
+		require.NoError(t, err)
+		require.True(t, model.IsSupportedClusterSize("SizeConfig"))
+		require.True(t, model.IsSupportedClusterSize("SizeShared-HA3"))
+		require.False(t, model.IsSupportedClusterSize("SizeStore"))
+	})
+
+	t.Run("store sizes", func(t *testing.T) {
+		err := sqlStore.CreateClusterSize(&model.ClusterSize{
+			Name: "SizeStore", NodeCount: 6, NodeInstanceType: "m5.xlarge", MasterCount: 3, MasterInstanceType: "t3.large",
+		})
+		require.NoError(t, err)
+		err = sqlStore.CreateClusterSize(&model.ClusterSize{
+			Name: "SizeShared", NodeCount: 8, NodeInstanceType: "m5.xlarge", MasterCount: 1, MasterInstanceType: "t3.large",
+		})
+		require.NoError(t, err)
+
+		err = loader.Do()
+		require.NoError(t, err)
+		require.True(t, model.IsSupportedClusterSize("SizeConfig"))
+		require.True(t, model.IsSupportedClusterSize("SizeStore"))
+
+		clusterSize := model.GetCustomClusterSize("SizeShared")
+		require.NotNil(t, clusterSize)
+		require.Equal(t, int64(8), clusterSize.NodeCount)
+	})
+
+	t.Run("deleted store sizes", func(t *testing.T) {
+		err := sqlStore.DeleteClusterSize("SizeStore")
+		require.NoError(t, err)
+
+		err = loader.Do()
+		require.NoError(t, err)
+		require.False(t, model.IsSupportedClusterSize("SizeStore"))
+	})
+}
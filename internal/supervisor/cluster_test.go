@@ -8,6 +8,7 @@ import (
 	"github.com/mattermost/mattermost-cloud/internal/testlib"
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
 	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -37,6 +38,10 @@ func (s *mockClusterStore) UpdateCluster(cluster *model.Cluster) error {
 	return nil
 }
 
+func (s *mockClusterStore) UpdateClusterAttempts(cluster *model.Cluster) error {
+	return nil
+}
+
 func (s *mockClusterStore) LockCluster(clusterID, lockerID string) (bool, error) {
 	return true, nil
 }
@@ -56,6 +61,14 @@ func (s *mockClusterStore) GetWebhooks(filter *model.WebhookFilter) ([]*model.We
 	return nil, nil
 }
 
+func (s *mockClusterStore) CreateEvent(event *model.Event) error {
+	return nil
+}
+
+func (s *mockClusterStore) CreateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	return nil
+}
+
 type mockClusterProvisioner struct{}
 
 func (p *mockClusterProvisioner) PrepareCluster(cluster *model.Cluster) (bool, error) {
@@ -74,6 +87,10 @@ func (p *mockClusterProvisioner) UpgradeCluster(cluster *model.Cluster) error {
 	return nil
 }
 
+func (p *mockClusterProvisioner) ResizeCluster(cluster *model.Cluster) error {
+	return nil
+}
+
 func (p *mockClusterProvisioner) DeleteCluster(cluster *model.Cluster, aws aws.AWS) error {
 	return nil
 }
@@ -82,6 +99,15 @@ func (p *mockClusterProvisioner) GetClusterVersion(cluster *model.Cluster) (stri
 	return "0.0.0", nil
 }
 
+// failingClusterProvisioner fails to create any clusters.
+type failingClusterProvisioner struct {
+	mockClusterProvisioner
+}
+
+func (p *failingClusterProvisioner) CreateCluster(cluster *model.Cluster, aws aws.AWS) error {
+	return errors.New("kops unavailable")
+}
+
 func TestClusterSupervisorDo(t *testing.T) {
 	t.Run("no clusters pending work", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
@@ -124,6 +150,7 @@ func TestClusterSupervisorSupervise(t *testing.T) {
 		{"creation requested", model.ClusterStateCreationRequested, model.ClusterStateStable},
 		{"provision requested", model.ClusterStateProvisioningRequested, model.ClusterStateStable},
 		{"upgrade requested", model.ClusterStateUpgradeRequested, model.ClusterStateStable},
+		{"resize requested", model.ClusterStateResizeRequested, model.ClusterStateStable},
 		{"deletion requested", model.ClusterStateDeletionRequested, model.ClusterStateDeleted},
 	}
 
@@ -149,3 +176,28 @@ func TestClusterSupervisorSupervise(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterSupervisorSuperviseFailure(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	supervisor := supervisor.NewClusterSupervisor(sqlStore, &failingClusterProvisioner{}, &mockAWS{}, "instanceID", logger)
+
+	cluster := &model.Cluster{
+		Provider: model.ProviderAWS,
+		Size:     model.SizeAlef500,
+		State:    model.ClusterStateCreationRequested,
+	}
+	err := sqlStore.CreateCluster(cluster)
+	require.NoError(t, err)
+
+	supervisor.Supervise(cluster)
+
+	cluster, err = sqlStore.GetCluster(cluster.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.ClusterStateCreationFailed, cluster.State)
+
+	// The error is kept for the failed state, which isn't retried.
+	require.Contains(t, cluster.LastError, "kops unavailable")
+	require.Equal(t, 0, cluster.Attempts)
+	require.Zero(t, cluster.NextAttemptAt)
+}
@@ -5,6 +5,28 @@ type Doer interface {
 	Do() error
 }
 
+// Stopper describes a doer that can be asked to stop picking up new work.
+type Stopper interface {
+	Stop()
+}
+
+// ResourceDoer describes a doer that can act on a single resource, where
+// resourceType is one of the model.LockResourceType constants.
+type ResourceDoer interface {
+	DoResource(resourceType, resourceID string) error
+}
+
+// isPendingWork returns whether the given state is one of the states pending work.
+func isPendingWork(state string, pendingStates []string) bool {
+	for _, pendingState := range pendingStates {
+		if state == pendingState {
+			return true
+		}
+	}
+
+	return false
+}
+
 // MultiDoer is a slice of doers.
 type MultiDoer []Doer
 
@@ -19,3 +41,37 @@ func (md MultiDoer) Do() error {
 
 	return nil
 }
+
+// DoResource executes each doer supporting it for the given resource, returning the first error.
+func (md MultiDoer) DoResource(resourceType, resourceID string) error {
+	for _, doer := range md {
+		resourceDoer, ok := doer.(ResourceDoer)
+		if !ok {
+			continue
+		}
+		err := resourceDoer.DoResource(resourceType, resourceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stop asks each doer supporting it to stop picking up new work.
+func (md MultiDoer) Stop() {
+	for _, doer := range md {
+		if stopper, ok := doer.(Stopper); ok {
+			stopper.Stop()
+		}
+	}
+}
+
+// SetPartitioner partitions the work of each doer supporting it between instances.
+func (md MultiDoer) SetPartitioner(partitioner Partitioner) {
+	for _, doer := range md {
+		if partitionable, ok := doer.(Partitionable); ok {
+			partitionable.SetPartitioner(partitioner)
+		}
+	}
+}
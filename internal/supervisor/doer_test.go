@@ -18,9 +18,50 @@ func (td *testDoer) Do() error {
 	return nil
 }
 
+type resourceDoer struct {
+	calls     chan bool
+	resources chan string
+}
+
+func (rd *resourceDoer) Do() error {
+	rd.calls <- true
+
+	return nil
+}
+
+func (rd *resourceDoer) DoResource(resourceType, resourceID string) error {
+	rd.resources <- resourceType + "/" + resourceID
+
+	return nil
+}
+
 type failDoer struct {
 }
 
+type stopDoer struct {
+	stopped bool
+}
+
+func (sd *stopDoer) Do() error {
+	return nil
+}
+
+func (sd *stopDoer) Stop() {
+	sd.stopped = true
+}
+
+type partitionableDoer struct {
+	partitioner supervisor.Partitioner
+}
+
+func (pd *partitionableDoer) Do() error {
+	return nil
+}
+
+func (pd *partitionableDoer) SetPartitioner(partitioner supervisor.Partitioner) {
+	pd.partitioner = partitioner
+}
+
 func (fd *failDoer) Do() error {
 	return fmt.Errorf("failed")
 }
@@ -77,4 +118,43 @@ func TestMultiDoer(t *testing.T) {
 			require.Fail(t, "doer3 not invoked")
 		}
 	})
+
+	t.Run("stop", func(t *testing.T) {
+		d1 := &stopDoer{}
+		d2 := &testDoer{calls: make(chan bool, 1)}
+		d3 := &stopDoer{}
+
+		doer := supervisor.MultiDoer{d1, d2, d3}
+		doer.Stop()
+
+		require.True(t, d1.stopped)
+		require.True(t, d3.stopped)
+	})
+
+	t.Run("do resource", func(t *testing.T) {
+		d1 := &resourceDoer{resources: make(chan string, 1)}
+		d2 := &testDoer{calls: make(chan bool, 1)}
+		d3 := &resourceDoer{resources: make(chan string, 1)}
+
+		doer := supervisor.MultiDoer{d1, d2, d3}
+		err := doer.DoResource("cluster", "id")
+		require.NoError(t, err)
+
+		require.Equal(t, "cluster/id", <-d1.resources)
+		require.Equal(t, "cluster/id", <-d3.resources)
+		require.Len(t, d2.calls, 0)
+	})
+
+	t.Run("set partitioner", func(t *testing.T) {
+		d1 := &partitionableDoer{}
+		d2 := &testDoer{calls: make(chan bool, 1)}
+		d3 := &partitionableDoer{}
+
+		partitioner := &testPartitioner{}
+		doer := supervisor.MultiDoer{d1, d2, d3}
+		doer.SetPartitioner(partitioner)
+
+		require.Equal(t, partitioner, d1.partitioner)
+		require.Equal(t, partitioner, d3.partitioner)
+	})
 }
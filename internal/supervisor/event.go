@@ -0,0 +1,103 @@
+package supervisor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// eventStore abstracts the database operations required to record state
+// change events.
+type eventStore interface {
+	CreateEvent(event *model.Event) error
+}
+
+// transitionLogger is the logger used while transitioning a resource. Every
+// entry is passed on to the supervisor logger, while the last entry logged at
+// error level or above is remembered, so that it can be recorded with the
+// resource and the resulting state change event. Entries are captured with a
+// hook, so that errors are remembered however they were logged.
+type transitionLogger struct {
+	log.FieldLogger
+	lastError string
+}
+
+func newTransitionLogger(logger log.FieldLogger) *transitionLogger {
+	l := &transitionLogger{}
+
+	captureLogger := log.New()
+	captureLogger.Out = ioutil.Discard
+	captureLogger.Level = log.TraceLevel
+	if entry, ok := logger.(*log.Entry); ok {
+		captureLogger.Level = entry.Logger.GetLevel()
+	}
+	captureLogger.AddHook(&transitionLoggerHook{transitionLogger: l, logger: logger})
+
+	l.FieldLogger = log.NewEntry(captureLogger)
+
+	return l
+}
+
+// transitionError returns the error to record for a transition of a resource
+// from the given state to the new state. A transition to any state other than
+// a failure state succeeded, so errors logged along the way were recovered
+// from and are not recorded.
+func (l *transitionLogger) transitionError(oldState, newState string) string {
+	if oldState != newState && !isFailedState(newState) {
+		return ""
+	}
+
+	return l.lastError
+}
+
+// isFailedState returns whether the given state is one of the failure states
+// of a resource.
+func isFailedState(state string) bool {
+	return strings.HasSuffix(state, "failed")
+}
+
+// transitionLoggerHook passes the entries of a transitionLogger on to the
+// supervisor logger, remembering the last error.
+type transitionLoggerHook struct {
+	transitionLogger *transitionLogger
+	logger           log.FieldLogger
+}
+
+func (h *transitionLoggerHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *transitionLoggerHook) Fire(entry *log.Entry) error {
+	if entry.Level <= log.ErrorLevel {
+		h.transitionLogger.lastError = entry.Message
+		if err, ok := entry.Data[log.ErrorKey].(error); ok {
+			h.transitionLogger.lastError = fmt.Sprintf("%s: %s", entry.Message, err)
+		}
+	}
+
+	logger := h.logger.WithFields(entry.Data)
+	switch entry.Level {
+	case log.PanicLevel, log.FatalLevel, log.ErrorLevel:
+		logger.Error(entry.Message)
+	case log.WarnLevel:
+		logger.Warn(entry.Message)
+	case log.InfoLevel:
+		logger.Info(entry.Message)
+	default:
+		logger.Debug(entry.Message)
+	}
+
+	return nil
+}
+
+// recordEvent persists the state transition described by the webhook payload
+// so that it remains available regardless of webhook delivery.
+func recordEvent(store eventStore, payload *model.WebhookPayload, instanceID, errorMessage string, logger log.FieldLogger) {
+	err := store.CreateEvent(model.NewEventFromWebhookPayload(payload, instanceID, errorMessage))
+	if err != nil {
+		logger.WithError(err).Error("Failed to record state change event")
+	}
+}
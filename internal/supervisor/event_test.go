@@ -0,0 +1,49 @@
+package supervisor
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransitionLogger(t *testing.T) {
+	t.Run("no error", func(t *testing.T) {
+		logger := newTransitionLogger(testlib.MakeLogger(t))
+		logger.WithField("key", "value").Info("transitioning")
+		logger.WithError(errors.New("retrying")).Warn("failed to lock")
+
+		require.Empty(t, logger.transitionError("state", "state"))
+	})
+
+	t.Run("error with fields", func(t *testing.T) {
+		logger := newTransitionLogger(testlib.MakeLogger(t))
+		logger.WithField("key", "value").Errorf("failed to %s", "provision")
+
+		require.Equal(t, "failed to provision", logger.transitionError("state", "state"))
+	})
+
+	t.Run("error with error", func(t *testing.T) {
+		logger := newTransitionLogger(testlib.MakeLogger(t))
+		logger.WithError(errors.New("unavailable")).Error("failed to provision")
+
+		require.Equal(t, "failed to provision: unavailable", logger.transitionError("state", "state"))
+	})
+
+	t.Run("last error", func(t *testing.T) {
+		logger := newTransitionLogger(testlib.MakeLogger(t))
+		logger.Error("first")
+		logger.WithField("key", "value").Error("second")
+
+		require.Equal(t, "second", logger.transitionError("state", "state"))
+	})
+
+	t.Run("cleared on success", func(t *testing.T) {
+		logger := newTransitionLogger(testlib.MakeLogger(t))
+		logger.Error("recovered")
+
+		require.Empty(t, logger.transitionError("state", "next-state"))
+		require.Equal(t, "recovered", logger.transitionError("state", "creation-failed"))
+	})
+}
@@ -1,14 +1,18 @@
 package supervisor
 
 import (
+	"time"
+
 	log "github.com/sirupsen/logrus"
 
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
 	"github.com/mattermost/mattermost-cloud/internal/store"
 	"github.com/mattermost/mattermost-cloud/model"
 )
 
 // groupStore abstracts the database operations required to query groups.
 type groupStore interface {
+	GetGroup(groupID string) (*model.Group, error)
 	GetUnlockedGroupsPendingWork() ([]*model.Group, error)
 	GetGroupRollingMetadata(groupID string) (*store.GroupRollingMetadata, error)
 	LockGroup(groupID, lockerID string) (bool, error)
@@ -23,12 +27,15 @@ type groupStore interface {
 // GroupSupervisor finds installations belonging to groups that need to have
 // their configuration reconciled to match a new group configuration setting.
 //
-// The degree of parallelism is controlled by a weighted semaphore, intended to
-// be shared with other clients needing to coordinate background jobs.
+// The number of groups supervised concurrently is controlled by SetWorkers.
 type GroupSupervisor struct {
 	store      groupStore
 	instanceID string
 	logger     log.FieldLogger
+
+	stopper
+	partitioned
+	workers
 }
 
 // NewGroupSupervisor creates a new GroupSupervisor.
@@ -43,15 +50,47 @@ func NewGroupSupervisor(store groupStore, instanceID string, logger log.FieldLog
 // Do looks for work to be done on any pending groups and attempts to schedule
 // the required work.
 func (s *GroupSupervisor) Do() error {
+	defer metrics.ObserveSupervisorDo("group", time.Now())
+
 	groups, err := s.store.GetUnlockedGroupsPendingWork()
 	if err != nil {
 		s.logger.WithError(err).Warn("Failed to query for groups")
 		return nil
 	}
 
+	pool := s.newWorkerPool()
 	for _, group := range groups {
-		s.Supervise(group)
+		if s.isStopped() {
+			s.logger.Info("Supervisor stopped, leaving remaining work for later")
+			break
+		}
+		if !s.owns(group.ID) {
+			continue
+		}
+		group := group
+		pool.Go(func() { s.Supervise(group) })
+	}
+	pool.Wait()
+
+	return nil
+}
+
+// DoResource supervises the given group immediately if it is pending work.
+func (s *GroupSupervisor) DoResource(resourceType, resourceID string) error {
+	if resourceType != model.LockResourceTypeGroup || s.isStopped() {
+		return nil
+	}
+
+	group, err := s.store.GetGroup(resourceID)
+	if err != nil {
+		s.logger.WithError(err).WithField("group", resourceID).Warn("Failed to get group")
+		return nil
 	}
+	if group == nil || group.DeleteAt != 0 {
+		return nil
+	}
+
+	s.Supervise(group)
 
 	return nil
 }
@@ -23,6 +23,10 @@ type mockGroupStore struct {
 	UpdateInstallationCalls int
 }
 
+func (s *mockGroupStore) GetGroup(groupID string) (*model.Group, error) {
+	return s.Group, nil
+}
+
 func (s *mockGroupStore) GetUnlockedGroupsPendingWork() ([]*model.Group, error) {
 	return s.UnlockedGroupsPendingWork, nil
 }
@@ -3,12 +3,15 @@ package supervisor
 import (
 	"time"
 
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
+	"github.com/mattermost/mattermost-cloud/internal/scheduling"
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
 	"github.com/mattermost/mattermost-cloud/internal/tools/k8s"
 	"github.com/mattermost/mattermost-cloud/internal/tools/utils"
 	"github.com/mattermost/mattermost-cloud/internal/webhook"
 	"github.com/mattermost/mattermost-cloud/model"
 	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -23,11 +26,17 @@ type installationStore interface {
 	GetUnlockedInstallationsPendingWork() ([]*model.Installation, error)
 	UpdateInstallation(installation *model.Installation) error
 	UpdateInstallationGroupSequence(installation *model.Installation) error
+	UpdateInstallationDNSRecords(installation *model.Installation) error
 	UpdateInstallationState(*model.Installation) error
+	UpdateInstallationSize(installation *model.Installation) error
+	UpdateInstallationAttempts(installation *model.Installation) error
+	UpdateInstallationRestoreProgress(installation *model.Installation) error
 	LockInstallation(installationID, lockerID string) (bool, error)
 	UnlockInstallation(installationID, lockerID string, force bool) (bool, error)
 	DeleteInstallation(installationID string) error
 
+	GetInstallationBackup(backupID string) (*model.InstallationBackup, error)
+
 	CreateClusterInstallation(clusterInstallation *model.ClusterInstallation) error
 	GetClusterInstallation(clusterInstallationID string) (*model.ClusterInstallation, error)
 	GetClusterInstallations(*model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error)
@@ -36,6 +45,8 @@ type installationStore interface {
 	UpdateClusterInstallation(clusterInstallation *model.ClusterInstallation) error
 
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	CreateEvent(event *model.Event) error
 }
 
 // provisioner abstracts the provisioning operations required by the installation supervisor.
@@ -43,14 +54,14 @@ type installationProvisioner interface {
 	CreateClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, awsClient aws.AWS) error
 	DeleteClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error
 	UpdateClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error
+	HibernateClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) (bool, error)
 	GetClusterInstallationResource(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) (*mmv1alpha1.ClusterInstallation, error)
-	GetClusterResources(cluster *model.Cluster, onlySchedulable bool) (*k8s.ClusterResources, error)
+	GetClusterResources(cluster *model.Cluster, nodePool string, onlySchedulable bool) (*k8s.ClusterResources, error)
 }
 
 // InstallationSupervisor finds installations pending work and effects the required changes.
 //
-// The degree of parallelism is controlled by a weighted semaphore, intended to be shared with
-// other clients needing to coordinate background jobs.
+// The number of installations supervised concurrently is controlled by SetWorkers.
 type InstallationSupervisor struct {
 	store                    installationStore
 	provisioner              installationProvisioner
@@ -60,7 +71,12 @@ type InstallationSupervisor struct {
 	keepDatabaseData         bool
 	keepFilestoreData        bool
 	resourceUtil             *utils.ResourceUtil
+	scheduler                *scheduling.Scheduler
 	logger                   log.FieldLogger
+
+	stopper
+	partitioned
+	workers
 }
 
 // NewInstallationSupervisor creates a new InstallationSupervisor.
@@ -74,22 +90,62 @@ func NewInstallationSupervisor(store installationStore, installationProvisioner
 		keepDatabaseData:         keepDatabaseData,
 		keepFilestoreData:        keepFilestoreData,
 		resourceUtil:             resourceUtil,
+		scheduler:                scheduling.NewScheduler(store, installationProvisioner, threshold, model.SchedulingStrategyFirstFit, logger),
 		logger:                   logger,
 	}
 }
 
+// SetSchedulingStrategy changes the strategy choosing the cluster new
+// installations are scheduled onto, unless they specify their own.
+func (s *InstallationSupervisor) SetSchedulingStrategy(strategy string) {
+	s.scheduler = scheduling.NewScheduler(s.store, s.provisioner, s.clusterResourceThreshold, strategy, s.logger)
+}
+
 // Do looks for work to be done on any pending installations and attempts to schedule the required work.
 func (s *InstallationSupervisor) Do() error {
+	defer metrics.ObserveSupervisorDo("installation", time.Now())
+
 	installations, err := s.store.GetUnlockedInstallationsPendingWork()
 	if err != nil {
 		s.logger.WithError(err).Warn("Failed to query for installation pending work")
 		return nil
 	}
 
+	pool := s.newWorkerPool()
 	for _, installation := range installations {
-		s.Supervise(installation)
+		if s.isStopped() {
+			s.logger.Info("Supervisor stopped, leaving remaining work for later")
+			break
+		}
+		if !s.owns(installation.ID) {
+			continue
+		}
+		installation := installation
+		pool.Go(func() { s.Supervise(installation) })
+	}
+	pool.Wait()
+
+	return nil
+}
+
+// DoResource supervises the given installation immediately if it is pending
+// work, without waiting for any backoff from previously failed attempts.
+func (s *InstallationSupervisor) DoResource(resourceType, resourceID string) error {
+	if resourceType != model.LockResourceTypeInstallation || s.isStopped() {
+		return nil
+	}
+
+	installation, err := s.store.GetInstallation(resourceID, true, false)
+	if err != nil {
+		s.logger.WithError(err).WithField("installation", resourceID).Warn("Failed to get installation")
+		return nil
+	}
+	if installation == nil || !isPendingWork(installation.State, model.AllInstallationStatesPendingWork) {
+		return nil
 	}
 
+	s.Supervise(installation)
+
 	return nil
 }
 
@@ -107,7 +163,8 @@ func (s *InstallationSupervisor) Supervise(installation *model.Installation) {
 
 	logger.Debugf("Supervising installation in state %s", installation.State)
 
-	newState := s.transitionInstallation(installation, s.instanceID, logger)
+	transitionLogger := newTransitionLogger(logger)
+	newState := s.transitionInstallation(installation, s.instanceID, transitionLogger)
 
 	installation, err := s.store.GetInstallation(installation.ID, true, false)
 	if err != nil {
@@ -115,6 +172,20 @@ func (s *InstallationSupervisor) Supervise(installation *model.Installation) {
 		return
 	}
 
+	lastError := transitionLogger.transitionError(installation.State, newState)
+	attempts, nextAttemptAt := nextTransitionAttempt(installation.Attempts, installation.State != newState, lastError)
+	if attempts != installation.Attempts || nextAttemptAt != installation.NextAttemptAt || lastError != installation.LastError {
+		installation.Attempts = attempts
+		installation.LastError = lastError
+		installation.NextAttemptAt = nextAttemptAt
+		err = s.store.UpdateInstallationAttempts(installation)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to record installation transition attempt")
+		} else if attempts > 0 {
+			logger.Warnf("Installation transition failed %d time(s) in a row; retrying in %s", attempts, transitionBackoff(attempts))
+		}
+	}
+
 	if installation.State == newState {
 		return
 	}
@@ -143,7 +214,9 @@ func (s *InstallationSupervisor) Supervise(installation *model.Installation) {
 		NewState:  newState,
 		OldState:  oldState,
 		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"OwnerID": installation.OwnerID},
 	}
+	recordEvent(s.store, webhookPayload, s.instanceID, lastError, logger)
 	err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
 	if err != nil {
 		logger.WithError(err).Error("Unable to process and send webhooks")
@@ -168,12 +241,31 @@ func (s *InstallationSupervisor) transitionInstallation(installation *model.Inst
 	case model.InstallationStateCreationDNS:
 		return s.configureInstallationDNS(installation, logger)
 
-	case model.InstallationStateUpdateRequested:
+	case model.InstallationStateUpdateRequested,
+		model.InstallationStateWakeUpRequested:
+		// Waking up restores the cluster installation resource saved when
+		// hibernating, so it follows the regular update flow.
 		return s.updateInstallation(installation, instanceID, logger)
 
 	case model.InstallationStateUpdateInProgress:
 		return s.waitForUpdateComplete(installation, instanceID, logger)
 
+	case model.InstallationStateHibernationRequested,
+		model.InstallationStateHibernationInProgress:
+		return s.hibernateInstallation(installation, instanceID, logger)
+
+	case model.InstallationStateMigrationRequested:
+		return s.migrateInstallation(installation, instanceID, logger)
+
+	case model.InstallationStateMigrationInProgress:
+		return s.waitForMigrationClusterInstallationStable(installation, instanceID, logger)
+
+	case model.InstallationStateMigrationDNS:
+		return s.switchMigrationDNS(installation, instanceID, logger)
+
+	case model.InstallationStateMigrationCleanup:
+		return s.cleanupMigration(installation, instanceID, logger)
+
 	case model.InstallationStateDeletionRequested,
 		model.InstallationStateDeletionInProgress:
 		return s.deleteInstallation(installation, instanceID, logger)
@@ -212,14 +304,44 @@ func (s *InstallationSupervisor) createInstallation(installation *model.Installa
 		return model.InstallationStateCreationRequested
 	}
 
-	for _, cluster := range clusters {
+	// With the first-fit strategy, the clusters are tried in order, each only
+	// evaluated once while locked, until the installation fits on one. Other
+	// strategies evaluate every cluster to order them by preference, and the
+	// preferred clusters are evaluated again while locked.
+	preferredClusters := clusters
+	if !s.scheduler.IsFirstFit(installation) {
+		decision, err := s.scheduler.Schedule(installation, clusters)
+		if err != nil {
+			logger.WithError(err).Error("Failed to schedule installation")
+			return model.InstallationStateCreationRequested
+		}
+
+		clustersByID := make(map[string]*model.Cluster)
+		for _, cluster := range clusters {
+			clustersByID[cluster.ID] = cluster
+		}
+
+		preferredClusters = nil
+		for _, candidate := range decision.Candidates {
+			if !candidate.Fits {
+				logger.Debugf("Cluster %s was rejected: %s", candidate.ClusterID, candidate.Reason)
+				continue
+			}
+			preferredClusters = append(preferredClusters, clustersByID[candidate.ClusterID])
+		}
+	}
+
+	// Try the clusters in order of preference, in case one changed since it
+	// was considered.
+	for _, cluster := range preferredClusters {
 		clusterInstallation := s.createClusterInstallation(cluster, installation, instanceID, logger)
 		if clusterInstallation != nil {
 			return s.preProvisionInstallation(installation, instanceID, logger)
 		}
 	}
 
-	// TODO: Support creating a cluster on demand if no existing cluster meets the criteria.
+	// The capacity manager, when enabled, adds capacity for installations
+	// left in this state.
 	logger.Warn("No compatible clusters available for installation scheduling")
 
 	return model.InstallationStateCreationNoCompatibleClusters
@@ -234,76 +356,9 @@ func (s *InstallationSupervisor) createClusterInstallation(cluster *model.Cluste
 	}
 	defer clusterLock.Unlock()
 
-	if cluster.State != model.ClusterStateStable {
-		logger.Debugf("Cluster %s is not stable (currently %s)", cluster.ID, cluster.State)
-		return nil
-	}
-	if !cluster.AllowInstallations {
-		logger.Debugf("Cluster %s is set to not allow for new installation scheduling", cluster.ID)
-		return nil
-	}
-
-	existingClusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
-		PerPage:   model.AllPerPage,
-		ClusterID: cluster.ID,
-	})
-
-	////////////////////////////////////////////////////////////////////////////
-	//                              MULTI-TENANCY                             //
-	////////////////////////////////////////////////////////////////////////////
-	// Current model:                                                         //
-	// - isolation=true  | 1 cluster installations                            //
-	// - isolation=false | X cluster installations, where "X" is as many as   //
-	//                     will fit with the given CPU and Memory threshold.  //
-	////////////////////////////////////////////////////////////////////////////
-	if installation.Affinity == model.InstallationAffinityIsolated {
-		if len(existingClusterInstallations) > 0 {
-			logger.Debugf("Cluster %s already has %d installations", cluster.ID, len(existingClusterInstallations))
-			return nil
-		}
-	} else {
-		if len(existingClusterInstallations) == 1 {
-			// This should be the only scenario where we need to check if the
-			// cluster installation running requires isolation or not.
-			installation, err := s.store.GetInstallation(existingClusterInstallations[0].InstallationID, true, false)
-			if err != nil {
-				logger.WithError(err).Warn("Unable to find installation")
-				return nil
-			}
-			if installation.Affinity == model.InstallationAffinityIsolated {
-				logger.Debugf("Cluster %s already has an isolated installation %s", cluster.ID, installation.ID)
-				return nil
-			}
-		}
-	}
-
-	// Begin final resource check.
-
-	size, err := mmv1alpha1.GetClusterSize(installation.Size)
-	if err != nil {
-		logger.WithError(err).Error("invalid cluster installation size")
-		return nil
-	}
-	clusterResources, err := s.provisioner.GetClusterResources(cluster, true)
-	if err != nil {
-		logger.WithError(err).Error("failed to get cluster resources")
-		return nil
-	}
-
-	cpuPercent := clusterResources.CalculateCPUPercentUsed(
-		size.CalculateCPUMilliRequirement(
-			installation.InternalDatabase(),
-			installation.InternalFilestore(),
-		),
-	)
-	memoryPercent := clusterResources.CalculateMemoryPercentUsed(
-		size.CalculateMemoryMilliRequirement(
-			installation.InternalDatabase(),
-			installation.InternalFilestore(),
-		),
-	)
-	if cpuPercent > s.clusterResourceThreshold || memoryPercent > s.clusterResourceThreshold {
-		logger.Debugf("Cluster %s would exceed the cluster load threshold (%d%%): CPU=%d%%, Memory=%d%%", cluster.ID, s.clusterResourceThreshold, cpuPercent, memoryPercent)
+	candidate := s.scheduler.Evaluate(cluster, installation)
+	if !candidate.Fits {
+		logger.Debugf("Cluster %s was rejected: %s", cluster.ID, candidate.Reason)
 		return nil
 	}
 
@@ -316,7 +371,7 @@ func (s *InstallationSupervisor) createClusterInstallation(cluster *model.Cluste
 		State:          model.ClusterInstallationStateCreationRequested,
 	}
 
-	err = s.store.CreateClusterInstallation(clusterInstallation)
+	err := s.store.CreateClusterInstallation(clusterInstallation)
 	if err != nil {
 		logger.WithError(err).Warn("Failed to create cluster installation")
 		return nil
@@ -334,24 +389,131 @@ func (s *InstallationSupervisor) createClusterInstallation(cluster *model.Cluste
 		logger.WithError(err).Error("Unable to process and send webhooks")
 	}
 
-	logger.Infof("Requested creation of cluster installation on cluster %s. Expected resource load: CPU=%d%%, Memory=%d%%", cluster.ID, cpuPercent, memoryPercent)
+	logger.Infof("Requested creation of cluster installation on cluster %s. Expected resource load: CPU=%d%%, Memory=%d%%", cluster.ID, candidate.CPUPercent, candidate.MemoryPercent)
 
 	return clusterInstallation
 }
 
+// checkClusterResourceThreshold calculates the CPU and memory load percentages
+// the cluster would have with the additional resource requirements, and
+// returns whether both stay within the cluster resource threshold. Only the
+// resources of the given node pool are considered, unless it is empty.
+func (s *InstallationSupervisor) checkClusterResourceThreshold(cluster *model.Cluster, nodePool string, additionalCPU, additionalMemory int64) (int, int, bool, error) {
+	clusterResources, err := s.provisioner.GetClusterResources(cluster, nodePool, true)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if clusterResources.MilliTotalCPU == 0 || clusterResources.MilliTotalMemory == 0 {
+		return 0, 0, false, errors.Errorf("cluster %s has no schedulable nodes", cluster.ID)
+	}
+
+	cpuPercent := clusterResources.CalculateCPUPercentUsed(additionalCPU)
+	memoryPercent := clusterResources.CalculateMemoryPercentUsed(additionalMemory)
+	fits := cpuPercent <= s.clusterResourceThreshold && memoryPercent <= s.clusterResourceThreshold
+
+	return cpuPercent, memoryPercent, fits, nil
+}
+
+// checkClusterInstallationResize checks if the cluster can support the
+// cluster installation being resized to the current installation size. The
+// size the cluster installation currently runs with is returned along with
+// whether the resize fits.
+func (s *InstallationSupervisor) checkClusterInstallationResize(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, logger log.FieldLogger) (string, bool, error) {
+	// A hibernating cluster installation has no resource until waking up
+	// restores the one saved when it went into hibernation.
+	if installation.State == model.InstallationStateWakeUpRequested {
+		return installation.Size, true, nil
+	}
+
+	cr, err := s.provisioner.GetClusterInstallationResource(cluster, installation, clusterInstallation)
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to get cluster installation resource")
+	}
+	if cr == nil {
+		logger.Warnf("Cluster installation %s has no resource to resize", clusterInstallation.ID)
+		return installation.Size, true, nil
+	}
+	if cr.Spec.Size == installation.Size {
+		return cr.Spec.Size, true, nil
+	}
+
+	size, err := mmv1alpha1.GetClusterSize(installation.Size)
+	if err != nil {
+		return cr.Spec.Size, false, errors.Wrap(err, "invalid cluster installation size")
+	}
+	additionalCPU := size.CalculateCPUMilliRequirement(installation.InternalDatabase(), installation.InternalFilestore())
+	additionalMemory := size.CalculateMemoryMilliRequirement(installation.InternalDatabase(), installation.InternalFilestore())
+
+	// The resources of the current size are released by the resize.
+	currentSize, err := mmv1alpha1.GetClusterSize(cr.Spec.Size)
+	if err == nil {
+		additionalCPU -= currentSize.CalculateCPUMilliRequirement(installation.InternalDatabase(), installation.InternalFilestore())
+		additionalMemory -= currentSize.CalculateMemoryMilliRequirement(installation.InternalDatabase(), installation.InternalFilestore())
+	}
+
+	cpuPercent, memoryPercent, fits, err := s.checkClusterResourceThreshold(cluster, installation.NodePool, additionalCPU, additionalMemory)
+	if err != nil {
+		return cr.Spec.Size, false, errors.Wrap(err, "failed to get cluster resources")
+	}
+	if !fits {
+		logger.Errorf("Unable to resize cluster installation %s to %s: cluster %s would exceed the cluster load threshold (%d%%): CPU=%d%%, Memory=%d%%", clusterInstallation.ID, installation.Size, cluster.ID, s.clusterResourceThreshold, cpuPercent, memoryPercent)
+		return cr.Spec.Size, false, nil
+	}
+
+	logger.Infof("Resizing cluster installation %s from %s to %s. Expected resource load: CPU=%d%%, Memory=%d%%", clusterInstallation.ID, cr.Spec.Size, installation.Size, cpuPercent, memoryPercent)
+
+	return cr.Spec.Size, true, nil
+}
+
 func (s *InstallationSupervisor) preProvisionInstallation(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
-	err := s.resourceUtil.GetDatabase(installation).Provision(s.store, logger)
+	database := s.resourceUtil.GetDatabase(installation)
+	filestore := s.resourceUtil.GetFilestore(installation)
+
+	if installation.RestoredFromBackupID != nil {
+		backup, err := s.store.GetInstallationBackup(*installation.RestoredFromBackupID)
+		if err != nil {
+			logger.WithError(err).Error("Failed to get installation backup to restore")
+			return model.InstallationStateCreationPreProvisioning
+		}
+		if backup == nil {
+			logger.Errorf("Installation backup %s to restore not found", *installation.RestoredFromBackupID)
+			return model.InstallationStateCreationFailed
+		}
+
+		database = s.resourceUtil.GetDatabaseFromBackup(installation, backup)
+		filestore = s.resourceUtil.GetFilestoreFromBackup(installation, backup)
+	}
+
+	databaseReady, err := database.Provision(s.store, logger)
 	if err != nil {
 		logger.WithError(err).Error("Failed to provision installation database")
 		return model.InstallationStateCreationPreProvisioning
 	}
 
-	err = s.resourceUtil.GetFilestore(installation).Provision(logger)
+	filestoreReady, err := filestore.Provision(logger)
+	if installation.RestoredFromBackupID != nil {
+		// Persist the filestore restore progress, even if the copy failed part
+		// way through, so that it resumes from there on the next attempt.
+		updateErr := s.store.UpdateInstallationRestoreProgress(installation)
+		if updateErr != nil {
+			logger.WithError(updateErr).Error("Failed to record installation restore progress")
+			return model.InstallationStateCreationPreProvisioning
+		}
+	}
 	if err != nil {
 		logger.WithError(err).Error("Failed to provision installation filestore")
 		return model.InstallationStateCreationPreProvisioning
 	}
 
+	if !databaseReady {
+		logger.Debug("Waiting for installation database to be ready")
+		return model.InstallationStateCreationPreProvisioning
+	}
+	if !filestoreReady {
+		logger.Debug("Waiting for installation filestore backup to be restored")
+		return model.InstallationStateCreationPreProvisioning
+	}
+
 	logger.Info("Installation pre-provisioning complete")
 
 	return s.waitForClusterInstallationStable(installation, instanceID, logger)
@@ -434,9 +596,9 @@ func (s *InstallationSupervisor) configureInstallationDNS(installation *model.In
 		endpoints = append(endpoints, cr.Status.Endpoint)
 	}
 
-	err = s.aws.CreatePublicCNAME(installation.DNS, endpoints, logger)
+	err = s.reconcileInstallationDNS(installation, endpoints, logger)
 	if err != nil {
-		logger.WithError(err).Error("Failed to create DNS CNAME record")
+		logger.WithError(err).Error("Failed to configure installation DNS")
 		return model.InstallationStateCreationDNS
 	}
 
@@ -445,6 +607,33 @@ func (s *InstallationSupervisor) configureInstallationDNS(installation *model.In
 	return model.InstallationStateStable
 }
 
+// reconcileInstallationDNS points every DNS name of the installation at the
+// given endpoints and removes the records of names that are no longer used.
+func (s *InstallationSupervisor) reconcileInstallationDNS(installation *model.Installation, endpoints []string, logger log.FieldLogger) error {
+	for _, dnsName := range installation.DNSNames() {
+		err := s.aws.CreatePublicCNAME(dnsName, endpoints, logger)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create DNS CNAME record %s", dnsName)
+		}
+	}
+
+	for _, dnsName := range installation.StaleDNSRecords() {
+		err := s.aws.DeletePublicCNAME(dnsName, logger)
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete stale DNS CNAME record %s", dnsName)
+		}
+		logger.Infof("Deleted stale DNS record %s", dnsName)
+	}
+
+	installation.DNSRecords = installation.DNSNames()
+	err := s.store.UpdateInstallationDNSRecords(installation)
+	if err != nil {
+		return errors.Wrap(err, "failed to record configured DNS names")
+	}
+
+	return nil
+}
+
 func (s *InstallationSupervisor) updateInstallation(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
 	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
 		PerPage:        model.AllPerPage,
@@ -483,6 +672,7 @@ func (s *InstallationSupervisor) updateInstallation(installation *model.Installa
 		}
 	}
 
+	clusters := make(map[string]*model.Cluster)
 	for _, clusterInstallation := range clusterInstallations {
 		cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
 		if err != nil {
@@ -493,6 +683,39 @@ func (s *InstallationSupervisor) updateInstallation(installation *model.Installa
 			logger.Errorf("Failed to find cluster %s", clusterInstallation.ClusterID)
 			return failedClusterInstallationState(clusterInstallation.State)
 		}
+		clusters[clusterInstallation.ID] = cluster
+
+		// Hold the cluster lock while checking resources so that no other
+		// installation is scheduled onto the cluster at the same time.
+		clusterLock := newClusterLock(cluster.ID, instanceID, s.store, logger)
+		if !clusterLock.TryLock() {
+			logger.Debugf("Failed to lock cluster %s", cluster.ID)
+			return installation.State
+		}
+		currentSize, fits, err := s.checkClusterInstallationResize(cluster, installation, clusterInstallation, logger)
+		clusterLock.Unlock()
+		if err != nil {
+			logger.WithError(err).Warn("Failed to check cluster resources for the installation size")
+			return installation.State
+		}
+		if !fits {
+			// The size was recorded by the update request, so it is reverted to
+			// the size still in use rather than left for the next update to
+			// apply unchecked.
+			if _, err = mmv1alpha1.GetClusterSize(currentSize); err == nil {
+				installation.Size = currentSize
+				err = s.store.UpdateInstallationSize(installation)
+				if err != nil {
+					logger.WithError(err).Warnf("Failed to revert installation size to %s", currentSize)
+					return installation.State
+				}
+			}
+			return model.InstallationStateUpdateFailed
+		}
+	}
+
+	for _, clusterInstallation := range clusterInstallations {
+		cluster := clusters[clusterInstallation.ID]
 
 		err = s.provisioner.UpdateClusterInstallation(cluster, installation, clusterInstallation)
 		if err != nil {
@@ -539,6 +762,37 @@ func (s *InstallationSupervisor) waitForUpdateComplete(installation *model.Insta
 	logger.Debugf("Found %d cluster installations, %d stable, %d reconciling, %d failed", len(clusterInstallations), stable, reconciling, failed)
 
 	if len(clusterInstallations) == stable {
+		var endpoints []string
+		for _, clusterInstallation := range clusterInstallations {
+			cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+			if err != nil {
+				logger.WithError(err).Warnf("Failed to query cluster %s", clusterInstallation.ClusterID)
+				return installation.State
+			}
+			if cluster == nil {
+				logger.Errorf("Failed to find cluster %s", clusterInstallation.ClusterID)
+				return model.InstallationStateUpdateFailed
+			}
+
+			cr, err := s.provisioner.GetClusterInstallationResource(cluster, installation, clusterInstallation)
+			if err != nil {
+				logger.WithError(err).Error("Failed to get cluster installation resource")
+				return installation.State
+			}
+			if cr == nil {
+				logger.Errorf("Failed to find the resource of cluster installation %s", clusterInstallation.ID)
+				return installation.State
+			}
+
+			endpoints = append(endpoints, cr.Status.Endpoint)
+		}
+
+		err = s.reconcileInstallationDNS(installation, endpoints, logger)
+		if err != nil {
+			logger.WithError(err).Error("Failed to update installation DNS")
+			return installation.State
+		}
+
 		logger.Infof("Finished updating installation")
 		return model.InstallationStateStable
 	}
@@ -550,6 +804,454 @@ func (s *InstallationSupervisor) waitForUpdateComplete(installation *model.Insta
 	return installation.State
 }
 
+func (s *InstallationSupervisor) hibernateInstallation(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:        model.AllPerPage,
+		InstallationID: installation.ID,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find cluster installations")
+		return installation.State
+	}
+
+	var clusterInstallationIDs []string
+	if len(clusterInstallations) > 0 {
+		for _, clusterInstallation := range clusterInstallations {
+			clusterInstallationIDs = append(clusterInstallationIDs, clusterInstallation.ID)
+		}
+
+		clusterInstallationLocks := newClusterInstallationLocks(clusterInstallationIDs, instanceID, s.store, logger)
+		if !clusterInstallationLocks.TryLock() {
+			logger.Debugf("Failed to lock %d cluster installations", len(clusterInstallations))
+			return installation.State
+		}
+		defer clusterInstallationLocks.Unlock()
+
+		// Fetch the same cluster installations again, now that we have the locks.
+		clusterInstallations, err = s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+			PerPage: model.AllPerPage,
+			IDs:     clusterInstallationIDs,
+		})
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to fetch %d cluster installations by ids", len(clusterInstallations))
+			return installation.State
+		}
+
+		if len(clusterInstallations) != len(clusterInstallationIDs) {
+			logger.Warnf("Found only %d cluster installations after locking, expected %d", len(clusterInstallations), len(clusterInstallationIDs))
+		}
+	}
+
+	drained := true
+	for _, clusterInstallation := range clusterInstallations {
+		cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to query cluster %s", clusterInstallation.ClusterID)
+			return installation.State
+		}
+		if cluster == nil {
+			logger.Errorf("Failed to find cluster %s", clusterInstallation.ClusterID)
+			return installation.State
+		}
+
+		hibernated, err := s.provisioner.HibernateClusterInstallation(cluster, installation, clusterInstallation)
+		if err != nil {
+			logger.WithError(err).Error("Failed to hibernate cluster installation")
+			return installation.State
+		}
+		if !hibernated {
+			drained = false
+		}
+	}
+
+	if !drained {
+		logger.Debug("Waiting for cluster installations to finish hibernating")
+		return model.InstallationStateHibernationInProgress
+	}
+
+	logger.Info("Finished hibernating installation")
+
+	return model.InstallationStateHibernating
+}
+
+func (s *InstallationSupervisor) migrateInstallation(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	if installation.MigrationTargetClusterID == nil {
+		logger.Error("Installation migration was requested without a target cluster")
+		return model.InstallationStateMigrationFailed
+	}
+
+	targetCluster, err := s.store.GetCluster(*installation.MigrationTargetClusterID)
+	if err != nil {
+		logger.WithError(err).Warnf("Failed to query target cluster %s", *installation.MigrationTargetClusterID)
+		return model.InstallationStateMigrationRequested
+	}
+	if targetCluster == nil {
+		logger.Errorf("Failed to find target cluster %s", *installation.MigrationTargetClusterID)
+		return model.InstallationStateMigrationFailed
+	}
+
+	targetClusterInstallation, err := s.getMigrationTargetClusterInstallation(installation)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find cluster installations")
+		return model.InstallationStateMigrationRequested
+	}
+	if targetClusterInstallation != nil {
+		logger.Warnf("Cluster installation %s already exists on target cluster", targetClusterInstallation.ID)
+		return s.waitForMigrationClusterInstallationStable(installation, instanceID, logger)
+	}
+
+	if !installation.IsMigrationSupported() {
+		logger.Errorf("Migration is not supported for installations with database %s and filestore %s", installation.Database, installation.Filestore)
+		return model.InstallationStateMigrationFailed
+	}
+
+	// The database is moved from a snapshot, so the installation must stop
+	// writing to it first.
+	stopped, err := s.stopMigrationSourceClusterInstallations(installation, instanceID, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to stop source cluster installations")
+		return model.InstallationStateMigrationRequested
+	}
+	if !stopped {
+		logger.Debug("Waiting for source cluster installations to stop")
+		return model.InstallationStateMigrationRequested
+	}
+
+	databaseMigration := s.resourceUtil.GetDatabaseMigration(installation)
+
+	status, err := databaseMigration.Setup(logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to set up installation database migration")
+		return model.InstallationStateMigrationRequested
+	}
+	if status != model.DatabaseMigrationStatusSetupComplete {
+		logger.Debugf("Installation database migration setup is %s", status)
+		return model.InstallationStateMigrationRequested
+	}
+
+	status, err = databaseMigration.Replicate(logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to replicate installation database")
+		return model.InstallationStateMigrationRequested
+	}
+	if status != model.DatabaseMigrationStatusReplicationComplete {
+		logger.Debugf("Installation database replication is %s", status)
+		return model.InstallationStateMigrationRequested
+	}
+
+	clusterInstallation := s.createClusterInstallation(targetCluster, installation, instanceID, logger)
+	if clusterInstallation == nil {
+		logger.Warnf("Unable to schedule cluster installation on target cluster %s", targetCluster.ID)
+		return model.InstallationStateMigrationRequested
+	}
+
+	return model.InstallationStateMigrationInProgress
+}
+
+// stopMigrationSourceClusterInstallations hibernates the cluster installations
+// outside of the migration target cluster and returns whether all of them have
+// stopped.
+func (s *InstallationSupervisor) stopMigrationSourceClusterInstallations(installation *model.Installation, instanceID string, logger log.FieldLogger) (bool, error) {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:        model.AllPerPage,
+		InstallationID: installation.ID,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to find cluster installations")
+	}
+
+	clusterInstallationIDs := migrationSourceClusterInstallationIDs(installation, clusterInstallations)
+	if len(clusterInstallationIDs) == 0 {
+		return true, nil
+	}
+
+	clusterInstallationLocks := newClusterInstallationLocks(clusterInstallationIDs, instanceID, s.store, logger)
+	if !clusterInstallationLocks.TryLock() {
+		logger.Debugf("Failed to lock %d cluster installations", len(clusterInstallationIDs))
+		return false, nil
+	}
+	defer clusterInstallationLocks.Unlock()
+
+	// Fetch the same cluster installations again, now that we have the locks.
+	clusterInstallations, err = s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage: model.AllPerPage,
+		IDs:     clusterInstallationIDs,
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to fetch %d cluster installations by ids", len(clusterInstallationIDs))
+	}
+
+	stopped := true
+	for _, clusterInstallation := range clusterInstallations {
+		cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to query cluster %s", clusterInstallation.ClusterID)
+		}
+		if cluster == nil {
+			return false, errors.Errorf("failed to find cluster %s", clusterInstallation.ClusterID)
+		}
+
+		hibernated, err := s.provisioner.HibernateClusterInstallation(cluster, installation, clusterInstallation)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to hibernate cluster installation %s", clusterInstallation.ID)
+		}
+		if !hibernated {
+			stopped = false
+		}
+	}
+
+	return stopped, nil
+}
+
+// restoreMigrationSourceClusterInstallations restores the cluster
+// installations stopped by stopMigrationSourceClusterInstallations and returns
+// whether all of them were restored.
+func (s *InstallationSupervisor) restoreMigrationSourceClusterInstallations(installation *model.Installation, instanceID string, logger log.FieldLogger) (bool, error) {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:        model.AllPerPage,
+		InstallationID: installation.ID,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to find cluster installations")
+	}
+
+	clusterInstallationIDs := migrationSourceClusterInstallationIDs(installation, clusterInstallations)
+	if len(clusterInstallationIDs) == 0 {
+		return true, nil
+	}
+
+	clusterInstallationLocks := newClusterInstallationLocks(clusterInstallationIDs, instanceID, s.store, logger)
+	if !clusterInstallationLocks.TryLock() {
+		logger.Debugf("Failed to lock %d cluster installations", len(clusterInstallationIDs))
+		return false, nil
+	}
+	defer clusterInstallationLocks.Unlock()
+
+	// Fetch the same cluster installations again, now that we have the locks.
+	clusterInstallations, err = s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage: model.AllPerPage,
+		IDs:     clusterInstallationIDs,
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to fetch %d cluster installations by ids", len(clusterInstallationIDs))
+	}
+
+	for _, clusterInstallation := range clusterInstallations {
+		cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to query cluster %s", clusterInstallation.ClusterID)
+		}
+		if cluster == nil {
+			return false, errors.Errorf("failed to find cluster %s", clusterInstallation.ClusterID)
+		}
+
+		// Updating a hibernated cluster installation restores its resource.
+		err = s.provisioner.UpdateClusterInstallation(cluster, installation, clusterInstallation)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to restore cluster installation %s", clusterInstallation.ID)
+		}
+
+		clusterInstallation.State = model.ClusterInstallationStateReconciling
+		err = s.store.UpdateClusterInstallation(clusterInstallation)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to change cluster installation state to %s", model.ClusterInstallationStateReconciling)
+		}
+	}
+
+	return true, nil
+}
+
+// migrationSourceClusterInstallationIDs returns the IDs of the given cluster
+// installations outside of the migration target cluster.
+func migrationSourceClusterInstallationIDs(installation *model.Installation, clusterInstallations []*model.ClusterInstallation) []string {
+	var clusterInstallationIDs []string
+	for _, clusterInstallation := range clusterInstallations {
+		if clusterInstallation.ClusterID != *installation.MigrationTargetClusterID {
+			clusterInstallationIDs = append(clusterInstallationIDs, clusterInstallation.ID)
+		}
+	}
+
+	return clusterInstallationIDs
+}
+
+// failMigration restores the source cluster installations before marking the
+// migration as failed, so that the installation keeps running on its source
+// clusters. The installation stays in its current state until they are
+// restored.
+func (s *InstallationSupervisor) failMigration(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	restored, err := s.restoreMigrationSourceClusterInstallations(installation, instanceID, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to restore source cluster installations")
+		return installation.State
+	}
+	if !restored {
+		logger.Debug("Waiting to restore source cluster installations")
+		return installation.State
+	}
+
+	return model.InstallationStateMigrationFailed
+}
+
+func (s *InstallationSupervisor) waitForMigrationClusterInstallationStable(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	clusterInstallation, err := s.getMigrationTargetClusterInstallation(installation)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find cluster installations")
+		return model.InstallationStateMigrationInProgress
+	}
+	if clusterInstallation == nil {
+		logger.Error("Expected a cluster installation on the target cluster, but found none")
+		return s.failMigration(installation, instanceID, logger)
+	}
+
+	switch clusterInstallation.State {
+	case model.ClusterInstallationStateStable:
+		logger.Info("Finished creating cluster installation on the target cluster")
+		return s.switchMigrationDNS(installation, instanceID, logger)
+	case model.ClusterInstallationStateCreationFailed:
+		logger.Errorf("Cluster installation %s on the target cluster failed creation", clusterInstallation.ID)
+		return s.failMigration(installation, instanceID, logger)
+	}
+
+	return model.InstallationStateMigrationInProgress
+}
+
+func (s *InstallationSupervisor) switchMigrationDNS(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	clusterInstallation, err := s.getMigrationTargetClusterInstallation(installation)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find cluster installations")
+		return model.InstallationStateMigrationDNS
+	}
+	if clusterInstallation == nil {
+		logger.Error("Expected a cluster installation on the target cluster, but found none")
+		return s.failMigration(installation, instanceID, logger)
+	}
+
+	cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+	if err != nil {
+		logger.WithError(err).Warnf("Failed to query cluster %s", clusterInstallation.ClusterID)
+		return model.InstallationStateMigrationDNS
+	}
+	if cluster == nil {
+		logger.Errorf("Failed to find cluster %s", clusterInstallation.ClusterID)
+		return s.failMigration(installation, instanceID, logger)
+	}
+
+	cr, err := s.provisioner.GetClusterInstallationResource(cluster, installation, clusterInstallation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get cluster installation resource")
+		return model.InstallationStateMigrationDNS
+	}
+	if cr == nil {
+		logger.Errorf("Failed to find the resource of cluster installation %s", clusterInstallation.ID)
+		return model.InstallationStateMigrationDNS
+	}
+
+	err = s.reconcileInstallationDNS(installation, []string{cr.Status.Endpoint}, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to switch installation DNS")
+		return model.InstallationStateMigrationDNS
+	}
+
+	logger.Infof("Successfully switched DNS %s to cluster %s", installation.DNS, cluster.ID)
+
+	return s.cleanupMigration(installation, instanceID, logger)
+}
+
+func (s *InstallationSupervisor) cleanupMigration(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:        model.AllPerPage,
+		InstallationID: installation.ID,
+		IncludeDeleted: true,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find cluster installations")
+		return model.InstallationStateMigrationCleanup
+	}
+
+	// Only the cluster installations outside of the target cluster are removed.
+	clusterInstallationIDs := migrationSourceClusterInstallationIDs(installation, clusterInstallations)
+
+	var sourceClusterInstallations []*model.ClusterInstallation
+	if len(clusterInstallationIDs) > 0 {
+		clusterInstallationLocks := newClusterInstallationLocks(clusterInstallationIDs, instanceID, s.store, logger)
+		if !clusterInstallationLocks.TryLock() {
+			logger.Debugf("Failed to lock %d cluster installations", len(clusterInstallationIDs))
+			return model.InstallationStateMigrationCleanup
+		}
+		defer clusterInstallationLocks.Unlock()
+
+		// Fetch the same cluster installations again, now that we have the locks.
+		sourceClusterInstallations, err = s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+			PerPage:        model.AllPerPage,
+			IDs:            clusterInstallationIDs,
+			IncludeDeleted: true,
+		})
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to fetch %d cluster installations by ids", len(clusterInstallationIDs))
+			return model.InstallationStateMigrationCleanup
+		}
+	}
+
+	deletingClusterInstallations := 0
+	for _, clusterInstallation := range sourceClusterInstallations {
+		switch clusterInstallation.State {
+		case model.ClusterInstallationStateDeleted:
+			continue
+		case model.ClusterInstallationStateDeletionRequested:
+			deletingClusterInstallations++
+			continue
+		case model.ClusterInstallationStateDeletionFailed:
+			logger.Errorf("Failed to delete cluster installation %s from the source cluster", clusterInstallation.ID)
+			return model.InstallationStateMigrationFailed
+		}
+
+		clusterInstallation.State = model.ClusterInstallationStateDeletionRequested
+		err = s.store.UpdateClusterInstallation(clusterInstallation)
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to mark cluster installation %s for deletion", clusterInstallation.ID)
+			return model.InstallationStateMigrationCleanup
+		}
+
+		deletingClusterInstallations++
+	}
+
+	if deletingClusterInstallations > 0 {
+		logger.Debugf("Waiting for %d cluster installations to be deleted from the source cluster", deletingClusterInstallations)
+		return model.InstallationStateMigrationCleanup
+	}
+
+	databaseMigration := s.resourceUtil.GetDatabaseMigration(installation)
+	if databaseMigration != nil {
+		_, err = databaseMigration.Teardown(logger)
+		if err != nil {
+			logger.WithError(err).Error("Failed to tear down installation database migration")
+			return model.InstallationStateMigrationCleanup
+		}
+	}
+
+	logger.Infof("Finished migrating installation to cluster %s", *installation.MigrationTargetClusterID)
+
+	return model.InstallationStateStable
+}
+
+// getMigrationTargetClusterInstallation returns the cluster installation
+// created on the migration target cluster, if any.
+func (s *InstallationSupervisor) getMigrationTargetClusterInstallation(installation *model.Installation) (*model.ClusterInstallation, error) {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:        model.AllPerPage,
+		InstallationID: installation.ID,
+		ClusterID:      *installation.MigrationTargetClusterID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(clusterInstallations) == 0 {
+		return nil, nil
+	}
+
+	return clusterInstallations[0], nil
+}
+
 func (s *InstallationSupervisor) deleteInstallation(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
 	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
 		PerPage:        model.AllPerPage,
@@ -656,13 +1358,15 @@ func (s *InstallationSupervisor) deleteInstallation(installation *model.Installa
 }
 
 func (s *InstallationSupervisor) finalDeletionCleanup(installation *model.Installation, logger log.FieldLogger) string {
-	err := s.aws.DeletePublicCNAME(installation.DNS, logger)
-	if err != nil {
-		logger.WithError(err).Error("Failed to delete installation DNS")
-		return model.InstallationStateDeletionFinalCleanup
+	for _, dnsName := range append(installation.DNSNames(), installation.StaleDNSRecords()...) {
+		err := s.aws.DeletePublicCNAME(dnsName, logger)
+		if err != nil {
+			logger.WithError(err).Errorf("Failed to delete installation DNS %s", dnsName)
+			return model.InstallationStateDeletionFinalCleanup
+		}
 	}
 
-	err = s.resourceUtil.GetDatabase(installation).Teardown(s.keepDatabaseData, logger)
+	err := s.resourceUtil.GetDatabase(installation).Teardown(s.keepDatabaseData, logger)
 	if err != nil {
 		logger.WithError(err).Error("Failed to delete database")
 		return model.InstallationStateDeletionFinalCleanup
@@ -0,0 +1,214 @@
+package supervisor
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
+	"github.com/mattermost/mattermost-cloud/internal/webhook"
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// installationBackupStore abstracts the database operations required to query
+// installation backups.
+type installationBackupStore interface {
+	GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error)
+
+	GetInstallationBackup(backupID string) (*model.InstallationBackup, error)
+	GetUnlockedInstallationBackupsPendingWork() ([]*model.InstallationBackup, error)
+	UpdateInstallationBackup(backup *model.InstallationBackup) error
+	LockInstallationBackup(backupID, lockerID string) (bool, error)
+	UnlockInstallationBackup(backupID, lockerID string, force bool) (bool, error)
+
+	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	CreateEvent(event *model.Event) error
+}
+
+// installationBackupProvisioner abstracts the operations required to back up
+// the managed services of an installation.
+type installationBackupProvisioner interface {
+	CreateInstallationBackup(installation *model.Installation, backup *model.InstallationBackup, logger log.FieldLogger) error
+	IsInstallationBackupComplete(backup *model.InstallationBackup, logger log.FieldLogger) (bool, error)
+}
+
+// InstallationBackupSupervisor finds installation backups pending work and
+// effects the required changes.
+//
+// The number of installation backups supervised concurrently is controlled by SetWorkers.
+type InstallationBackupSupervisor struct {
+	store       installationBackupStore
+	provisioner installationBackupProvisioner
+	instanceID  string
+	logger      log.FieldLogger
+
+	stopper
+	partitioned
+	workers
+}
+
+// NewInstallationBackupSupervisor creates a new InstallationBackupSupervisor.
+func NewInstallationBackupSupervisor(store installationBackupStore, provisioner installationBackupProvisioner, instanceID string, logger log.FieldLogger) *InstallationBackupSupervisor {
+	return &InstallationBackupSupervisor{
+		store:       store,
+		provisioner: provisioner,
+		instanceID:  instanceID,
+		logger:      logger,
+	}
+}
+
+// Do looks for work to be done on any pending installation backups and
+// attempts to schedule the required work.
+func (s *InstallationBackupSupervisor) Do() error {
+	defer metrics.ObserveSupervisorDo("installation_backup", time.Now())
+
+	backups, err := s.store.GetUnlockedInstallationBackupsPendingWork()
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to query for installation backups pending work")
+		return nil
+	}
+
+	pool := s.newWorkerPool()
+	for _, backup := range backups {
+		if s.isStopped() {
+			s.logger.Info("Supervisor stopped, leaving remaining work for later")
+			break
+		}
+		if !s.owns(backup.ID) {
+			continue
+		}
+		backup := backup
+		pool.Go(func() { s.Supervise(backup) })
+	}
+	pool.Wait()
+
+	return nil
+}
+
+// DoResource supervises the given installation backup immediately if it is pending work.
+func (s *InstallationBackupSupervisor) DoResource(resourceType, resourceID string) error {
+	if resourceType != model.LockResourceTypeInstallationBackup || s.isStopped() {
+		return nil
+	}
+
+	backup, err := s.store.GetInstallationBackup(resourceID)
+	if err != nil {
+		s.logger.WithError(err).WithField("backup", resourceID).Warn("Failed to get installation backup")
+		return nil
+	}
+	if backup == nil || !isPendingWork(backup.State, model.AllInstallationBackupStatesPendingWork) {
+		return nil
+	}
+
+	s.Supervise(backup)
+
+	return nil
+}
+
+// Supervise schedules the required work on the given installation backup.
+func (s *InstallationBackupSupervisor) Supervise(backup *model.InstallationBackup) {
+	logger := s.logger.WithFields(log.Fields{
+		"installation": backup.InstallationID,
+		"backup":       backup.ID,
+	})
+
+	lock := newInstallationBackupLock(backup.ID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		return
+	}
+	defer lock.Unlock()
+
+	logger.Debugf("Supervising installation backup in state %s", backup.State)
+
+	transitionLogger := newTransitionLogger(logger)
+	newState := s.transitionInstallationBackup(backup, transitionLogger)
+
+	if backup.State == newState {
+		return
+	}
+
+	oldState := backup.State
+	backup.State = newState
+
+	err := s.store.UpdateInstallationBackup(backup)
+	if err != nil {
+		logger.WithError(err).Warnf("Failed to set installation backup state to %s", newState)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallationBackup,
+		ID:        backup.ID,
+		NewState:  newState,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+	}
+	recordEvent(s.store, webhookPayload, s.instanceID, transitionLogger.transitionError(oldState, newState), logger)
+	err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	logger.Debugf("Transitioned installation backup from %s to %s", oldState, newState)
+}
+
+// transitionInstallationBackup works with the given installation backup to
+// transition it to a final state.
+func (s *InstallationBackupSupervisor) transitionInstallationBackup(backup *model.InstallationBackup, logger log.FieldLogger) string {
+	switch backup.State {
+	case model.InstallationBackupStateBackupRequested:
+		return s.createBackup(backup, logger)
+	case model.InstallationBackupStateBackupInProgress:
+		return s.checkBackupComplete(backup, logger)
+	default:
+		logger.Warnf("Found installation backup pending work in unexpected state %s", backup.State)
+		return backup.State
+	}
+}
+
+func (s *InstallationBackupSupervisor) createBackup(backup *model.InstallationBackup, logger log.FieldLogger) string {
+	installation, err := s.store.GetInstallation(backup.InstallationID, true, false)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get installation to back up")
+		return model.InstallationBackupStateBackupRequested
+	}
+	if installation == nil {
+		logger.Error("Installation to back up not found")
+		return model.InstallationBackupStateBackupFailed
+	}
+	if !installation.IsBackupSupported() {
+		logger.Errorf("Backups are not supported for installations with database %s and filestore %s", installation.Database, installation.Filestore)
+		return model.InstallationBackupStateBackupFailed
+	}
+
+	err = s.provisioner.CreateInstallationBackup(installation, backup, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create installation backup")
+		return model.InstallationBackupStateBackupFailed
+	}
+
+	logger.Info("Installation backup started")
+
+	return model.InstallationBackupStateBackupInProgress
+}
+
+func (s *InstallationBackupSupervisor) checkBackupComplete(backup *model.InstallationBackup, logger log.FieldLogger) string {
+	complete, err := s.provisioner.IsInstallationBackupComplete(backup, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to check installation backup status")
+		return model.InstallationBackupStateBackupFailed
+	}
+	if !complete {
+		// Persist the filestore copy progress, as the state is unchanged.
+		err = s.store.UpdateInstallationBackup(backup)
+		if err != nil {
+			logger.WithError(err).Error("Failed to record installation backup progress")
+		}
+		logger.Debug("Installation backup is still in progress")
+		return model.InstallationBackupStateBackupInProgress
+	}
+
+	logger.Info("Installation backup complete")
+
+	return model.InstallationBackupStateBackupSucceeded
+}
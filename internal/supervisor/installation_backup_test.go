@@ -0,0 +1,307 @@
+package supervisor_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type mockInstallationBackupStore struct {
+	Installation                           *model.Installation
+	InstallationBackup                     *model.InstallationBackup
+	UnlockedInstallationBackupsPendingWork []*model.InstallationBackup
+
+	UnlockChan                    chan interface{}
+	UpdateInstallationBackupCalls int
+}
+
+func (s *mockInstallationBackupStore) GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error) {
+	return s.Installation, nil
+}
+
+func (s *mockInstallationBackupStore) GetInstallationBackup(backupID string) (*model.InstallationBackup, error) {
+	return s.InstallationBackup, nil
+}
+
+func (s *mockInstallationBackupStore) GetUnlockedInstallationBackupsPendingWork() ([]*model.InstallationBackup, error) {
+	return s.UnlockedInstallationBackupsPendingWork, nil
+}
+
+func (s *mockInstallationBackupStore) UpdateInstallationBackup(backup *model.InstallationBackup) error {
+	s.UpdateInstallationBackupCalls++
+	return nil
+}
+
+func (s *mockInstallationBackupStore) LockInstallationBackup(backupID, lockerID string) (bool, error) {
+	return true, nil
+}
+
+func (s *mockInstallationBackupStore) UnlockInstallationBackup(backupID, lockerID string, force bool) (bool, error) {
+	if s.UnlockChan != nil {
+		close(s.UnlockChan)
+	}
+	return true, nil
+}
+
+func (s *mockInstallationBackupStore) GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error) {
+	return nil, nil
+}
+
+func (s *mockInstallationBackupStore) CreateEvent(event *model.Event) error {
+	return nil
+}
+
+func (s *mockInstallationBackupStore) CreateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	return nil
+}
+
+type mockInstallationBackupProvisioner struct {
+	CreateError error
+	Complete    bool
+	StatusError error
+}
+
+func (p *mockInstallationBackupProvisioner) CreateInstallationBackup(installation *model.Installation, backup *model.InstallationBackup, logger log.FieldLogger) error {
+	if p.CreateError != nil {
+		return p.CreateError
+	}
+	backup.DatabaseSnapshotID = "snapshot-" + backup.ID
+	backup.FilestoreBucket = "bucket-" + installation.ID
+	backup.FilestorePrefix = backup.ID + "/"
+
+	return nil
+}
+
+func (p *mockInstallationBackupProvisioner) IsInstallationBackupComplete(backup *model.InstallationBackup, logger log.FieldLogger) (bool, error) {
+	backup.FilestoreCopyMarker = "data/file"
+	return p.Complete, p.StatusError
+}
+
+func TestInstallationBackupSupervisorDo(t *testing.T) {
+	t.Run("no backups pending work", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		mockStore := &mockInstallationBackupStore{}
+
+		supervisor := supervisor.NewInstallationBackupSupervisor(mockStore, &mockInstallationBackupProvisioner{}, "instanceID", logger)
+		err := supervisor.Do()
+		require.NoError(t, err)
+
+		require.Equal(t, 0, mockStore.UpdateInstallationBackupCalls)
+	})
+
+	t.Run("mock backup creation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		mockStore := &mockInstallationBackupStore{}
+
+		mockStore.Installation = &model.Installation{
+			ID:        model.NewID(),
+			Database:  model.InstallationDatabaseAwsRDS,
+			Filestore: model.InstallationFilestoreAwsS3,
+			State:     model.InstallationStateStable,
+		}
+		mockStore.UnlockedInstallationBackupsPendingWork = []*model.InstallationBackup{{
+			ID:             model.NewID(),
+			InstallationID: mockStore.Installation.ID,
+			State:          model.InstallationBackupStateBackupRequested,
+		}}
+		mockStore.UnlockChan = make(chan interface{})
+
+		supervisor := supervisor.NewInstallationBackupSupervisor(mockStore, &mockInstallationBackupProvisioner{}, "instanceID", logger)
+		err := supervisor.Do()
+		require.NoError(t, err)
+
+		<-mockStore.UnlockChan
+		require.Equal(t, 1, mockStore.UpdateInstallationBackupCalls)
+	})
+}
+
+func TestInstallationBackupSupervisorDoResource(t *testing.T) {
+	setup := func(t *testing.T, state string) *mockInstallationBackupStore {
+		mockStore := &mockInstallationBackupStore{}
+		mockStore.Installation = &model.Installation{
+			ID:        model.NewID(),
+			Database:  model.InstallationDatabaseAwsRDS,
+			Filestore: model.InstallationFilestoreAwsS3,
+			State:     model.InstallationStateStable,
+		}
+		mockStore.InstallationBackup = &model.InstallationBackup{
+			ID:             model.NewID(),
+			InstallationID: mockStore.Installation.ID,
+			State:          state,
+		}
+
+		return mockStore
+	}
+
+	t.Run("backup pending work", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		mockStore := setup(t, model.InstallationBackupStateBackupRequested)
+
+		supervisor := supervisor.NewInstallationBackupSupervisor(mockStore, &mockInstallationBackupProvisioner{}, "instanceID", logger)
+		err := supervisor.DoResource(model.LockResourceTypeInstallationBackup, mockStore.InstallationBackup.ID)
+		require.NoError(t, err)
+
+		require.Equal(t, 1, mockStore.UpdateInstallationBackupCalls)
+	})
+
+	t.Run("backup not pending work", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		mockStore := setup(t, model.InstallationBackupStateBackupSucceeded)
+
+		supervisor := supervisor.NewInstallationBackupSupervisor(mockStore, &mockInstallationBackupProvisioner{}, "instanceID", logger)
+		err := supervisor.DoResource(model.LockResourceTypeInstallationBackup, mockStore.InstallationBackup.ID)
+		require.NoError(t, err)
+
+		require.Equal(t, 0, mockStore.UpdateInstallationBackupCalls)
+	})
+
+	t.Run("other resource type", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		mockStore := setup(t, model.InstallationBackupStateBackupRequested)
+
+		supervisor := supervisor.NewInstallationBackupSupervisor(mockStore, &mockInstallationBackupProvisioner{}, "instanceID", logger)
+		err := supervisor.DoResource(model.LockResourceTypeInstallation, mockStore.InstallationBackup.ID)
+		require.NoError(t, err)
+
+		require.Equal(t, 0, mockStore.UpdateInstallationBackupCalls)
+	})
+
+	t.Run("backup not found", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		mockStore := setup(t, model.InstallationBackupStateBackupRequested)
+		mockStore.InstallationBackup = nil
+
+		supervisor := supervisor.NewInstallationBackupSupervisor(mockStore, &mockInstallationBackupProvisioner{}, "instanceID", logger)
+		err := supervisor.DoResource(model.LockResourceTypeInstallationBackup, model.NewID())
+		require.NoError(t, err)
+
+		require.Equal(t, 0, mockStore.UpdateInstallationBackupCalls)
+	})
+}
+
+func TestInstallationBackupSupervisor(t *testing.T) {
+	expectBackupState := func(t *testing.T, sqlStore *store.SQLStore, backup *model.InstallationBackup, expectedState string) {
+		t.Helper()
+		backup, err := sqlStore.GetInstallationBackup(backup.ID)
+		require.NoError(t, err)
+		require.Equal(t, expectedState, backup.State)
+	}
+
+	createInstallation := func(t *testing.T, sqlStore *store.SQLStore, database, filestore string) *model.Installation {
+		t.Helper()
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Database:  database,
+			Filestore: filestore,
+			State:     model.InstallationStateStable,
+		}
+		err := sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		return installation
+	}
+
+	createBackup := func(t *testing.T, sqlStore *store.SQLStore, installationID, state string) *model.InstallationBackup {
+		t.Helper()
+		backup := &model.InstallationBackup{
+			InstallationID: installationID,
+			State:          state,
+		}
+		err := sqlStore.CreateInstallationBackup(backup)
+		require.NoError(t, err)
+
+		return backup
+	}
+
+	t.Run("backup requested", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, &mockInstallationBackupProvisioner{}, "instanceID", logger)
+
+		installation := createInstallation(t, sqlStore, model.InstallationDatabaseAwsRDS, model.InstallationFilestoreAwsS3)
+		backup := createBackup(t, sqlStore, installation.ID, model.InstallationBackupStateBackupRequested)
+
+		supervisor.Supervise(backup)
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupInProgress)
+
+		backup, err := sqlStore.GetInstallationBackup(backup.ID)
+		require.NoError(t, err)
+		require.Equal(t, "snapshot-"+backup.ID, backup.DatabaseSnapshotID)
+		require.Equal(t, "bucket-"+installation.ID, backup.FilestoreBucket)
+		require.Equal(t, backup.ID+"/", backup.FilestorePrefix)
+	})
+
+	t.Run("backup requested, unsupported installation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, &mockInstallationBackupProvisioner{}, "instanceID", logger)
+
+		installation := createInstallation(t, sqlStore, model.InstallationDatabaseMysqlOperator, model.InstallationFilestoreMinioOperator)
+		backup := createBackup(t, sqlStore, installation.ID, model.InstallationBackupStateBackupRequested)
+
+		supervisor.Supervise(backup)
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupFailed)
+	})
+
+	t.Run("backup requested, provisioner error", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationBackupProvisioner{CreateError: errors.New("snapshot failed")}
+		supervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, provisioner, "instanceID", logger)
+
+		installation := createInstallation(t, sqlStore, model.InstallationDatabaseAwsRDS, model.InstallationFilestoreAwsS3)
+		backup := createBackup(t, sqlStore, installation.ID, model.InstallationBackupStateBackupRequested)
+
+		supervisor.Supervise(backup)
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupFailed)
+	})
+
+	t.Run("backup in progress, not complete", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, &mockInstallationBackupProvisioner{}, "instanceID", logger)
+
+		installation := createInstallation(t, sqlStore, model.InstallationDatabaseAwsRDS, model.InstallationFilestoreAwsS3)
+		backup := createBackup(t, sqlStore, installation.ID, model.InstallationBackupStateBackupInProgress)
+
+		supervisor.Supervise(backup)
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupInProgress)
+
+		backup, err := sqlStore.GetInstallationBackup(backup.ID)
+		require.NoError(t, err)
+		require.Equal(t, "data/file", backup.FilestoreCopyMarker)
+	})
+
+	t.Run("backup in progress, complete", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, &mockInstallationBackupProvisioner{Complete: true}, "instanceID", logger)
+
+		installation := createInstallation(t, sqlStore, model.InstallationDatabaseAwsRDS, model.InstallationFilestoreAwsS3)
+		backup := createBackup(t, sqlStore, installation.ID, model.InstallationBackupStateBackupInProgress)
+
+		supervisor.Supervise(backup)
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupSucceeded)
+	})
+
+	t.Run("backup in progress, status error", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationBackupProvisioner{StatusError: errors.New("snapshot failed")}
+		supervisor := supervisor.NewInstallationBackupSupervisor(sqlStore, provisioner, "instanceID", logger)
+
+		installation := createInstallation(t, sqlStore, model.InstallationDatabaseAwsRDS, model.InstallationFilestoreAwsS3)
+		backup := createBackup(t, sqlStore, installation.ID, model.InstallationBackupStateBackupInProgress)
+
+		supervisor.Supervise(backup)
+		expectBackupState(t, sqlStore, backup, model.InstallationBackupStateBackupFailed)
+	})
+}
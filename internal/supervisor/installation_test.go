@@ -3,6 +3,7 @@ package supervisor_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/acm"
 	"github.com/mattermost/mattermost-cloud/internal/store"
@@ -13,6 +14,7 @@ import (
 	"github.com/mattermost/mattermost-cloud/internal/tools/utils"
 	"github.com/mattermost/mattermost-cloud/model"
 	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 )
@@ -58,11 +60,27 @@ func (s *mockInstallationStore) UpdateInstallationGroupSequence(installation *mo
 	return nil
 }
 
+func (s *mockInstallationStore) UpdateInstallationDNSRecords(installation *model.Installation) error {
+	return nil
+}
+
 func (s *mockInstallationStore) UpdateInstallationState(installation *model.Installation) error {
 	s.UpdateInstallationCalls++
 	return nil
 }
 
+func (s *mockInstallationStore) UpdateInstallationSize(installation *model.Installation) error {
+	return nil
+}
+
+func (s *mockInstallationStore) UpdateInstallationAttempts(installation *model.Installation) error {
+	return nil
+}
+
+func (s *mockInstallationStore) UpdateInstallationRestoreProgress(installation *model.Installation) error {
+	return nil
+}
+
 func (s *mockInstallationStore) LockInstallation(installationID, lockerID string) (bool, error) {
 	return true, nil
 }
@@ -78,6 +96,10 @@ func (s *mockInstallationStore) DeleteInstallation(installationID string) error
 	return nil
 }
 
+func (s *mockInstallationStore) GetInstallationBackup(backupID string) (*model.InstallationBackup, error) {
+	return nil, nil
+}
+
 func (s *mockInstallationStore) CreateClusterInstallation(clusterInstallation *model.ClusterInstallation) error {
 	return nil
 }
@@ -106,9 +128,21 @@ func (s *mockInstallationStore) GetWebhooks(filter *model.WebhookFilter) ([]*mod
 	return nil, nil
 }
 
+func (s *mockInstallationStore) CreateEvent(event *model.Event) error {
+	return nil
+}
+
+func (s *mockInstallationStore) CreateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	return nil
+}
+
 type mockInstallationProvisioner struct {
 	UseCustomClusterResources bool
 	CustomClusterResources    *k8s.ClusterResources
+	ClusterInstallationSize   string
+	HibernationPending        bool
+	Hibernated                bool
+	ClusterResourcesRequested int
 }
 
 func (p *mockInstallationProvisioner) CreateClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, awsClient aws.AWS) error {
@@ -116,16 +150,27 @@ func (p *mockInstallationProvisioner) CreateClusterInstallation(cluster *model.C
 }
 
 func (p *mockInstallationProvisioner) UpdateClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error {
+	p.Hibernated = false
 	return nil
 }
 
+func (p *mockInstallationProvisioner) HibernateClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) (bool, error) {
+	return !p.HibernationPending, nil
+}
+
 func (p *mockInstallationProvisioner) DeleteClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) error {
 	return nil
 }
 
 func (p *mockInstallationProvisioner) GetClusterInstallationResource(cluster *model.Cluster, installation *model.Installation, clusterIntallation *model.ClusterInstallation) (*mmv1alpha1.ClusterInstallation, error) {
+	if p.Hibernated {
+		return nil, errors.New("cluster installation not found")
+	}
+
 	return &mmv1alpha1.ClusterInstallation{
-			Spec: mmv1alpha1.ClusterInstallationSpec{},
+			Spec: mmv1alpha1.ClusterInstallationSpec{
+				Size: p.ClusterInstallationSize,
+			},
 			Status: mmv1alpha1.ClusterInstallationStatus{
 				State:    mmv1alpha1.Stable,
 				Endpoint: "example-dns.mattermost.cloud",
@@ -134,7 +179,8 @@ func (p *mockInstallationProvisioner) GetClusterInstallationResource(cluster *mo
 		nil
 }
 
-func (p *mockInstallationProvisioner) GetClusterResources(cluster *model.Cluster, onlySchedulable bool) (*k8s.ClusterResources, error) {
+func (p *mockInstallationProvisioner) GetClusterResources(cluster *model.Cluster, nodePool string, onlySchedulable bool) (*k8s.ClusterResources, error) {
+	p.ClusterResourcesRequested++
 	if p.UseCustomClusterResources {
 		return p.CustomClusterResources, nil
 	}
@@ -208,6 +254,15 @@ func (a *mockAWS) SecretsManagerGetIAMAccessKey(installationID string, logger lo
 	return nil, nil
 }
 
+// failingDNSAWS fails to create any public DNS records.
+type failingDNSAWS struct {
+	mockAWS
+}
+
+func (a *failingDNSAWS) CreatePublicCNAME(dnsName string, dnsEndpoints []string, logger log.FieldLogger) error {
+	return errors.New("route53 unavailable")
+}
+
 func TestInstallationSupervisorDo(t *testing.T) {
 	t.Run("no clusters pending work", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
@@ -372,6 +427,107 @@ func TestInstallationSupervisor(t *testing.T) {
 		expectClusterInstallations(t, sqlStore, installation, 0, "")
 	})
 
+	t.Run("creation requested, cluster installations not yet created, cluster lacks node pool", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			NodePool: "memory",
+			GroupID:  &groupID,
+			State:    model.InstallationStateCreationRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationNoCompatibleClusters)
+		expectClusterInstallations(t, sqlStore, installation, 0, "")
+	})
+
+	t.Run("creation requested, cluster installations not yet created, cluster with node pool", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := cluster.SetProvisionerMetadata(model.KopsMetadata{
+			InstanceGroups: []model.KopsInstanceGroup{{Name: "memory", MachineType: "r5.large", MinCount: 1, MaxCount: 1}},
+		})
+		require.NoError(t, err)
+		err = sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			NodePool: "memory",
+			GroupID:  &groupID,
+			State:    model.InstallationStateCreationRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
+	})
+
+	t.Run("creation requested, cluster installations not yet created, several clusters fit", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		mockInstallationProvisioner := &mockInstallationProvisioner{}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster1 := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster1)
+		require.NoError(t, err)
+		time.Sleep(1 * time.Millisecond)
+		cluster2 := standardStableTestCluster()
+		err = sqlStore.CreateCluster(cluster2)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:  model.NewID(),
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityMultiTenant,
+			State:    model.InstallationStateCreationRequested,
+		}
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
+
+		// With the first-fit strategy, only the first cluster is evaluated, and
+		// only once.
+		clusterInstallations, err := sqlStore.GetClusterInstallations(&model.ClusterInstallationFilter{InstallationID: installation.ID, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, cluster1.ID, clusterInstallations[0].ClusterID)
+		require.Equal(t, 1, mockInstallationProvisioner.ClusterResourcesRequested)
+	})
+
 	t.Run("creation requested, cluster installations not yet created, no empty clusters", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
@@ -750,6 +906,78 @@ func TestInstallationSupervisor(t *testing.T) {
 		supervisor.Supervise(installation)
 		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
 		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"dns.example.com"}, installation.DNSRecords)
+
+		events, err := sqlStore.GetEvents(&model.EventFilter{ResourceID: installation.ID, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, model.TypeInstallation, events[0].ResourceType)
+		require.Equal(t, model.InstallationStateCreationDNS, events[0].OldState)
+		require.Equal(t, model.InstallationStateStable, events[0].NewState)
+		require.Equal(t, "instanceID", events[0].InstanceID)
+		require.Empty(t, events[0].ErrorMessage)
+	})
+
+	t.Run("creation dns requested, dns keeps failing", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		failingSupervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &failingDNSAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:  model.NewID(),
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			State:    model.InstallationStateCreationDNS,
+		}
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		failingSupervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationDNS)
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Equal(t, 1, installation.Attempts)
+		require.Contains(t, installation.LastError, "route53 unavailable")
+		require.Greater(t, installation.NextAttemptAt, store.GetMillis())
+
+		// The installation is backed off rather than picked up on the next poll.
+		installations, err := sqlStore.GetUnlockedInstallationsPendingWork()
+		require.NoError(t, err)
+		require.Empty(t, installations)
+
+		failingSupervisor.Supervise(installation)
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Equal(t, 2, installation.Attempts)
+
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Equal(t, 0, installation.Attempts)
+		require.Empty(t, installation.LastError)
+		require.Zero(t, installation.NextAttemptAt)
 	})
 
 	t.Run("no compatible clusters, cluster installations not yet created, no clusters", func(t *testing.T) {
@@ -920,6 +1148,269 @@ func TestInstallationSupervisor(t *testing.T) {
 		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
 	})
 
+	t.Run("upgrade in progress, dns changed", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:    owner,
+			Version:    "version",
+			DNS:        "new-dns.example.com",
+			DNSAliases: []string{"alias.example.com"},
+			DNSRecords: []string{"dns.example.com"},
+			Size:       mmv1alpha1.Size100String,
+			Affinity:   model.InstallationAffinityIsolated,
+			GroupID:    &groupID,
+			State:      model.InstallationStateUpdateInProgress,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"new-dns.example.com", "alias.example.com"}, installation.DNSRecords)
+		require.Empty(t, installation.StaleDNSRecords())
+	})
+
+	t.Run("upgrade requested, resize exceeds cluster resources", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		mockInstallationProvisioner := &mockInstallationProvisioner{
+			UseCustomClusterResources: true,
+			CustomClusterResources: &k8s.ClusterResources{
+				MilliTotalCPU:    200,
+				MilliUsedCPU:     100,
+				MilliTotalMemory: 200,
+				MilliUsedMemory:  100,
+			},
+			ClusterInstallationSize: mmv1alpha1.Size100String,
+		}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size1000String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateUpdateRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateUpdateFailed)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Equal(t, mmv1alpha1.Size100String, installation.Size)
+		require.Contains(t, installation.LastError, "would exceed the cluster load threshold")
+	})
+
+	t.Run("hibernation requested, cluster installations stable", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateHibernationRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateHibernating)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+	})
+
+	t.Run("hibernation in progress, pods still terminating", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationProvisioner{HibernationPending: true}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateHibernationRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateHibernationInProgress)
+
+		provisioner.HibernationPending = false
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateHibernating)
+	})
+
+	t.Run("wake up requested, cluster installations stable", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateWakeUpRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateUpdateInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
+	})
+
+	t.Run("wake up requested, cluster installation resources hibernated", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationProvisioner{Hibernated: true}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:  model.NewID(),
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			State:    model.InstallationStateWakeUpRequested,
+		}
+		err = sqlStore.CreateInstallation(installation)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateUpdateInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
+		require.False(t, provisioner.Hibernated)
+
+		clusterInstallation.State = model.ClusterInstallationStateStable
+		err = sqlStore.UpdateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+	})
+
 	t.Run("deletion requested, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
@@ -1264,4 +1755,274 @@ func TestInstallationSupervisor(t *testing.T) {
 			expectClusterInstallationsOnCluster(t, sqlStore, cluster, 0)
 		})
 	})
+
+	t.Run("migration", func(t *testing.T) {
+		createMigratingInstallation := func(t *testing.T, sqlStore *store.SQLStore, targetClusterID *string, state string) *model.Installation {
+			t.Helper()
+			installation := &model.Installation{
+				OwnerID:                  model.NewID(),
+				Version:                  "version",
+				DNS:                      "dns.example.com",
+				Size:                     mmv1alpha1.Size100String,
+				Affinity:                 model.InstallationAffinityIsolated,
+				Database:                 model.InstallationDatabaseMysqlOperator,
+				Filestore:                model.InstallationFilestoreMinioOperator,
+				MigrationTargetClusterID: targetClusterID,
+				State:                    state,
+			}
+			err := sqlStore.CreateInstallation(installation)
+			require.NoError(t, err)
+
+			return installation
+		}
+
+		createClusterInstallation := func(t *testing.T, sqlStore *store.SQLStore, cluster *model.Cluster, installation *model.Installation, state string) *model.ClusterInstallation {
+			t.Helper()
+			clusterInstallation := &model.ClusterInstallation{
+				ClusterID:      cluster.ID,
+				InstallationID: installation.ID,
+				Namespace:      installation.ID,
+				State:          state,
+			}
+			err := sqlStore.CreateClusterInstallation(clusterInstallation)
+			require.NoError(t, err)
+
+			return clusterInstallation
+		}
+
+		t.Run("migration requested, no target cluster", func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+			sourceCluster := standardStableTestCluster()
+			err := sqlStore.CreateCluster(sourceCluster)
+			require.NoError(t, err)
+
+			targetClusterID := model.NewID()
+			installation := createMigratingInstallation(t, sqlStore, &targetClusterID, model.InstallationStateMigrationRequested)
+			createClusterInstallation(t, sqlStore, sourceCluster, installation, model.ClusterInstallationStateStable)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationFailed)
+			expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+
+			events, err := sqlStore.GetEvents(&model.EventFilter{ResourceID: installation.ID, PerPage: model.AllPerPage})
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+			require.Equal(t, model.InstallationStateMigrationFailed, events[0].NewState)
+			require.NotEmpty(t, events[0].ErrorMessage)
+		})
+
+		t.Run("migration requested, unsupported installation", func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+			sourceCluster := standardStableTestCluster()
+			err := sqlStore.CreateCluster(sourceCluster)
+			require.NoError(t, err)
+
+			targetCluster := standardStableTestCluster()
+			err = sqlStore.CreateCluster(targetCluster)
+			require.NoError(t, err)
+
+			installation := createMigratingInstallation(t, sqlStore, &targetCluster.ID, model.InstallationStateMigrationRequested)
+			createClusterInstallation(t, sqlStore, sourceCluster, installation, model.ClusterInstallationStateStable)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationFailed)
+			expectClusterInstallationsOnCluster(t, sqlStore, targetCluster, 0)
+		})
+
+		t.Run("migration requested, target cluster installation already created", func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+			sourceCluster := standardStableTestCluster()
+			err := sqlStore.CreateCluster(sourceCluster)
+			require.NoError(t, err)
+
+			targetCluster := standardStableTestCluster()
+			err = sqlStore.CreateCluster(targetCluster)
+			require.NoError(t, err)
+
+			installation := createMigratingInstallation(t, sqlStore, &targetCluster.ID, model.InstallationStateMigrationRequested)
+			createClusterInstallation(t, sqlStore, sourceCluster, installation, model.ClusterInstallationStateStable)
+			createClusterInstallation(t, sqlStore, targetCluster, installation, model.ClusterInstallationStateCreationRequested)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationInProgress)
+		})
+
+		t.Run("migration requested, source cluster installation still stopping", func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			provisioner := &mockInstallationProvisioner{HibernationPending: true}
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+			sourceCluster := standardStableTestCluster()
+			err := sqlStore.CreateCluster(sourceCluster)
+			require.NoError(t, err)
+
+			targetCluster := standardStableTestCluster()
+			err = sqlStore.CreateCluster(targetCluster)
+			require.NoError(t, err)
+
+			installation := createMigratingInstallation(t, sqlStore, &targetCluster.ID, model.InstallationStateMigrationRequested)
+			installation.Database = model.InstallationDatabaseAwsRDS
+			installation.Filestore = model.InstallationFilestoreAwsS3
+			err = sqlStore.UpdateInstallation(installation)
+			require.NoError(t, err)
+			createClusterInstallation(t, sqlStore, sourceCluster, installation, model.ClusterInstallationStateStable)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationRequested)
+			expectClusterInstallationsOnCluster(t, sqlStore, targetCluster, 0)
+		})
+
+		t.Run("migration in progress, target cluster installation failed", func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+			sourceCluster := standardStableTestCluster()
+			err := sqlStore.CreateCluster(sourceCluster)
+			require.NoError(t, err)
+
+			targetCluster := standardStableTestCluster()
+			err = sqlStore.CreateCluster(targetCluster)
+			require.NoError(t, err)
+
+			installation := createMigratingInstallation(t, sqlStore, &targetCluster.ID, model.InstallationStateMigrationInProgress)
+			sourceClusterInstallation := createClusterInstallation(t, sqlStore, sourceCluster, installation, model.ClusterInstallationStateStable)
+			createClusterInstallation(t, sqlStore, targetCluster, installation, model.ClusterInstallationStateCreationFailed)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationFailed)
+
+			sourceClusterInstallation, err = sqlStore.GetClusterInstallation(sourceClusterInstallation.ID)
+			require.NoError(t, err)
+			require.Equal(t, model.ClusterInstallationStateReconciling, sourceClusterInstallation.State)
+		})
+
+		t.Run("migration in progress, target cluster installation failed, source cluster installation stopped", func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			provisioner := &mockInstallationProvisioner{Hibernated: true}
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+			sourceCluster := standardStableTestCluster()
+			err := sqlStore.CreateCluster(sourceCluster)
+			require.NoError(t, err)
+
+			targetCluster := standardStableTestCluster()
+			err = sqlStore.CreateCluster(targetCluster)
+			require.NoError(t, err)
+
+			installation := createMigratingInstallation(t, sqlStore, &targetCluster.ID, model.InstallationStateMigrationInProgress)
+			sourceClusterInstallation := createClusterInstallation(t, sqlStore, sourceCluster, installation, model.ClusterInstallationStateStable)
+			createClusterInstallation(t, sqlStore, targetCluster, installation, model.ClusterInstallationStateCreationFailed)
+
+			t.Run("source cluster installation locked", func(t *testing.T) {
+				locked, err := sqlStore.LockClusterInstallations([]string{sourceClusterInstallation.ID}, "otherInstanceID")
+				require.NoError(t, err)
+				require.True(t, locked)
+
+				supervisor.Supervise(installation)
+				expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationInProgress)
+				require.True(t, provisioner.Hibernated)
+
+				unlocked, err := sqlStore.UnlockClusterInstallations([]string{sourceClusterInstallation.ID}, "otherInstanceID", false)
+				require.NoError(t, err)
+				require.True(t, unlocked)
+			})
+
+			t.Run("source cluster installation restored", func(t *testing.T) {
+				supervisor.Supervise(installation)
+				expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationFailed)
+				require.False(t, provisioner.Hibernated)
+
+				sourceClusterInstallation, err = sqlStore.GetClusterInstallation(sourceClusterInstallation.ID)
+				require.NoError(t, err)
+				require.Equal(t, model.ClusterInstallationStateReconciling, sourceClusterInstallation.State)
+			})
+		})
+
+		t.Run("migration in progress, target cluster installation stable", func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+			sourceCluster := standardStableTestCluster()
+			err := sqlStore.CreateCluster(sourceCluster)
+			require.NoError(t, err)
+
+			targetCluster := standardStableTestCluster()
+			err = sqlStore.CreateCluster(targetCluster)
+			require.NoError(t, err)
+
+			installation := createMigratingInstallation(t, sqlStore, &targetCluster.ID, model.InstallationStateMigrationInProgress)
+			sourceClusterInstallation := createClusterInstallation(t, sqlStore, sourceCluster, installation, model.ClusterInstallationStateStable)
+			targetClusterInstallation := createClusterInstallation(t, sqlStore, targetCluster, installation, model.ClusterInstallationStateStable)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationCleanup)
+
+			sourceClusterInstallation, err = sqlStore.GetClusterInstallation(sourceClusterInstallation.ID)
+			require.NoError(t, err)
+			require.Equal(t, model.ClusterInstallationStateDeletionRequested, sourceClusterInstallation.State)
+
+			targetClusterInstallation, err = sqlStore.GetClusterInstallation(targetClusterInstallation.ID)
+			require.NoError(t, err)
+			require.Equal(t, model.ClusterInstallationStateStable, targetClusterInstallation.State)
+		})
+
+		t.Run("migration cleanup, source cluster installation deleted", func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+			sourceCluster := standardStableTestCluster()
+			err := sqlStore.CreateCluster(sourceCluster)
+			require.NoError(t, err)
+
+			targetCluster := standardStableTestCluster()
+			err = sqlStore.CreateCluster(targetCluster)
+			require.NoError(t, err)
+
+			installation := createMigratingInstallation(t, sqlStore, &targetCluster.ID, model.InstallationStateMigrationCleanup)
+			sourceClusterInstallation := createClusterInstallation(t, sqlStore, sourceCluster, installation, model.ClusterInstallationStateDeleted)
+			err = sqlStore.DeleteClusterInstallation(sourceClusterInstallation.ID)
+			require.NoError(t, err)
+			createClusterInstallation(t, sqlStore, targetCluster, installation, model.ClusterInstallationStateStable)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+			expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+			expectClusterInstallationsOnCluster(t, sqlStore, targetCluster, 1)
+		})
+
+		t.Run("migration cleanup, source cluster installation deletion failed", func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, false, false, &utils.ResourceUtil{}, logger)
+
+			sourceCluster := standardStableTestCluster()
+			err := sqlStore.CreateCluster(sourceCluster)
+			require.NoError(t, err)
+
+			targetCluster := standardStableTestCluster()
+			err = sqlStore.CreateCluster(targetCluster)
+			require.NoError(t, err)
+
+			installation := createMigratingInstallation(t, sqlStore, &targetCluster.ID, model.InstallationStateMigrationCleanup)
+			createClusterInstallation(t, sqlStore, sourceCluster, installation, model.ClusterInstallationStateDeletionFailed)
+			createClusterInstallation(t, sqlStore, targetCluster, installation, model.ClusterInstallationStateStable)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateMigrationFailed)
+		})
+	})
 }
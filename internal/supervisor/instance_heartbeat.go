@@ -0,0 +1,58 @@
+package supervisor
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// InstanceHeartbeatInterval is the interval at which a provisioning server
+// instance should record a heartbeat.
+const InstanceHeartbeatInterval = 15 * time.Second
+
+// instanceHeartbeatTimeout is the time after its last heartbeat at which an
+// instance is considered to no longer be running.
+const instanceHeartbeatTimeout = 4 * InstanceHeartbeatInterval
+
+// instanceHeartbeatStore abstracts the database operations required to record
+// instance heartbeats.
+type instanceHeartbeatStore interface {
+	HeartbeatInstance(instance *model.Instance) error
+}
+
+// InstanceHeartbeat records that the provisioning server instance is alive,
+// allowing other instances to tell its locks apart from those left behind by
+// instances that are no longer running, and to partition work only with the
+// instances running the same supervisors.
+type InstanceHeartbeat struct {
+	store    instanceHeartbeatStore
+	instance *model.Instance
+	logger   log.FieldLogger
+}
+
+// NewInstanceHeartbeat creates a new InstanceHeartbeat.
+func NewInstanceHeartbeat(store instanceHeartbeatStore, instanceID string, logger log.FieldLogger) *InstanceHeartbeat {
+	return &InstanceHeartbeat{
+		store:    store,
+		instance: &model.Instance{ID: instanceID},
+		logger:   logger,
+	}
+}
+
+// SetPartitionedSupervisors records the supervisors whose work the instance
+// partitions with the other instances running them. It must be called before
+// the heartbeat is first recorded.
+func (h *InstanceHeartbeat) SetPartitionedSupervisors(supervisors []string) {
+	h.instance.PartitionedSupervisors = supervisors
+}
+
+// Do records a heartbeat for the instance.
+func (h *InstanceHeartbeat) Do() error {
+	err := h.store.HeartbeatInstance(h.instance)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to record instance heartbeat")
+	}
+
+	return nil
+}
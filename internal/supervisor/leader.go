@@ -0,0 +1,109 @@
+package supervisor
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// LeaderLease is the name of the lease held by the leader instance.
+const LeaderLease = "leader"
+
+// LeaderLeaseRenewInterval is the interval at which the leader lease should
+// be acquired or renewed.
+const LeaderLeaseRenewInterval = 10 * time.Second
+
+// leaderLeaseDuration is the time for which the leader lease is held without
+// being renewed, after which another instance may take over.
+const leaderLeaseDuration = 3 * LeaderLeaseRenewInterval
+
+// leaderElectionStore abstracts the database operations required to elect a leader.
+type leaderElectionStore interface {
+	AcquireLease(name, holderID string, duration time.Duration) (bool, error)
+	ReleaseLease(name, holderID string) error
+}
+
+// LeaderElection elects a single leader among the running provisioning server
+// instances, allowing work that must not run concurrently on several instances
+// to be restricted to the leader.
+type LeaderElection struct {
+	store      leaderElectionStore
+	instanceID string
+	logger     log.FieldLogger
+	leader     int32
+}
+
+// NewLeaderElection creates a new LeaderElection.
+func NewLeaderElection(store leaderElectionStore, instanceID string, logger log.FieldLogger) *LeaderElection {
+	return &LeaderElection{
+		store:      store,
+		instanceID: instanceID,
+		logger:     logger,
+	}
+}
+
+// Do acquires or renews the leader lease for the instance.
+func (l *LeaderElection) Do() error {
+	acquired, err := l.store.AcquireLease(LeaderLease, l.instanceID, leaderLeaseDuration)
+	if err != nil {
+		l.logger.WithError(err).Warn("Failed to acquire leader lease")
+		// Step down rather than risk two leaders if the lease can't be renewed.
+		acquired = false
+	}
+
+	l.setLeader(acquired)
+
+	return nil
+}
+
+// IsLeader returns whether the instance currently holds the leader lease.
+func (l *LeaderElection) IsLeader() bool {
+	return atomic.LoadInt32(&l.leader) == 1
+}
+
+// Resign releases the leader lease if held, allowing another instance to take
+// over without waiting for the lease to expire.
+func (l *LeaderElection) Resign() error {
+	l.setLeader(false)
+
+	return l.store.ReleaseLease(LeaderLease, l.instanceID)
+}
+
+// Only wraps the given doer to only be invoked while the instance is the leader.
+func (l *LeaderElection) Only(doer Doer) Doer {
+	return &leaderOnlyDoer{doer: doer, election: l}
+}
+
+func (l *LeaderElection) setLeader(isLeader bool) {
+	var leader int32
+	if isLeader {
+		leader = 1
+	}
+
+	previous := atomic.SwapInt32(&l.leader, leader)
+	if previous != leader {
+		if isLeader {
+			l.logger.Info("Acquired leader lease")
+		} else {
+			l.logger.Info("Lost leader lease")
+		}
+	}
+	metrics.SetLeader(isLeader)
+}
+
+// leaderOnlyDoer invokes the wrapped doer only while the instance is the leader.
+type leaderOnlyDoer struct {
+	doer     Doer
+	election *LeaderElection
+}
+
+// Do invokes the wrapped doer if the instance is the leader.
+func (d *leaderOnlyDoer) Do() error {
+	if !d.election.IsLeader() {
+		return nil
+	}
+
+	return d.doer.Do()
+}
@@ -0,0 +1,66 @@
+package supervisor_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderElection(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	election1 := supervisor.NewLeaderElection(sqlStore, "instance1", logger)
+	election2 := supervisor.NewLeaderElection(sqlStore, "instance2", logger)
+
+	require.False(t, election1.IsLeader())
+	require.False(t, election2.IsLeader())
+
+	err := election1.Do()
+	require.NoError(t, err)
+	err = election2.Do()
+	require.NoError(t, err)
+
+	require.True(t, election1.IsLeader())
+	require.False(t, election2.IsLeader())
+
+	t.Run("leader only", func(t *testing.T) {
+		d1 := &testDoer{calls: make(chan bool, 1)}
+		d2 := &testDoer{calls: make(chan bool, 1)}
+
+		err := election1.Only(d1).Do()
+		require.NoError(t, err)
+		err = election2.Only(d2).Do()
+		require.NoError(t, err)
+
+		require.Len(t, d1.calls, 1)
+		require.Len(t, d2.calls, 0)
+	})
+
+	t.Run("renew", func(t *testing.T) {
+		err := election1.Do()
+		require.NoError(t, err)
+		err = election2.Do()
+		require.NoError(t, err)
+
+		require.True(t, election1.IsLeader())
+		require.False(t, election2.IsLeader())
+	})
+
+	t.Run("resign", func(t *testing.T) {
+		err := election1.Resign()
+		require.NoError(t, err)
+		require.False(t, election1.IsLeader())
+
+		err = election2.Do()
+		require.NoError(t, err)
+		err = election1.Do()
+		require.NoError(t, err)
+
+		require.False(t, election1.IsLeader())
+		require.True(t, election2.IsLeader())
+	})
+}
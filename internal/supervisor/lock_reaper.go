@@ -0,0 +1,102 @@
+package supervisor
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// lockReaperStore abstracts the database operations required to release stale locks.
+type lockReaperStore interface {
+	GetLocks(filter *model.LockFilter) ([]*model.Lock, error)
+	UnlockResource(resourceType, resourceID, lockerID string, force bool) (bool, error)
+
+	GetInstances() ([]*model.Instance, error)
+	DeleteInstancesHeartbeatBefore(cutoff int64) (int64, error)
+}
+
+// LockReaper releases locks left behind by provisioning server instances that
+// are no longer running, such as after a crash.
+//
+// A lock is considered stale once it has been held for longer than the lock
+// TTL by a locker that is not a running instance. This also covers locks held
+// by API requests, which are acquired with the request ID.
+type LockReaper struct {
+	store      lockReaperStore
+	instanceID string
+	lockTTL    time.Duration
+	logger     log.FieldLogger
+}
+
+// NewLockReaper creates a new LockReaper.
+func NewLockReaper(store lockReaperStore, instanceID string, lockTTL time.Duration, logger log.FieldLogger) *LockReaper {
+	return &LockReaper{
+		store:      store,
+		instanceID: instanceID,
+		lockTTL:    lockTTL,
+		logger:     logger,
+	}
+}
+
+// Do releases any stale locks.
+func (r *LockReaper) Do() error {
+	defer metrics.ObserveSupervisorDo("lock_reaper", time.Now())
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	instances, err := r.store.GetInstances()
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to query for instances")
+		return nil
+	}
+
+	heartbeatCutoff := now - instanceHeartbeatTimeout.Milliseconds()
+	runningInstances := map[string]bool{r.instanceID: true}
+	for _, instance := range instances {
+		if instance.LastHeartbeatAt >= heartbeatCutoff {
+			runningInstances[instance.ID] = true
+		}
+	}
+
+	locks, err := r.store.GetLocks(&model.LockFilter{
+		AcquiredBefore: now - r.lockTTL.Milliseconds(),
+	})
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to query for locks")
+		return nil
+	}
+
+	for _, lock := range locks {
+		if runningInstances[lock.LockAcquiredBy] {
+			continue
+		}
+
+		logger := r.logger.WithFields(log.Fields{
+			"resource-type": lock.ResourceType,
+			"resource":      lock.ResourceID,
+			"locker":        lock.LockAcquiredBy,
+		})
+
+		// Only release the lock if it is still held by the same locker, in
+		// case it was released and acquired again since being queried.
+		unlocked, err := r.store.UnlockResource(lock.ResourceType, lock.ResourceID, lock.LockAcquiredBy, false)
+		if err != nil {
+			logger.WithError(err).Error("Failed to release stale lock")
+			continue
+		}
+		if unlocked {
+			logger.Warnf("Released stale lock acquired %s ago", time.Duration(now-lock.LockAcquiredAt)*time.Millisecond)
+		}
+	}
+
+	deleted, err := r.store.DeleteInstancesHeartbeatBefore(heartbeatCutoff)
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to delete stopped instances")
+	} else if deleted > 0 {
+		r.logger.Infof("Deleted %d stopped instance(s)", deleted)
+	}
+
+	return nil
+}
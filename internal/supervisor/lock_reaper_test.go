@@ -0,0 +1,73 @@
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockReaper(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	// The running instance records heartbeats, while the stopped one never did.
+	err := supervisor.NewInstanceHeartbeat(sqlStore, "running-instance", logger).Do()
+	require.NoError(t, err)
+
+	createLockedCluster := func(t *testing.T, lockerID string) *model.Cluster {
+		t.Helper()
+		cluster := &model.Cluster{}
+		err := sqlStore.CreateCluster(cluster)
+		require.NoError(t, err)
+
+		locked, err := sqlStore.LockCluster(cluster.ID, lockerID)
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		return cluster
+	}
+
+	isLocked := func(t *testing.T, cluster *model.Cluster) bool {
+		t.Helper()
+		cluster, err := sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+
+		return cluster.LockAcquiredAt != 0
+	}
+
+	stoppedInstanceCluster := createLockedCluster(t, "stopped-instance")
+	runningInstanceCluster := createLockedCluster(t, "running-instance")
+	reaperInstanceCluster := createLockedCluster(t, "reaper-instance")
+
+	t.Run("locks within ttl", func(t *testing.T) {
+		reaper := supervisor.NewLockReaper(sqlStore, "reaper-instance", time.Hour, logger)
+		err := reaper.Do()
+		require.NoError(t, err)
+
+		require.True(t, isLocked(t, stoppedInstanceCluster))
+		require.True(t, isLocked(t, runningInstanceCluster))
+		require.True(t, isLocked(t, reaperInstanceCluster))
+	})
+
+	t.Run("stale locks", func(t *testing.T) {
+		time.Sleep(5 * time.Millisecond)
+
+		reaper := supervisor.NewLockReaper(sqlStore, "reaper-instance", time.Millisecond, logger)
+		err := reaper.Do()
+		require.NoError(t, err)
+
+		require.False(t, isLocked(t, stoppedInstanceCluster))
+		require.True(t, isLocked(t, runningInstanceCluster))
+		require.True(t, isLocked(t, reaperInstanceCluster))
+
+		instances, err := sqlStore.GetInstances()
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+		require.Equal(t, "running-instance", instances[0].ID)
+	})
+}
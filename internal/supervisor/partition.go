@@ -0,0 +1,138 @@
+package supervisor
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// Partitioner decides which resources an instance is responsible for supervising.
+type Partitioner interface {
+	Owns(resourceID string) bool
+}
+
+// Partitionable describes a doer whose work can be partitioned between instances.
+type Partitionable interface {
+	SetPartitioner(partitioner Partitioner)
+}
+
+// partitioned allows a supervisor's work to be partitioned between instances.
+type partitioned struct {
+	partitioner Partitioner
+}
+
+// SetPartitioner restricts the supervisor to the resources owned by the instance
+// according to the given partitioner. It must be called before the supervisor
+// is first invoked.
+func (p *partitioned) SetPartitioner(partitioner Partitioner) {
+	p.partitioner = partitioner
+}
+
+// owns returns whether the supervisor is responsible for the given resource.
+func (p *partitioned) owns(resourceID string) bool {
+	return p.partitioner == nil || p.partitioner.Owns(resourceID)
+}
+
+// instancePartitionerStore abstracts the database operations required to partition work.
+type instancePartitionerStore interface {
+	GetInstances() ([]*model.Instance, error)
+}
+
+// InstancePartitioner partitions the resources of a supervisor between the
+// running provisioning server instances partitioning the work of that
+// supervisor, using rendezvous hashing of the resource ID, so that each
+// resource is supervised by a single instance and only the resources of an
+// instance joining or leaving move to another instance. Instances that don't
+// run the supervisor, or don't partition its work, are never given any of its
+// resources.
+//
+// Instances may briefly disagree on ownership while an instance joins or
+// leaves, so supervisors still lock resources before working on them.
+type InstancePartitioner struct {
+	store      instancePartitionerStore
+	instanceID string
+	supervisor string
+	logger     log.FieldLogger
+
+	mu          sync.Mutex
+	instanceIDs []string
+	refreshedAt time.Time
+}
+
+// NewInstancePartitioner creates a new InstancePartitioner for the given
+// supervisor, which must be among the partitioned supervisors recorded with the
+// heartbeat of the instance.
+func NewInstancePartitioner(store instancePartitionerStore, instanceID, supervisor string, logger log.FieldLogger) *InstancePartitioner {
+	return &InstancePartitioner{
+		store:       store,
+		instanceID:  instanceID,
+		supervisor:  supervisor,
+		logger:      logger.WithField("partitioned-supervisor", supervisor),
+		instanceIDs: []string{instanceID},
+	}
+}
+
+// Owns returns whether the instance is responsible for the given resource.
+func (p *InstancePartitioner) Owns(resourceID string) bool {
+	instanceIDs := p.runningInstanceIDs()
+
+	var owner string
+	var highest uint64
+	for _, instanceID := range instanceIDs {
+		weight := rendezvousWeight(instanceID, resourceID)
+		if owner == "" || weight > highest || (weight == highest && instanceID < owner) {
+			owner = instanceID
+			highest = weight
+		}
+	}
+
+	return owner == p.instanceID
+}
+
+// runningInstanceIDs returns the IDs of the running instances partitioning the
+// work of the supervisor, refreshing them from the store at most once per
+// heartbeat interval. If the instances can't be queried, the last known
+// instances are used.
+func (p *InstancePartitioner) runningInstanceIDs() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Since(p.refreshedAt) < InstanceHeartbeatInterval {
+		return p.instanceIDs
+	}
+
+	instances, err := p.store.GetInstances()
+	if err != nil {
+		p.logger.WithError(err).Warn("Failed to query for instances")
+		return p.instanceIDs
+	}
+
+	heartbeatCutoff := time.Now().UnixNano()/int64(time.Millisecond) - instanceHeartbeatTimeout.Milliseconds()
+	instanceIDs := []string{p.instanceID}
+	for _, instance := range instances {
+		if instance.ID != p.instanceID && instance.LastHeartbeatAt >= heartbeatCutoff && instance.PartitionsSupervisor(p.supervisor) {
+			instanceIDs = append(instanceIDs, instance.ID)
+		}
+	}
+
+	if len(instanceIDs) != len(p.instanceIDs) {
+		p.logger.Infof("Partitioning work between %d instance(s)", len(instanceIDs))
+	}
+	p.instanceIDs = instanceIDs
+	p.refreshedAt = time.Now()
+
+	return p.instanceIDs
+}
+
+// rendezvousWeight returns the weight of the given instance for the given resource.
+func rendezvousWeight(instanceID, resourceID string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(instanceID))
+	h.Write([]byte{0})
+	h.Write([]byte(resourceID))
+
+	return h.Sum64()
+}
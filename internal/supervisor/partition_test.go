@@ -0,0 +1,72 @@
+package supervisor_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+type testPartitioner struct {
+	owned map[string]bool
+}
+
+func (p *testPartitioner) Owns(resourceID string) bool {
+	return p.owned[resourceID]
+}
+
+func TestInstancePartitioner(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	resourceIDs := make([]string, 100)
+	for i := range resourceIDs {
+		resourceIDs[i] = model.NewID()
+	}
+
+	t.Run("single instance", func(t *testing.T) {
+		partitioner := supervisor.NewInstancePartitioner(sqlStore, "instance1", "cluster", logger)
+		for _, resourceID := range resourceIDs {
+			require.True(t, partitioner.Owns(resourceID))
+		}
+	})
+
+	t.Run("several instances", func(t *testing.T) {
+		instanceIDs := []string{"instance1", "instance2", "instance3"}
+		for _, instanceID := range instanceIDs {
+			err := sqlStore.HeartbeatInstance(&model.Instance{ID: instanceID, PartitionedSupervisors: []string{"cluster"}})
+			require.NoError(t, err)
+		}
+
+		// Instances that serve the API only, or partition other supervisors,
+		// must not be given any resources of the cluster supervisor.
+		err := sqlStore.HeartbeatInstance(&model.Instance{ID: "api-instance"})
+		require.NoError(t, err)
+		err = sqlStore.HeartbeatInstance(&model.Instance{ID: "installation-instance", PartitionedSupervisors: []string{"installation"}})
+		require.NoError(t, err)
+
+		var partitioners []*supervisor.InstancePartitioner
+		for _, instanceID := range instanceIDs {
+			partitioners = append(partitioners, supervisor.NewInstancePartitioner(sqlStore, instanceID, "cluster", logger))
+		}
+
+		owned := make(map[string]int)
+		for _, resourceID := range resourceIDs {
+			owners := 0
+			for i, partitioner := range partitioners {
+				if partitioner.Owns(resourceID) {
+					owners++
+					owned[instanceIDs[i]]++
+				}
+			}
+			require.Equal(t, 1, owners, "resource %s should have exactly one owner", resourceID)
+		}
+
+		for _, instanceID := range instanceIDs {
+			require.NotZero(t, owned[instanceID], "instance %s should own some resources", instanceID)
+		}
+	})
+}
@@ -1,16 +1,35 @@
 package supervisor
 
 import (
+	"sync"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 // Scheduler schedules a doer for periodic, serial execution.
+//
+// If the doer is a ResourceDoer, individual resources may also be enqueued for
+// immediate execution, independently of the periodic execution of the doer.
 type Scheduler struct {
-	doer   Doer
-	period time.Duration
-	notify chan bool
-	stop   chan bool
-	done   chan bool
+	doer     Doer
+	period   time.Duration
+	notify   chan bool
+	stop     chan bool
+	stopOnce sync.Once
+	done     chan bool
+
+	queueLock sync.Mutex
+	queue     []resourceRef
+	queued    map[resourceRef]bool
+	enqueued  chan bool
+	queueDone chan bool
+}
+
+// resourceRef identifies a resource enqueued for execution.
+type resourceRef struct {
+	resourceType string
+	resourceID   string
 }
 
 // NewScheduler creates a new scheduler.
@@ -19,14 +38,18 @@ type Scheduler struct {
 // specifies how long to wait after its last successful execution.
 func NewScheduler(doer Doer, period time.Duration) *Scheduler {
 	s := &Scheduler{
-		doer:   doer,
-		period: period,
-		notify: make(chan bool, 1),
-		stop:   make(chan bool),
-		done:   make(chan bool),
+		doer:      doer,
+		period:    period,
+		notify:    make(chan bool, 1),
+		stop:      make(chan bool),
+		done:      make(chan bool),
+		queued:    make(map[resourceRef]bool),
+		enqueued:  make(chan bool, 1),
+		queueDone: make(chan bool),
 	}
 
 	go s.run()
+	go s.runQueue()
 
 	return s
 }
@@ -45,6 +68,46 @@ func (s *Scheduler) Do() error {
 	return nil
 }
 
+// Enqueue requests an execution of the scheduled doer for the given resource only, where
+// resourceType is one of the model.LockResourceType constants.
+//
+// The resource is executed separately from, and possibly concurrently with, the periodic
+// execution of the doer, which remains as a fallback. If the doer does not support executing a
+// single resource, a full execution is requested instead. Resources already enqueued but not yet
+// executed are only executed once. Enqueue never blocks.
+func (s *Scheduler) Enqueue(resourceType, resourceID string) {
+	if _, ok := s.doer.(ResourceDoer); !ok {
+		s.Do()
+		return
+	}
+
+	ref := resourceRef{resourceType: resourceType, resourceID: resourceID}
+
+	s.queueLock.Lock()
+	if !s.queued[ref] {
+		s.queued[ref] = true
+		s.queue = append(s.queue, ref)
+	}
+	s.queueLock.Unlock()
+
+	select {
+	case s.enqueued <- true:
+	default:
+	}
+}
+
+// dequeue removes and returns all enqueued resources.
+func (s *Scheduler) dequeue() []resourceRef {
+	s.queueLock.Lock()
+	defer s.queueLock.Unlock()
+
+	queue := s.queue
+	s.queue = nil
+	s.queued = make(map[resourceRef]bool)
+
+	return queue
+}
+
 // run is the main thread of the scheduler and responsible for triggering the doer as required.
 func (s *Scheduler) run() {
 	for {
@@ -68,11 +131,60 @@ func (s *Scheduler) run() {
 	}
 }
 
+// runQueue is responsible for executing the doer for any enqueued resources.
+func (s *Scheduler) runQueue() {
+	defer close(s.queueDone)
+
+	resourceDoer, ok := s.doer.(ResourceDoer)
+	if !ok || s.period <= 0 {
+		<-s.stop
+		return
+	}
+
+	for {
+		select {
+		case <-s.enqueued:
+			for _, ref := range s.dequeue() {
+				select {
+				case <-s.stop:
+					return
+				default:
+				}
+
+				_ = resourceDoer.DoResource(ref.resourceType, ref.resourceID)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
 // Close waits for any active doer to finish, terminates the main thread of the scheduler, and
 // ensures the doer is no longer invoked.
 func (s *Scheduler) Close() error {
-	close(s.stop)
+	s.stopOnce.Do(func() { close(s.stop) })
 	<-s.done
+	<-s.queueDone
+
+	return nil
+}
+
+// Shutdown ensures the doer is no longer invoked, asks any active doer to stop picking up new
+// work if supported, and waits up to the given timeout for it to finish.
+func (s *Scheduler) Shutdown(timeout time.Duration) error {
+	if stopper, ok := s.doer.(Stopper); ok {
+		stopper.Stop()
+	}
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	deadline := time.After(timeout)
+	for _, done := range []chan bool{s.done, s.queueDone} {
+		select {
+		case <-done:
+		case <-deadline:
+			return errors.Errorf("timed out after %s waiting for the doer to finish", timeout)
+		}
+	}
 
 	return nil
 }
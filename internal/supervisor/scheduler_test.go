@@ -108,4 +108,175 @@ func TestScheduler(t *testing.T) {
 		case <-doer.calls:
 		}
 	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		t.Parallel()
+
+		doer := &testDoer{
+			calls: make(chan bool),
+		}
+		scheduler := supervisor.NewScheduler(doer, 30*time.Second)
+
+		scheduler.Do()
+
+		// Let the doer finish while the scheduler is shutting down.
+		go func() {
+			time.Sleep(500 * time.Millisecond)
+			<-doer.calls
+		}()
+
+		err := scheduler.Shutdown(5 * time.Second)
+		assert.NoError(t, err)
+
+		scheduler.Do()
+
+		select {
+		case <-doer.calls:
+			assert.Fail(t, "doer should not have been invoked")
+		case <-time.After(500 * time.Millisecond):
+		}
+	})
+
+	t.Run("shutdown timeout", func(t *testing.T) {
+		t.Parallel()
+
+		doer := &testDoer{
+			calls: make(chan bool),
+		}
+		scheduler := supervisor.NewScheduler(doer, 30*time.Second)
+
+		scheduler.Do()
+
+		// Give the doer time to start, but never let it finish before the timeout.
+		time.Sleep(500 * time.Millisecond)
+
+		err := scheduler.Shutdown(100 * time.Millisecond)
+		assert.EqualError(t, err, "timed out after 100ms waiting for the doer to finish")
+
+		<-doer.calls
+	})
+
+	t.Run("enqueue", func(t *testing.T) {
+		t.Parallel()
+
+		doer := &resourceDoer{
+			calls:     make(chan bool, 1),
+			resources: make(chan string, 1),
+		}
+		scheduler := supervisor.NewScheduler(doer, 30*time.Second)
+		defer scheduler.Close()
+
+		scheduler.Enqueue("cluster", "id")
+
+		select {
+		case resource := <-doer.resources:
+			assert.Equal(t, "cluster/id", resource)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "resource not invoked within 5 seconds")
+		}
+
+		select {
+		case <-doer.calls:
+			assert.Fail(t, "doer should not have been invoked")
+		case <-time.After(500 * time.Millisecond):
+		}
+	})
+
+	t.Run("enqueue duplicates", func(t *testing.T) {
+		t.Parallel()
+
+		doer := &resourceDoer{
+			calls:     make(chan bool, 1),
+			resources: make(chan string),
+		}
+		scheduler := supervisor.NewScheduler(doer, 30*time.Second)
+		defer scheduler.Close()
+
+		// Block the queue on the first resource while enqueueing the others.
+		scheduler.Enqueue("cluster", "id1")
+		time.Sleep(100 * time.Millisecond)
+		scheduler.Enqueue("cluster", "id2")
+		scheduler.Enqueue("installation", "id2")
+		scheduler.Enqueue("cluster", "id2")
+
+		var resources []string
+		for i := 0; i < 3; i++ {
+			select {
+			case resource := <-doer.resources:
+				resources = append(resources, resource)
+			case <-time.After(5 * time.Second):
+				assert.Fail(t, "resource not invoked within 5 seconds")
+			}
+		}
+		assert.Equal(t, []string{"cluster/id1", "cluster/id2", "installation/id2"}, resources)
+
+		select {
+		case resource := <-doer.resources:
+			assert.Failf(t, "resource should not have been invoked", resource)
+		case <-time.After(500 * time.Millisecond):
+		}
+	})
+
+	t.Run("enqueue while doing", func(t *testing.T) {
+		t.Parallel()
+
+		doer := &resourceDoer{
+			calls:     make(chan bool),
+			resources: make(chan string, 1),
+		}
+		scheduler := supervisor.NewScheduler(doer, 30*time.Second)
+		defer func() {
+			<-doer.calls
+			scheduler.Close()
+		}()
+
+		// The doer blocks until the test finishes, but the resource is not held up.
+		scheduler.Do()
+		time.Sleep(100 * time.Millisecond)
+		scheduler.Enqueue("cluster", "id")
+
+		select {
+		case resource := <-doer.resources:
+			assert.Equal(t, "cluster/id", resource)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "resource not invoked within 5 seconds")
+		}
+	})
+
+	t.Run("enqueue without resource doer", func(t *testing.T) {
+		t.Parallel()
+
+		doer := &testDoer{
+			calls: make(chan bool, 1),
+		}
+		scheduler := supervisor.NewScheduler(doer, 30*time.Second)
+		defer scheduler.Close()
+
+		scheduler.Enqueue("cluster", "id")
+
+		select {
+		case <-doer.calls:
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "doer not invoked within 5 seconds")
+		}
+	})
+
+	t.Run("enqueue disabled", func(t *testing.T) {
+		t.Parallel()
+
+		doer := &resourceDoer{
+			calls:     make(chan bool, 1),
+			resources: make(chan string, 1),
+		}
+		scheduler := supervisor.NewScheduler(doer, 0*time.Second)
+		defer scheduler.Close()
+
+		scheduler.Enqueue("cluster", "id")
+
+		select {
+		case <-doer.resources:
+			assert.Fail(t, "resource should not have been invoked")
+		case <-time.After(500 * time.Millisecond):
+		}
+	})
 }
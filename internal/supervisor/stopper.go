@@ -0,0 +1,19 @@
+package supervisor
+
+import "sync/atomic"
+
+// stopper allows a supervisor to be asked to stop picking up new work, while
+// letting any resource already being supervised finish its transition.
+type stopper struct {
+	stopped int32
+}
+
+// Stop asks the supervisor not to supervise any further resources.
+func (s *stopper) Stop() {
+	atomic.StoreInt32(&s.stopped, 1)
+}
+
+// isStopped returns whether the supervisor has been asked to stop.
+func (s *stopper) isStopped() bool {
+	return atomic.LoadInt32(&s.stopped) == 1
+}
@@ -0,0 +1,213 @@
+package supervisor
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
+	"github.com/mattermost/mattermost-cloud/internal/webhook"
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// webhookDeliveryMaxAttempts is the number of failed attempts after which
+	// a webhook delivery is parked in the dead-letter list.
+	webhookDeliveryMaxAttempts = 10
+	// webhookDeliveryInitialBackoff is the delay before the first retry of a
+	// failed webhook delivery. It doubles with each further failure.
+	webhookDeliveryInitialBackoff = 15 * time.Second
+	// webhookDeliveryMaxBackoff caps the delay between retries of a failed
+	// webhook delivery.
+	webhookDeliveryMaxBackoff = 1 * time.Hour
+)
+
+// webhookDeliveryStore abstracts the database operations required to deliver
+// queued webhook payloads.
+type webhookDeliveryStore interface {
+	GetWebhook(webhookID string) (*model.Webhook, error)
+
+	GetWebhookDelivery(deliveryID string) (*model.WebhookDelivery, error)
+	GetUnlockedWebhookDeliveriesPendingWork() ([]*model.WebhookDelivery, error)
+	UpdateWebhookDelivery(delivery *model.WebhookDelivery) error
+	LockWebhookDelivery(deliveryID, lockerID string) (bool, error)
+	UnlockWebhookDelivery(deliveryID, lockerID string, force bool) (bool, error)
+	DeleteWebhookDeliveriesDeliveredBefore(cutoff int64) (int64, error)
+}
+
+// WebhookDeliverySupervisor finds queued webhook deliveries that are due and
+// sends them, retrying failures with exponential backoff. Deliveries that
+// were delivered are deleted once older than the retention period, if any.
+type WebhookDeliverySupervisor struct {
+	store      webhookDeliveryStore
+	instanceID string
+	retention  time.Duration
+	logger     log.FieldLogger
+
+	stopper
+	partitioned
+	workers
+}
+
+// NewWebhookDeliverySupervisor creates a new WebhookDeliverySupervisor.
+func NewWebhookDeliverySupervisor(store webhookDeliveryStore, instanceID string, logger log.FieldLogger) *WebhookDeliverySupervisor {
+	return &WebhookDeliverySupervisor{
+		store:      store,
+		instanceID: instanceID,
+		logger:     logger,
+	}
+}
+
+// SetRetention sets how long delivered webhook deliveries are kept before
+// being deleted. A retention of 0 keeps them forever.
+func (s *WebhookDeliverySupervisor) SetRetention(retention time.Duration) {
+	s.retention = retention
+}
+
+// Do looks for webhook deliveries that are due and attempts to send them, then
+// deletes the delivered webhook deliveries older than the retention period.
+func (s *WebhookDeliverySupervisor) Do() error {
+	defer metrics.ObserveSupervisorDo("webhook_delivery", time.Now())
+
+	s.deliverPending()
+	s.deleteDelivered()
+
+	return nil
+}
+
+// DoResource attempts to send any webhook deliveries pending work, regardless
+// of the resource given, since acting on any resource may have queued some.
+func (s *WebhookDeliverySupervisor) DoResource(resourceType, resourceID string) error {
+	defer metrics.ObserveSupervisorDo("webhook_delivery", time.Now())
+
+	s.deliverPending()
+
+	return nil
+}
+
+// deliverPending attempts to send the webhook deliveries that are due.
+func (s *WebhookDeliverySupervisor) deliverPending() {
+	deliveries, err := s.store.GetUnlockedWebhookDeliveriesPendingWork()
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to query for webhook deliveries pending work")
+		return
+	}
+
+	pool := s.newWorkerPool()
+	for _, delivery := range deliveries {
+		if s.isStopped() {
+			s.logger.Info("Supervisor stopped, leaving remaining work for later")
+			break
+		}
+		if !s.owns(delivery.ID) {
+			continue
+		}
+		delivery := delivery
+		pool.Go(func() { s.Supervise(delivery) })
+	}
+	pool.Wait()
+}
+
+// deleteDelivered deletes the delivered webhook deliveries older than the
+// retention period.
+func (s *WebhookDeliverySupervisor) deleteDelivered() {
+	if s.retention <= 0 || s.isStopped() {
+		return
+	}
+
+	cutoff := time.Now().Add(-s.retention).UnixNano() / int64(time.Millisecond)
+	deleted, err := s.store.DeleteWebhookDeliveriesDeliveredBefore(cutoff)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to delete delivered webhook deliveries")
+	} else if deleted > 0 {
+		s.logger.Infof("Deleted %d delivered webhook deliveries older than %s", deleted, s.retention)
+	}
+}
+
+// Supervise attempts to send the given webhook delivery.
+func (s *WebhookDeliverySupervisor) Supervise(delivery *model.WebhookDelivery) {
+	logger := s.logger.WithFields(log.Fields{
+		"webhook":  delivery.WebhookID,
+		"delivery": delivery.ID,
+	})
+
+	lock := newWebhookDeliveryLock(delivery.ID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		return
+	}
+	defer lock.Unlock()
+
+	// The delivery may have been sent by another supervisor between being
+	// queried and locked, so only act on it as currently stored.
+	delivery, err := s.store.GetWebhookDelivery(delivery.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get webhook delivery")
+		return
+	}
+	if delivery == nil || !isPendingWork(delivery.State, model.AllWebhookDeliveryStatesPendingWork) || delivery.NextAttemptAt > time.Now().UnixNano()/int64(time.Millisecond) {
+		logger.Debug("Webhook delivery is no longer pending; skipping")
+		return
+	}
+
+	logger.Debugf("Supervising webhook delivery after %d attempt(s)", delivery.Attempts)
+
+	hook, err := s.store.GetWebhook(delivery.WebhookID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get webhook")
+		return
+	}
+
+	if hook == nil || hook.IsDeleted() {
+		logger.Warn("Webhook no longer exists; parking delivery in the dead-letter list")
+		delivery.State = model.WebhookDeliveryStateFailed
+		delivery.LastError = "webhook was deleted"
+	} else {
+		s.attemptDelivery(hook, delivery, logger)
+	}
+
+	err = s.store.UpdateWebhookDelivery(delivery)
+	if err != nil {
+		logger.WithError(err).Errorf("Failed to update webhook delivery in state %s", delivery.State)
+	}
+}
+
+// attemptDelivery sends the delivery payload to the webhook and records the
+// outcome of the attempt on the delivery.
+func (s *WebhookDeliverySupervisor) attemptDelivery(hook *model.Webhook, delivery *model.WebhookDelivery, logger log.FieldLogger) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = now.UnixNano() / int64(time.Millisecond)
+
+	err := webhook.Deliver(hook, delivery.Payload)
+	if err == nil {
+		logger.Debug("Webhook delivered")
+		delivery.State = model.WebhookDeliveryStateDelivered
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+
+	if delivery.Attempts >= webhookDeliveryMaxAttempts {
+		logger.WithError(err).Warnf("Webhook delivery failed after %d attempts; parking delivery in the dead-letter list", delivery.Attempts)
+		delivery.State = model.WebhookDeliveryStateFailed
+		return
+	}
+
+	backoff := webhookDeliveryBackoff(delivery.Attempts)
+	delivery.NextAttemptAt = now.Add(backoff).UnixNano() / int64(time.Millisecond)
+	logger.WithError(err).Warnf("Webhook delivery failed; retrying in %s", backoff)
+}
+
+// webhookDeliveryBackoff returns the delay before the next delivery attempt
+// after the given number of failed attempts.
+func webhookDeliveryBackoff(attempts int) time.Duration {
+	backoff := webhookDeliveryInitialBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= webhookDeliveryMaxBackoff {
+			return webhookDeliveryMaxBackoff
+		}
+	}
+
+	return backoff
+}
@@ -0,0 +1,219 @@
+package supervisor_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliverySupervisor(t *testing.T) {
+	var statusCode int
+	var received int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+		w.WriteHeader(statusCode)
+	}))
+	defer ts.Close()
+
+	setup := func(t *testing.T) (*store.SQLStore, *model.Webhook, *supervisor.WebhookDeliverySupervisor) {
+		t.Helper()
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+
+		hook := &model.Webhook{
+			OwnerID: model.NewID(),
+			URL:     ts.URL,
+		}
+		err := sqlStore.CreateWebhook(hook)
+		require.NoError(t, err)
+
+		return sqlStore, hook, supervisor.NewWebhookDeliverySupervisor(sqlStore, "instanceID", logger)
+	}
+
+	createDelivery := func(t *testing.T, sqlStore *store.SQLStore, webhookID string, attempts int) *model.WebhookDelivery {
+		t.Helper()
+		delivery := &model.WebhookDelivery{
+			WebhookID: webhookID,
+			Payload:   `{"id":"id"}`,
+			State:     model.WebhookDeliveryStatePending,
+			Attempts:  attempts,
+		}
+		err := sqlStore.CreateWebhookDelivery(delivery)
+		require.NoError(t, err)
+
+		return delivery
+	}
+
+	getDelivery := func(t *testing.T, sqlStore *store.SQLStore, delivery *model.WebhookDelivery) *model.WebhookDelivery {
+		t.Helper()
+		delivery, err := sqlStore.GetWebhookDelivery(delivery.ID)
+		require.NoError(t, err)
+
+		return delivery
+	}
+
+	t.Run("delivered", func(t *testing.T) {
+		sqlStore, hook, supervisor := setup(t)
+		delivery := createDelivery(t, sqlStore, hook.ID, 0)
+
+		statusCode = http.StatusOK
+		received = 0
+		err := supervisor.Do()
+		require.NoError(t, err)
+		require.Equal(t, 1, received)
+
+		delivery = getDelivery(t, sqlStore, delivery)
+		require.Equal(t, model.WebhookDeliveryStateDelivered, delivery.State)
+		require.Equal(t, 1, delivery.Attempts)
+		require.NotZero(t, delivery.LastAttemptAt)
+		require.Empty(t, delivery.LastError)
+		require.Nil(t, delivery.LockAcquiredBy)
+
+		err = supervisor.Do()
+		require.NoError(t, err)
+		require.Equal(t, 1, received)
+	})
+
+	t.Run("already delivered by another supervisor", func(t *testing.T) {
+		sqlStore, hook, supervisor := setup(t)
+		delivery := createDelivery(t, sqlStore, hook.ID, 0)
+
+		statusCode = http.StatusOK
+		received = 0
+		err := supervisor.Do()
+		require.NoError(t, err)
+		require.Equal(t, 1, received)
+
+		// Supervise the delivery as it was before it was delivered.
+		supervisor.Supervise(delivery)
+		require.Equal(t, 1, received)
+
+		delivery = getDelivery(t, sqlStore, delivery)
+		require.Equal(t, model.WebhookDeliveryStateDelivered, delivery.State)
+		require.Equal(t, 1, delivery.Attempts)
+	})
+
+	t.Run("failed, retry scheduled", func(t *testing.T) {
+		sqlStore, hook, supervisor := setup(t)
+		delivery := createDelivery(t, sqlStore, hook.ID, 0)
+
+		statusCode = http.StatusInternalServerError
+		received = 0
+		err := supervisor.Do()
+		require.NoError(t, err)
+		require.Equal(t, 1, received)
+
+		delivery = getDelivery(t, sqlStore, delivery)
+		require.Equal(t, model.WebhookDeliveryStatePending, delivery.State)
+		require.Equal(t, 1, delivery.Attempts)
+		require.Equal(t, "webhook receiver responded with status code 500", delivery.LastError)
+		require.True(t, delivery.NextAttemptAt > time.Now().UnixNano()/int64(time.Millisecond))
+
+		// The retry is not yet due.
+		err = supervisor.Do()
+		require.NoError(t, err)
+		require.Equal(t, 1, received)
+	})
+
+	t.Run("failed, retries exhausted", func(t *testing.T) {
+		sqlStore, hook, supervisor := setup(t)
+		delivery := createDelivery(t, sqlStore, hook.ID, 9)
+
+		statusCode = http.StatusBadGateway
+		received = 0
+		err := supervisor.Do()
+		require.NoError(t, err)
+		require.Equal(t, 1, received)
+
+		delivery = getDelivery(t, sqlStore, delivery)
+		require.Equal(t, model.WebhookDeliveryStateFailed, delivery.State)
+		require.Equal(t, 10, delivery.Attempts)
+		require.Equal(t, "webhook receiver responded with status code 502", delivery.LastError)
+	})
+
+	t.Run("webhook deleted", func(t *testing.T) {
+		sqlStore, hook, supervisor := setup(t)
+		delivery := createDelivery(t, sqlStore, hook.ID, 0)
+
+		err := sqlStore.DeleteWebhook(hook.ID)
+		require.NoError(t, err)
+
+		received = 0
+		err = supervisor.Do()
+		require.NoError(t, err)
+		require.Equal(t, 0, received)
+
+		delivery = getDelivery(t, sqlStore, delivery)
+		require.Equal(t, model.WebhookDeliveryStateFailed, delivery.State)
+		require.Equal(t, 0, delivery.Attempts)
+		require.Equal(t, "webhook was deleted", delivery.LastError)
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		sqlStore, hook, supervisor := setup(t)
+		delivery := createDelivery(t, sqlStore, hook.ID, 0)
+
+		statusCode = http.StatusOK
+		received = 0
+		supervisor.Stop()
+		err := supervisor.Do()
+		require.NoError(t, err)
+		require.Equal(t, 0, received)
+
+		delivery = getDelivery(t, sqlStore, delivery)
+		require.Equal(t, model.WebhookDeliveryStatePending, delivery.State)
+		require.Equal(t, 0, delivery.Attempts)
+	})
+
+	t.Run("owned by another instance", func(t *testing.T) {
+		sqlStore, hook, supervisor := setup(t)
+		delivery := createDelivery(t, sqlStore, hook.ID, 0)
+
+		statusCode = http.StatusOK
+		received = 0
+		supervisor.SetPartitioner(&testPartitioner{owned: map[string]bool{}})
+		err := supervisor.Do()
+		require.NoError(t, err)
+		require.Equal(t, 0, received)
+
+		delivery = getDelivery(t, sqlStore, delivery)
+		require.Equal(t, model.WebhookDeliveryStatePending, delivery.State)
+
+		supervisor.SetPartitioner(&testPartitioner{owned: map[string]bool{delivery.ID: true}})
+		err = supervisor.Do()
+		require.NoError(t, err)
+		require.Equal(t, 1, received)
+
+		delivery = getDelivery(t, sqlStore, delivery)
+		require.Equal(t, model.WebhookDeliveryStateDelivered, delivery.State)
+	})
+
+	t.Run("delivered, retention elapsed", func(t *testing.T) {
+		sqlStore, hook, supervisor := setup(t)
+		delivery := createDelivery(t, sqlStore, hook.ID, 0)
+
+		statusCode = http.StatusOK
+		received = 0
+		supervisor.SetRetention(time.Millisecond)
+		err := supervisor.DoResource(model.TypeInstallation, model.NewID())
+		require.NoError(t, err)
+		require.Equal(t, 1, received)
+
+		time.Sleep(2 * time.Millisecond)
+
+		err = supervisor.DoResource(model.TypeInstallation, model.NewID())
+		require.NoError(t, err)
+		require.Equal(t, model.WebhookDeliveryStateDelivered, getDelivery(t, sqlStore, delivery).State)
+
+		err = supervisor.Do()
+		require.NoError(t, err)
+		require.Nil(t, getDelivery(t, sqlStore, delivery))
+	})
+}
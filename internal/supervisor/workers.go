@@ -0,0 +1,62 @@
+package supervisor
+
+import "sync"
+
+// Concurrent describes a doer that can work on several resources concurrently.
+type Concurrent interface {
+	SetWorkers(workers int)
+}
+
+// workers allows a supervisor to supervise several resources concurrently.
+//
+// Resources are still locked before being worked on, so the same resource is
+// never supervised concurrently, even by another instance.
+type workers struct {
+	workers int
+}
+
+// SetWorkers sets the maximum number of resources the supervisor works on
+// concurrently. It must be called before the supervisor is first invoked.
+func (w *workers) SetWorkers(workers int) {
+	w.workers = workers
+}
+
+// newWorkerPool returns a worker pool for a single invocation of the supervisor.
+func (w *workers) newWorkerPool() *workerPool {
+	pool := &workerPool{}
+	if w.workers > 1 {
+		pool.slots = make(chan struct{}, w.workers)
+	}
+
+	return pool
+}
+
+// workerPool runs work with bounded parallelism.
+type workerPool struct {
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+// Go runs the given work once a worker is available, blocking until then.
+// Without more than one worker, the work is run immediately and synchronously.
+func (p *workerPool) Go(work func()) {
+	if p.slots == nil {
+		work()
+		return
+	}
+
+	p.slots <- struct{}{}
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.slots
+			p.wg.Done()
+		}()
+		work()
+	}()
+}
+
+// Wait blocks until all work has finished.
+func (p *workerPool) Wait() {
+	p.wg.Wait()
+}
@@ -0,0 +1,83 @@
+package supervisor_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervisorWorkers(t *testing.T) {
+	var inFlight, maxInFlight, received int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	setup := func(t *testing.T, deliveries int) *store.SQLStore {
+		t.Helper()
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+
+		hook := &model.Webhook{
+			OwnerID: model.NewID(),
+			URL:     ts.URL,
+		}
+		err := sqlStore.CreateWebhook(hook)
+		require.NoError(t, err)
+
+		for i := 0; i < deliveries; i++ {
+			err = sqlStore.CreateWebhookDelivery(&model.WebhookDelivery{
+				WebhookID: hook.ID,
+				Payload:   `{"id":"id"}`,
+				State:     model.WebhookDeliveryStatePending,
+			})
+			require.NoError(t, err)
+		}
+
+		atomic.StoreInt32(&maxInFlight, 0)
+		atomic.StoreInt32(&received, 0)
+
+		return sqlStore
+	}
+
+	t.Run("one worker", func(t *testing.T) {
+		sqlStore := setup(t, 4)
+		supervisor := supervisor.NewWebhookDeliverySupervisor(sqlStore, "instanceID", testlib.MakeLogger(t))
+		supervisor.SetWorkers(1)
+
+		err := supervisor.Do()
+		require.NoError(t, err)
+		require.EqualValues(t, 4, atomic.LoadInt32(&received))
+		require.EqualValues(t, 1, atomic.LoadInt32(&maxInFlight))
+	})
+
+	t.Run("several workers", func(t *testing.T) {
+		sqlStore := setup(t, 6)
+		supervisor := supervisor.NewWebhookDeliverySupervisor(sqlStore, "instanceID", testlib.MakeLogger(t))
+		supervisor.SetWorkers(3)
+
+		err := supervisor.Do()
+		require.NoError(t, err)
+		require.EqualValues(t, 6, atomic.LoadInt32(&received))
+		require.EqualValues(t, 3, atomic.LoadInt32(&maxInFlight))
+	})
+}
@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// backupFilestoreCopyBatchSize is the number of filestore objects copied into
+// the backup bucket each time the backup is polled.
+const backupFilestoreCopyBatchSize = 500
+
+// CreateInstallationBackup starts a snapshot of the RDS database of the
+// installation and ensures the backup bucket of the installation exists. The
+// backup is updated with the location of the backed up data; the filestore is
+// copied there as the backup is polled by IsInstallationBackupComplete.
+func (a *Client) CreateInstallationBackup(installation *model.Installation, backup *model.InstallationBackup, logger log.FieldLogger) error {
+	if !installation.IsBackupSupported() {
+		return errors.Errorf("backups are not supported for database %s and filestore %s", installation.Database, installation.Filestore)
+	}
+
+	awsID := CloudID(installation.ID)
+	snapshotID := RDSBackupSnapshotID(installation.ID, backup.ID)
+	bucketName := S3BackupBucketName(installation.ID)
+	prefix := backup.ID + "/"
+
+	err := a.rdsEnsureDBClusterSnapshotCreated(awsID, snapshotID, logger)
+	if err != nil {
+		return errors.Wrap(err, "unable to snapshot RDS database")
+	}
+
+	err = a.s3EnsureBucketExists(bucketName, logger)
+	if err != nil {
+		return errors.Wrap(err, "unable to ensure AWS S3 backup bucket exists")
+	}
+
+	backup.DatabaseSnapshotID = snapshotID
+	backup.FilestoreBucket = bucketName
+	backup.FilestorePrefix = prefix
+	backup.FilestoreCopyMarker = ""
+	backup.FilestoreCopied = false
+
+	return nil
+}
+
+// IsInstallationBackupComplete copies the next batch of the filestore into the
+// backup bucket and returns whether both the filestore copy and the database
+// snapshot of the installation backup have completed or not. The copy progress
+// is recorded on the backup, which the caller is expected to persist.
+//
+// Errors reaching S3 or RDS leave the backup in progress to be checked again,
+// and an error is only returned once the database snapshot can no longer
+// complete.
+func (a *Client) IsInstallationBackupComplete(backup *model.InstallationBackup, logger log.FieldLogger) (bool, error) {
+	if !backup.FilestoreCopied {
+		marker, copied, err := a.s3CopyObjectsBatch(CloudID(backup.InstallationID), "", backup.FilestoreBucket, backup.FilestorePrefix, backup.FilestoreCopyMarker, backupFilestoreCopyBatchSize, logger)
+		backup.FilestoreCopyMarker = marker
+		backup.FilestoreCopied = copied
+		if err != nil {
+			logger.WithError(err).Warn("Unable to backup AWS S3 filestore; will retry")
+			return false, nil
+		}
+	}
+
+	status, err := a.rdsGetDBClusterSnapshotStatus(backup.DatabaseSnapshotID)
+	if err != nil {
+		logger.WithError(err).Warn("Unable to get AWS DB cluster snapshot status; will retry")
+		return false, nil
+	}
+
+	switch status {
+	case "available":
+		return backup.FilestoreCopied, nil
+	case "failed", "deleting", "deleted":
+		return false, errors.Errorf("DB cluster snapshot %s is in terminal status %s", backup.DatabaseSnapshotID, status)
+	}
+
+	logger.WithField("db-cluster-snapshot-name", backup.DatabaseSnapshotID).Debugf("AWS DB cluster snapshot is still being created (status %s)", status)
+
+	return false, nil
+}
@@ -0,0 +1,170 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/golang/mock/gomock"
+	testlib "github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+func (a *AWSTestSuite) TestRDSEnsureDBClusterSnapshotCreated() {
+	snapshotID := RDSBackupSnapshotID(a.InstallationA.ID, "backup")
+
+	a.Mocks.API.RDS.EXPECT().
+		DescribeDBClusterSnapshots(gomock.Any()).
+		Return(nil, errors.New("snapshot does not exist")).
+		Times(1)
+
+	a.Mocks.API.RDS.EXPECT().
+		CreateDBClusterSnapshot(gomock.Any()).
+		Return(&rds.CreateDBClusterSnapshotOutput{}, nil).
+		Do(func(input *rds.CreateDBClusterSnapshotInput) {
+			a.Assert().Equal(CloudID(a.InstallationA.ID), *input.DBClusterIdentifier)
+			a.Assert().Equal(snapshotID, *input.DBClusterSnapshotIdentifier)
+		}).
+		Times(1)
+
+	a.Mocks.Log.Logger.EXPECT().
+		WithField("db-cluster-snapshot-name", snapshotID).
+		Return(testlib.NewLoggerEntry()).
+		Times(1)
+
+	err := a.Mocks.AWS.rdsEnsureDBClusterSnapshotCreated(CloudID(a.InstallationA.ID), snapshotID, a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+}
+
+func (a *AWSTestSuite) TestRDSEnsureDBClusterSnapshotCreatedAlreadyCreated() {
+	snapshotID := RDSBackupSnapshotID(a.InstallationA.ID, "backup")
+
+	a.Mocks.API.RDS.EXPECT().
+		DescribeDBClusterSnapshots(gomock.Any()).
+		Return(&rds.DescribeDBClusterSnapshotsOutput{}, nil).
+		Times(1)
+
+	a.Mocks.Log.Logger.EXPECT().
+		WithField("db-cluster-snapshot-name", snapshotID).
+		Return(testlib.NewLoggerEntry()).
+		Times(1)
+
+	err := a.Mocks.AWS.rdsEnsureDBClusterSnapshotCreated(CloudID(a.InstallationA.ID), snapshotID, a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+}
+
+func (a *AWSTestSuite) TestIsInstallationBackupComplete() {
+	backup := &model.InstallationBackup{
+		DatabaseSnapshotID: RDSBackupSnapshotID(a.InstallationA.ID, "backup"),
+		FilestoreCopied:    true,
+	}
+
+	describeSnapshot := func(status string) {
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBClusterSnapshots(gomock.Any()).
+			Return(&rds.DescribeDBClusterSnapshotsOutput{
+				DBClusterSnapshots: []*rds.DBClusterSnapshot{{Status: aws.String(status)}},
+			}, nil).
+			Times(1)
+	}
+
+	describeSnapshot("available")
+	complete, err := a.Mocks.AWS.IsInstallationBackupComplete(backup, a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+	a.Assert().True(complete)
+
+	describeSnapshot("creating")
+	a.Mocks.Log.Logger.EXPECT().
+		WithField("db-cluster-snapshot-name", backup.DatabaseSnapshotID).
+		Return(testlib.NewLoggerEntry()).
+		Times(1)
+	complete, err = a.Mocks.AWS.IsInstallationBackupComplete(backup, a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+	a.Assert().False(complete)
+
+	describeSnapshot("failed")
+	complete, err = a.Mocks.AWS.IsInstallationBackupComplete(backup, a.Mocks.Log.Logger)
+	a.Assert().Error(err)
+	a.Assert().False(complete)
+}
+
+func (a *AWSTestSuite) TestIsInstallationBackupCompleteTransientError() {
+	backup := &model.InstallationBackup{
+		DatabaseSnapshotID: RDSBackupSnapshotID(a.InstallationA.ID, "backup"),
+		FilestoreCopied:    true,
+	}
+
+	a.Mocks.API.RDS.EXPECT().
+		DescribeDBClusterSnapshots(gomock.Any()).
+		Return(nil, errors.New("throttled")).
+		Times(1)
+
+	a.Mocks.Log.Logger.EXPECT().
+		WithError(gomock.Any()).
+		Return(testlib.NewLoggerEntry()).
+		Times(1)
+
+	complete, err := a.Mocks.AWS.IsInstallationBackupComplete(backup, a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+	a.Assert().False(complete)
+}
+
+func (a *AWSTestSuite) TestIsInstallationBackupCompleteCopiesFilestore() {
+	backup := &model.InstallationBackup{
+		InstallationID:      a.InstallationA.ID,
+		DatabaseSnapshotID:  RDSBackupSnapshotID(a.InstallationA.ID, "backup"),
+		FilestoreBucket:     S3BackupBucketName(a.InstallationA.ID),
+		FilestorePrefix:     "backup/",
+		FilestoreCopyMarker: "data/a",
+	}
+
+	a.Mocks.API.S3.EXPECT().
+		ListObjectsV2(gomock.Any()).
+		Return(&s3.ListObjectsV2Output{
+			Contents:    []*s3.Object{{Key: aws.String("data/b")}},
+			IsTruncated: aws.Bool(true),
+		}, nil).
+		Do(func(input *s3.ListObjectsV2Input) {
+			a.Assert().Equal(CloudID(a.InstallationA.ID), *input.Bucket)
+			a.Assert().Equal("data/a", *input.StartAfter)
+		}).
+		Times(1)
+
+	a.Mocks.API.S3.EXPECT().
+		CopyObject(gomock.Any()).
+		Return(&s3.CopyObjectOutput{}, nil).
+		Do(func(input *s3.CopyObjectInput) {
+			a.Assert().Equal(backup.FilestoreBucket, *input.Bucket)
+			a.Assert().Equal("backup/data/b", *input.Key)
+		}).
+		Times(1)
+
+	a.Mocks.API.RDS.EXPECT().
+		DescribeDBClusterSnapshots(gomock.Any()).
+		Return(&rds.DescribeDBClusterSnapshotsOutput{
+			DBClusterSnapshots: []*rds.DBClusterSnapshot{{Status: aws.String("available")}},
+		}, nil).
+		Times(1)
+
+	a.Mocks.Log.Logger.EXPECT().
+		WithFields(gomock.Any()).
+		Return(testlib.NewLoggerEntry()).
+		Times(1)
+
+	complete, err := a.Mocks.AWS.IsInstallationBackupComplete(backup, a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+	a.Assert().False(complete)
+	a.Assert().Equal("data/b", backup.FilestoreCopyMarker)
+	a.Assert().False(backup.FilestoreCopied)
+}
+
+func (a *AWSTestSuite) TestCreateInstallationBackupUnsupported() {
+	installation := &model.Installation{
+		ID:        a.InstallationA.ID,
+		Database:  model.InstallationDatabaseMysqlOperator,
+		Filestore: model.InstallationFilestoreAwsS3,
+	}
+
+	err := a.Mocks.AWS.CreateInstallationBackup(installation, &model.InstallationBackup{ID: "backup"}, a.Mocks.Log.Logger)
+	a.Assert().Error(err)
+}
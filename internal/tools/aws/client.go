@@ -90,6 +90,9 @@ type Client struct {
 
 // Service contructs an AWS session if not yet successfully done and returns AWS clients.
 func (c *Client) Service() *Service {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
 	if c.service == nil {
 		sess, err := NewAWSSessionWithLogger(c.config, c.logger.WithField("tools-aws", "client"))
 		if err != nil {
@@ -98,9 +101,7 @@ func (c *Client) Service() *Service {
 			return NewService(&session.Session{})
 		}
 
-		c.mux.Lock()
 		c.service = NewService(sess)
-		c.mux.Unlock()
 	}
 
 	return c.service
@@ -108,12 +109,18 @@ func (c *Client) Service() *Service {
 
 // AddSQLStore adds SQLStore functionality to the AWS client.
 func (c *Client) AddSQLStore(store model.InstallationDatabaseStoreInterface) {
-	if !c.HasSQLStore() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.store == nil {
 		c.store = store
 	}
 }
 
 // HasSQLStore returns whether the AWS client has a SQL store or not.
 func (c *Client) HasSQLStore() bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
 	return c.store != nil
 }
@@ -196,6 +196,31 @@ func (a *AWSTestSuite) TestNewClient() {
 	a.Assert().Error(err)
 }
 
+func (a *AWSTestSuite) TestClientConcurrentInitialization() {
+	client := &Client{
+		logger: logrus.New(),
+		mux:    &sync.Mutex{},
+	}
+	store := a.Mocks.Model.DatabaseInstallationStore
+
+	var wg sync.WaitGroup
+	services := make([]*Service, 10)
+	for i := range services {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client.AddSQLStore(store)
+			services[i] = client.Service()
+		}(i)
+	}
+	wg.Wait()
+
+	a.Assert().True(client.HasSQLStore())
+	for _, service := range services {
+		a.Assert().Same(services[0], service)
+	}
+}
+
 func TestAWSSuite(t *testing.T) {
 	suite.Run(t, NewAWSTestSuite(t))
 }
@@ -102,9 +102,21 @@ const (
 	// existing installations.
 	rdsSuffix = "-rds"
 
+	// backupBucketSuffix is the suffix value used when referencing the S3
+	// bucket holding installation filestore backups.
+	// Warning:
+	// changing this value will break the connection to existing installation
+	// backups.
+	backupBucketSuffix = "-backups"
+
 	// DefaultClusterInstallationSnapshotTagKey is used for tagging snapshots of a cluster installation.
 	DefaultClusterInstallationSnapshotTagKey = "tag:ClusterInstallationSnapshot"
 
+	// RDSPasswordResetPendingTagKey tags DB clusters restored from a snapshot
+	// whose master password still has to be reset to the one of the
+	// installation.
+	RDSPasswordResetPendingTagKey = "MattermostCloudPasswordResetPending"
+
 	// DefaultAWSClientRetries supplies how many time the AWS client will retry a failed call.
 	DefaultAWSClientRetries = 3
 
@@ -5,8 +5,6 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/ec2"
-	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/aws/aws-sdk-go/service/rds"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -23,6 +21,7 @@ const connStringTemplate = "mysql://%s:%s@tcp(%s:3306)/mattermost?charset=utf8mb
 type RDSDatabase struct {
 	client         *Client
 	installationID string
+	snapshotID     string
 }
 
 // NewRDSDatabase returns a new RDSDatabase interface.
@@ -33,16 +32,29 @@ func NewRDSDatabase(installationID string, client *Client) *RDSDatabase {
 	}
 }
 
-// Provision completes all the steps necessary to provision a RDS database.
-func (d *RDSDatabase) Provision(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+// NewRDSDatabaseFromSnapshot returns a new RDSDatabase interface that is
+// provisioned by restoring the given DB cluster snapshot.
+func NewRDSDatabaseFromSnapshot(installationID, snapshotID string, client *Client) *RDSDatabase {
+	return &RDSDatabase{
+		client:         client,
+		installationID: installationID,
+		snapshotID:     snapshotID,
+	}
+}
+
+// Provision completes all the steps necessary to provision a RDS database,
+// returning whether the database is ready. A database restored from a
+// snapshot is not ready until the restored DB cluster is available, so
+// Provision must be called again until then.
+func (d *RDSDatabase) Provision(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (bool, error) {
 	d.client.AddSQLStore(store)
 
-	err := d.rdsDatabaseProvision(d.installationID, logger)
+	ready, err := d.rdsDatabaseProvision(d.installationID, logger)
 	if err != nil {
-		return errors.Wrap(err, "unable to provision RDS database")
+		return false, errors.Wrap(err, "unable to provision RDS database")
 	}
 
-	return nil
+	return ready, nil
 }
 
 // Teardown removes all AWS resources related to a RDS database.
@@ -134,14 +146,14 @@ func (d *RDSDatabase) GenerateDatabaseSpecAndSecret(logger log.FieldLogger) (*mm
 	return databaseSpec, databaseSecret, nil
 }
 
-func (d *RDSDatabase) rdsDatabaseProvision(installationID string, logger log.FieldLogger) error {
+func (d *RDSDatabase) rdsDatabaseProvision(installationID string, logger log.FieldLogger) (bool, error) {
 	awsID := CloudID(installationID)
 	logger.Infof("Provisioning AWS RDS database with ID %s", awsID)
 
 	// To properly provision the database we need a SQL client to lookup which
 	// cluster(s) the installation is running on.
 	if !d.client.HasSQLStore() {
-		return errors.New("the provided AWS client does not have SQL store access")
+		return false, errors.New("the provided AWS client does not have SQL store access")
 	}
 
 	clusterInstallations, err := d.client.store.GetClusterInstallations(&model.ClusterInstallationFilter{
@@ -149,65 +161,51 @@ func (d *RDSDatabase) rdsDatabaseProvision(installationID string, logger log.Fie
 		InstallationID: installationID,
 	})
 	if err != nil {
-		return errors.Wrapf(err, "unable to lookup cluster installations for installation %s", installationID)
+		return false, errors.Wrapf(err, "unable to lookup cluster installations for installation %s", installationID)
 	}
 
 	clusterInstallationCount := len(clusterInstallations)
 	if clusterInstallationCount == 0 {
-		return fmt.Errorf("no cluster installations found for %s", installationID)
+		return false, fmt.Errorf("no cluster installations found for %s", installationID)
 	}
 	if clusterInstallationCount != 1 {
-		return fmt.Errorf("RDS provisioning is not currently supported for multiple cluster installations (found %d)", clusterInstallationCount)
+		return false, fmt.Errorf("RDS provisioning is not currently supported for multiple cluster installations (found %d)", clusterInstallationCount)
 	}
 
-	clusterID := clusterInstallations[0].ClusterID
-	vpcFilters := []*ec2.Filter{
-		{
-			Name:   aws.String(VpcClusterIDTagKey),
-			Values: []*string{aws.String(clusterID)},
-		},
-		{
-			Name:   aws.String(VpcAvailableTagKey),
-			Values: []*string{aws.String(VpcAvailableTagValueFalse)},
-		},
-	}
-	vpcs, err := d.client.GetVpcsWithFilters(vpcFilters)
+	vpcID, err := d.client.getClusterVpcID(clusterInstallations[0].ClusterID)
 	if err != nil {
-		return err
-	}
-	if len(vpcs) != 1 {
-		return fmt.Errorf("expected 1 VPC for cluster %s, but got %d", clusterID, len(vpcs))
+		return false, err
 	}
 
 	rdsSecret, err := d.client.secretsManagerEnsureRDSSecretCreated(awsID, logger)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	encryptionKey, err := d.client.kmsCreateSymmetricKey(awsID, "Key used for encrypting RDS database")
+	encryptionKey, err := d.client.kmsEnsureSymmetricKeyCreated(awsID, KMSAliasNameRDS(awsID), "Key used for encrypting RDS database", logger)
 	if err != nil {
-		return errors.Wrapf(err, "unable to create RDS encryption key for installation %s", installationID)
+		return false, errors.Wrapf(err, "unable to create RDS encryption key for installation %s", installationID)
 	}
 
-	err = d.client.kmsCreateAlias(*encryptionKey.KeyId, KMSAliasNameRDS(awsID))
-	if err != nil && !IsErrorCode(err, kms.ErrCodeAlreadyExistsException) {
-		deletionKeyErr := d.client.kmsScheduleKeyDeletion(*encryptionKey.KeyId, KMSMinTimeEncryptionKeyDeletion)
-		if deletionKeyErr != nil {
-			logger.WithError(deletionKeyErr).Errorf("Failed to schedule encryption key %s for deletition", *encryptionKey.KeyId)
+	if d.snapshotID != "" {
+		restored, err := d.client.rdsEnsureDBClusterRestored(awsID, d.snapshotID, vpcID, rdsSecret.MasterPassword, *encryptionKey.KeyId, logger)
+		if err != nil {
+			return false, err
+		}
+		if !restored {
+			return false, nil
+		}
+	} else {
+		err = d.client.rdsEnsureDBClusterCreated(awsID, vpcID, rdsSecret.MasterUsername, rdsSecret.MasterPassword, *encryptionKey.KeyId, logger)
+		if err != nil {
+			return false, err
 		}
-
-		return errors.Wrapf(err, "unable to create a RDS encryption key alias name for installation %s", installationID)
-	}
-
-	err = d.client.rdsEnsureDBClusterCreated(awsID, *vpcs[0].VpcId, rdsSecret.MasterUsername, rdsSecret.MasterPassword, *encryptionKey.KeyId, logger)
-	if err != nil {
-		return err
 	}
 
 	err = d.client.rdsEnsureDBClusterInstanceCreated(awsID, fmt.Sprintf("%s-master", awsID), logger)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return true, nil
 }
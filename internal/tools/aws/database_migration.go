@@ -2,158 +2,204 @@ package aws
 
 import (
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/rds"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/mattermost/mattermost-cloud/model"
 )
 
-// RDSDatabaseMigration is a migrated database backed by AWS RDS.
+// RDSDatabaseMigration moves the RDS database of an installation into the VPC
+// of the cluster the installation is migrated to.
+//
+// The database is snapshotted and the snapshot restored into the target VPC.
+// The restored DB cluster then takes over the name of the installation DB
+// cluster, so that cluster installations created afterwards connect to it,
+// while the source DB cluster is kept under another name until teardown.
 type RDSDatabaseMigration struct {
-	awsClient            *Client
-	masterInstallationID string
-	slaveInstallationID  string
+	awsClient       *Client
+	installationID  string
+	targetClusterID string
 }
 
 // NewRDSDatabaseMigration returns a new RDSDatabaseMigration.
-func NewRDSDatabaseMigration(masterInstallationID, slaveInstallationID string, awsClient *Client) *RDSDatabaseMigration {
+func NewRDSDatabaseMigration(installationID, targetClusterID string, awsClient *Client) *RDSDatabaseMigration {
 	return &RDSDatabaseMigration{
-		awsClient:            awsClient,
-		masterInstallationID: masterInstallationID,
-		slaveInstallationID:  slaveInstallationID,
+		awsClient:       awsClient,
+		installationID:  installationID,
+		targetClusterID: targetClusterID,
 	}
 }
 
-// Setup sets access from one RDS database to another and sets any configuration needed for replication.
+// Setup snapshots the installation database. The snapshot only holds every
+// change once the installation stopped writing to the database, so the
+// cluster installations of the installation must be stopped beforehand.
 func (d *RDSDatabaseMigration) Setup(logger log.FieldLogger) (string, error) {
-	masterInstanceSG, err := d.describeDBInstanceSecurityGroup(RDSMasterInstanceID(d.masterInstallationID))
+	snapshotID := RDSMigrationSnapshotID(d.installationID)
+
+	err := d.awsClient.rdsEnsureDBClusterSnapshotCreated(CloudID(d.installationID), snapshotID, logger)
 	if err != nil {
 		return "", d.toSetupError(err)
 	}
 
-	slaveInstanceSG, err := d.describeDBInstanceSecurityGroup(RDSMigrationInstanceID(d.slaveInstallationID))
+	status, err := d.awsClient.rdsGetDBClusterSnapshotStatus(snapshotID)
 	if err != nil {
 		return "", d.toSetupError(err)
 	}
 
-	_, err = d.awsClient.Service().ec2.AuthorizeSecurityGroupIngress(&ec2.AuthorizeSecurityGroupIngressInput{
-		GroupId: masterInstanceSG.GroupId,
-		IpPermissions: []*ec2.IpPermission{
-			{
-				FromPort:   aws.Int64(3306),
-				IpProtocol: aws.String("tcp"),
-				ToPort:     aws.Int64(3306),
-				UserIdGroupPairs: []*ec2.UserIdGroupPair{
-					{
-						Description: aws.String("Ingress Traffic from other RDS instance"),
-						GroupId:     slaveInstanceSG.GroupId,
-					},
-				},
-			},
-		},
-	})
-	// Why "InvalidPermission.Duplicate" is hardcoded? https://github.com/aws/aws-sdk-go/issues/3235
-	if err != nil && !IsErrorCode(err, "InvalidPermission.Duplicate") {
-		return "", d.toSetupError(err)
+	switch status {
+	case "available":
+		logger.WithFields(log.Fields{
+			"installation-id":   d.installationID,
+			"target-cluster-id": d.targetClusterID,
+		}).Info("Database migration setup completed")
+		return model.DatabaseMigrationStatusSetupComplete, nil
+	case "creating":
+		return model.DatabaseMigrationStatusSetupIP, nil
 	}
 
-	logger.WithFields(logrus.Fields{
-		"master-installation-id": d.masterInstallationID,
-		"slave-installation-id":  d.slaveInstallationID,
-	}).Info("Database migration setup completed")
-
-	return model.DatabaseMigrationStatusSetupComplete, nil
+	return "", d.toSetupError(errors.Errorf("DB cluster snapshot %s is in unexpected status %s", snapshotID, status))
 }
 
-// Teardown removes access from one RDS database to another and rollback any previous database configuration.
+// Teardown deletes the source DB cluster, along with its DB instances, and the
+// snapshot the installation database was moved with.
 func (d *RDSDatabaseMigration) Teardown(logger log.FieldLogger) (string, error) {
-	masterInstanceSG, err := d.describeDBInstanceSecurityGroup(RDSMasterInstanceID(d.masterInstallationID))
+	err := d.awsClient.rdsEnsureDBClusterDeleted(RDSMigrationSourceClusterID(d.installationID), logger)
 	if err != nil {
 		return "", d.toTeardownError(err)
 	}
 
-	slaveInstanceSG, err := d.describeDBInstanceSecurityGroup(RDSMigrationInstanceID(d.slaveInstallationID))
+	err = d.awsClient.rdsEnsureDBClusterSnapshotDeleted(RDSMigrationSnapshotID(d.installationID), logger)
 	if err != nil {
 		return "", d.toTeardownError(err)
 	}
 
-	_, err = d.awsClient.Service().ec2.RevokeSecurityGroupIngress(&ec2.RevokeSecurityGroupIngressInput{
-		GroupId: masterInstanceSG.GroupId,
-		IpPermissions: []*ec2.IpPermission{
-			{
-				FromPort:   aws.Int64(3306),
-				IpProtocol: aws.String("tcp"),
-				ToPort:     aws.Int64(3306),
-				UserIdGroupPairs: []*ec2.UserIdGroupPair{
-					{
-						GroupId: slaveInstanceSG.GroupId,
-					},
-				},
-			},
-		},
-	})
-	// Why "InvalidPermission.NotFound" is hardcoded? https://github.com/aws/aws-sdk-go/issues/3235
-	if err != nil && !IsErrorCode(err, "InvalidPermission.NotFound") {
-		return "", d.toTeardownError(err)
-	}
-
-	logger.WithFields(logrus.Fields{
-		"master-installation-id": d.masterInstallationID,
-		"slave-installation-id":  d.slaveInstallationID,
+	logger.WithFields(log.Fields{
+		"installation-id":   d.installationID,
+		"target-cluster-id": d.targetClusterID,
 	}).Info("Database migration teardown completed")
 
 	return model.DatabaseMigrationStatusTeardownComplete, nil
 }
 
-// Replicate starts the process for replicating an master RDS database. This method must return an
-// resplication status or an error.
+// Replicate restores the snapshot taken by Setup into the VPC of the target
+// cluster and swaps the restored DB cluster in for the installation DB
+// cluster. Each call advances the process by one step, so this method must be
+// called until it returns a complete replication status.
 func (d *RDSDatabaseMigration) Replicate(logger log.FieldLogger) (string, error) {
-	return "", errors.New("not implemented")
+	awsID := CloudID(d.installationID)
+	migrationClusterID := RDSMigrationClusterID(d.installationID)
+
+	vpcID, err := d.awsClient.getClusterVpcID(d.targetClusterID)
+	if err != nil {
+		return "", d.toReplicationError(err)
+	}
+
+	dbCluster, err := d.awsClient.rdsGetDBCluster(awsID)
+	if err != nil {
+		return "", d.toReplicationError(err)
+	}
+	if dbCluster != nil && aws.StringValue(dbCluster.DBSubnetGroup) == DBSubnetGroupName(vpcID) {
+		if aws.StringValue(dbCluster.Status) != "available" {
+			return model.DatabaseMigrationStatusReplicationIP, nil
+		}
+
+		logger.WithFields(log.Fields{
+			"installation-id":   d.installationID,
+			"target-cluster-id": d.targetClusterID,
+		}).Info("Database migration replication completed")
+
+		return model.DatabaseMigrationStatusReplicationComplete, nil
+	}
+
+	migrationCluster, err := d.awsClient.rdsGetDBCluster(migrationClusterID)
+	if err != nil {
+		return "", d.toReplicationError(err)
+	}
+	if migrationCluster == nil {
+		if dbCluster == nil {
+			return "", d.toReplicationError(errors.Errorf("DB cluster %s not found", awsID))
+		}
+
+		encryptionKey, err := d.awsClient.kmsGetSymmetricKey(KMSAliasNameRDS(awsID))
+		if err != nil {
+			return "", d.toReplicationError(errors.Wrap(err, "unable to get RDS encryption key"))
+		}
+
+		err = d.awsClient.rdsRestoreDBClusterFromSnapshot(migrationClusterID, RDSMigrationSnapshotID(d.installationID), vpcID, *encryptionKey.KeyId, nil, logger)
+		if err != nil {
+			return "", d.toReplicationError(err)
+		}
+
+		return model.DatabaseMigrationStatusReplicationIP, nil
+	}
+	if aws.StringValue(migrationCluster.Status) != "available" {
+		return model.DatabaseMigrationStatusReplicationIP, nil
+	}
+
+	instanceID := RDSMigrationInstanceID(d.installationID)
+	err = d.awsClient.rdsEnsureDBClusterInstanceCreated(migrationClusterID, instanceID, logger)
+	if err != nil {
+		return "", d.toReplicationError(err)
+	}
+
+	status, err := d.awsClient.rdsGetDBInstanceStatus(instanceID)
+	if err != nil {
+		return "", d.toReplicationError(err)
+	}
+	if status != "available" {
+		return model.DatabaseMigrationStatusReplicationIP, nil
+	}
+
+	// The source DB cluster first gives up the installation DB cluster name,
+	// which the restored DB cluster takes over once free.
+	if dbCluster != nil {
+		if aws.StringValue(dbCluster.Status) != "available" {
+			return model.DatabaseMigrationStatusReplicationIP, nil
+		}
+
+		err = d.renameDBCluster(awsID, RDSMigrationSourceClusterID(d.installationID), logger)
+		if err != nil {
+			return "", d.toReplicationError(err)
+		}
+
+		return model.DatabaseMigrationStatusReplicationIP, nil
+	}
+
+	err = d.renameDBCluster(migrationClusterID, awsID, logger)
+	if err != nil {
+		return "", d.toReplicationError(err)
+	}
+
+	return model.DatabaseMigrationStatusReplicationIP, nil
 }
 
-func (d *RDSDatabaseMigration) describeDBInstanceSecurityGroup(instanceID string) (*ec2.SecurityGroup, error) {
-	output, err := d.awsClient.Service().rds.DescribeDBInstances(&rds.DescribeDBInstancesInput{
-		DBInstanceIdentifier: aws.String(instanceID),
+func (d *RDSDatabaseMigration) renameDBCluster(awsID, newAWSID string, logger log.FieldLogger) error {
+	_, err := d.awsClient.Service().rds.ModifyDBCluster(&rds.ModifyDBClusterInput{
+		DBClusterIdentifier:    aws.String(awsID),
+		NewDBClusterIdentifier: aws.String(newAWSID),
+		ApplyImmediately:       aws.Bool(true),
 	})
 	if err != nil {
-		return nil, err
-	}
-
-	for _, instance := range output.DBInstances {
-		for _, vpcSG := range instance.VpcSecurityGroups {
-			sgOutput, err := d.awsClient.Service().ec2.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
-				GroupIds: []*string{vpcSG.VpcSecurityGroupId},
-			})
-			if err != nil {
-				return nil, err
-			}
-			if len(sgOutput.SecurityGroups) == 1 && isRDSInstanceSecurityGroup(sgOutput.SecurityGroups[0]) {
-				return sgOutput.SecurityGroups[0], nil
-			}
-		}
+		return errors.Wrapf(err, "unable to rename DB cluster %s to %s", awsID, newAWSID)
 	}
 
-	return nil, errors.Errorf("security group for RDS DB instance %s not found", instanceID)
+	logger.WithField("db-cluster-name", awsID).Debugf("AWS DB cluster renamed to %s", newAWSID)
+
+	return nil
 }
 
 func (d *RDSDatabaseMigration) toSetupError(err error) error {
-	return errors.Wrapf(err, "unable to setup database migration for master installation id: %s and to slave installation id: %s",
-		d.masterInstallationID, d.masterInstallationID)
+	return errors.Wrapf(err, "unable to setup database migration for installation id: %s to cluster id: %s",
+		d.installationID, d.targetClusterID)
 }
 
 func (d *RDSDatabaseMigration) toTeardownError(err error) error {
-	return errors.Wrapf(err, "unable to setup database migration for master installation id: %s and to slave installation id: %s",
-		d.masterInstallationID, d.masterInstallationID)
+	return errors.Wrapf(err, "unable to teardown database migration for installation id: %s to cluster id: %s",
+		d.installationID, d.targetClusterID)
 }
 
-func isRDSInstanceSecurityGroup(securityGroup *ec2.SecurityGroup) bool {
-	for _, tag := range securityGroup.Tags {
-		if *tag.Key == trimTagPrefix(DefaultDBSecurityGroupTagKey) && *tag.Value == DefaultDBSecurityGroupTagValue {
-			return true
-		}
-	}
-	return false
+func (d *RDSDatabaseMigration) toReplicationError(err error) error {
+	return errors.Wrapf(err, "unable to replicate database for installation id: %s to cluster id: %s",
+		d.installationID, d.targetClusterID)
 }
@@ -4,51 +4,45 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/aws/aws-sdk-go/service/rds"
 	"github.com/golang/mock/gomock"
 	testlib "github.com/mattermost/mattermost-cloud/internal/testlib"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 )
 
 func (a *AWSTestSuite) TestDatabaseRDSMigrationSetup() {
-	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterInstallationB.InstallationID, a.Mocks.AWS)
+	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterB.ID, a.Mocks.AWS)
+	a.ExpectAnyLogging()
 
 	gomock.InOrder(
-		a.SetDescribeDBInstancesExpectation("sg-123-id-master").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-db-sg-123-master", "vpc-db-sg-123-id-master", a.DefaultRDSTag).Times(1),
-		a.SetDescribeDBInstancesExpectation("sg-123-id-slave").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-db-sg-123-slave", "vpc-db-sg-123-id-slave", a.DefaultRDSTag).Times(1),
-		a.SetAuthorizeSecurityGroupIngress("Ingress Traffic from other RDS instance", "vpc-db-sg-123-master", "vpc-db-sg-123-slave").
-			Return(&ec2.AuthorizeSecurityGroupIngressOutput{}, nil).
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBClusterSnapshots(gomock.Any()).
+			Return(nil, awserr.New(rds.ErrCodeDBClusterSnapshotNotFoundFault, "snapshot not found", nil)).
 			Times(1),
-		a.Mocks.Log.Logger.EXPECT().
-			WithFields(logrus.Fields{"master-installation-id": a.InstallationA.ID, "slave-installation-id": a.ClusterInstallationB.InstallationID}).
-			Return(testlib.NewLoggerEntry()).
+		a.Mocks.API.RDS.EXPECT().
+			CreateDBClusterSnapshot(gomock.Any()).
+			Do(func(input *rds.CreateDBClusterSnapshotInput) {
+				a.Assert().Equal(CloudID(a.InstallationA.ID), *input.DBClusterIdentifier)
+				a.Assert().Equal(RDSMigrationSnapshotID(a.InstallationA.ID), *input.DBClusterSnapshotIdentifier)
+			}).
+			Return(&rds.CreateDBClusterSnapshotOutput{}, nil).
 			Times(1),
+		a.SetDescribeDBClusterSnapshotsStatusExpectation("creating").Times(1),
 	)
 
 	status, err := database.Setup(a.Mocks.Log.Logger)
 	a.Assert().NoError(err)
-	a.Assert().Equal(model.DatabaseMigrationStatusSetupComplete, status)
+	a.Assert().Equal(model.DatabaseMigrationStatusSetupIP, status)
 }
 
-func (a *AWSTestSuite) TestDatabaseRDSMigrationSetupAlreadyExist() {
-	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterInstallationB.InstallationID, a.Mocks.AWS)
+func (a *AWSTestSuite) TestDatabaseRDSMigrationSetupComplete() {
+	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterB.ID, a.Mocks.AWS)
+	a.ExpectAnyLogging()
 
 	gomock.InOrder(
-		a.SetDescribeDBInstancesExpectation("sg-123-id-master").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-db-sg-123-master", "vpc-db-sg-123-id-master", a.DefaultRDSTag).Times(1),
-		a.SetDescribeDBInstancesExpectation("sg-123-id-slave").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-db-sg-123-slave", "vpc-db-sg-123-id-slave", a.DefaultRDSTag).Times(1),
-		a.SetAuthorizeSecurityGroupIngress("Ingress Traffic from other RDS instance", "vpc-db-sg-123-master", "vpc-db-sg-123-slave").
-			Return(nil, awserr.New("InvalidPermission.Duplicate", "rule already exists", nil)).
-			Times(1),
-		a.Mocks.Log.Logger.EXPECT().
-			WithFields(logrus.Fields{"master-installation-id": a.InstallationA.ID, "slave-installation-id": a.ClusterInstallationB.InstallationID}).
-			Return(testlib.NewLoggerEntry()).
-			Times(1),
+		a.SetDescribeDBClusterSnapshotsStatusExpectation("available").Times(2),
 	)
 
 	status, err := database.Setup(a.Mocks.Log.Logger)
@@ -57,57 +51,76 @@ func (a *AWSTestSuite) TestDatabaseRDSMigrationSetupAlreadyExist() {
 }
 
 func (a *AWSTestSuite) TestDatabaseRDSMigrationSetupError() {
-	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterInstallationB.InstallationID, a.Mocks.AWS)
+	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterB.ID, a.Mocks.AWS)
+	a.ExpectAnyLogging()
 
-	gomock.InOrder(
-		a.SetDescribeDBInstancesExpectation("sg-123-id-master").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-db-sg-123-master", "vpc-db-sg-123-id-master", a.DefaultRDSTag).Times(1),
-		a.SetDescribeDBInstancesExpectation("sg-123-id-slave").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-db-sg-123-slave", "vpc-db-sg-123-id-slave", a.DefaultRDSTag).Times(1),
-		a.SetAuthorizeSecurityGroupIngress("Ingress Traffic from other RDS instance", "vpc-db-sg-123-master", "vpc-db-sg-123-slave").
-			Return(nil, errors.New("invalid group id")).
-			Times(1),
-	)
+	a.SetDescribeDBClusterSnapshotsStatusExpectation("failed").Times(2)
 
 	status, err := database.Setup(a.Mocks.Log.Logger)
 	a.Assert().Error(err)
-	a.Assert().Equal("unable to setup database migration for master installation id: "+
-		"id000000000000000000000000a and to slave installation id: id000000000000000000000000a: invalid group id", err.Error())
+	a.Assert().Equal("unable to setup database migration for installation id: id000000000000000000000000a to cluster id: "+
+		"id000000000000000000000000b: DB cluster snapshot cloud-id000000000000000000000000a-migration is in unexpected status failed", err.Error())
 	a.Assert().Equal("", status)
 }
 
-func (a *AWSTestSuite) TestDatabaseRDSMigrationSetupSGNotFoundError() {
-	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterInstallationB.InstallationID, a.Mocks.AWS)
+func (a *AWSTestSuite) TestDatabaseRDSMigrationTeardown() {
+	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterB.ID, a.Mocks.AWS)
+	a.ExpectAnyLogging()
 
 	gomock.InOrder(
-		a.SetDescribeDBInstancesExpectation("sg-123-id-master").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-123-master", "vpc-sg-123-id-master", &ec2.Tag{
-			Key:   aws.String("dummy_tag)"),
-			Value: aws.String(DefaultDBSecurityGroupTagValue),
-		}).Times(1),
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBClusters(gomock.Any()).
+			Do(func(input *rds.DescribeDBClustersInput) {
+				a.Assert().Equal(RDSMigrationSourceClusterID(a.InstallationA.ID), *input.DBClusterIdentifier)
+			}).
+			Return(&rds.DescribeDBClustersOutput{
+				DBClusters: []*rds.DBCluster{{
+					DBClusterMembers: []*rds.DBClusterMember{{
+						DBInstanceIdentifier: aws.String(RDSMasterInstanceID(a.InstallationA.ID)),
+					}},
+				}},
+			}, nil).
+			Times(1),
+		a.Mocks.API.RDS.EXPECT().
+			DeleteDBInstance(gomock.Any()).
+			Do(func(input *rds.DeleteDBInstanceInput) {
+				a.Assert().Equal(RDSMasterInstanceID(a.InstallationA.ID), *input.DBInstanceIdentifier)
+			}).
+			Return(&rds.DeleteDBInstanceOutput{}, nil).
+			Times(1),
+		a.Mocks.API.RDS.EXPECT().
+			DeleteDBCluster(gomock.Any()).
+			Do(func(input *rds.DeleteDBClusterInput) {
+				a.Assert().Equal(RDSMigrationSourceClusterID(a.InstallationA.ID), *input.DBClusterIdentifier)
+			}).
+			Return(&rds.DeleteDBClusterOutput{}, nil).
+			Times(1),
+		a.Mocks.API.RDS.EXPECT().
+			DeleteDBClusterSnapshot(gomock.Any()).
+			Do(func(input *rds.DeleteDBClusterSnapshotInput) {
+				a.Assert().Equal(RDSMigrationSnapshotID(a.InstallationA.ID), *input.DBClusterSnapshotIdentifier)
+			}).
+			Return(&rds.DeleteDBClusterSnapshotOutput{}, nil).
+			Times(1),
 	)
 
-	status, err := database.Setup(a.Mocks.Log.Logger)
-	a.Assert().Error(err)
-	a.Assert().Equal("unable to setup database migration for master installation id: id000000000000000000000000a and "+
-		"to slave installation id: id000000000000000000000000a: security group for RDS DB instance cloud-id000000000000000000000000a-master not found", err.Error())
-	a.Assert().Equal("", status)
+	status, err := database.Teardown(a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+	a.Assert().Equal(model.DatabaseMigrationStatusTeardownComplete, status)
 }
 
-func (a *AWSTestSuite) TestDatabaseRDSMigrationTeardown() {
-	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterInstallationB.InstallationID, a.Mocks.AWS)
+func (a *AWSTestSuite) TestDatabaseRDSMigrationTeardownAlreadyDeleted() {
+	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterB.ID, a.Mocks.AWS)
+	a.ExpectAnyLogging()
 
 	gomock.InOrder(
-		a.SetDescribeDBInstancesExpectation("sg-123-id-master").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-db-sg-123-master", "vpc-db-sg-123-id-master", a.DefaultRDSTag).Times(1),
-		a.SetDescribeDBInstancesExpectation("sg-123-id-slave").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-db-sg-123-slave", "vpc-db-sg-123-id-slave", a.DefaultRDSTag).Times(1),
-		a.SetRevokeSecurityGroupIngress("Ingress Traffic from other RDS instance", "vpc-db-sg-123-master", "vpc-db-sg-123-slave").
-			Return(&ec2.RevokeSecurityGroupIngressOutput{}, nil).
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBClusters(gomock.Any()).
+			Return(nil, awserr.New(rds.ErrCodeDBClusterNotFoundFault, "cluster not found", nil)).
 			Times(1),
-		a.Mocks.Log.Logger.EXPECT().
-			WithFields(logrus.Fields{"master-installation-id": a.InstallationA.ID, "slave-installation-id": a.ClusterInstallationB.InstallationID}).
-			Return(testlib.NewLoggerEntry()).
+		a.Mocks.API.RDS.EXPECT().
+			DeleteDBClusterSnapshot(gomock.Any()).
+			Return(nil, awserr.New(rds.ErrCodeDBClusterSnapshotNotFoundFault, "snapshot not found", nil)).
 			Times(1),
 	)
 
@@ -116,109 +129,222 @@ func (a *AWSTestSuite) TestDatabaseRDSMigrationTeardown() {
 	a.Assert().Equal(model.DatabaseMigrationStatusTeardownComplete, status)
 }
 
-func (a *AWSTestSuite) TestDatabaseRDSMigrationTeardownRuleError() {
-	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterInstallationB.InstallationID, a.Mocks.AWS)
+func (a *AWSTestSuite) TestDatabaseRDSMigrationTeardownError() {
+	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterB.ID, a.Mocks.AWS)
+	a.ExpectAnyLogging()
+
+	a.Mocks.API.RDS.EXPECT().
+		DescribeDBClusters(gomock.Any()).
+		Return(nil, errors.New("not enough permissions")).
+		Times(1)
+
+	status, err := database.Teardown(a.Mocks.Log.Logger)
+	a.Assert().Error(err)
+	a.Assert().Equal("unable to teardown database migration for installation id: id000000000000000000000000a to cluster id: "+
+		"id000000000000000000000000b: not enough permissions", err.Error())
+	a.Assert().Equal("", status)
+}
+
+func (a *AWSTestSuite) TestDatabaseRDSMigrationReplicateRestore() {
+	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterB.ID, a.Mocks.AWS)
+	a.ExpectAnyLogging()
 
 	gomock.InOrder(
-		a.SetDescribeDBInstancesExpectation("sg-123-id-master").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-db-sg-123-master", "vpc-db-sg-123-id-master", a.DefaultRDSTag).Times(1),
-		a.SetDescribeDBInstancesExpectation("sg-123-id-slave").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-db-sg-123-slave", "vpc-db-sg-123-id-slave", a.DefaultRDSTag).Times(1),
-		a.SetRevokeSecurityGroupIngress("Ingress Traffic from other RDS instance", "vpc-db-sg-123-master", "vpc-db-sg-123-slave").
-			Return(&ec2.RevokeSecurityGroupIngressOutput{}, awserr.New("InvalidPermission.NotFound", "rule not found", nil)).
+		a.SetDescribeVpcsExpectation(a.VPCb).Times(1),
+		a.SetDescribeDBClustersExpectation(CloudID(a.InstallationA.ID), DBSubnetGroupName(a.VPCa), "available").Times(1),
+		a.SetDescribeDBClustersNotFoundExpectation(RDSMigrationClusterID(a.InstallationA.ID)).Times(1),
+		a.Mocks.API.KMS.EXPECT().
+			DescribeKey(gomock.Any()).
+			Return(&kms.DescribeKeyOutput{
+				KeyMetadata: &kms.KeyMetadata{KeyId: aws.String(a.RDSEncryptionKeyID)},
+			}, nil).
+			Times(1),
+		a.Mocks.API.EC2.EXPECT().
+			DescribeSecurityGroups(gomock.Any()).
+			Return(&ec2.DescribeSecurityGroupsOutput{
+				SecurityGroups: []*ec2.SecurityGroup{{GroupId: aws.String(a.GroupID)}},
+			}, nil).
 			Times(1),
-		a.Mocks.Log.Logger.EXPECT().
-			WithFields(logrus.Fields{"master-installation-id": a.InstallationA.ID, "slave-installation-id": a.ClusterInstallationB.InstallationID}).
-			Return(testlib.NewLoggerEntry()).
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBSubnetGroups(gomock.Any()).
+			Return(&rds.DescribeDBSubnetGroupsOutput{
+				DBSubnetGroups: []*rds.DBSubnetGroup{{DBSubnetGroupName: aws.String(DBSubnetGroupName(a.VPCb))}},
+			}, nil).
+			Times(1),
+		a.Mocks.API.RDS.EXPECT().
+			RestoreDBClusterFromSnapshot(gomock.Any()).
+			Do(func(input *rds.RestoreDBClusterFromSnapshotInput) {
+				a.Assert().Equal(RDSMigrationClusterID(a.InstallationA.ID), *input.DBClusterIdentifier)
+				a.Assert().Equal(RDSMigrationSnapshotID(a.InstallationA.ID), *input.SnapshotIdentifier)
+				a.Assert().Equal(DBSubnetGroupName(a.VPCb), *input.DBSubnetGroupName)
+				a.Assert().Equal(a.RDSEncryptionKeyID, *input.KmsKeyId)
+			}).
+			Return(&rds.RestoreDBClusterFromSnapshotOutput{}, nil).
 			Times(1),
 	)
 
-	status, err := database.Teardown(a.Mocks.Log.Logger)
+	status, err := database.Replicate(a.Mocks.Log.Logger)
 	a.Assert().NoError(err)
-	a.Assert().Equal(model.DatabaseMigrationStatusTeardownComplete, status)
+	a.Assert().Equal(model.DatabaseMigrationStatusReplicationIP, status)
 }
 
-func (a *AWSTestSuite) TestDatabaseRDSMigrationTeardownError() {
-	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterInstallationB.InstallationID, a.Mocks.AWS)
+func (a *AWSTestSuite) TestDatabaseRDSMigrationReplicateInstanceCreating() {
+	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterB.ID, a.Mocks.AWS)
+	a.ExpectAnyLogging()
 
 	gomock.InOrder(
-		a.SetDescribeDBInstancesExpectation("sg-123-id-master").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-db-sg-123-master", "vpc-db-sg-123-id-master", a.DefaultRDSTag).Times(1),
-		a.SetDescribeDBInstancesExpectation("sg-123-id-slave").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-db-sg-123-slave", "vpc-db-sg-123-id-slave", a.DefaultRDSTag).Times(1),
-		a.SetRevokeSecurityGroupIngress("Ingress Traffic from other RDS instance", "vpc-db-sg-123-master", "vpc-db-sg-123-slave").
-			Return(&ec2.RevokeSecurityGroupIngressOutput{}, errors.New("not enough permissions to revoke ingress rule")).
+		a.SetDescribeVpcsExpectation(a.VPCb).Times(1),
+		a.SetDescribeDBClustersExpectation(CloudID(a.InstallationA.ID), DBSubnetGroupName(a.VPCa), "available").Times(1),
+		a.SetDescribeDBClustersExpectation(RDSMigrationClusterID(a.InstallationA.ID), DBSubnetGroupName(a.VPCb), "available").Times(1),
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBInstances(gomock.Any()).
+			Return(nil, awserr.New(rds.ErrCodeDBInstanceNotFoundFault, "instance not found", nil)).
+			Times(1),
+		a.Mocks.API.RDS.EXPECT().
+			CreateDBInstance(gomock.Any()).
+			Do(func(input *rds.CreateDBInstanceInput) {
+				a.Assert().Equal(RDSMigrationClusterID(a.InstallationA.ID), *input.DBClusterIdentifier)
+				a.Assert().Equal(RDSMigrationInstanceID(a.InstallationA.ID), *input.DBInstanceIdentifier)
+			}).
+			Return(&rds.CreateDBInstanceOutput{}, nil).
 			Times(1),
+		a.SetDescribeDBInstancesStatusExpectation("creating").Times(1),
 	)
 
-	status, err := database.Teardown(a.Mocks.Log.Logger)
-	a.Assert().Error(err)
-	a.Assert().Equal("unable to setup database migration for master installation id: id000000000000000000000000a and "+
-		"to slave installation id: id000000000000000000000000a: not enough permissions to revoke ingress rule", err.Error())
-	a.Assert().Equal("", status)
+	status, err := database.Replicate(a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+	a.Assert().Equal(model.DatabaseMigrationStatusReplicationIP, status)
 }
 
-func (a *AWSTestSuite) TestDatabaseRDSMigrationTeardownSGNotFoundError() {
-	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterInstallationB.InstallationID, a.Mocks.AWS)
+func (a *AWSTestSuite) TestDatabaseRDSMigrationReplicateRenameSource() {
+	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterB.ID, a.Mocks.AWS)
+	a.ExpectAnyLogging()
 
 	gomock.InOrder(
-		a.SetDescribeDBInstancesExpectation("123-id-master").Times(1),
-		a.SetDescribeSecurityGroupsExpectation("vpc-sg-123-master", "vpc-sg-123-id-master", &ec2.Tag{
-			Key:   aws.String("dummy_tag)"),
-			Value: aws.String(DefaultDBSecurityGroupTagValue),
-		}).Times(1),
+		a.SetDescribeVpcsExpectation(a.VPCb).Times(1),
+		a.SetDescribeDBClustersExpectation(CloudID(a.InstallationA.ID), DBSubnetGroupName(a.VPCa), "available").Times(1),
+		a.SetDescribeDBClustersExpectation(RDSMigrationClusterID(a.InstallationA.ID), DBSubnetGroupName(a.VPCb), "available").Times(1),
+		a.SetDescribeDBInstancesStatusExpectation("available").Times(2),
+		a.Mocks.API.RDS.EXPECT().
+			ModifyDBCluster(gomock.Any()).
+			Do(func(input *rds.ModifyDBClusterInput) {
+				a.Assert().Equal(CloudID(a.InstallationA.ID), *input.DBClusterIdentifier)
+				a.Assert().Equal(RDSMigrationSourceClusterID(a.InstallationA.ID), *input.NewDBClusterIdentifier)
+			}).
+			Return(&rds.ModifyDBClusterOutput{}, nil).
+			Times(1),
 	)
 
-	status, err := database.Teardown(a.Mocks.Log.Logger)
+	status, err := database.Replicate(a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+	a.Assert().Equal(model.DatabaseMigrationStatusReplicationIP, status)
+}
+
+func (a *AWSTestSuite) TestDatabaseRDSMigrationReplicateRenameMigration() {
+	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterB.ID, a.Mocks.AWS)
+	a.ExpectAnyLogging()
+
+	gomock.InOrder(
+		a.SetDescribeVpcsExpectation(a.VPCb).Times(1),
+		a.SetDescribeDBClustersNotFoundExpectation(CloudID(a.InstallationA.ID)).Times(1),
+		a.SetDescribeDBClustersExpectation(RDSMigrationClusterID(a.InstallationA.ID), DBSubnetGroupName(a.VPCb), "available").Times(1),
+		a.SetDescribeDBInstancesStatusExpectation("available").Times(2),
+		a.Mocks.API.RDS.EXPECT().
+			ModifyDBCluster(gomock.Any()).
+			Do(func(input *rds.ModifyDBClusterInput) {
+				a.Assert().Equal(RDSMigrationClusterID(a.InstallationA.ID), *input.DBClusterIdentifier)
+				a.Assert().Equal(CloudID(a.InstallationA.ID), *input.NewDBClusterIdentifier)
+			}).
+			Return(&rds.ModifyDBClusterOutput{}, nil).
+			Times(1),
+	)
+
+	status, err := database.Replicate(a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+	a.Assert().Equal(model.DatabaseMigrationStatusReplicationIP, status)
+}
+
+func (a *AWSTestSuite) TestDatabaseRDSMigrationReplicateComplete() {
+	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterB.ID, a.Mocks.AWS)
+	a.ExpectAnyLogging()
+
+	gomock.InOrder(
+		a.SetDescribeVpcsExpectation(a.VPCb).Times(1),
+		a.SetDescribeDBClustersExpectation(CloudID(a.InstallationA.ID), DBSubnetGroupName(a.VPCb), "available").Times(1),
+	)
+
+	status, err := database.Replicate(a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+	a.Assert().Equal(model.DatabaseMigrationStatusReplicationComplete, status)
+}
+
+func (a *AWSTestSuite) TestDatabaseRDSMigrationReplicateError() {
+	database := NewRDSDatabaseMigration(a.InstallationA.ID, a.ClusterB.ID, a.Mocks.AWS)
+	a.ExpectAnyLogging()
+
+	gomock.InOrder(
+		a.SetDescribeVpcsExpectation(a.VPCb).Times(1),
+		a.SetDescribeDBClustersNotFoundExpectation(CloudID(a.InstallationA.ID)).Times(1),
+		a.SetDescribeDBClustersNotFoundExpectation(RDSMigrationClusterID(a.InstallationA.ID)).Times(1),
+	)
+
+	status, err := database.Replicate(a.Mocks.Log.Logger)
 	a.Assert().Error(err)
-	a.Assert().Equal("unable to setup database migration for master installation id: id000000000000000000000000a and to "+
-		"slave installation id: id000000000000000000000000a: security group for RDS DB instance cloud-id000000000000000000000000a-master not found", err.Error())
+	a.Assert().Equal("unable to replicate database for installation id: id000000000000000000000000a to cluster id: "+
+		"id000000000000000000000000b: DB cluster cloud-id000000000000000000000000a not found", err.Error())
 	a.Assert().Equal("", status)
 }
 
 // Helpers
 
-func (a *AWSTestSuite) SetDescribeDBInstancesExpectation(vpcSecurityGroupID string) *gomock.Call {
-	return a.Mocks.API.RDS.EXPECT().DescribeDBInstances(gomock.Any()).
-		Return(&rds.DescribeDBInstancesOutput{
-			DBInstances: []*rds.DBInstance{{
-				VpcSecurityGroups: []*rds.VpcSecurityGroupMembership{{
-					VpcSecurityGroupId: aws.String(vpcSecurityGroupID),
-				}},
+func (a *AWSTestSuite) ExpectAnyLogging() {
+	a.Mocks.Log.Logger.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(testlib.NewLoggerEntry()).AnyTimes()
+	a.Mocks.Log.Logger.EXPECT().WithFields(gomock.Any()).Return(testlib.NewLoggerEntry()).AnyTimes()
+}
+
+func (a *AWSTestSuite) SetDescribeVpcsExpectation(vpcID string) *gomock.Call {
+	return a.Mocks.API.EC2.EXPECT().DescribeVpcs(gomock.Any()).
+		Return(&ec2.DescribeVpcsOutput{
+			Vpcs: []*ec2.Vpc{{VpcId: aws.String(vpcID)}},
+		}, nil)
+}
+
+func (a *AWSTestSuite) SetDescribeDBClustersExpectation(awsID, dbSubnetGroupName, status string) *gomock.Call {
+	return a.Mocks.API.RDS.EXPECT().DescribeDBClusters(gomock.Any()).
+		Do(func(input *rds.DescribeDBClustersInput) {
+			a.Assert().Equal(awsID, *input.DBClusterIdentifier)
+		}).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []*rds.DBCluster{{
+				DBClusterIdentifier: aws.String(awsID),
+				DBSubnetGroup:       aws.String(dbSubnetGroupName),
+				Status:              aws.String(status),
 			}},
 		}, nil)
 }
 
-func (a *AWSTestSuite) SetDescribeSecurityGroupsExpectation(groupID, groupName string, tag *ec2.Tag) *gomock.Call {
-	return a.Mocks.API.EC2.EXPECT().DescribeSecurityGroups(gomock.Any()).
-		Return(&ec2.DescribeSecurityGroupsOutput{
-			SecurityGroups: []*ec2.SecurityGroup{{
-				GroupId:   aws.String(groupID),
-				GroupName: aws.String(groupName),
-				Tags:      []*ec2.Tag{tag},
+func (a *AWSTestSuite) SetDescribeDBClustersNotFoundExpectation(awsID string) *gomock.Call {
+	return a.Mocks.API.RDS.EXPECT().DescribeDBClusters(gomock.Any()).
+		Do(func(input *rds.DescribeDBClustersInput) {
+			a.Assert().Equal(awsID, *input.DBClusterIdentifier)
+		}).
+		Return(nil, awserr.New(rds.ErrCodeDBClusterNotFoundFault, "cluster not found", nil))
+}
+
+func (a *AWSTestSuite) SetDescribeDBClusterSnapshotsStatusExpectation(status string) *gomock.Call {
+	return a.Mocks.API.RDS.EXPECT().DescribeDBClusterSnapshots(gomock.Any()).
+		Return(&rds.DescribeDBClusterSnapshotsOutput{
+			DBClusterSnapshots: []*rds.DBClusterSnapshot{{
+				Status: aws.String(status),
 			}},
 		}, nil)
 }
 
-func (a *AWSTestSuite) SetAuthorizeSecurityGroupIngress(description, groupIDMaster, groupIDSlave string) *gomock.Call {
-	return a.Mocks.API.EC2.EXPECT().AuthorizeSecurityGroupIngress(gomock.Any()).
-		Do(func(input *ec2.AuthorizeSecurityGroupIngressInput) {
-			a.Assert().Equal(description, *input.IpPermissions[0].UserIdGroupPairs[0].Description)
-			a.Assert().Equal(groupIDSlave, *input.IpPermissions[0].UserIdGroupPairs[0].GroupId)
-			a.Assert().Equal(groupIDMaster, *input.GroupId)
-			a.Assert().Equal("tcp", *input.IpPermissions[0].IpProtocol)
-			a.Assert().Equal(int64(3306), *input.IpPermissions[0].ToPort)
-			a.Assert().Equal(int64(3306), *input.IpPermissions[0].FromPort)
-		})
-}
-
-func (a *AWSTestSuite) SetRevokeSecurityGroupIngress(description, groupIDMaster, groupIDSlave string) *gomock.Call {
-	return a.Mocks.API.EC2.EXPECT().RevokeSecurityGroupIngress(gomock.Any()).
-		Do(func(input *ec2.RevokeSecurityGroupIngressInput) {
-			a.Assert().Equal(groupIDSlave, *input.IpPermissions[0].UserIdGroupPairs[0].GroupId)
-			a.Assert().Equal(groupIDMaster, *input.GroupId)
-			a.Assert().Equal("tcp", *input.IpPermissions[0].IpProtocol)
-			a.Assert().Equal(int64(3306), *input.IpPermissions[0].ToPort)
-			a.Assert().Equal(int64(3306), *input.IpPermissions[0].FromPort)
-		})
+func (a *AWSTestSuite) SetDescribeDBInstancesStatusExpectation(status string) *gomock.Call {
+	return a.Mocks.API.RDS.EXPECT().DescribeDBInstances(gomock.Any()).
+		Return(&rds.DescribeDBInstancesOutput{
+			DBInstances: []*rds.DBInstance{{
+				DBInstanceStatus: aws.String(status),
+			}},
+		}, nil)
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/aws/aws-sdk-go/service/rds"
@@ -169,6 +170,14 @@ func (a *AWSTestSuite) TestProvisionRDS() {
 			}).
 			Times(1))
 
+	a.Mocks.API.KMS.EXPECT().
+		DescribeKey(gomock.Any()).
+		Return(nil, awserr.New(kms.ErrCodeNotFoundException, "alias not found", nil)).
+		Do(func(input *kms.DescribeKeyInput) {
+			a.Assert().Equal(*input.KeyId, KMSAliasNameRDS(CloudID(a.InstallationA.ID)))
+		}).
+		Times(1)
+
 	a.Mocks.API.KMS.EXPECT().
 		CreateKey(gomock.Any()).
 		Return(&kms.CreateKeyOutput{
@@ -189,8 +198,9 @@ func (a *AWSTestSuite) TestProvisionRDS() {
 		}).
 		Times(1)
 
-	err := database.Provision(a.Mocks.Model.DatabaseInstallationStore, a.Mocks.Log.Logger)
+	ready, err := database.Provision(a.Mocks.Model.DatabaseInstallationStore, a.Mocks.Log.Logger)
 	a.Assert().NoError(err)
+	a.Assert().True(ready)
 }
 
 // WARNING:
@@ -209,7 +219,7 @@ func TestDatabaseProvision(t *testing.T) {
 		mux: &sync.Mutex{},
 	})
 
-	err := database.Provision(nil, logger)
+	_, err := database.Provision(nil, logger)
 	require.NoError(t, err)
 }
 
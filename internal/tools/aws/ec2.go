@@ -2,6 +2,7 @@ package aws
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -105,6 +106,28 @@ func (a *Client) GetVpcsWithFilters(filters []*ec2.Filter) ([]*ec2.Vpc, error) {
 	return vpcOutput.Vpcs, nil
 }
 
+// getClusterVpcID returns the ID of the VPC claimed by the given cluster.
+func (a *Client) getClusterVpcID(clusterID string) (string, error) {
+	vpcs, err := a.GetVpcsWithFilters([]*ec2.Filter{
+		{
+			Name:   aws.String(VpcClusterIDTagKey),
+			Values: []*string{aws.String(clusterID)},
+		},
+		{
+			Name:   aws.String(VpcAvailableTagKey),
+			Values: []*string{aws.String(VpcAvailableTagValueFalse)},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(vpcs) != 1 {
+		return "", fmt.Errorf("expected 1 VPC for cluster %s, but got %d", clusterID, len(vpcs))
+	}
+
+	return *vpcs[0].VpcId, nil
+}
+
 // GetSubnetsWithFilters returns subnets matching a given filter.
 func (a *Client) GetSubnetsWithFilters(filters []*ec2.Filter) ([]*ec2.Subnet, error) {
 	subnetOutput, err := a.Service().ec2.DescribeSubnets(&ec2.DescribeSubnetsInput{
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/mattermost/mattermost-cloud/model"
 	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -11,10 +12,18 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// restoreFilestoreCopyBatchSize is the number of backup filestore objects
+// copied into the filestore of a restored installation on each provision
+// attempt.
+const restoreFilestoreCopyBatchSize = 500
+
 // S3Filestore is a filestore backed by AWS S3.
 type S3Filestore struct {
 	installationID string
 	awsClient      *Client
+	installation   *model.Installation
+	backupBucket   string
+	backupPrefix   string
 }
 
 // NewS3Filestore returns a new S3Filestore interface.
@@ -25,14 +34,31 @@ func NewS3Filestore(installationID string, awsClient *Client) *S3Filestore {
 	}
 }
 
-// Provision completes all the steps necessary to provision an S3 filestore.
-func (f *S3Filestore) Provision(logger log.FieldLogger) error {
-	err := f.s3FilestoreProvision(f.installationID, logger)
+// NewS3FilestoreFromBackup returns a new S3Filestore interface that is
+// provisioned with the contents of the given filestore backup. The restore
+// progress is recorded on the installation.
+func NewS3FilestoreFromBackup(installation *model.Installation, backupBucket, backupPrefix string, awsClient *Client) *S3Filestore {
+	return &S3Filestore{
+		installationID: installation.ID,
+		awsClient:      awsClient,
+		installation:   installation,
+		backupBucket:   backupBucket,
+		backupPrefix:   backupPrefix,
+	}
+}
+
+// Provision completes all the steps necessary to provision an S3 filestore,
+// returning whether the filestore is ready. A filestore restored from a backup
+// is copied in batches and is not ready until every object has been copied, so
+// Provision must be called again until then. The copy progress is recorded on
+// the installation, which the caller is expected to persist.
+func (f *S3Filestore) Provision(logger log.FieldLogger) (bool, error) {
+	ready, err := f.s3FilestoreProvision(f.installationID, logger)
 	if err != nil {
-		return errors.Wrap(err, "unable to provision AWS S3 filestore")
+		return false, errors.Wrap(err, "unable to provision AWS S3 filestore")
 	}
 
-	return nil
+	return ready, nil
 }
 
 // Teardown removes all AWS resources related to an S3 filestore.
@@ -98,30 +124,30 @@ func (f *S3Filestore) GenerateFilestoreSpecAndSecret(logger log.FieldLogger) (*m
 }
 
 // s3FilestoreProvision provisions an S3 filestore for an installation.
-func (f *S3Filestore) s3FilestoreProvision(installationID string, logger log.FieldLogger) error {
+func (f *S3Filestore) s3FilestoreProvision(installationID string, logger log.FieldLogger) (bool, error) {
 	logger.Info("Provisioning AWS S3 filestore")
 
 	awsID := CloudID(installationID)
 
 	user, err := f.awsClient.iamEnsureUserCreated(awsID, logger)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// The IAM policy lookup requires the AWS account ID for the ARN. The user
 	// object contains this ID so we will user that.
 	arn, err := arn.Parse(*user.Arn)
 	if err != nil {
-		return err
+		return false, err
 	}
 	policyARN := fmt.Sprintf("arn:aws:iam::%s:policy/%s", arn.AccountID, awsID)
 	policy, err := f.awsClient.iamEnsurePolicyCreated(awsID, policyARN, logger)
 	if err != nil {
-		return err
+		return false, err
 	}
 	err = f.awsClient.iamEnsurePolicyAttached(awsID, policyARN, logger)
 	if err != nil {
-		return err
+		return false, err
 	}
 	logger.WithFields(log.Fields{
 		"iam-policy-name": *policy.PolicyName,
@@ -130,21 +156,35 @@ func (f *S3Filestore) s3FilestoreProvision(installationID string, logger log.Fie
 
 	err = f.awsClient.s3EnsureBucketCreated(awsID, logger)
 	if err != nil {
-		return err
+		return false, err
 	}
 	logger.WithField("s3-bucket-name", awsID).Debug("AWS S3 bucket created")
 
+	if f.backupBucket != "" && !f.installation.RestoreFilestoreCopied {
+		marker, copied, err := f.awsClient.s3CopyObjectsBatch(f.backupBucket, f.backupPrefix, awsID, "", f.installation.RestoreFilestoreCopyMarker, restoreFilestoreCopyBatchSize, logger)
+		f.installation.RestoreFilestoreCopyMarker = marker
+		f.installation.RestoreFilestoreCopied = copied
+		if err != nil {
+			return false, errors.Wrap(err, "unable to restore AWS S3 filestore backup")
+		}
+		if !copied {
+			logger.WithField("s3-bucket-name", awsID).Debug("AWS S3 filestore backup is still being restored")
+			return false, nil
+		}
+		logger.WithField("s3-bucket-name", awsID).Debug("AWS S3 filestore backup restored")
+	}
+
 	ak, err := f.awsClient.iamEnsureAccessKeyCreated(awsID, logger)
 	if err != nil {
-		return err
+		return false, err
 	}
 	logger.WithField("iam-user-name", *user.UserName).Debug("AWS IAM user access key created")
 
 	err = f.awsClient.secretsManagerEnsureIAMAccessKeySecretCreated(awsID, ak, logger)
 	if err != nil {
-		return err
+		return false, err
 	}
 	logger.WithField("iam-user-name", *user.UserName).Debug("AWS secrets manager secret created")
 
-	return nil
+	return true, nil
 }
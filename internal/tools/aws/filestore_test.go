@@ -27,8 +27,9 @@ func TestFilestoreProvision(t *testing.T) {
 
 	logger.Warnf("Provisioning down AWS filestore %s", id)
 
-	err := filestore.Provision(logger)
+	ready, err := filestore.Provision(logger)
 	require.NoError(t, err)
+	require.True(t, ready)
 }
 
 func TestFilestoreTeardown(t *testing.T) {
@@ -20,6 +20,18 @@ func RDSSnapshotTagValue(cloudID string) string {
 	return fmt.Sprintf("rds-snapshot-%s", cloudID)
 }
 
+// RDSBackupSnapshotID returns the RDS DB cluster snapshot ID used for the
+// given installation backup.
+func RDSBackupSnapshotID(installationID, backupID string) string {
+	return fmt.Sprintf("%s-backup-%s", CloudID(installationID), backupID)
+}
+
+// S3BackupBucketName returns the name of the S3 bucket holding the filestore
+// backups of the given installation.
+func S3BackupBucketName(installationID string) string {
+	return CloudID(installationID) + backupBucketSuffix
+}
+
 // IAMSecretName returns the IAM Access Key secret name for a given Cloud ID.
 func IAMSecretName(cloudID string) string {
 	return cloudID + iamSuffix
@@ -68,6 +80,24 @@ func RDSMigrationInstanceID(installationID string) string {
 	return fmt.Sprintf("%s-migration", CloudID(installationID))
 }
 
+// RDSMigrationClusterID formats the name of the RDS DB cluster an installation
+// database is moved to before it takes over the installation DB cluster name.
+func RDSMigrationClusterID(installationID string) string {
+	return fmt.Sprintf("%s-migration", CloudID(installationID))
+}
+
+// RDSMigrationSourceClusterID formats the name the RDS DB cluster of a moved
+// installation database is given until it is deleted.
+func RDSMigrationSourceClusterID(installationID string) string {
+	return fmt.Sprintf("%s-migration-source", CloudID(installationID))
+}
+
+// RDSMigrationSnapshotID formats the name of the RDS DB cluster snapshot used
+// to move an installation database.
+func RDSMigrationSnapshotID(installationID string) string {
+	return fmt.Sprintf("%s-migration", CloudID(installationID))
+}
+
 // IsErrorCode asserts that an AWS error has a certain code.
 func IsErrorCode(err error, code string) bool {
 	if err != nil {
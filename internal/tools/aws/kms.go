@@ -4,6 +4,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 // kmsCreateSymmetricKey creates a symmetric encryption key with alias.
@@ -18,6 +19,35 @@ func (a *Client) kmsCreateSymmetricKey(awsID, keyDescription string) (*kms.KeyMe
 	return createKeyOut.KeyMetadata, nil
 }
 
+// kmsEnsureSymmetricKeyCreated returns the symmetric encryption key with the
+// given alias, creating the key and its alias only if they do not exist yet.
+func (a *Client) kmsEnsureSymmetricKeyCreated(awsID, aliasName, keyDescription string, logger log.FieldLogger) (*kms.KeyMetadata, error) {
+	keyMetadata, err := a.kmsGetSymmetricKey(aliasName)
+	if err == nil {
+		return keyMetadata, nil
+	}
+	if !IsErrorCode(errors.Cause(err), kms.ErrCodeNotFoundException) {
+		return nil, err
+	}
+
+	keyMetadata, err = a.kmsCreateSymmetricKey(awsID, keyDescription)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.kmsCreateAlias(*keyMetadata.KeyId, aliasName)
+	if err != nil {
+		deletionKeyErr := a.kmsScheduleKeyDeletion(*keyMetadata.KeyId, KMSMinTimeEncryptionKeyDeletion)
+		if deletionKeyErr != nil {
+			logger.WithError(deletionKeyErr).Errorf("Failed to schedule encryption key %s for deletion", *keyMetadata.KeyId)
+		}
+
+		return nil, err
+	}
+
+	return keyMetadata, nil
+}
+
 // kmsCreateAlias creates an alias for a symmetric encryption key. Alias allows retrieving the key ID in one call and
 // without special permissions that would be necessary if looking up it by tags for example.
 func (a *Client) kmsCreateAlias(keyID, aliasName string) error {
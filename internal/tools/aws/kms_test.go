@@ -0,0 +1,30 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/golang/mock/gomock"
+)
+
+func (a *AWSTestSuite) TestKMSEnsureSymmetricKeyCreatedExistingKey() {
+	aliasName := KMSAliasNameRDS(CloudID(a.InstallationA.ID))
+
+	a.Mocks.API.KMS.EXPECT().
+		DescribeKey(gomock.Any()).
+		Return(&kms.DescribeKeyOutput{
+			KeyMetadata: &kms.KeyMetadata{
+				KeyId: aws.String(a.RDSEncryptionKeyID),
+			},
+		}, nil).
+		Do(func(input *kms.DescribeKeyInput) {
+			a.Assert().Equal(*input.KeyId, aliasName)
+		}).
+		Times(1)
+
+	a.Mocks.API.KMS.EXPECT().CreateKey(gomock.Any()).Times(0)
+	a.Mocks.API.KMS.EXPECT().CreateAlias(gomock.Any()).Times(0)
+
+	keyMetadata, err := a.Mocks.AWS.kmsEnsureSymmetricKeyCreated(CloudID(a.InstallationA.ID), aliasName, "Key used for encrypting RDS database", a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+	a.Assert().Equal(a.RDSEncryptionKeyID, *keyMetadata.KeyId)
+}
@@ -187,3 +187,205 @@ func (a *Client) rdsEnsureDBClusterDeleted(awsID string, logger log.FieldLogger)
 
 	return nil
 }
+
+func (a *Client) rdsEnsureDBClusterSnapshotCreated(awsID, snapshotID string, logger log.FieldLogger) error {
+	_, err := a.Service().rds.DescribeDBClusterSnapshots(&rds.DescribeDBClusterSnapshotsInput{
+		DBClusterSnapshotIdentifier: aws.String(snapshotID),
+	})
+	if err == nil {
+		logger.WithField("db-cluster-snapshot-name", snapshotID).Debug("AWS DB cluster snapshot already created")
+
+		return nil
+	}
+
+	_, err = a.Service().rds.CreateDBClusterSnapshot(&rds.CreateDBClusterSnapshotInput{
+		DBClusterIdentifier:         aws.String(awsID),
+		DBClusterSnapshotIdentifier: aws.String(snapshotID),
+		Tags: []*rds.Tag{{
+			Key:   aws.String(DefaultClusterInstallationSnapshotTagKey),
+			Value: aws.String(RDSSnapshotTagValue(awsID)),
+		}},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create a DB cluster snapshot")
+	}
+
+	logger.WithField("db-cluster-snapshot-name", snapshotID).Debug("AWS DB cluster snapshot in progress")
+
+	return nil
+}
+
+// rdsGetDBClusterSnapshotStatus returns the status of the given DB cluster
+// snapshot, such as "creating" or "available".
+func (a *Client) rdsGetDBClusterSnapshotStatus(snapshotID string) (string, error) {
+	result, err := a.Service().rds.DescribeDBClusterSnapshots(&rds.DescribeDBClusterSnapshotsInput{
+		DBClusterSnapshotIdentifier: aws.String(snapshotID),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to describe DB cluster snapshot")
+	}
+	if len(result.DBClusterSnapshots) != 1 {
+		return "", fmt.Errorf("expected 1 DB cluster snapshot, but got %d", len(result.DBClusterSnapshots))
+	}
+
+	return *result.DBClusterSnapshots[0].Status, nil
+}
+
+// rdsEnsureDBClusterRestored restores a DB cluster from the given snapshot.
+// The restored DB cluster keeps the master password of the snapshotted
+// cluster, so it is reset to the given password once the cluster becomes
+// available. It returns whether the restored cluster is available.
+func (a *Client) rdsEnsureDBClusterRestored(awsID, snapshotID, vpcID, password, kmsKeyID string, logger log.FieldLogger) (bool, error) {
+	result, err := a.Service().rds.DescribeDBClusters(&rds.DescribeDBClustersInput{
+		DBClusterIdentifier: aws.String(awsID),
+	})
+	if err == nil {
+		if len(result.DBClusters) != 1 {
+			return false, fmt.Errorf("expected 1 DB cluster, but got %d", len(result.DBClusters))
+		}
+		dbCluster := result.DBClusters[0]
+		if *dbCluster.Status != "available" {
+			logger.WithField("db-cluster-name", awsID).Debugf("Restored AWS DB cluster is not yet available (status %s)", *dbCluster.Status)
+			return false, nil
+		}
+
+		// The restored DB cluster keeps the master password of the snapshot
+		// until it is reset once to the one of the installation.
+		tags, err := a.Service().rds.ListTagsForResource(&rds.ListTagsForResourceInput{
+			ResourceName: dbCluster.DBClusterArn,
+		})
+		if err != nil {
+			return false, errors.Wrap(err, "unable to list tags of restored DB cluster")
+		}
+		for _, tag := range tags.TagList {
+			if *tag.Key != RDSPasswordResetPendingTagKey {
+				continue
+			}
+
+			_, err = a.Service().rds.ModifyDBCluster(&rds.ModifyDBClusterInput{
+				DBClusterIdentifier: aws.String(awsID),
+				MasterUserPassword:  aws.String(password),
+				ApplyImmediately:    aws.Bool(true),
+			})
+			if err != nil {
+				return false, errors.Wrap(err, "unable to reset restored DB cluster master password")
+			}
+
+			_, err = a.Service().rds.RemoveTagsFromResource(&rds.RemoveTagsFromResourceInput{
+				ResourceName: dbCluster.DBClusterArn,
+				TagKeys:      []*string{aws.String(RDSPasswordResetPendingTagKey)},
+			})
+			if err != nil {
+				return false, errors.Wrap(err, "unable to untag restored DB cluster")
+			}
+
+			logger.WithField("db-cluster-name", awsID).Debug("AWS DB cluster master password reset")
+		}
+
+		logger.WithField("db-cluster-name", awsID).Debug("AWS DB cluster restored")
+
+		return true, nil
+	}
+
+	err = a.rdsRestoreDBClusterFromSnapshot(awsID, snapshotID, vpcID, kmsKeyID, []*rds.Tag{{
+		Key:   aws.String(RDSPasswordResetPendingTagKey),
+		Value: aws.String("true"),
+	}}, logger)
+	if err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// rdsRestoreDBClusterFromSnapshot starts restoring a DB cluster from the
+// given snapshot into the given VPC.
+func (a *Client) rdsRestoreDBClusterFromSnapshot(awsID, snapshotID, vpcID, kmsKeyID string, tags []*rds.Tag, logger log.FieldLogger) error {
+	dbSecurityGroupIDs, err := a.rdsGetDBSecurityGroupIDs(vpcID, logger)
+	if err != nil {
+		return err
+	}
+
+	dbSubnetGroupName, err := a.rdsGetDBSubnetGroupName(vpcID, logger)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.Service().rds.RestoreDBClusterFromSnapshot(&rds.RestoreDBClusterFromSnapshotInput{
+		AvailabilityZones: []*string{
+			aws.String("us-east-1a"),
+			aws.String("us-east-1b"),
+			aws.String("us-east-1c"),
+		},
+		DBClusterIdentifier: aws.String(awsID),
+		SnapshotIdentifier:  aws.String(snapshotID),
+		EngineMode:          aws.String("provisioned"),
+		Engine:              aws.String("aurora-mysql"),
+		EngineVersion:       aws.String("5.7"),
+		Port:                aws.Int64(3306),
+		DBSubnetGroupName:   aws.String(dbSubnetGroupName),
+		VpcSecurityGroupIds: aws.StringSlice(dbSecurityGroupIDs),
+		KmsKeyId:            aws.String(kmsKeyID),
+		Tags:                tags,
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to restore DB cluster from snapshot")
+	}
+
+	logger.WithField("db-cluster-name", awsID).Debugf("AWS DB cluster restore from snapshot %s started", snapshotID)
+
+	return nil
+}
+
+// rdsGetDBCluster returns the DB cluster with the given identifier, or nil if
+// it does not exist.
+func (a *Client) rdsGetDBCluster(awsID string) (*rds.DBCluster, error) {
+	result, err := a.Service().rds.DescribeDBClusters(&rds.DescribeDBClustersInput{
+		DBClusterIdentifier: aws.String(awsID),
+	})
+	if IsErrorCode(err, rds.ErrCodeDBClusterNotFoundFault) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe DB cluster")
+	}
+	if len(result.DBClusters) != 1 {
+		return nil, fmt.Errorf("expected 1 DB cluster, but got %d", len(result.DBClusters))
+	}
+
+	return result.DBClusters[0], nil
+}
+
+// rdsGetDBInstanceStatus returns the status of the given DB instance, such as
+// "creating" or "available".
+func (a *Client) rdsGetDBInstanceStatus(instanceName string) (string, error) {
+	result, err := a.Service().rds.DescribeDBInstances(&rds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: aws.String(instanceName),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to describe DB instance")
+	}
+	if len(result.DBInstances) != 1 {
+		return "", fmt.Errorf("expected 1 DB instance, but got %d", len(result.DBInstances))
+	}
+
+	return aws.StringValue(result.DBInstances[0].DBInstanceStatus), nil
+}
+
+// rdsEnsureDBClusterSnapshotDeleted deletes the given DB cluster snapshot.
+func (a *Client) rdsEnsureDBClusterSnapshotDeleted(snapshotID string, logger log.FieldLogger) error {
+	_, err := a.Service().rds.DeleteDBClusterSnapshot(&rds.DeleteDBClusterSnapshotInput{
+		DBClusterSnapshotIdentifier: aws.String(snapshotID),
+	})
+	if IsErrorCode(err, rds.ErrCodeDBClusterSnapshotNotFoundFault) {
+		logger.WithField("db-cluster-snapshot-name", snapshotID).Warn("DB cluster snapshot could not be found; assuming already deleted")
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to delete DB cluster snapshot")
+	}
+
+	logger.WithField("db-cluster-snapshot-name", snapshotID).Debug("DB cluster snapshot deleted")
+
+	return nil
+}
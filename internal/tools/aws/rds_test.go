@@ -90,6 +90,53 @@ func (a *AWSTestSuite) TestRDSEnsureDBClusterCreatedAlreadyCreated() {
 	a.Assert().NoError(err)
 }
 
+func (a *AWSTestSuite) TestRDSEnsureDBClusterRestored() {
+	awsID := CloudID(a.InstallationA.ID)
+	a.ExpectAnyLogging()
+
+	describeDBCluster := func(status string) {
+		a.Mocks.API.RDS.EXPECT().
+			DescribeDBClusters(gomock.Any()).
+			Return(&rds.DescribeDBClustersOutput{
+				DBClusters: []*rds.DBCluster{{
+					DBClusterArn: aws.String("arn"),
+					Status:       aws.String(status),
+				}},
+			}, nil).
+			Times(1)
+	}
+
+	describeDBCluster("creating")
+	restored, err := a.Mocks.AWS.rdsEnsureDBClusterRestored(awsID, "snapshot", a.VPCa, a.DBPassword, a.RDSEncryptionKeyID, a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+	a.Assert().False(restored)
+
+	describeDBCluster("available")
+	gomock.InOrder(
+		a.Mocks.API.RDS.EXPECT().
+			ListTagsForResource(gomock.Any()).
+			Return(&rds.ListTagsForResourceOutput{
+				TagList: []*rds.Tag{{Key: aws.String(RDSPasswordResetPendingTagKey), Value: aws.String("true")}},
+			}, nil).
+			Times(1),
+		a.Mocks.API.RDS.EXPECT().
+			ModifyDBCluster(gomock.Any()).
+			Do(func(input *rds.ModifyDBClusterInput) {
+				a.Assert().Equal(awsID, *input.DBClusterIdentifier)
+				a.Assert().Equal(a.DBPassword, *input.MasterUserPassword)
+			}).
+			Return(&rds.ModifyDBClusterOutput{}, nil).
+			Times(1),
+		a.Mocks.API.RDS.EXPECT().
+			RemoveTagsFromResource(gomock.Any()).
+			Return(&rds.RemoveTagsFromResourceOutput{}, nil).
+			Times(1),
+	)
+	restored, err = a.Mocks.AWS.rdsEnsureDBClusterRestored(awsID, "snapshot", a.VPCa, a.DBPassword, a.RDSEncryptionKeyID, a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+	a.Assert().True(restored)
+}
+
 func (a *AWSTestSuite) TestRDSEnsureDBClusterCreatedWithSGError() {
 	a.Mocks.API.RDS.EXPECT().
 		DescribeDBClusters(gomock.Any()).
@@ -1,6 +1,9 @@
 package aws
 
 import (
+	"net/url"
+	"strings"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -66,3 +69,93 @@ func (a *Client) s3EnsureBucketDeleted(bucketName string, logger log.FieldLogger
 
 	return nil
 }
+
+func (a *Client) s3EnsureBucketExists(bucketName string, logger log.FieldLogger) error {
+	_, err := a.Service().s3.HeadBucket(&s3.HeadBucketInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err == nil {
+		return nil
+	}
+
+	return a.s3EnsureBucketCreated(bucketName, logger)
+}
+
+// s3CopyObjects copies every object under sourcePrefix in the source bucket to
+// the destination bucket, replacing sourcePrefix with destinationPrefix.
+func (a *Client) s3CopyObjects(sourceBucket, sourcePrefix, destinationBucket, destinationPrefix string, logger log.FieldLogger) error {
+	var copied int
+	var copyErr error
+	err := a.Service().s3.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(sourceBucket),
+		Prefix: aws.String(sourcePrefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			copySource := &url.URL{Path: sourceBucket + "/" + *object.Key}
+			_, copyErr = a.Service().s3.CopyObject(&s3.CopyObjectInput{
+				Bucket:     aws.String(destinationBucket),
+				Key:        aws.String(destinationPrefix + strings.TrimPrefix(*object.Key, sourcePrefix)),
+				CopySource: aws.String(copySource.EscapedPath()),
+			})
+			if copyErr != nil {
+				return false
+			}
+			copied++
+		}
+		return true
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to list bucket contents")
+	}
+	if copyErr != nil {
+		return errors.Wrap(copyErr, "unable to copy bucket object")
+	}
+
+	logger.WithFields(log.Fields{
+		"s3-source-bucket-name":      sourceBucket,
+		"s3-destination-bucket-name": destinationBucket,
+	}).Debugf("Copied %d AWS S3 objects", copied)
+
+	return nil
+}
+
+// s3CopyObjectsBatch copies up to maxKeys objects under sourcePrefix that sort
+// after startAfter in the source bucket to the destination bucket, replacing
+// sourcePrefix with destinationPrefix. It returns the key of the last copied
+// object and whether every object has now been copied.
+func (a *Client) s3CopyObjectsBatch(sourceBucket, sourcePrefix, destinationBucket, destinationPrefix, startAfter string, maxKeys int64, logger log.FieldLogger) (string, bool, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(sourceBucket),
+		Prefix:  aws.String(sourcePrefix),
+		MaxKeys: aws.Int64(maxKeys),
+	}
+	if startAfter != "" {
+		input.StartAfter = aws.String(startAfter)
+	}
+
+	page, err := a.Service().s3.ListObjectsV2(input)
+	if err != nil {
+		return startAfter, false, errors.Wrap(err, "unable to list bucket contents")
+	}
+
+	lastKey := startAfter
+	for _, object := range page.Contents {
+		copySource := &url.URL{Path: sourceBucket + "/" + *object.Key}
+		_, err = a.Service().s3.CopyObject(&s3.CopyObjectInput{
+			Bucket:     aws.String(destinationBucket),
+			Key:        aws.String(destinationPrefix + strings.TrimPrefix(*object.Key, sourcePrefix)),
+			CopySource: aws.String(copySource.EscapedPath()),
+		})
+		if err != nil {
+			return lastKey, false, errors.Wrap(err, "unable to copy bucket object")
+		}
+		lastKey = *object.Key
+	}
+
+	logger.WithFields(log.Fields{
+		"s3-source-bucket-name":      sourceBucket,
+		"s3-destination-bucket-name": destinationBucket,
+	}).Debugf("Copied %d AWS S3 objects", len(page.Contents))
+
+	return lastKey, !aws.BoolValue(page.IsTruncated), nil
+}
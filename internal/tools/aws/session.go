@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
 )
@@ -48,3 +49,19 @@ func NewAWSSessionWithLogger(config *aws.Config, logger log.FieldLogger) (*sessi
 
 	return awsSession, nil
 }
+
+// CheckSession verifies that an AWS session can be created and that credentials
+// are available to it.
+func (c *Client) CheckSession() error {
+	awsSession, err := session.NewSession(c.config)
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize AWS session")
+	}
+
+	_, err = awsSession.Config.Credentials.Get()
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve AWS credentials")
+	}
+
+	return nil
+}
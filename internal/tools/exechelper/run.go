@@ -9,13 +9,32 @@ import (
 	"bytes"
 	"io"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/mattermost/mattermost-cloud/internal/metrics"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
+// limiter bounds the number of commands running concurrently, if set.
+var limiter chan struct{}
+
+// SetMaxConcurrent limits the number of commands running concurrently, with
+// further invocations waiting for a running command to finish. A limit of
+// zero removes the limit. It must be called before any commands are run.
+func SetMaxConcurrent(max int) {
+	if max <= 0 {
+		limiter = nil
+		return
+	}
+
+	limiter = make(chan struct{}, max)
+}
+
 // OutputLogger allows custom logging of the run command output.
 type OutputLogger func(line string, logger log.FieldLogger)
 
@@ -45,6 +64,11 @@ func Run(cmd *exec.Cmd, logger log.FieldLogger, outputLogger OutputLogger) ([]by
 		"run": runID,
 	})
 
+	if limiter != nil {
+		limiter <- struct{}{}
+		defer func() { <-limiter }()
+	}
+
 	logger.WithFields(log.Fields{
 		"cmd":  cmd.Path,
 		"args": cmd.Args,
@@ -79,6 +103,7 @@ func Run(cmd *exec.Cmd, logger log.FieldLogger, outputLogger OutputLogger) ([]by
 	}()
 
 	var err error
+	start := time.Now()
 	go func() {
 		err = cmd.Run()
 		wStdout.Close()
@@ -86,6 +111,7 @@ func Run(cmd *exec.Cmd, logger log.FieldLogger, outputLogger OutputLogger) ([]by
 	}()
 
 	wg.Wait()
+	metrics.ObserveCommand(filepath.Base(cmd.Path), subcommand(cmd), time.Since(start), err != nil)
 
 	if err != nil {
 		logger.WithError(err).Error("failed invocation")
@@ -95,3 +121,12 @@ func Run(cmd *exec.Cmd, logger log.FieldLogger, outputLogger OutputLogger) ([]by
 
 	return stdout.Bytes(), stderr.Bytes(), nil
 }
+
+// subcommand returns the first argument given to the command, if it is not a flag.
+func subcommand(cmd *exec.Cmd) string {
+	if len(cmd.Args) < 2 || strings.HasPrefix(cmd.Args[1], "-") {
+		return ""
+	}
+
+	return cmd.Args[1]
+}
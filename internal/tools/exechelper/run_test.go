@@ -0,0 +1,43 @@
+package exechelper
+
+import (
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	stdout, _, err := Run(exec.Command("echo", "hello"), logger, nil)
+	require.NoError(t, err)
+	require.Equal(t, "hello\n", string(stdout))
+
+	_, _, err = Run(exec.Command("false"), logger, nil)
+	require.Error(t, err)
+}
+
+func TestSetMaxConcurrent(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	SetMaxConcurrent(1)
+	defer SetMaxConcurrent(0)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := Run(exec.Command("sleep", "0.2"), logger, nil)
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(400*time.Millisecond))
+}
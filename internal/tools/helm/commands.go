@@ -11,3 +11,26 @@ func (c *Cmd) RunGenericCommand(arg ...string) error {
 
 	return nil
 }
+
+// List invokes helm list against the cluster of the given kubeconfig, and
+// returns the releases as JSON from the stdout.
+func (c *Cmd) List(kubeconfigPath string, silent bool) ([]byte, error) {
+	args := []string{
+		"list",
+		"--kubeconfig", kubeconfigPath,
+		"--output", "json",
+	}
+	var stdout []byte
+	var err error
+	if silent {
+		stdout, _, err = c.runSilent(args...)
+	} else {
+		stdout, _, err = c.run(args...)
+	}
+
+	if err != nil {
+		return stdout, errors.Wrap(err, "failed to invoke helm list")
+	}
+
+	return stdout, nil
+}
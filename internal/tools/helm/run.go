@@ -1,6 +1,7 @@
 package helm
 
 import (
+	"io/ioutil"
 	"os/exec"
 	"strings"
 
@@ -22,3 +23,16 @@ func (c *Cmd) run(arg ...string) ([]byte, []byte, error) {
 
 	return exechelper.Run(cmd, c.logger, outputLogger)
 }
+
+func (c *Cmd) runSilent(arg ...string) ([]byte, []byte, error) {
+	cmd := exec.Command(c.helmPath, arg...)
+
+	return exechelper.Run(cmd, silentLogger(), func(string, log.FieldLogger) {})
+}
+
+func silentLogger() log.FieldLogger {
+	silentLogger := log.New()
+	silentLogger.Out = ioutil.Discard
+
+	return silentLogger
+}
@@ -0,0 +1,317 @@
+package kops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// InstanceGroupRoleMaster is the role of instance groups running the
+	// kubernetes control plane.
+	InstanceGroupRoleMaster = "Master"
+	// InstanceGroupRoleNode is the role of instance groups running workloads.
+	InstanceGroupRoleNode = "Node"
+
+	// InstanceGroupLabel is the node label holding the name of the instance
+	// group a node belongs to.
+	InstanceGroupLabel = "kops.k8s.io/instancegroup"
+)
+
+// InstanceGroup is a kops instance group manifest. Only the fields edited by
+// the provisioner have accessors; the rest of the manifest is preserved as-is
+// when the instance group is replaced.
+type InstanceGroup map[string]interface{}
+
+// Name returns the name of the instance group.
+func (ig InstanceGroup) Name() string {
+	value, _ := ig.section("metadata")["name"].(string)
+	return value
+}
+
+// Role returns the role of the instance group, such as Master or Node.
+func (ig InstanceGroup) Role() string {
+	value, _ := ig.section("spec")["role"].(string)
+	return value
+}
+
+// MachineType returns the instance type used by the instance group.
+func (ig InstanceGroup) MachineType() string {
+	value, _ := ig.section("spec")["machineType"].(string)
+	return value
+}
+
+// SetMachineType changes the instance type used by the instance group.
+func (ig InstanceGroup) SetMachineType(machineType string) {
+	ig.section("spec")["machineType"] = machineType
+}
+
+// MinSize returns the minimum number of instances in the instance group.
+func (ig InstanceGroup) MinSize() int64 {
+	return toInt64(ig.section("spec")["minSize"])
+}
+
+// MaxSize returns the maximum number of instances in the instance group.
+func (ig InstanceGroup) MaxSize() int64 {
+	return toInt64(ig.section("spec")["maxSize"])
+}
+
+// SetSize changes the minimum and maximum number of instances in the instance
+// group.
+func (ig InstanceGroup) SetSize(minSize, maxSize int64) {
+	spec := ig.section("spec")
+	spec["minSize"] = minSize
+	spec["maxSize"] = maxSize
+}
+
+// SetMaxPrice changes the maximum hourly price paid for spot instances in the
+// instance group. An empty price switches the group to on-demand instances.
+func (ig InstanceGroup) SetMaxPrice(maxPrice string) {
+	spec := ig.section("spec")
+	if maxPrice == "" {
+		delete(spec, "maxPrice")
+		return
+	}
+	spec["maxPrice"] = maxPrice
+}
+
+// SetNodeLabel sets a label on every node of the instance group.
+func (ig InstanceGroup) SetNodeLabel(key, value string) {
+	spec := ig.section("spec")
+	labels, ok := spec["nodeLabels"].(map[string]interface{})
+	if !ok {
+		labels = make(map[string]interface{})
+		spec["nodeLabels"] = labels
+	}
+	labels[key] = value
+}
+
+// section returns the named top-level object of the manifest, creating it if
+// missing.
+func (ig InstanceGroup) section(name string) map[string]interface{} {
+	value, ok := ig[name].(map[string]interface{})
+	if !ok {
+		value = make(map[string]interface{})
+		ig[name] = value
+	}
+
+	return value
+}
+
+// applyInstanceGroupSpec changes the instance group to match the declared
+// additional instance group.
+func applyInstanceGroupSpec(instanceGroup InstanceGroup, spec model.KopsInstanceGroup) {
+	instanceGroup.SetMachineType(spec.MachineType)
+	instanceGroup.SetSize(spec.MinCount, spec.MaxCount)
+	instanceGroup.SetMaxPrice(spec.MaxPrice)
+	instanceGroup.SetNodeLabel(InstanceGroupLabel, spec.Name)
+}
+
+// newInstanceGroupFromTemplate returns a manifest for the declared additional
+// instance group, copying the rest of its configuration such as subnets and
+// image from the given template.
+func newInstanceGroupFromTemplate(template InstanceGroup, spec model.KopsInstanceGroup) (InstanceGroup, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode instance group template")
+	}
+	var instanceGroup InstanceGroup
+	err = json.Unmarshal(data, &instanceGroup)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode instance group template")
+	}
+
+	metadata := instanceGroup.section("metadata")
+	delete(metadata, "creationTimestamp")
+	metadata["name"] = spec.Name
+	applyInstanceGroupSpec(instanceGroup, spec)
+
+	return instanceGroup, nil
+}
+
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	}
+
+	return 0
+}
+
+// parseInstanceGroups decodes the output of kops get instancegroups, which is
+// either a single manifest or a list of manifests depending on the number of
+// instance groups and the version of kops.
+func parseInstanceGroups(output []byte) ([]InstanceGroup, error) {
+	var instanceGroups []InstanceGroup
+
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode instance groups")
+		}
+
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+			var list []InstanceGroup
+			err = json.Unmarshal(raw, &list)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to decode instance group list")
+			}
+			instanceGroups = append(instanceGroups, list...)
+			continue
+		}
+
+		var instanceGroup InstanceGroup
+		err = json.Unmarshal(raw, &instanceGroup)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode instance group")
+		}
+		instanceGroups = append(instanceGroups, instanceGroup)
+	}
+
+	return instanceGroups, nil
+}
+
+// GetInstanceGroups invokes kops get instancegroups, using the context of the
+// created Cmd, and returns the instance groups of the named cluster.
+func (c *Cmd) GetInstanceGroups(name string) ([]InstanceGroup, error) {
+	stdout, _, err := c.run(
+		"get",
+		"instancegroups",
+		arg("name", name),
+		arg("state", "s3://", c.s3StateStore),
+		arg("output", "json"),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to invoke kops get instancegroups")
+	}
+
+	return parseInstanceGroups(stdout)
+}
+
+// CreateInstanceGroup invokes kops create with the given instance group
+// manifest, using the context of the created Cmd. The instance group is not
+// created in the cloud until the cluster is updated.
+func (c *Cmd) CreateInstanceGroup(instanceGroup InstanceGroup) error {
+	filename, err := c.writeInstanceGroup(instanceGroup)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = c.run(
+		"create",
+		arg("filename", filename),
+		arg("state", "s3://", c.s3StateStore),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke kops create")
+	}
+
+	return nil
+}
+
+// ReplaceInstanceGroup invokes kops replace with the given instance group
+// manifest, using the context of the created Cmd. Changes are not applied to
+// the cluster until it is updated.
+func (c *Cmd) ReplaceInstanceGroup(instanceGroup InstanceGroup) error {
+	filename, err := c.writeInstanceGroup(instanceGroup)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = c.run(
+		"replace",
+		arg("filename", filename),
+		arg("state", "s3://", c.s3StateStore),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke kops replace")
+	}
+
+	return nil
+}
+
+// EnsureInstanceGroups creates or updates the declared additional instance
+// groups of the named cluster. New instance groups are based on the default
+// node instance group. Instance groups that are no longer declared are left
+// untouched.
+func (c *Cmd) EnsureInstanceGroups(name string, specs []model.KopsInstanceGroup) error {
+	if len(specs) == 0 {
+		return nil
+	}
+
+	instanceGroups, err := c.GetInstanceGroups(name)
+	if err != nil {
+		return err
+	}
+
+	declared := make(map[string]bool)
+	for _, spec := range specs {
+		declared[spec.Name] = true
+	}
+
+	var template InstanceGroup
+	existing := make(map[string]InstanceGroup)
+	for _, instanceGroup := range instanceGroups {
+		existing[instanceGroup.Name()] = instanceGroup
+		if template == nil && instanceGroup.Role() == InstanceGroupRoleNode && !declared[instanceGroup.Name()] {
+			template = instanceGroup
+		}
+	}
+
+	for _, spec := range specs {
+		if instanceGroup, ok := existing[spec.Name]; ok {
+			applyInstanceGroupSpec(instanceGroup, spec)
+			err = c.ReplaceInstanceGroup(instanceGroup)
+			if err != nil {
+				return errors.Wrapf(err, "failed to update instance group %s", spec.Name)
+			}
+			continue
+		}
+
+		if template == nil {
+			return errors.New("found no default node instance group to base new instance groups on")
+		}
+		instanceGroup, err := newInstanceGroupFromTemplate(template, spec)
+		if err != nil {
+			return err
+		}
+		err = c.CreateInstanceGroup(instanceGroup)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create instance group %s", spec.Name)
+		}
+	}
+
+	return nil
+}
+
+// writeInstanceGroup writes the instance group manifest to a file in the
+// temporary directory and returns its path.
+func (c *Cmd) writeInstanceGroup(instanceGroup InstanceGroup) (string, error) {
+	data, err := json.Marshal(instanceGroup)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode instance group")
+	}
+
+	filename := path.Join(c.tempDir, fmt.Sprintf("instancegroup-%s.json", instanceGroup.Name()))
+	err = ioutil.WriteFile(filename, data, 0600)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to write instance group manifest")
+	}
+
+	return filename, nil
+}
@@ -0,0 +1,125 @@
+package kops
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testInstanceGroupMaster = `{
+	"kind": "InstanceGroup",
+	"metadata": {"name": "master-us-east-1a", "labels": {"kops.k8s.io/cluster": "test"}},
+	"spec": {"role": "Master", "machineType": "t3.medium", "minSize": 1, "maxSize": 1}
+}`
+
+const testInstanceGroupNodes = `{
+	"kind": "InstanceGroup",
+	"metadata": {"name": "nodes"},
+	"spec": {"role": "Node", "machineType": "m5.large", "minSize": 2, "maxSize": 2, "subnets": ["us-east-1a"]}
+}`
+
+func TestParseInstanceGroups(t *testing.T) {
+	var tests = []struct {
+		name     string
+		output   string
+		expected []string
+	}{
+		{"empty", "", nil},
+		{"single", testInstanceGroupNodes, []string{"nodes"}},
+		{"list", "[" + testInstanceGroupMaster + "," + testInstanceGroupNodes + "]", []string{"master-us-east-1a", "nodes"}},
+		{"stream", testInstanceGroupMaster + "\n" + testInstanceGroupNodes, []string{"master-us-east-1a", "nodes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instanceGroups, err := parseInstanceGroups([]byte(tt.output))
+			require.NoError(t, err)
+
+			var names []string
+			for _, instanceGroup := range instanceGroups {
+				names = append(names, instanceGroup.Name())
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := parseInstanceGroups([]byte("{"))
+		assert.Error(t, err)
+	})
+}
+
+func TestInstanceGroup(t *testing.T) {
+	instanceGroups, err := parseInstanceGroups([]byte(testInstanceGroupNodes))
+	require.NoError(t, err)
+	require.Len(t, instanceGroups, 1)
+	instanceGroup := instanceGroups[0]
+
+	assert.Equal(t, InstanceGroupRoleNode, instanceGroup.Role())
+	assert.Equal(t, "m5.large", instanceGroup.MachineType())
+	assert.Equal(t, int64(2), instanceGroup.MinSize())
+	assert.Equal(t, int64(2), instanceGroup.MaxSize())
+
+	instanceGroup.SetMachineType("m5.xlarge")
+	instanceGroup.SetSize(4, 6)
+	assert.Equal(t, "m5.xlarge", instanceGroup.MachineType())
+	assert.Equal(t, int64(4), instanceGroup.MinSize())
+	assert.Equal(t, int64(6), instanceGroup.MaxSize())
+
+	data, err := json.Marshal(instanceGroup)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"kind": "InstanceGroup",
+		"metadata": {"name": "nodes"},
+		"spec": {"role": "Node", "machineType": "m5.xlarge", "minSize": 4, "maxSize": 6, "subnets": ["us-east-1a"]}
+	}`, string(data))
+}
+
+func TestNewInstanceGroupFromTemplate(t *testing.T) {
+	instanceGroups, err := parseInstanceGroups([]byte(testInstanceGroupNodes))
+	require.NoError(t, err)
+	template := instanceGroups[0]
+
+	instanceGroup, err := newInstanceGroupFromTemplate(template, model.KopsInstanceGroup{
+		Name:        "memory",
+		MachineType: "r5.xlarge",
+		MinCount:    1,
+		MaxCount:    3,
+		MaxPrice:    "0.10",
+	})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(instanceGroup)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"kind": "InstanceGroup",
+		"metadata": {"name": "memory"},
+		"spec": {
+			"role": "Node",
+			"machineType": "r5.xlarge",
+			"minSize": 1,
+			"maxSize": 3,
+			"maxPrice": "0.10",
+			"nodeLabels": {"kops.k8s.io/instancegroup": "memory"},
+			"subnets": ["us-east-1a"]
+		}
+	}`, string(data))
+
+	// The template is left unchanged.
+	assert.Equal(t, "nodes", template.Name())
+	assert.Equal(t, "m5.large", template.MachineType())
+
+	t.Run("switch to on-demand", func(t *testing.T) {
+		applyInstanceGroupSpec(instanceGroup, model.KopsInstanceGroup{
+			Name:        "memory",
+			MachineType: "r5.xlarge",
+			MinCount:    1,
+			MaxCount:    3,
+		})
+		_, ok := instanceGroup.section("spec")["maxPrice"]
+		assert.False(t, ok)
+	})
+}
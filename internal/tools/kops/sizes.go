@@ -2,6 +2,7 @@ package kops
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/mattermost/mattermost-cloud/model"
@@ -67,7 +68,7 @@ var sizeAlef10000 = ClusterSize{
 }
 
 // GetSize takes a size keyword and returns the matching kops cluster
-// configuration.
+// configuration, falling back to the custom sizes defined by configuration.
 func GetSize(size string) (ClusterSize, error) {
 	parsedSize, masterCount, err := parseHA(size)
 	if err != nil {
@@ -75,7 +76,20 @@ func GetSize(size string) (ClusterSize, error) {
 	}
 	kopsClusterSize, ok := validSizes[parsedSize]
 	if !ok {
-		return ClusterSize{}, fmt.Errorf("unsupported size %s", size)
+		customSize := model.GetCustomClusterSize(parsedSize)
+		if customSize == nil {
+			return ClusterSize{}, fmt.Errorf("unsupported size %s", size)
+		}
+		kopsClusterSize = ClusterSize{
+			NodeCount:  strconv.FormatInt(customSize.NodeCount, 10),
+			NodeSize:   customSize.NodeInstanceType,
+			MasterSize: customSize.MasterInstanceType,
+		}
+		// Custom sizes define their own master count unless an HA variant
+		// is requested explicitly.
+		if !strings.Contains(size, haSeparator) {
+			masterCount = strconv.FormatInt(customSize.MasterCount, 10)
+		}
 	}
 	kopsClusterSize.MasterCount = masterCount
 
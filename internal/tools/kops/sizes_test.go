@@ -3,6 +3,7 @@ package kops
 import (
 	"testing"
 
+	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -61,3 +62,29 @@ func TestGetSize(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCustomSize(t *testing.T) {
+	model.SetCustomClusterSizes([]*model.ClusterSize{
+		{Name: "SizeCustom", NodeCount: 4, NodeInstanceType: "m5.2xlarge", MasterCount: 3, MasterInstanceType: "t3.large"},
+	})
+	defer model.SetCustomClusterSizes(nil)
+
+	customSize := ClusterSize{
+		NodeCount:   "4",
+		NodeSize:    "m5.2xlarge",
+		MasterCount: "3",
+		MasterSize:  "t3.large",
+	}
+
+	clusterSize, err := GetSize("SizeCustom")
+	assert.NoError(t, err)
+	assert.Equal(t, customSize, clusterSize)
+
+	clusterSize, err = GetSize("SizeCustom-HA2")
+	assert.NoError(t, err)
+	customSize.MasterCount = "2"
+	assert.Equal(t, customSize, clusterSize)
+
+	_, err = GetSize("SizeOther")
+	assert.Error(t, err)
+}
@@ -120,6 +120,37 @@ func (r *ResourceUtil) GetDatabase(installation *model.Installation) model.Datab
 	return model.NewMysqlOperatorDatabase()
 }
 
+// GetFilestoreFromBackup returns the Filestore interface that matches the
+// installation and is provisioned with the contents of the given backup.
+func (r *ResourceUtil) GetFilestoreFromBackup(installation *model.Installation, backup *model.InstallationBackup) model.Filestore {
+	if installation.Filestore == model.InstallationFilestoreAwsS3 {
+		return aws.NewS3FilestoreFromBackup(installation, backup.FilestoreBucket, backup.FilestorePrefix, r.awsClient)
+	}
+
+	return r.GetFilestore(installation)
+}
+
+// GetDatabaseFromBackup returns the Database interface that matches the
+// installation and is provisioned from the given backup.
+func (r *ResourceUtil) GetDatabaseFromBackup(installation *model.Installation, backup *model.InstallationBackup) model.Database {
+	if installation.Database == model.InstallationDatabaseAwsRDS {
+		return aws.NewRDSDatabaseFromSnapshot(installation.ID, backup.DatabaseSnapshotID, r.awsClient)
+	}
+
+	return r.GetDatabase(installation)
+}
+
+// GetDatabaseMigration returns the CIMigrationDatabase interface used to move
+// the installation database to the migration target cluster of the
+// installation, or nil if the installation database cannot be migrated.
+func (r *ResourceUtil) GetDatabaseMigration(installation *model.Installation) model.CIMigrationDatabase {
+	if installation.Database == model.InstallationDatabaseAwsRDS && installation.MigrationTargetClusterID != nil {
+		return aws.NewRDSDatabaseMigration(installation.ID, *installation.MigrationTargetClusterID, r.awsClient)
+	}
+
+	return nil
+}
+
 // Retry is retrying a function for a maximum number of attempts and time
 func Retry(attempts int, sleep time.Duration, f func() error) error {
 	if err := f(); err != nil {
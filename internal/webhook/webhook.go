@@ -3,6 +3,7 @@ package webhook
 import (
 	"bytes"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/mattermost/mattermost-cloud/model"
@@ -12,9 +13,12 @@ import (
 
 type webhookStore interface {
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
 }
 
-// SendToAllWebhooks sends a given payload to all webhooks.
+// SendToAllWebhooks queues a given payload for delivery to all webhooks that
+// are subscribed to it. The deliveries themselves are made by the webhook
+// delivery supervisor.
 func SendToAllWebhooks(store webhookStore, payload *model.WebhookPayload, logger *log.Entry) error {
 	hooks, err := store.GetWebhooks(&model.WebhookFilter{
 		PerPage:        model.AllPerPage,
@@ -24,41 +28,72 @@ func SendToAllWebhooks(store webhookStore, payload *model.WebhookPayload, logger
 		return errors.Wrap(err, "Failed to find webhooks")
 	}
 
-	sendWebhooks(hooks, payload, logger)
+	var subscribedHooks []*model.Webhook
+	for _, hook := range hooks {
+		if hook.IsSubscribedTo(payload) {
+			subscribedHooks = append(subscribedHooks, hook)
+		}
+	}
 
-	return nil
+	return queueWebhooks(store, subscribedHooks, payload, logger)
 }
 
-// sendWebhooks sends webhooks via fire-and-forget goroutines. The send-webhook
-// failures are logged, but not handled.
-func sendWebhooks(hooks []*model.Webhook, payload *model.WebhookPayload, logger *log.Entry) {
+// queueWebhooks records a pending delivery of the payload for each of the
+// given webhooks.
+func queueWebhooks(store webhookStore, hooks []*model.Webhook, payload *model.WebhookPayload, logger *log.Entry) error {
 	if len(hooks) == 0 {
-		return
+		return nil
+	}
+
+	payloadStr, err := payload.ToJSON()
+	if err != nil {
+		return errors.Wrap(err, "unable to create payload string to send to webhooks")
 	}
 
-	logger.Debugf("Sending %d webhook(s)", len(hooks))
+	logger.Debugf("Queueing %d webhook(s)", len(hooks))
 
+	var queueErr error
 	for _, hook := range hooks {
-		go sendWebhook(hook, payload, logger)
+		err = store.CreateWebhookDelivery(&model.WebhookDelivery{
+			WebhookID: hook.ID,
+			Payload:   payloadStr,
+			State:     model.WebhookDeliveryStatePending,
+		})
+		if err != nil {
+			logger.WithField("webhookURL", hook.URL).WithError(err).Error("Unable to queue webhook delivery")
+			queueErr = errors.Wrap(err, "unable to queue webhook delivery")
+		}
 	}
+
+	return queueErr
 }
 
-func sendWebhook(hook *model.Webhook, payload *model.WebhookPayload, logger *log.Entry) error {
-	payloadStr, err := payload.ToJSON()
+// Deliver sends the given payload to the webhook. If the webhook has a secret,
+// the request is signed. An error is returned if the receiver could not be
+// reached or did not respond with a 2xx status code.
+func Deliver(hook *model.Webhook, payload string) error {
+	req, err := http.NewRequest("POST", hook.URL, bytes.NewBuffer([]byte(payload)))
 	if err != nil {
-		logger.WithField("webhookURL", hook.URL).WithError(err).Error("Unable to create payload string to send to webhook")
-		return errors.Wrap(err, "unable to create payload string to send to webhook")
+		return errors.Wrap(err, "unable to create webhook request")
 	}
-
-	req, err := http.NewRequest("POST", hook.URL, bytes.NewBuffer([]byte(payloadStr)))
 	req.Header.Set("Content-Type", "application/json")
 
+	if hook.Secret != "" {
+		timestamp := time.Now().Unix()
+		req.Header.Set(model.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(model.WebhookSignatureHeader, model.SignWebhookPayload(hook.Secret, timestamp, []byte(payload)))
+	}
+
 	client := &http.Client{Timeout: 5 * time.Second}
-	_, err = client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		logger.WithField("webhookURL", hook.URL).WithError(err).Error("Unable to send webhook")
 		return errors.Wrap(err, "unable to send webhook")
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("webhook receiver responded with status code %d", resp.StatusCode)
+	}
 
 	return nil
 }
@@ -1,6 +1,9 @@
 package webhook
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -11,22 +14,37 @@ import (
 )
 
 type mockWebhookStore struct {
-	Webhooks []*model.Webhook
+	Webhooks   []*model.Webhook
+	Deliveries []*model.WebhookDelivery
 }
 
 func (s *mockWebhookStore) GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error) {
 	return s.Webhooks, nil
 }
 
+func (s *mockWebhookStore) CreateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	s.Deliveries = append(s.Deliveries, delivery)
+	return nil
+}
+
 func TestGetAndSendWebhooks(t *testing.T) {
 	mockStore := &mockWebhookStore{}
 	logger := testlib.MakeLogger(t).WithFields(log.Fields{
 		"webhooks-tests": true,
 	})
+	payload := &model.WebhookPayload{
+		Type:     "type",
+		ID:       model.NewID(),
+		NewState: "new_state",
+		OldState: "old_state",
+	}
+	payloadStr, err := payload.ToJSON()
+	require.NoError(t, err)
 
 	t.Run("no webhooks", func(t *testing.T) {
-		err := SendToAllWebhooks(mockStore, nil, logger)
+		err := SendToAllWebhooks(mockStore, payload, logger)
 		require.NoError(t, err)
+		require.Empty(t, mockStore.Deliveries)
 	})
 
 	mockStore.Webhooks = append(mockStore.Webhooks, &model.Webhook{
@@ -38,8 +56,14 @@ func TestGetAndSendWebhooks(t *testing.T) {
 	})
 
 	t.Run("1 webhook", func(t *testing.T) {
-		err := SendToAllWebhooks(mockStore, nil, logger)
+		mockStore.Deliveries = nil
+
+		err := SendToAllWebhooks(mockStore, payload, logger)
 		require.NoError(t, err)
+		require.Len(t, mockStore.Deliveries, 1)
+		require.Equal(t, mockStore.Webhooks[0].ID, mockStore.Deliveries[0].WebhookID)
+		require.Equal(t, payloadStr, mockStore.Deliveries[0].Payload)
+		require.Equal(t, model.WebhookDeliveryStatePending, mockStore.Deliveries[0].State)
 	})
 
 	mockStore.Webhooks = append(mockStore.Webhooks, &model.Webhook{
@@ -51,32 +75,117 @@ func TestGetAndSendWebhooks(t *testing.T) {
 	})
 
 	t.Run("2 webhooks", func(t *testing.T) {
-		err := SendToAllWebhooks(mockStore, nil, logger)
+		mockStore.Deliveries = nil
+
+		err := SendToAllWebhooks(mockStore, payload, logger)
 		require.NoError(t, err)
+		require.Len(t, mockStore.Deliveries, 2)
+		require.Equal(t, mockStore.Webhooks[1].ID, mockStore.Deliveries[1].WebhookID)
 	})
 }
 
-// TODO: add happy-path test.
-func TestSendWebhooks(t *testing.T) {
+func TestSendToSubscribedWebhooks(t *testing.T) {
 	logger := testlib.MakeLogger(t).WithFields(log.Fields{
 		"webhooks-tests": true,
 	})
-	hook := &model.Webhook{
-		ID:       model.NewID(),
-		OwnerID:  model.NewID(),
-		URL:      "https://not-a-real-host",
-		CreateAt: 10,
-		DeleteAt: 0,
+
+	allHook := &model.Webhook{ID: model.NewID(), URL: "https://all.com"}
+	clusterHook := &model.Webhook{ID: model.NewID(), URL: "https://cluster.com", Types: []string{model.TypeCluster}}
+	stableHook := &model.Webhook{ID: model.NewID(), URL: "https://stable.com", NewStates: []string{model.InstallationStateStable}}
+	ownerHook := &model.Webhook{ID: model.NewID(), URL: "https://owner.com", InstallationOwnerID: "owner1"}
+	mockStore := &mockWebhookStore{
+		Webhooks: []*model.Webhook{allHook, clusterHook, stableHook, ownerHook},
 	}
-	payload := &model.WebhookPayload{
-		Type:      "type",
-		ID:        model.NewID(),
-		NewState:  "new_state",
-		OldState:  "old_state",
-		Timestamp: time.Now().UnixNano(),
-		ExtraData: map[string]string{"ClusterID": model.NewID()},
+
+	deliveredTo := func() []string {
+		var webhookIDs []string
+		for _, delivery := range mockStore.Deliveries {
+			webhookIDs = append(webhookIDs, delivery.WebhookID)
+		}
+		return webhookIDs
 	}
 
-	err := sendWebhook(hook, payload, logger)
-	require.Contains(t, err.Error(), "unable to send webhook")
+	t.Run("installation payload", func(t *testing.T) {
+		mockStore.Deliveries = nil
+
+		err := SendToAllWebhooks(mockStore, &model.WebhookPayload{
+			ID:        model.NewID(),
+			Type:      model.TypeInstallation,
+			NewState:  model.InstallationStateStable,
+			ExtraData: map[string]string{"OwnerID": "owner2"},
+		}, logger)
+		require.NoError(t, err)
+		require.Equal(t, []string{allHook.ID, stableHook.ID}, deliveredTo())
+	})
+
+	t.Run("cluster payload", func(t *testing.T) {
+		mockStore.Deliveries = nil
+
+		err := SendToAllWebhooks(mockStore, &model.WebhookPayload{
+			ID:       model.NewID(),
+			Type:     model.TypeCluster,
+			NewState: model.ClusterStateProvisioningRequested,
+		}, logger)
+		require.NoError(t, err)
+		require.Equal(t, []string{allHook.ID, clusterHook.ID, ownerHook.ID}, deliveredTo())
+	})
+}
+
+func TestDeliver(t *testing.T) {
+	var statusCode int
+	var body []byte
+	var header http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		body, err = ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		header = r.Header
+		w.WriteHeader(statusCode)
+	}))
+	defer ts.Close()
+
+	hook := &model.Webhook{
+		ID:      model.NewID(),
+		OwnerID: model.NewID(),
+		URL:     ts.URL,
+	}
+
+	t.Run("unreachable receiver", func(t *testing.T) {
+		err := Deliver(&model.Webhook{URL: "https://not-a-real-host"}, `{}`)
+		require.Contains(t, err.Error(), "unable to send webhook")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		statusCode = http.StatusOK
+		err := Deliver(hook, `{"id":"payload1"}`)
+		require.NoError(t, err)
+		require.Equal(t, `{"id":"payload1"}`, string(body))
+		require.Empty(t, header.Get(model.WebhookSignatureHeader))
+		require.Empty(t, header.Get(model.WebhookTimestampHeader))
+	})
+
+	t.Run("non-2xx response", func(t *testing.T) {
+		statusCode = http.StatusInternalServerError
+		err := Deliver(hook, `{"id":"payload2"}`)
+		require.EqualError(t, err, "webhook receiver responded with status code 500")
+		require.Equal(t, `{"id":"payload2"}`, string(body))
+	})
+
+	t.Run("signed", func(t *testing.T) {
+		statusCode = http.StatusOK
+		signedHook := &model.Webhook{
+			ID:      model.NewID(),
+			OwnerID: model.NewID(),
+			URL:     ts.URL,
+			Secret:  "secret",
+		}
+		err := Deliver(signedHook, `{"id":"payload3"}`)
+		require.NoError(t, err)
+
+		err = model.VerifyWebhookSignature("secret", header.Get(model.WebhookTimestampHeader), header.Get(model.WebhookSignatureHeader), body, time.Minute)
+		require.NoError(t, err)
+
+		err = model.VerifyWebhookSignature("other", header.Get(model.WebhookTimestampHeader), header.Get(model.WebhookSignatureHeader), body, time.Minute)
+		require.Error(t, err)
+	})
 }
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+)
+
+// APIKeyHeader is the request header used to authenticate with an API key.
+const APIKeyHeader = "X-Api-Key"
+
+const (
+	// APIKeyScopeReadOnly allows reading any resource.
+	APIKeyScopeReadOnly = "read-only"
+	// APIKeyScopeInstallationAdmin allows reading any resource and managing
+	// installations, groups and webhooks.
+	APIKeyScopeInstallationAdmin = "installation-admin"
+	// APIKeyScopeClusterAdmin allows reading and managing any resource.
+	APIKeyScopeClusterAdmin = "cluster-admin"
+)
+
+// AllAPIKeyScopes is a list of all scopes an API key can be granted.
+var AllAPIKeyScopes = []string{
+	APIKeyScopeReadOnly,
+	APIKeyScopeInstallationAdmin,
+	APIKeyScopeClusterAdmin,
+}
+
+// APIKey is a credential used to authenticate with the provisioning server.
+// Only a hash of the key itself is stored.
+type APIKey struct {
+	ID          string
+	Description string
+	KeyHash     string `json:"-"`
+	Scopes      []string
+
+	// OwnerID, if set, limits the API key to the installations and webhooks
+	// with the given owner.
+	OwnerID string `json:"OwnerID,omitempty"`
+
+	CreateAt int64
+	DeleteAt int64
+}
+
+// APIKeyFilter describes the parameters used to constrain a set of API keys.
+type APIKeyFilter struct {
+	OwnerID        string
+	Page           int
+	PerPage        int
+	IncludeDeleted bool
+}
+
+// IsDeleted returns whether the API key was revoked or not.
+func (k *APIKey) IsDeleted() bool {
+	return k.DeleteAt != 0
+}
+
+// HasScope returns whether the API key was granted the given scope or not.
+func (k *APIKey) HasScope(scope string) bool {
+	return containsString(k.Scopes, scope)
+}
+
+// NewAPIKeySecret generates a new random API key to be handed out to a client.
+func NewAPIKeySecret() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate random API key")
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// HashAPIKey returns the hash of the given API key as it is stored.
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// ValidateAPIKeyScopes checks that at least one scope was given and that all
+// of the given scopes are known.
+func ValidateAPIKeyScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("must specify at least one scope")
+	}
+	for _, scope := range scopes {
+		if !containsString(AllAPIKeyScopes, scope) {
+			return errors.Errorf("unsupported API key scope %s", scope)
+		}
+	}
+
+	return nil
+}
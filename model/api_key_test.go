@@ -0,0 +1,34 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIKeySecret(t *testing.T) {
+	key1, err := model.NewAPIKeySecret()
+	require.NoError(t, err)
+	require.Len(t, key1, 64)
+
+	key2, err := model.NewAPIKeySecret()
+	require.NoError(t, err)
+	require.NotEqual(t, key1, key2)
+
+	require.Equal(t, model.HashAPIKey(key1), model.HashAPIKey(key1))
+	require.NotEqual(t, model.HashAPIKey(key1), model.HashAPIKey(key2))
+	require.NotEqual(t, key1, model.HashAPIKey(key1))
+}
+
+func TestValidateAPIKeyScopes(t *testing.T) {
+	require.EqualError(t, model.ValidateAPIKeyScopes(nil), "must specify at least one scope")
+	require.EqualError(t, model.ValidateAPIKeyScopes([]string{"unknown"}), "unsupported API key scope unknown")
+	require.NoError(t, model.ValidateAPIKeyScopes([]string{model.APIKeyScopeReadOnly, model.APIKeyScopeClusterAdmin}))
+}
+
+func TestAPIKeyHasScope(t *testing.T) {
+	apiKey := &model.APIKey{Scopes: []string{model.APIKeyScopeInstallationAdmin}}
+	require.True(t, apiKey.HasScope(model.APIKeyScopeInstallationAdmin))
+	require.False(t, apiKey.HasScope(model.APIKeyScopeClusterAdmin))
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// AuditRecord is a persisted record of a mutating request handled by the API.
+type AuditRecord struct {
+	ID        string
+	RequestID string
+	// APIKeyID identifies the API key the request was authenticated with, and
+	// is empty for unauthenticated requests.
+	APIKeyID   string `json:",omitempty"`
+	RemoteAddr string
+	Method     string
+	// Route is the path template matched by the request, for example
+	// /api/cluster/{cluster}.
+	Route      string
+	Path       string
+	ResourceID string `json:",omitempty"`
+	// RequestBody is the request body with sensitive values redacted.
+	RequestBody string `json:",omitempty"`
+	StatusCode  int
+	CreateAt    int64
+}
+
+// AuditRecordFilter describes the parameters used to constrain a set of audit records.
+type AuditRecordFilter struct {
+	APIKeyID   string
+	ResourceID string
+	Page       int
+	PerPage    int
+}
+
+// AuditRecordsFromReader decodes a json-encoded list of audit records from the given io.Reader.
+func AuditRecordsFromReader(reader io.Reader) ([]*AuditRecord, error) {
+	auditRecords := []*AuditRecord{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&auditRecords)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return auditRecords, nil
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// GetAuditRecordsRequest describes the parameters to request a list of audit records.
+type GetAuditRecordsRequest struct {
+	APIKeyID   string
+	ResourceID string
+	Page       int
+	PerPage    int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetAuditRecordsRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	addNonEmptyQueryParam(q, "api_key_id", request.APIKeyID)
+	addNonEmptyQueryParam(q, "resource_id", request.ResourceID)
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	u.RawQuery = q.Encode()
+}
@@ -254,6 +254,23 @@ func (c *Client) UpgradeCluster(clusterID, version string) error {
 	}
 }
 
+// ResizeCluster resizes a cluster's kops instance groups to the given size.
+func (c *Client) ResizeCluster(clusterID string, request *ResizeClusterRequest) (*Cluster, error) {
+	resp, err := c.doPut(c.buildURL("/api/cluster/%s/size", clusterID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return ClusterFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // DeleteCluster deletes the given cluster and all resources contained therein.
 func (c *Client) DeleteCluster(clusterID string) error {
 	resp, err := c.doDelete(c.buildURL("/api/cluster/%s", clusterID))
@@ -271,6 +288,60 @@ func (c *Client) DeleteCluster(clusterID string) error {
 	}
 }
 
+// GetClusterSizes fetches the custom cluster sizes supported by the configured
+// provisioning server in addition to the built-in sizes.
+func (c *Client) GetClusterSizes() ([]*ClusterSize, error) {
+	resp, err := c.doGet(c.buildURL("/api/cluster/sizes"))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ClusterSizesFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// CreateClusterSize records a new custom cluster size with the configured
+// provisioning server.
+func (c *Client) CreateClusterSize(clusterSize *ClusterSize) (*ClusterSize, error) {
+	resp, err := c.doPost(c.buildURL("/api/cluster/sizes"), clusterSize)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ClusterSizeFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// DeleteClusterSize deletes the given custom cluster size, which must not be
+// used by any cluster.
+func (c *Client) DeleteClusterSize(name string) error {
+	resp, err := c.doDelete(c.buildURL("/api/cluster/sizes/%s", name))
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+
+	default:
+		return errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // CreateInstallation requests the creation of a installation from the configured provisioning server.
 func (c *Client) CreateInstallation(request *CreateInstallationRequest) (*Installation, error) {
 	resp, err := c.doPost(c.buildURL("/api/installations"), request)
@@ -288,6 +359,25 @@ func (c *Client) CreateInstallation(request *CreateInstallationRequest) (*Instal
 	}
 }
 
+// ScheduleInstallation asks the configured provisioning server which cluster
+// an installation with the given requirements would be scheduled onto,
+// without creating it.
+func (c *Client) ScheduleInstallation(request *ScheduleInstallationRequest) (*SchedulingDecision, error) {
+	resp, err := c.doPost(c.buildURL("/api/installations/schedule"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return SchedulingDecisionFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // RetryCreateInstallation retries the creation of a installation from the configured provisioning server.
 func (c *Client) RetryCreateInstallation(installationID string) error {
 	resp, err := c.doPost(c.buildURL("/api/installation/%s", installationID), nil)
@@ -373,6 +463,57 @@ func (c *Client) UpdateInstallation(installationID string, request *PatchInstall
 	}
 }
 
+// HibernateInstallation puts an installation into hibernation.
+func (c *Client) HibernateInstallation(installationID string) (*Installation, error) {
+	resp, err := c.doPost(c.buildURL("/api/installation/%s/hibernate", installationID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// WakeupInstallation wakes up a hibernating installation.
+func (c *Client) WakeupInstallation(installationID string) (*Installation, error) {
+	resp, err := c.doPost(c.buildURL("/api/installation/%s/wakeup", installationID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// MigrateInstallation moves an installation to the cluster given in the request.
+func (c *Client) MigrateInstallation(installationID string, request *MigrateInstallationRequest) (*Installation, error) {
+	resp, err := c.doPost(c.buildURL("/api/installation/%s/migrate", installationID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // DeleteInstallation deletes the given installation and all resources contained therein.
 func (c *Client) DeleteInstallation(installationID string) error {
 	resp, err := c.doDelete(c.buildURL("/api/installation/%s", installationID))
@@ -390,6 +531,84 @@ func (c *Client) DeleteInstallation(installationID string) error {
 	}
 }
 
+// CreateInstallationBackup requests a new backup of the given installation.
+func (c *Client) CreateInstallationBackup(installationID string) (*InstallationBackup, error) {
+	resp, err := c.doPost(c.buildURL("/api/installation/%s/backups", installationID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationBackupFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetInstallationBackup fetches the specified installation backup from the configured provisioning server.
+func (c *Client) GetInstallationBackup(installationID, backupID string) (*InstallationBackup, error) {
+	resp, err := c.doGet(c.buildURL("/api/installation/%s/backup/%s", installationID, backupID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return InstallationBackupFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetInstallationBackups fetches the list of backups of the given installation from the configured provisioning server.
+func (c *Client) GetInstallationBackups(installationID string, request *GetInstallationBackupsRequest) ([]*InstallationBackup, error) {
+	u, err := url.Parse(c.buildURL("/api/installation/%s/backups", installationID))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return InstallationBackupsFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// RestoreInstallationBackup requests the creation of a new installation from the given installation backup.
+func (c *Client) RestoreInstallationBackup(installationID, backupID string, request *RestoreInstallationBackupRequest) (*Installation, error) {
+	resp, err := c.doPost(c.buildURL("/api/installation/%s/backup/%s/restore", installationID, backupID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // GetClusterInstallation fetches the specified cluster installation from the configured provisioning server.
 func (c *Client) GetClusterInstallation(clusterInstallationID string) (*ClusterInstallation, error) {
 	resp, err := c.doGet(c.buildURL("/api/cluster_installation/%s", clusterInstallationID))
@@ -705,3 +924,133 @@ func (c *Client) DeleteWebhook(webhookID string) error {
 		return errors.Errorf("failed with status code %d", resp.StatusCode)
 	}
 }
+
+// GetWebhookDeliveries fetches the list of deliveries of the given webhook from the configured provisioning server.
+func (c *Client) GetWebhookDeliveries(webhookID string, request *GetWebhookDeliveriesRequest) ([]*WebhookDelivery, error) {
+	u, err := url.Parse(c.buildURL("/api/webhook/%s/deliveries", webhookID))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return WebhookDeliveriesFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// ReplayWebhookDelivery requests that a failed webhook delivery be queued for delivery again.
+func (c *Client) ReplayWebhookDelivery(webhookID, deliveryID string) (*WebhookDelivery, error) {
+	resp, err := c.doPost(c.buildURL("/api/webhook/%s/deliveries/%s/replay", webhookID, deliveryID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return WebhookDeliveryFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetEvents fetches the list of state transition events from the configured provisioning server.
+func (c *Client) GetEvents(request *GetEventsRequest) ([]*Event, error) {
+	u, err := url.Parse(c.buildURL("/api/events"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return EventsFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetAuditRecords fetches the list of audited API requests from the configured provisioning server.
+func (c *Client) GetAuditRecords(request *GetAuditRecordsRequest) ([]*AuditRecord, error) {
+	u, err := url.Parse(c.buildURL("/api/audit"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return AuditRecordsFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetLocks fetches the list of locks currently held on resources from the configured provisioning server.
+func (c *Client) GetLocks(request *GetLocksRequest) ([]*Lock, error) {
+	u, err := url.Parse(c.buildURL("/api/locks"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return LocksFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// ReleaseLock forcibly releases the lock held on the given resource.
+func (c *Client) ReleaseLock(resourceType, resourceID string) error {
+	resp, err := c.doDelete(c.buildURL("/api/lock/%s/%s", resourceType, resourceID))
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+
+	default:
+		return errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
@@ -24,6 +24,21 @@ type Cluster struct {
 	LockAcquiredBy      *string
 	LockAcquiredAt      int64
 	UtilityMetadata     []byte `json:",omitempty"`
+
+	// Attempts is the number of consecutive failed attempts to transition the
+	// cluster out of its current state, with LastError the error from the last
+	// attempt. The cluster is not supervised again before NextAttemptAt.
+	Attempts      int
+	LastError     string `json:",omitempty"`
+	NextAttemptAt int64
+}
+
+// ResetAttempts clears the record of failed attempts to transition the
+// cluster out of its current state, as is done whenever its state changes.
+func (c *Cluster) ResetAttempts() {
+	c.Attempts = 0
+	c.LastError = ""
+	c.NextAttemptAt = 0
 }
 
 // Clone returns a deep copy the cluster.
@@ -67,6 +82,53 @@ func (c *Cluster) SetProvisionerMetadata(data interface{}) error {
 	return nil
 }
 
+// HasNodePool returns whether installations pinned to the given node pool can
+// run on the cluster. Every cluster has the default node pool, named "".
+func (c *Cluster) HasNodePool(nodePool string) bool {
+	if nodePool == "" {
+		return true
+	}
+
+	kopsMetadata, err := NewKopsMetadata(c.ProvisionerMetadata)
+	if err != nil {
+		return false
+	}
+
+	return kopsMetadata.GetInstanceGroup(nodePool) != nil
+}
+
+// IsCapacityManaged returns whether the cluster was requested by the capacity
+// manager, and so may be deleted by it once empty.
+func (c *Cluster) IsCapacityManaged() bool {
+	kopsMetadata, err := NewKopsMetadata(c.ProvisionerMetadata)
+	if err != nil {
+		return false
+	}
+
+	return kopsMetadata.CapacityManaged
+}
+
+// InZone returns whether installations pinned to the given availability zone
+// can run on the cluster. Installations not pinned to a zone, named "", can
+// run on any cluster.
+func (c *Cluster) InZone(zone string) bool {
+	if zone == "" {
+		return true
+	}
+
+	awsMetadata, err := NewAWSMetadata(c.ProviderMetadata)
+	if err != nil {
+		return false
+	}
+	for _, clusterZone := range awsMetadata.Zones {
+		if clusterZone == zone {
+			return true
+		}
+	}
+
+	return false
+}
+
 // ClusterFromReader decodes a json-encoded cluster from the given io.Reader.
 func ClusterFromReader(reader io.Reader) (*Cluster, error) {
 	cluster := Cluster{}
@@ -94,9 +156,13 @@ func ClustersFromReader(reader io.Reader) ([]*Cluster, error) {
 
 // ClusterFilter describes the parameters used to constrain a set of clusters.
 type ClusterFilter struct {
-	Page           int
-	PerPage        int
-	IncludeDeleted bool
+	States             []string
+	Size               string
+	Version            string
+	AllowInstallations *bool
+	Page               int
+	PerPage            int
+	IncludeDeleted     bool
 }
 
 var clusterVersionMatcher = regexp.MustCompile(`^(([0-9]{1,3}.[0-9]{1,3}.[0-9]{1,3})|(latest))$`)
@@ -11,13 +11,14 @@ import (
 
 // CreateClusterRequest specifies the parameters for a new cluster.
 type CreateClusterRequest struct {
-	Provider               string            `json:"provider,omitempty"`
-	Version                string            `json:"version,omitempty"`
-	KopsAMI                string            `json:"kops-ami,omitempty"`
-	Size                   string            `json:"size,omitempty"`
-	Zones                  []string          `json:"zones,omitempty"`
-	AllowInstallations     bool              `json:"allow-installations,omitempty"`
-	DesiredUtilityVersions map[string]string `json:"utility-versions,omitempty"`
+	Provider               string              `json:"provider,omitempty"`
+	Version                string              `json:"version,omitempty"`
+	KopsAMI                string              `json:"kops-ami,omitempty"`
+	Size                   string              `json:"size,omitempty"`
+	Zones                  []string            `json:"zones,omitempty"`
+	AllowInstallations     bool                `json:"allow-installations,omitempty"`
+	DesiredUtilityVersions map[string]string   `json:"utility-versions,omitempty"`
+	InstanceGroups         []KopsInstanceGroup `json:"instance-groups,omitempty"`
 }
 
 // SetDefaults sets the default values for a cluster create request.
@@ -59,6 +60,10 @@ func (request *CreateClusterRequest) Validate() error {
 	if !IsSupportedClusterSize(request.Size) {
 		return errors.Errorf("unsupported size %s", request.Size)
 	}
+	err := validateInstanceGroups(request.InstanceGroups)
+	if err != nil {
+		return errors.Wrap(err, "invalid instance groups")
+	}
 	// TODO: check zones?
 
 	return nil
@@ -84,14 +89,26 @@ func NewCreateClusterRequestFromReader(reader io.Reader) (*CreateClusterRequest,
 
 // GetClustersRequest describes the parameters to request a list of clusters.
 type GetClustersRequest struct {
-	Page           int
-	PerPage        int
-	IncludeDeleted bool
+	States             []string
+	Size               string
+	Version            string
+	AllowInstallations *bool
+	Page               int
+	PerPage            int
+	IncludeDeleted     bool
 }
 
 // ApplyToURL modifies the given url to include query string parameters for the request.
 func (request *GetClustersRequest) ApplyToURL(u *url.URL) {
 	q := u.Query()
+	for _, state := range request.States {
+		q.Add("state", state)
+	}
+	addNonEmptyQueryParam(q, "size", request.Size)
+	addNonEmptyQueryParam(q, "version", request.Version)
+	if request.AllowInstallations != nil {
+		q.Add("allow_installations", strconv.FormatBool(*request.AllowInstallations))
+	}
 	q.Add("page", strconv.Itoa(request.Page))
 	q.Add("per_page", strconv.Itoa(request.PerPage))
 	if request.IncludeDeleted {
@@ -129,3 +146,41 @@ func NewProvisionClusterRequestFromReader(reader io.Reader) (*ProvisionClusterRe
 	}
 	return &provisionClusterRequest, nil
 }
+
+// ResizeClusterRequest specifies the new size of an existing cluster. Any
+// instance groups given replace the declared instance groups of the same name
+// or are added to the cluster.
+type ResizeClusterRequest struct {
+	Size           string              `json:"size,omitempty"`
+	InstanceGroups []KopsInstanceGroup `json:"instance-groups,omitempty"`
+}
+
+// Validate validates the values of a cluster resize request.
+func (request *ResizeClusterRequest) Validate() error {
+	if !IsSupportedClusterSize(request.Size) {
+		return errors.Errorf("unsupported size %s", request.Size)
+	}
+	err := validateInstanceGroups(request.InstanceGroups)
+	if err != nil {
+		return errors.Wrap(err, "invalid instance groups")
+	}
+
+	return nil
+}
+
+// NewResizeClusterRequestFromReader will create a ResizeClusterRequest from an
+// io.Reader with JSON data.
+func NewResizeClusterRequestFromReader(reader io.Reader) (*ResizeClusterRequest, error) {
+	var resizeClusterRequest ResizeClusterRequest
+	err := json.NewDecoder(reader).Decode(&resizeClusterRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode resize cluster request")
+	}
+
+	err = resizeClusterRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "resize cluster request failed validation")
+	}
+
+	return &resizeClusterRequest, nil
+}
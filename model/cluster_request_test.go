@@ -17,6 +17,12 @@ func TestCreateClusterRequestValid(t *testing.T) {
 		{"invalid provider", &model.CreateClusterRequest{Provider: "blah"}, true},
 		{"invalid version", &model.CreateClusterRequest{Version: "blah"}, true},
 		{"invalid size", &model.CreateClusterRequest{Size: "blah"}, true},
+		{"instance groups", &model.CreateClusterRequest{InstanceGroups: []model.KopsInstanceGroup{{Name: "memory", MachineType: "r5.large", MinCount: 1, MaxCount: 2}}}, false},
+		{"invalid instance group", &model.CreateClusterRequest{InstanceGroups: []model.KopsInstanceGroup{{Name: "memory", MinCount: 1, MaxCount: 2}}}, true},
+		{"duplicate instance groups", &model.CreateClusterRequest{InstanceGroups: []model.KopsInstanceGroup{
+			{Name: "memory", MachineType: "r5.large", MinCount: 1, MaxCount: 2},
+			{Name: "memory", MachineType: "r5.xlarge", MinCount: 1, MaxCount: 2},
+		}}, true},
 	}
 
 	for _, tc := range testCases {
@@ -31,3 +37,26 @@ func TestCreateClusterRequestValid(t *testing.T) {
 		})
 	}
 }
+
+func TestResizeClusterRequestValid(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		request      *model.ResizeClusterRequest
+		requireError bool
+	}{
+		{"empty", &model.ResizeClusterRequest{}, true},
+		{"invalid size", &model.ResizeClusterRequest{Size: "blah"}, true},
+		{"valid size", &model.ResizeClusterRequest{Size: model.SizeAlef1000}, false},
+		{"valid HA size", &model.ResizeClusterRequest{Size: model.SizeAlef1000 + "-HA3"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.request.Validate())
+			} else {
+				assert.NoError(t, tc.request.Validate())
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// clusterSizeNameMatcher matches valid custom cluster size names. Hyphens are
+// not allowed so that the HA suffix of a size can always be parsed.
+var clusterSizeNameMatcher = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]{0,62}$`)
+
+// ClusterSize is a custom cluster size, defined by configuration in addition
+// to the built-in sizes.
+type ClusterSize struct {
+	Name               string
+	NodeCount          int64
+	NodeInstanceType   string
+	MasterCount        int64
+	MasterInstanceType string
+	CreateAt           int64
+}
+
+// SetDefaults sets the default values for a custom cluster size.
+func (s *ClusterSize) SetDefaults() {
+	if s.MasterCount == 0 {
+		s.MasterCount = 1
+	}
+}
+
+// Validate validates the values of a custom cluster size.
+func (s *ClusterSize) Validate() error {
+	if !clusterSizeNameMatcher.MatchString(s.Name) {
+		return errors.Errorf("invalid cluster size name %s", s.Name)
+	}
+	if IsBuiltinClusterSize(s.Name) {
+		return errors.Errorf("cluster size name %s is reserved by a built-in size", s.Name)
+	}
+	if s.NodeCount < 1 {
+		return errors.New("node count must be 1 or greater")
+	}
+	if s.NodeInstanceType == "" {
+		return errors.New("must specify a node instance type")
+	}
+	if s.MasterCount < 1 || s.MasterCount > 3 {
+		return errors.New("master count must be between 1 and 3")
+	}
+	if s.MasterInstanceType == "" {
+		return errors.New("must specify a master instance type")
+	}
+
+	return nil
+}
+
+// NewClusterSizeFromReader will create a custom ClusterSize from an io.Reader
+// with JSON data.
+func NewClusterSizeFromReader(reader io.Reader) (*ClusterSize, error) {
+	var clusterSize ClusterSize
+	err := json.NewDecoder(reader).Decode(&clusterSize)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode cluster size")
+	}
+
+	clusterSize.SetDefaults()
+	err = clusterSize.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid cluster size")
+	}
+
+	return &clusterSize, nil
+}
+
+// ClusterSizeFromReader decodes a json-encoded custom cluster size from the
+// given io.Reader.
+func ClusterSizeFromReader(reader io.Reader) (*ClusterSize, error) {
+	clusterSize := ClusterSize{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&clusterSize)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &clusterSize, nil
+}
+
+// ClusterSizesFromReader decodes a json-encoded list of custom cluster sizes
+// from the given io.Reader.
+func ClusterSizesFromReader(reader io.Reader) ([]*ClusterSize, error) {
+	clusterSizes := []*ClusterSize{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&clusterSizes)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return clusterSizes, nil
+}
+
+// ValidateClusterSizes sets the default values of the given custom cluster
+// sizes and validates them, requiring unique names.
+func ValidateClusterSizes(clusterSizes []*ClusterSize) error {
+	seen := make(map[string]bool)
+	for _, clusterSize := range clusterSizes {
+		clusterSize.SetDefaults()
+		err := clusterSize.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid cluster size")
+		}
+		if seen[clusterSize.Name] {
+			return errors.Errorf("cluster size %s was provided more than once", clusterSize.Name)
+		}
+		seen[clusterSize.Name] = true
+	}
+
+	return nil
+}
+
+// customClusterSizes holds the custom cluster sizes currently supported,
+// keyed by name.
+var customClusterSizes = struct {
+	sync.RWMutex
+	sizes map[string]ClusterSize
+}{sizes: make(map[string]ClusterSize)}
+
+// SetCustomClusterSizes replaces the custom cluster sizes supported in
+// addition to the built-in sizes.
+func SetCustomClusterSizes(clusterSizes []*ClusterSize) {
+	sizes := make(map[string]ClusterSize, len(clusterSizes))
+	for _, clusterSize := range clusterSizes {
+		sizes[clusterSize.Name] = *clusterSize
+	}
+
+	customClusterSizes.Lock()
+	defer customClusterSizes.Unlock()
+	customClusterSizes.sizes = sizes
+}
+
+// AddCustomClusterSize adds or replaces a supported custom cluster size.
+func AddCustomClusterSize(clusterSize *ClusterSize) {
+	customClusterSizes.Lock()
+	defer customClusterSizes.Unlock()
+	customClusterSizes.sizes[clusterSize.Name] = *clusterSize
+}
+
+// RemoveCustomClusterSize removes a supported custom cluster size.
+func RemoveCustomClusterSize(name string) {
+	customClusterSizes.Lock()
+	defer customClusterSizes.Unlock()
+	delete(customClusterSizes.sizes, name)
+}
+
+// GetCustomClusterSize returns the custom cluster size with the given name, or
+// nil if no such size is supported.
+func GetCustomClusterSize(name string) *ClusterSize {
+	customClusterSizes.RLock()
+	defer customClusterSizes.RUnlock()
+
+	clusterSize, ok := customClusterSizes.sizes[name]
+	if !ok {
+		return nil
+	}
+
+	return &clusterSize
+}
+
+// GetCustomClusterSizes returns the supported custom cluster sizes, sorted by
+// name.
+func GetCustomClusterSizes() []*ClusterSize {
+	customClusterSizes.RLock()
+	defer customClusterSizes.RUnlock()
+
+	clusterSizes := make([]*ClusterSize, 0, len(customClusterSizes.sizes))
+	for name := range customClusterSizes.sizes {
+		clusterSize := customClusterSizes.sizes[name]
+		clusterSizes = append(clusterSizes, &clusterSize)
+	}
+	sort.Slice(clusterSizes, func(i, j int) bool {
+		return clusterSizes[i].Name < clusterSizes[j].Name
+	})
+
+	return clusterSizes
+}
@@ -0,0 +1,138 @@
+package model_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterSizeValidate(t *testing.T) {
+	valid := func() *model.ClusterSize {
+		return &model.ClusterSize{
+			Name:               "SizeCustom",
+			NodeCount:          4,
+			NodeInstanceType:   "m5.2xlarge",
+			MasterCount:        1,
+			MasterInstanceType: "t3.large",
+		}
+	}
+
+	var testCases = []struct {
+		description string
+		modify      func(*model.ClusterSize)
+		expectError bool
+	}{
+		{"valid", func(s *model.ClusterSize) {}, false},
+		{"three masters", func(s *model.ClusterSize) { s.MasterCount = 3 }, false},
+		{"empty name", func(s *model.ClusterSize) { s.Name = "" }, true},
+		{"hyphenated name", func(s *model.ClusterSize) { s.Name = "Size-Custom" }, true},
+		{"built-in name", func(s *model.ClusterSize) { s.Name = model.SizeAlef1000 }, true},
+		{"no nodes", func(s *model.ClusterSize) { s.NodeCount = 0 }, true},
+		{"no node instance type", func(s *model.ClusterSize) { s.NodeInstanceType = "" }, true},
+		{"no masters", func(s *model.ClusterSize) { s.MasterCount = 0 }, true},
+		{"too many masters", func(s *model.ClusterSize) { s.MasterCount = 4 }, true},
+		{"no master instance type", func(s *model.ClusterSize) { s.MasterInstanceType = "" }, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			clusterSize := valid()
+			tc.modify(clusterSize)
+			if tc.expectError {
+				assert.Error(t, clusterSize.Validate())
+			} else {
+				assert.NoError(t, clusterSize.Validate())
+			}
+		})
+	}
+}
+
+func TestNewClusterSizeFromReader(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		clusterSize, err := model.NewClusterSizeFromReader(bytes.NewReader([]byte(
+			`{"Name":"SizeCustom","NodeCount":2,"NodeInstanceType":"m5.large","MasterInstanceType":"t3.medium"}`,
+		)))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), clusterSize.MasterCount)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := model.NewClusterSizeFromReader(bytes.NewReader([]byte(`{"Name":"SizeCustom"}`)))
+		assert.Error(t, err)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := model.NewClusterSizeFromReader(bytes.NewReader([]byte(`{`)))
+		assert.Error(t, err)
+	})
+}
+
+func TestClusterSizesFromReader(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		clusterSizes, err := model.ClusterSizesFromReader(bytes.NewReader([]byte("")))
+		require.NoError(t, err)
+		assert.Empty(t, clusterSizes)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		clusterSizes, err := model.ClusterSizesFromReader(bytes.NewReader([]byte(`[
+			{"Name":"SizeA","NodeCount":2},
+			{"Name":"SizeB","NodeCount":6}
+		]`)))
+		require.NoError(t, err)
+		require.Len(t, clusterSizes, 2)
+		assert.Equal(t, "SizeB", clusterSizes[1].Name)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := model.ClusterSizesFromReader(bytes.NewReader([]byte(`[{`)))
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateClusterSizes(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		clusterSizes := []*model.ClusterSize{
+			{Name: "SizeA", NodeCount: 2, NodeInstanceType: "m5.large", MasterInstanceType: "t3.medium"},
+			{Name: "SizeB", NodeCount: 6, NodeInstanceType: "m5.xlarge", MasterCount: 3, MasterInstanceType: "t3.large"},
+		}
+		require.NoError(t, model.ValidateClusterSizes(clusterSizes))
+		assert.Equal(t, int64(1), clusterSizes[0].MasterCount)
+		assert.Equal(t, int64(3), clusterSizes[1].MasterCount)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.Error(t, model.ValidateClusterSizes([]*model.ClusterSize{{Name: "SizeA"}}))
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		assert.Error(t, model.ValidateClusterSizes([]*model.ClusterSize{
+			{Name: "SizeA", NodeCount: 2, NodeInstanceType: "m5.large", MasterInstanceType: "t3.medium"},
+			{Name: "SizeA", NodeCount: 2, NodeInstanceType: "m5.large", MasterInstanceType: "t3.medium"},
+		}))
+	})
+}
+
+func TestCustomClusterSizes(t *testing.T) {
+	defer model.SetCustomClusterSizes(nil)
+
+	model.SetCustomClusterSizes([]*model.ClusterSize{
+		{Name: "SizeB", NodeCount: 2},
+		{Name: "SizeA", NodeCount: 1},
+	})
+	clusterSizes := model.GetCustomClusterSizes()
+	require.Len(t, clusterSizes, 2)
+	assert.Equal(t, "SizeA", clusterSizes[0].Name)
+	assert.Equal(t, "SizeB", clusterSizes[1].Name)
+
+	model.AddCustomClusterSize(&model.ClusterSize{Name: "SizeC", NodeCount: 3})
+	require.NotNil(t, model.GetCustomClusterSize("SizeC"))
+	assert.Equal(t, int64(3), model.GetCustomClusterSize("SizeC").NodeCount)
+
+	model.RemoveCustomClusterSize("SizeA")
+	assert.Nil(t, model.GetCustomClusterSize("SizeA"))
+	assert.Len(t, model.GetCustomClusterSizes(), 2)
+}
@@ -143,3 +143,42 @@ func TestValidClusterVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterHasNodePool(t *testing.T) {
+	cluster := &Cluster{}
+	require.True(t, cluster.HasNodePool(""))
+	require.False(t, cluster.HasNodePool("memory"))
+
+	err := cluster.SetProvisionerMetadata(KopsMetadata{
+		InstanceGroups: []KopsInstanceGroup{{Name: "memory", MachineType: "r5.large", MinCount: 1, MaxCount: 1}},
+	})
+	require.NoError(t, err)
+	require.True(t, cluster.HasNodePool(""))
+	require.True(t, cluster.HasNodePool("memory"))
+	require.False(t, cluster.HasNodePool("spot"))
+}
+
+func TestClusterIsCapacityManaged(t *testing.T) {
+	cluster := &Cluster{}
+	require.False(t, cluster.IsCapacityManaged())
+
+	err := cluster.SetProvisionerMetadata(KopsMetadata{Version: "1.15.0"})
+	require.NoError(t, err)
+	require.False(t, cluster.IsCapacityManaged())
+
+	err = cluster.SetProvisionerMetadata(KopsMetadata{CapacityManaged: true})
+	require.NoError(t, err)
+	require.True(t, cluster.IsCapacityManaged())
+}
+
+func TestClusterInZone(t *testing.T) {
+	cluster := &Cluster{}
+	require.True(t, cluster.InZone(""))
+	require.False(t, cluster.InZone("us-east-1a"))
+
+	err := cluster.SetProviderMetadata(AWSMetadata{Zones: []string{"us-east-1a", "us-east-1b"}})
+	require.NoError(t, err)
+	require.True(t, cluster.InZone(""))
+	require.True(t, cluster.InZone("us-east-1b"))
+	require.False(t, cluster.InZone("us-east-1c"))
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Event is a persisted record of a resource transitioning between states.
+type Event struct {
+	ID           string
+	ResourceType string
+	ResourceID   string
+	OldState     string
+	NewState     string
+	// Timestamp is the time of the transition in nanoseconds, matching the
+	// timestamp of the corresponding webhook payload.
+	Timestamp    int64
+	InstanceID   string
+	ErrorMessage string `json:",omitempty"`
+}
+
+// EventFilter describes the parameters used to constrain a set of events.
+type EventFilter struct {
+	ResourceType string
+	ResourceID   string
+	Page         int
+	PerPage      int
+}
+
+// NewEventFromWebhookPayload creates an event describing the same state
+// transition as the given webhook payload.
+func NewEventFromWebhookPayload(payload *WebhookPayload, instanceID, errorMessage string) *Event {
+	return &Event{
+		ResourceType: payload.Type,
+		ResourceID:   payload.ID,
+		OldState:     payload.OldState,
+		NewState:     payload.NewState,
+		Timestamp:    payload.Timestamp,
+		InstanceID:   instanceID,
+		ErrorMessage: errorMessage,
+	}
+}
+
+// EventsFromReader decodes a json-encoded list of events from the given io.Reader.
+func EventsFromReader(reader io.Reader) ([]*Event, error) {
+	events := []*Event{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&events)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return events, nil
+}
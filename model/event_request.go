@@ -0,0 +1,24 @@
+package model
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// GetEventsRequest describes the parameters to request a list of events.
+type GetEventsRequest struct {
+	ResourceType string
+	ResourceID   string
+	Page         int
+	PerPage      int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetEventsRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	addNonEmptyQueryParam(q, "resource_type", request.ResourceType)
+	addNonEmptyQueryParam(q, "resource_id", request.ResourceID)
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	u.RawQuery = q.Encode()
+}
@@ -1,6 +1,27 @@
 package model
 
+import "net/url"
+
 const (
 	// AllPerPage signals the store to return all results, avoid pagination of any kind.
 	AllPerPage = -1
 )
+
+// addNonEmptyQueryParam adds the query parameter only when it has a value, so
+// that unset filters are left out of request URLs.
+func addNonEmptyQueryParam(q url.Values, name, value string) {
+	if value != "" {
+		q.Add(name, value)
+	}
+}
+
+// containsString returns whether the given value is in the list or not.
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
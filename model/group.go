@@ -23,6 +23,9 @@ type Group struct {
 
 // GroupFilter describes the parameters used to constrain a set of groups.
 type GroupFilter struct {
+	Name           string
+	Version        string
+	Image          string
 	Page           int
 	PerPage        int
 	IncludeDeleted bool
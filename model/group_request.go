@@ -136,6 +136,9 @@ func (p *PatchGroupRequest) Validate() error {
 
 // GetGroupsRequest describes the parameters to request a list of groups.
 type GetGroupsRequest struct {
+	Name           string
+	Version        string
+	Image          string
 	Page           int
 	PerPage        int
 	IncludeDeleted bool
@@ -144,6 +147,9 @@ type GetGroupsRequest struct {
 // ApplyToURL modifies the given url to include query string parameters for the request.
 func (request *GetGroupsRequest) ApplyToURL(u *url.URL) {
 	q := u.Query()
+	addNonEmptyQueryParam(q, "name", request.Name)
+	addNonEmptyQueryParam(q, "version", request.Version)
+	addNonEmptyQueryParam(q, "image", request.Image)
 	q.Add("page", strconv.Itoa(request.Page))
 	q.Add("per_page", strconv.Itoa(request.PerPage))
 	if request.IncludeDeleted {
@@ -0,0 +1,27 @@
+package model
+
+const (
+	// HealthStatusOK indicates a dependency check passed.
+	HealthStatusOK = "ok"
+	// HealthStatusFailed indicates a dependency check failed.
+	HealthStatusFailed = "failed"
+)
+
+// HealthReport describes the outcome of checking the dependencies of the provisioning server.
+type HealthReport struct {
+	Status string
+	Checks []*HealthCheckResult
+}
+
+// HealthCheckResult describes the outcome of checking a single dependency.
+type HealthCheckResult struct {
+	Name    string
+	Status  string
+	Version string `json:",omitempty"`
+	Error   string `json:",omitempty"`
+}
+
+// IsHealthy returns whether every dependency check passed.
+func (r *HealthReport) IsHealthy() bool {
+	return r.Status == HealthStatusOK
+}
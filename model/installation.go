@@ -4,8 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
+
+	"github.com/pkg/errors"
 )
 
+// ErrInstallationDNSInUse is returned when the DNS name, or one of the DNS
+// aliases, of an installation is already used by another installation.
+var ErrInstallationDNSInUse = errors.New("DNS name is already used by another installation")
+
 // Installation represents a Mattermost installation.
 type Installation struct {
 	ID             string
@@ -15,6 +22,7 @@ type Installation struct {
 	Version        string
 	Image          string
 	DNS            string
+	DNSAliases     []string `json:"DNSAliases,omitempty"`
 	Database       string
 	Filestore      string
 	License        string
@@ -28,6 +36,47 @@ type Installation struct {
 	LockAcquiredAt int64
 	GroupOverrides map[string]string `json:"GroupOverrides,omitempty"`
 
+	// RestoredFromBackupID is set when the installation was created from an
+	// installation backup.
+	RestoredFromBackupID *string `json:"RestoredFromBackupID,omitempty"`
+
+	// RestoreFilestoreCopyMarker is the last backup filestore object key
+	// copied into the filestore of the installation, from which the restore
+	// resumes on the next attempt.
+	RestoreFilestoreCopyMarker string `json:",omitempty"`
+	RestoreFilestoreCopied     bool   `json:",omitempty"`
+
+	// DNSRecords are the public DNS names that were last configured to point
+	// at the installation. They are compared against the current DNS names to
+	// find records that are stale and need to be removed.
+	DNSRecords []string `json:"DNSRecords,omitempty"`
+
+	// MigrationTargetClusterID is the cluster the installation is being, or
+	// was last, migrated to.
+	MigrationTargetClusterID *string `json:"MigrationTargetClusterID,omitempty"`
+
+	// NodePool is the additional instance group of the cluster that the
+	// installation runs on. The installation can run on the default nodes of
+	// any cluster if left empty.
+	NodePool string `json:",omitempty"`
+
+	// Zone is the availability zone the installation runs in. The
+	// installation can run on clusters in any zone if left empty.
+	Zone string `json:",omitempty"`
+
+	// SchedulingStrategy chooses among the clusters the installation fits on
+	// when it is created, overriding the default strategy of the provisioning
+	// server if set.
+	SchedulingStrategy string `json:",omitempty"`
+
+	// Attempts is the number of consecutive failed attempts to transition the
+	// installation out of its current state, with LastError the error from the
+	// last attempt. The installation is not supervised again before
+	// NextAttemptAt.
+	Attempts      int
+	LastError     string `json:",omitempty"`
+	NextAttemptAt int64
+
 	// configconfigMergedWithGroup is set when the installation configuration
 	// has been overridden with group configuration. This value can then be
 	// checked later to determine whether the installation is safe to save or
@@ -43,6 +92,13 @@ type Installation struct {
 type InstallationFilter struct {
 	OwnerID        string
 	GroupID        string
+	States         []string
+	DNS            string
+	Version        string
+	Database       string
+	Filestore      string
+	Affinity       string
+	Size           string
 	Page           int
 	PerPage        int
 	IncludeDeleted bool
@@ -57,11 +113,50 @@ func (i *Installation) Clone() *Installation {
 	return &clone
 }
 
+// ResetAttempts clears the record of failed attempts to transition the
+// installation out of its current state, as is done whenever its state
+// changes.
+func (i *Installation) ResetAttempts() {
+	i.Attempts = 0
+	i.LastError = ""
+	i.NextAttemptAt = 0
+}
+
 // IsInGroup returns if the installation is in a group or not.
 func (i *Installation) IsInGroup() bool {
 	return i.GroupID != nil
 }
 
+// DNSNames returns the primary DNS name of the installation followed by any
+// aliases.
+func (i *Installation) DNSNames() []string {
+	return append([]string{i.DNS}, i.DNSAliases...)
+}
+
+// StaleDNSRecords returns the configured DNS records that no longer match the
+// DNS names of the installation. DNS names are compared case-insensitively.
+func (i *Installation) StaleDNSRecords() []string {
+	current := make(map[string]bool)
+	for _, name := range i.DNSNames() {
+		current[strings.ToLower(name)] = true
+	}
+
+	var stale []string
+	for _, record := range i.DNSRecords {
+		if !current[strings.ToLower(record)] {
+			stale = append(stale, record)
+		}
+	}
+
+	return stale
+}
+
+// IsMigrationSupported returns true if the installation data lives outside of
+// the kubernetes cluster and can therefore be moved to another cluster.
+func (i *Installation) IsMigrationSupported() bool {
+	return !i.InternalDatabase() && !i.InternalFilestore()
+}
+
 // ConfigMergedWithGroup returns if the installation currently has inherited
 // group configuration values.
 func (i *Installation) ConfigMergedWithGroup() bool {
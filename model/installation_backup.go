@@ -0,0 +1,84 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// InstallationBackup represents a point-in-time backup of the database and
+// filestore of an installation.
+type InstallationBackup struct {
+	ID                 string
+	InstallationID     string
+	State              string
+	DatabaseSnapshotID string
+	FilestoreBucket    string
+	FilestorePrefix    string
+	// FilestoreCopyMarker is the last filestore object key copied into the
+	// backup bucket, from which the copy resumes on the next poll.
+	FilestoreCopyMarker string
+	FilestoreCopied     bool
+	CreateAt            int64
+	DeleteAt            int64
+	LockAcquiredBy      *string
+	LockAcquiredAt      int64
+}
+
+// InstallationBackupFilter describes the parameters used to constrain a set of
+// installation backups.
+type InstallationBackupFilter struct {
+	InstallationID string
+	Page           int
+	PerPage        int
+	IncludeDeleted bool
+}
+
+// IsDeleted returns whether the installation backup was marked as deleted or not.
+func (b *InstallationBackup) IsDeleted() bool {
+	return b.DeleteAt != 0
+}
+
+// IsRestorable returns whether the installation backup can be used to restore
+// a new installation or not.
+func (b *InstallationBackup) IsRestorable() bool {
+	return b.State == InstallationBackupStateBackupSucceeded && !b.IsDeleted()
+}
+
+// IsBackupSupported returns whether the installation is backed by managed
+// services that support backups or not.
+func (i *Installation) IsBackupSupported() bool {
+	return i.Database == InstallationDatabaseAwsRDS && i.Filestore == InstallationFilestoreAwsS3
+}
+
+// IsBackupAllowed returns whether a backup may be requested for the
+// installation in its current state.
+func (i *Installation) IsBackupAllowed() bool {
+	return i.State == InstallationStateStable || i.State == InstallationStateHibernating
+}
+
+// InstallationBackupFromReader decodes a json-encoded installation backup from
+// the given io.Reader.
+func InstallationBackupFromReader(reader io.Reader) (*InstallationBackup, error) {
+	backup := InstallationBackup{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&backup)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &backup, nil
+}
+
+// InstallationBackupsFromReader decodes a json-encoded list of installation
+// backups from the given io.Reader.
+func InstallationBackupsFromReader(reader io.Reader) ([]*InstallationBackup, error) {
+	backups := []*InstallationBackup{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&backups)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return backups, nil
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// GetInstallationBackupsRequest describes the parameters to request a list of
+// installation backups.
+type GetInstallationBackupsRequest struct {
+	Page           int
+	PerPage        int
+	IncludeDeleted bool
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetInstallationBackupsRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	if request.IncludeDeleted {
+		q.Add("include_deleted", "true")
+	}
+	u.RawQuery = q.Encode()
+}
+
+// RestoreInstallationBackupRequest specifies the parameters for a new
+// installation restored from an installation backup.
+type RestoreInstallationBackupRequest struct {
+	OwnerID string
+	DNS     string
+}
+
+// Validate validates the values of an installation backup restore request.
+func (request *RestoreInstallationBackupRequest) Validate() error {
+	if request.OwnerID == "" {
+		return errors.New("must specify owner")
+	}
+	if request.DNS == "" {
+		return errors.New("must specify DNS")
+	}
+	if len(request.DNS) >= 64 {
+		return errors.Errorf("DNS names must be less than 64 characters, but name was %d long. DNS=%s", len(request.DNS), request.DNS)
+	}
+	_, err := url.Parse(request.DNS)
+	if err != nil {
+		return errors.Wrapf(err, "invalid DNS %s", request.DNS)
+	}
+
+	return nil
+}
+
+// NewRestoreInstallationBackupRequestFromReader will create a
+// RestoreInstallationBackupRequest from an io.Reader with JSON data.
+func NewRestoreInstallationBackupRequestFromReader(reader io.Reader) (*RestoreInstallationBackupRequest, error) {
+	var restoreRequest RestoreInstallationBackupRequest
+	err := json.NewDecoder(reader).Decode(&restoreRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode restore installation backup request")
+	}
+
+	err = restoreRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "restore installation backup request failed validation")
+	}
+
+	return &restoreRequest, nil
+}
@@ -0,0 +1,38 @@
+package model
+
+const (
+	// InstallationBackupStateBackupRequested is an installation backup that
+	// is waiting to be started.
+	InstallationBackupStateBackupRequested = "backup-requested"
+	// InstallationBackupStateBackupInProgress is an installation backup that
+	// is waiting for the database snapshot to complete.
+	InstallationBackupStateBackupInProgress = "backup-in-progress"
+	// InstallationBackupStateBackupSucceeded is an installation backup that
+	// completed and can be restored.
+	InstallationBackupStateBackupSucceeded = "backup-succeeded"
+	// InstallationBackupStateBackupFailed is an installation backup that failed.
+	InstallationBackupStateBackupFailed = "backup-failed"
+)
+
+// AllInstallationBackupStates is a list of all states an installation backup
+// can be in.
+// Warning:
+// When creating a new installation backup state, it must be added to this list.
+var AllInstallationBackupStates = []string{
+	InstallationBackupStateBackupRequested,
+	InstallationBackupStateBackupInProgress,
+	InstallationBackupStateBackupSucceeded,
+	InstallationBackupStateBackupFailed,
+}
+
+// AllInstallationBackupStatesPendingWork is a list of all installation backup
+// states that the supervisor will attempt to transition towards succeeded on
+// the next "tick".
+// Warning:
+// When creating a new installation backup state, it must be added to this list
+// if the cloud installation backup supervisor should perform some action on
+// its next work cycle.
+var AllInstallationBackupStatesPendingWork = []string{
+	InstallationBackupStateBackupRequested,
+	InstallationBackupStateBackupInProgress,
+}
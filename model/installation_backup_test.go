@@ -0,0 +1,55 @@
+package model_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationBackupIsRestorable(t *testing.T) {
+	backup := &model.InstallationBackup{State: model.InstallationBackupStateBackupInProgress}
+	assert.False(t, backup.IsRestorable())
+
+	backup.State = model.InstallationBackupStateBackupSucceeded
+	assert.True(t, backup.IsRestorable())
+
+	backup.DeleteAt = 10
+	assert.False(t, backup.IsRestorable())
+}
+
+func TestInstallationIsBackupSupported(t *testing.T) {
+	installation := &model.Installation{
+		Database:  model.InstallationDatabaseMysqlOperator,
+		Filestore: model.InstallationFilestoreAwsS3,
+	}
+	assert.False(t, installation.IsBackupSupported())
+
+	installation.Database = model.InstallationDatabaseAwsRDS
+	assert.True(t, installation.IsBackupSupported())
+
+	installation.Filestore = model.InstallationFilestoreMinioOperator
+	assert.False(t, installation.IsBackupSupported())
+}
+
+func TestNewRestoreInstallationBackupRequestFromReader(t *testing.T) {
+	t.Run("empty request", func(t *testing.T) {
+		request, err := model.NewRestoreInstallationBackupRequestFromReader(bytes.NewReader([]byte("")))
+		require.EqualError(t, err, "restore installation backup request failed validation: must specify owner")
+		require.Nil(t, request)
+	})
+
+	t.Run("missing DNS", func(t *testing.T) {
+		request, err := model.NewRestoreInstallationBackupRequestFromReader(bytes.NewReader([]byte(`{"OwnerID":"owner"}`)))
+		require.EqualError(t, err, "restore installation backup request failed validation: must specify DNS")
+		require.Nil(t, request)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		request, err := model.NewRestoreInstallationBackupRequestFromReader(bytes.NewReader([]byte(`{"OwnerID":"owner","DNS":"restored.example.com"}`)))
+		require.NoError(t, err)
+		require.Equal(t, &model.RestoreInstallationBackupRequest{OwnerID: "owner", DNS: "restored.example.com"}, request)
+	})
+}
@@ -16,7 +16,7 @@ const (
 
 // Database is the interface for managing Mattermost databases.
 type Database interface {
-	Provision(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
+	Provision(store InstallationDatabaseStoreInterface, logger log.FieldLogger) (bool, error)
 	Teardown(keepData bool, logger log.FieldLogger) error
 	Snapshot(logger log.FieldLogger) error
 	GenerateDatabaseSpecAndSecret(logger log.FieldLogger) (*mmv1alpha1.Database, *corev1.Secret, error)
@@ -37,10 +37,10 @@ func NewMysqlOperatorDatabase() *MysqlOperatorDatabase {
 }
 
 // Provision completes all the steps necessary to provision a MySQL operator database.
-func (d *MysqlOperatorDatabase) Provision(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+func (d *MysqlOperatorDatabase) Provision(store InstallationDatabaseStoreInterface, logger log.FieldLogger) (bool, error) {
 	logger.Info("MySQL operator database requires no pre-provisioning; skipping...")
 
-	return nil
+	return true, nil
 }
 
 // Snapshot is not supported by the operator and it should return an error.
@@ -16,7 +16,7 @@ const (
 
 // Filestore is the interface for managing Mattermost filestores.
 type Filestore interface {
-	Provision(logger log.FieldLogger) error
+	Provision(logger log.FieldLogger) (bool, error)
 	Teardown(keepData bool, logger log.FieldLogger) error
 	GenerateFilestoreSpecAndSecret(logger log.FieldLogger) (*mmv1alpha1.Minio, *corev1.Secret, error)
 }
@@ -30,10 +30,10 @@ func NewMinioOperatorFilestore() *MinioOperatorFilestore {
 }
 
 // Provision completes all the steps necessary to provision a MinIO operator filestore.
-func (f *MinioOperatorFilestore) Provision(logger log.FieldLogger) error {
+func (f *MinioOperatorFilestore) Provision(logger log.FieldLogger) (bool, error) {
 	logger.Info("MinIO operator filestore requires no pre-provisioning; skipping...")
 
-	return nil
+	return true, nil
 }
 
 // Teardown removes all MinIO operator resources related to a given installation.
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -13,17 +14,21 @@ import (
 
 // CreateInstallationRequest specifies the parameters for a new installation.
 type CreateInstallationRequest struct {
-	OwnerID       string
-	GroupID       string
-	Version       string
-	Image         string
-	DNS           string
-	License       string
-	Size          string
-	Affinity      string
-	Database      string
-	Filestore     string
-	MattermostEnv EnvVarMap
+	OwnerID            string
+	GroupID            string
+	Version            string
+	Image              string
+	DNS                string
+	DNSAliases         []string
+	License            string
+	Size               string
+	Affinity           string
+	Database           string
+	Filestore          string
+	NodePool           string
+	Zone               string
+	SchedulingStrategy string
+	MattermostEnv      EnvVarMap
 }
 
 // SetDefaults sets the default values for an installation create request.
@@ -56,16 +61,13 @@ func (request *CreateInstallationRequest) Validate() error {
 	if request.DNS == "" {
 		return errors.New("must specify DNS")
 	}
-	if len(request.DNS) >= 64 {
-		return errors.Errorf("DNS names must be less than 64 characters, but name was %d long. DNS=%s", len(request.DNS), request.DNS)
-	}
-	_, err := mmv1alpha1.GetClusterSize(request.Size)
+	err := validateDNSNames(append([]string{request.DNS}, request.DNSAliases...))
 	if err != nil {
-		return errors.Wrap(err, "invalid size")
+		return err
 	}
-	_, err = url.Parse(request.DNS)
+	_, err = mmv1alpha1.GetClusterSize(request.Size)
 	if err != nil {
-		return errors.Wrapf(err, "invalid DNS %s", request.DNS)
+		return errors.Wrap(err, "invalid size")
 	}
 	if !IsSupportedAffinity(request.Affinity) {
 		return errors.Errorf("unsupported affinity %s", request.Affinity)
@@ -76,6 +78,15 @@ func (request *CreateInstallationRequest) Validate() error {
 	if !IsSupportedFilestore(request.Filestore) {
 		return errors.Errorf("unsupported filestore %s", request.Filestore)
 	}
+	if request.NodePool != "" && !ValidInstanceGroupName(request.NodePool) {
+		return errors.Errorf("invalid node pool %s", request.NodePool)
+	}
+	if request.Zone != "" && !ValidZone(request.Zone) {
+		return errors.Errorf("invalid zone %s", request.Zone)
+	}
+	if request.SchedulingStrategy != "" && !IsSupportedSchedulingStrategy(request.SchedulingStrategy) {
+		return errors.Errorf("unsupported scheduling strategy %s", request.SchedulingStrategy)
+	}
 	err = request.MattermostEnv.Validate()
 	if err != nil {
 		return errors.Wrap(err, "invalid env var settings")
@@ -84,6 +95,30 @@ func (request *CreateInstallationRequest) Validate() error {
 	return nil
 }
 
+// validateDNSNames validates a list of DNS names that will all point at the
+// same installation.
+func validateDNSNames(names []string) error {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if name == "" {
+			return errors.New("DNS names must not be blank")
+		}
+		if len(name) >= 64 {
+			return errors.Errorf("DNS names must be less than 64 characters, but name was %d long. DNS=%s", len(name), name)
+		}
+		_, err := url.Parse(name)
+		if err != nil {
+			return errors.Wrapf(err, "invalid DNS %s", name)
+		}
+		if seen[strings.ToLower(name)] {
+			return errors.Errorf("DNS name %s was provided more than once", name)
+		}
+		seen[strings.ToLower(name)] = true
+	}
+
+	return nil
+}
+
 // NewCreateInstallationRequestFromReader will create a CreateInstallationRequest from an io.Reader with JSON data.
 func NewCreateInstallationRequestFromReader(reader io.Reader) (*CreateInstallationRequest, error) {
 	var createInstallationRequest CreateInstallationRequest
@@ -126,6 +161,13 @@ func (request *GetInstallationRequest) ApplyToURL(u *url.URL) {
 type GetInstallationsRequest struct {
 	OwnerID                     string
 	GroupID                     string
+	States                      []string
+	DNS                         string
+	Version                     string
+	Database                    string
+	Filestore                   string
+	Affinity                    string
+	Size                        string
 	IncludeGroupConfig          bool
 	IncludeGroupConfigOverrides bool
 	Page                        int
@@ -138,6 +180,15 @@ func (request *GetInstallationsRequest) ApplyToURL(u *url.URL) {
 	q := u.Query()
 	q.Add("owner", request.OwnerID)
 	q.Add("group", request.GroupID)
+	for _, state := range request.States {
+		q.Add("state", state)
+	}
+	addNonEmptyQueryParam(q, "dns", request.DNS)
+	addNonEmptyQueryParam(q, "version", request.Version)
+	addNonEmptyQueryParam(q, "database", request.Database)
+	addNonEmptyQueryParam(q, "filestore", request.Filestore)
+	addNonEmptyQueryParam(q, "affinity", request.Affinity)
+	addNonEmptyQueryParam(q, "size", request.Size)
 	if !request.IncludeGroupConfig {
 		q.Add("include_group_config", "false")
 	}
@@ -157,7 +208,13 @@ type PatchInstallationRequest struct {
 	Version       *string
 	Image         *string
 	License       *string
+	Size          *string
+	DNS           *string
 	MattermostEnv EnvVarMap
+
+	// DNSAliases replaces the DNS aliases of the installation when set. An
+	// empty, non-nil list removes all aliases.
+	DNSAliases []string
 }
 
 // Validate validates the values of a installation patch request.
@@ -168,7 +225,24 @@ func (p *PatchInstallationRequest) Validate() error {
 	if p.Image != nil && len(*p.Image) == 0 {
 		return errors.New("provided image update value was blank")
 	}
-	err := p.MattermostEnv.Validate()
+	if p.Size != nil {
+		_, err := mmv1alpha1.GetClusterSize(*p.Size)
+		if err != nil {
+			return errors.Wrap(err, "invalid size")
+		}
+	}
+	if p.DNS != nil && len(*p.DNS) == 0 {
+		return errors.New("provided DNS update value was blank")
+	}
+	var dnsNames []string
+	if p.DNS != nil {
+		dnsNames = append(dnsNames, *p.DNS)
+	}
+	err := validateDNSNames(append(dnsNames, p.DNSAliases...))
+	if err != nil {
+		return errors.Wrap(err, "invalid DNS settings")
+	}
+	err = p.MattermostEnv.Validate()
 	if err != nil {
 		return errors.Wrap(err, "invalid env var settings")
 	}
@@ -192,6 +266,18 @@ func (p *PatchInstallationRequest) Apply(installation *Installation) bool {
 		applied = true
 		installation.License = *p.License
 	}
+	if p.Size != nil && *p.Size != installation.Size {
+		applied = true
+		installation.Size = *p.Size
+	}
+	if p.DNS != nil && *p.DNS != installation.DNS {
+		applied = true
+		installation.DNS = *p.DNS
+	}
+	if p.DNSAliases != nil && !stringSlicesEqual(p.DNSAliases, installation.DNSAliases) {
+		applied = true
+		installation.DNSAliases = p.DNSAliases
+	}
 	if p.MattermostEnv != nil {
 		applied = true
 		installation.MattermostEnv = p.MattermostEnv
@@ -200,6 +286,19 @@ func (p *PatchInstallationRequest) Apply(installation *Installation) bool {
 	return applied
 }
 
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
 // NewPatchInstallationRequestFromReader will create a PatchInstallationRequest from an io.Reader with JSON data.
 func NewPatchInstallationRequestFromReader(reader io.Reader) (*PatchInstallationRequest, error) {
 	var patchInstallationRequest PatchInstallationRequest
@@ -215,3 +314,34 @@ func NewPatchInstallationRequestFromReader(reader io.Reader) (*PatchInstallation
 
 	return &patchInstallationRequest, nil
 }
+
+// MigrateInstallationRequest specifies the parameters for moving an
+// installation to another cluster.
+type MigrateInstallationRequest struct {
+	ClusterID string
+}
+
+// Validate validates the values of an installation migrate request.
+func (request *MigrateInstallationRequest) Validate() error {
+	if request.ClusterID == "" {
+		return errors.New("must specify target cluster")
+	}
+
+	return nil
+}
+
+// NewMigrateInstallationRequestFromReader will create a MigrateInstallationRequest from an io.Reader with JSON data.
+func NewMigrateInstallationRequestFromReader(reader io.Reader) (*MigrateInstallationRequest, error) {
+	var migrateInstallationRequest MigrateInstallationRequest
+	err := json.NewDecoder(reader).Decode(&migrateInstallationRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode migrate installation request")
+	}
+
+	err = migrateInstallationRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "migrate installation request failed validation")
+	}
+
+	return &migrateInstallationRequest, nil
+}
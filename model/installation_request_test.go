@@ -7,6 +7,8 @@ import (
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
 )
 
 func TestCreateInstallationRequestValid(t *testing.T) {
@@ -81,6 +83,33 @@ func TestCreateInstallationRequestValid(t *testing.T) {
 				Filestore: "none",
 			},
 		},
+		{
+			"dns aliases",
+			false,
+			&model.CreateInstallationRequest{
+				OwnerID:    "owner1",
+				DNS:        "domain.com",
+				DNSAliases: []string{"alias1.com", "alias2.com"},
+			},
+		},
+		{
+			"duplicate dns alias",
+			true,
+			&model.CreateInstallationRequest{
+				OwnerID:    "owner1",
+				DNS:        "domain.com",
+				DNSAliases: []string{"domain.com"},
+			},
+		},
+		{
+			"blank dns alias",
+			true,
+			&model.CreateInstallationRequest{
+				OwnerID:    "owner1",
+				DNS:        "domain.com",
+				DNSAliases: []string{""},
+			},
+		},
 		{
 			"invalid mattermost env",
 			true,
@@ -92,6 +121,52 @@ func TestCreateInstallationRequestValid(t *testing.T) {
 				},
 			},
 		},
+		{
+			"node pool",
+			false,
+			&model.CreateInstallationRequest{
+				OwnerID:  "owner1",
+				DNS:      "domain.com",
+				NodePool: "memory",
+			},
+		},
+		{
+			"invalid node pool",
+			true,
+			&model.CreateInstallationRequest{
+				OwnerID:  "owner1",
+				DNS:      "domain.com",
+				NodePool: "nodes",
+			},
+		},
+		{
+			"zone and scheduling strategy",
+			false,
+			&model.CreateInstallationRequest{
+				OwnerID:            "owner1",
+				DNS:                "domain.com",
+				Zone:               "us-east-1a",
+				SchedulingStrategy: model.SchedulingStrategySpread,
+			},
+		},
+		{
+			"invalid zone",
+			true,
+			&model.CreateInstallationRequest{
+				OwnerID: "owner1",
+				DNS:     "domain.com",
+				Zone:    "east",
+			},
+		},
+		{
+			"invalid scheduling strategy",
+			true,
+			&model.CreateInstallationRequest{
+				OwnerID:            "owner1",
+				DNS:                "domain.com",
+				SchedulingStrategy: "random",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -188,6 +263,63 @@ func TestPatchInstallationRequestValid(t *testing.T) {
 				Image: sToP(""),
 			},
 		},
+		{
+			"size only",
+			false,
+			&model.PatchInstallationRequest{
+				Size: sToP(mmv1alpha1.Size1000String),
+			},
+		},
+		{
+			"invalid size only",
+			true,
+			&model.PatchInstallationRequest{
+				Size: sToP("1user"),
+			},
+		},
+		{
+			"dns only",
+			false,
+			&model.PatchInstallationRequest{
+				DNS: sToP("domain.com"),
+			},
+		},
+		{
+			"invalid dns only",
+			true,
+			&model.PatchInstallationRequest{
+				DNS: sToP(""),
+			},
+		},
+		{
+			"dns aliases only",
+			false,
+			&model.PatchInstallationRequest{
+				DNSAliases: []string{"alias1.com", "alias2.com"},
+			},
+		},
+		{
+			"remove dns aliases",
+			false,
+			&model.PatchInstallationRequest{
+				DNSAliases: []string{},
+			},
+		},
+		{
+			"duplicate dns aliases",
+			true,
+			&model.PatchInstallationRequest{
+				DNS:        sToP("domain.com"),
+				DNSAliases: []string{"alias1.com", "domain.com"},
+			},
+		},
+		{
+			"too long dns alias",
+			true,
+			&model.PatchInstallationRequest{
+				DNSAliases: []string{"alias-that-is-far-too-long-to-be-used-as-a-domain-name.example.com"},
+			},
+		},
 		{
 			"invalid mattermost env",
 			true,
@@ -259,6 +391,65 @@ func TestPatchInstallationRequestApply(t *testing.T) {
 				License: "license1",
 			},
 		},
+		{
+			"size only",
+			true,
+			&model.PatchInstallationRequest{
+				Size: sToP(mmv1alpha1.Size1000String),
+			},
+			&model.Installation{},
+			&model.Installation{
+				Size: mmv1alpha1.Size1000String,
+			},
+		},
+		{
+			"dns only",
+			true,
+			&model.PatchInstallationRequest{
+				DNS: sToP("domain.com"),
+			},
+			&model.Installation{},
+			&model.Installation{
+				DNS: "domain.com",
+			},
+		},
+		{
+			"dns aliases only",
+			true,
+			&model.PatchInstallationRequest{
+				DNSAliases: []string{"alias1.com"},
+			},
+			&model.Installation{},
+			&model.Installation{
+				DNSAliases: []string{"alias1.com"},
+			},
+		},
+		{
+			"same dns aliases",
+			false,
+			&model.PatchInstallationRequest{
+				DNSAliases: []string{"alias1.com"},
+			},
+			&model.Installation{
+				DNSAliases: []string{"alias1.com"},
+			},
+			&model.Installation{
+				DNSAliases: []string{"alias1.com"},
+			},
+		},
+		{
+			"remove dns aliases",
+			true,
+			&model.PatchInstallationRequest{
+				DNSAliases: []string{},
+			},
+			&model.Installation{
+				DNSAliases: []string{"alias1.com"},
+			},
+			&model.Installation{
+				DNSAliases: []string{},
+			},
+		},
 		{
 			"mattermost env only",
 			true,
@@ -346,6 +537,32 @@ func TestNewPatchInstallationRequestFromReader(t *testing.T) {
 	})
 }
 
+func TestNewMigrateInstallationRequestFromReader(t *testing.T) {
+	t.Run("empty request", func(t *testing.T) {
+		request, err := model.NewMigrateInstallationRequestFromReader(bytes.NewReader([]byte(
+			``,
+		)))
+		require.EqualError(t, err, "migrate installation request failed validation: must specify target cluster")
+		require.Nil(t, request)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		request, err := model.NewMigrateInstallationRequestFromReader(bytes.NewReader([]byte(
+			`{test`,
+		)))
+		require.Error(t, err)
+		require.Nil(t, request)
+	})
+
+	t.Run("request", func(t *testing.T) {
+		request, err := model.NewMigrateInstallationRequestFromReader(bytes.NewReader([]byte(
+			`{"ClusterID":"cluster1"}`,
+		)))
+		require.NoError(t, err)
+		require.Equal(t, &model.MigrateInstallationRequest{ClusterID: "cluster1"}, request)
+	})
+}
+
 func sToP(s string) *string {
 	return &s
 }
@@ -26,6 +26,31 @@ const (
 	InstallationStateUpdateInProgress = "update-in-progress"
 	// InstallationStateUpdateFailed is an installation that failed to update.
 	InstallationStateUpdateFailed = "update-failed"
+	// InstallationStateHibernationRequested is an installation that is about
+	// to be put into hibernation.
+	InstallationStateHibernationRequested = "hibernation-requested"
+	// InstallationStateHibernationInProgress is an installation waiting for
+	// its Mattermost pods to terminate.
+	InstallationStateHibernationInProgress = "hibernation-in-progress"
+	// InstallationStateHibernating is an installation that is hibernating.
+	InstallationStateHibernating = "hibernating"
+	// InstallationStateWakeUpRequested is an installation that is about to be
+	// woken up from hibernation.
+	InstallationStateWakeUpRequested = "wake-up-requested"
+	// InstallationStateMigrationRequested is an installation that is about to
+	// be moved to another cluster.
+	InstallationStateMigrationRequested = "migration-requested"
+	// InstallationStateMigrationInProgress is an installation waiting for its
+	// cluster installation on the target cluster to become stable.
+	InstallationStateMigrationInProgress = "migration-in-progress"
+	// InstallationStateMigrationDNS is an installation in the process of
+	// switching DNS to the target cluster.
+	InstallationStateMigrationDNS = "migration-switching-dns"
+	// InstallationStateMigrationCleanup is an installation in the process of
+	// removing its cluster installation from the source cluster.
+	InstallationStateMigrationCleanup = "migration-cleanup"
+	// InstallationStateMigrationFailed is an installation that failed to migrate.
+	InstallationStateMigrationFailed = "migration-failed"
 	// InstallationStateDeletionRequested is an installation to be deleted.
 	InstallationStateDeletionRequested = "deletion-requested"
 	// InstallationStateDeletionInProgress is an installation being deleted.
@@ -57,6 +82,15 @@ var AllInstallationStates = []string{
 	InstallationStateUpdateRequested,
 	InstallationStateUpdateInProgress,
 	InstallationStateUpdateFailed,
+	InstallationStateHibernationRequested,
+	InstallationStateHibernationInProgress,
+	InstallationStateHibernating,
+	InstallationStateWakeUpRequested,
+	InstallationStateMigrationRequested,
+	InstallationStateMigrationInProgress,
+	InstallationStateMigrationDNS,
+	InstallationStateMigrationCleanup,
+	InstallationStateMigrationFailed,
 	InstallationStateDeletionRequested,
 	InstallationStateDeletionInProgress,
 	InstallationStateDeletionFinalCleanup,
@@ -77,6 +111,13 @@ var AllInstallationStatesPendingWork = []string{
 	InstallationStateCreationDNS,
 	InstallationStateUpdateRequested,
 	InstallationStateUpdateInProgress,
+	InstallationStateHibernationRequested,
+	InstallationStateHibernationInProgress,
+	InstallationStateWakeUpRequested,
+	InstallationStateMigrationRequested,
+	InstallationStateMigrationInProgress,
+	InstallationStateMigrationDNS,
+	InstallationStateMigrationCleanup,
 	InstallationStateDeletionRequested,
 	InstallationStateDeletionInProgress,
 	InstallationStateDeletionFinalCleanup,
@@ -90,6 +131,9 @@ var AllInstallationStatesPendingWork = []string{
 var AllInstallationRequestStates = []string{
 	InstallationStateCreationRequested,
 	InstallationStateUpdateRequested,
+	InstallationStateHibernationRequested,
+	InstallationStateWakeUpRequested,
+	InstallationStateMigrationRequested,
 	InstallationStateDeletionRequested,
 }
 
@@ -101,6 +145,12 @@ func (i *Installation) ValidTransitionState(newState string) bool {
 		return validTransitionToInstallationStateCreationRequested(i.State)
 	case InstallationStateUpdateRequested:
 		return validTransitionToInstallationStateUpgradeRequested(i.State)
+	case InstallationStateHibernationRequested:
+		return validTransitionToInstallationStateHibernationRequested(i.State)
+	case InstallationStateWakeUpRequested:
+		return validTransitionToInstallationStateWakeUpRequested(i.State)
+	case InstallationStateMigrationRequested:
+		return validTransitionToInstallationStateMigrationRequested(i.State)
 	case InstallationStateDeletionRequested:
 		return validTransitionToInstallationStateDeletionRequested(i.State)
 	}
@@ -129,6 +179,37 @@ func validTransitionToInstallationStateUpgradeRequested(currentState string) boo
 	return false
 }
 
+func validTransitionToInstallationStateHibernationRequested(currentState string) bool {
+	switch currentState {
+	case InstallationStateStable,
+		InstallationStateHibernationRequested:
+		return true
+	}
+
+	return false
+}
+
+func validTransitionToInstallationStateWakeUpRequested(currentState string) bool {
+	switch currentState {
+	case InstallationStateHibernating,
+		InstallationStateWakeUpRequested:
+		return true
+	}
+
+	return false
+}
+
+func validTransitionToInstallationStateMigrationRequested(currentState string) bool {
+	switch currentState {
+	case InstallationStateStable,
+		InstallationStateMigrationRequested,
+		InstallationStateMigrationFailed:
+		return true
+	}
+
+	return false
+}
+
 func validTransitionToInstallationStateDeletionRequested(currentState string) bool {
 	switch currentState {
 	case InstallationStateStable,
@@ -141,6 +222,11 @@ func validTransitionToInstallationStateDeletionRequested(currentState string) bo
 		InstallationStateUpdateRequested,
 		InstallationStateUpdateInProgress,
 		InstallationStateUpdateFailed,
+		InstallationStateHibernationRequested,
+		InstallationStateHibernationInProgress,
+		InstallationStateHibernating,
+		InstallationStateWakeUpRequested,
+		InstallationStateMigrationFailed,
 		InstallationStateDeletionRequested,
 		InstallationStateDeletionInProgress,
 		InstallationStateDeletionFinalCleanup,
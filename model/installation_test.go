@@ -269,3 +269,81 @@ func TestMergeWithGroup(t *testing.T) {
 		assert.NotEmpty(t, installation.GroupOverrides)
 	})
 }
+
+func TestInstallationHibernationTransitions(t *testing.T) {
+	testCases := []struct {
+		currentState string
+		newState     string
+		expected     bool
+	}{
+		{InstallationStateStable, InstallationStateHibernationRequested, true},
+		{InstallationStateHibernationRequested, InstallationStateHibernationRequested, true},
+		{InstallationStateUpdateRequested, InstallationStateHibernationRequested, false},
+		{InstallationStateHibernating, InstallationStateHibernationRequested, false},
+		{InstallationStateHibernating, InstallationStateWakeUpRequested, true},
+		{InstallationStateWakeUpRequested, InstallationStateWakeUpRequested, true},
+		{InstallationStateStable, InstallationStateWakeUpRequested, false},
+		{InstallationStateHibernating, InstallationStateUpdateRequested, false},
+		{InstallationStateHibernating, InstallationStateDeletionRequested, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.currentState+" to "+tc.newState, func(t *testing.T) {
+			installation := &Installation{State: tc.currentState}
+			assert.Equal(t, tc.expected, installation.ValidTransitionState(tc.newState))
+		})
+	}
+}
+
+func TestInstallationMigrationTransitions(t *testing.T) {
+	testCases := []struct {
+		currentState string
+		newState     string
+		expected     bool
+	}{
+		{InstallationStateStable, InstallationStateMigrationRequested, true},
+		{InstallationStateMigrationRequested, InstallationStateMigrationRequested, true},
+		{InstallationStateMigrationFailed, InstallationStateMigrationRequested, true},
+		{InstallationStateMigrationInProgress, InstallationStateMigrationRequested, false},
+		{InstallationStateHibernating, InstallationStateMigrationRequested, false},
+		{InstallationStateMigrationInProgress, InstallationStateDeletionRequested, false},
+		{InstallationStateMigrationFailed, InstallationStateDeletionRequested, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.currentState+" to "+tc.newState, func(t *testing.T) {
+			installation := &Installation{State: tc.currentState}
+			assert.Equal(t, tc.expected, installation.ValidTransitionState(tc.newState))
+		})
+	}
+}
+
+func TestInstallationIsMigrationSupported(t *testing.T) {
+	installation := &Installation{
+		Database:  InstallationDatabaseAwsRDS,
+		Filestore: InstallationFilestoreAwsS3,
+	}
+	assert.True(t, installation.IsMigrationSupported())
+
+	installation.Database = InstallationDatabaseMysqlOperator
+	assert.False(t, installation.IsMigrationSupported())
+
+	installation.Database = InstallationDatabaseAwsRDS
+	installation.Filestore = InstallationFilestoreMinioOperator
+	assert.False(t, installation.IsMigrationSupported())
+}
+
+func TestInstallationDNSNames(t *testing.T) {
+	installation := &Installation{DNS: "domain.com"}
+	assert.Equal(t, []string{"domain.com"}, installation.DNSNames())
+	assert.Empty(t, installation.StaleDNSRecords())
+
+	installation.DNSAliases = []string{"alias1.com", "alias2.com"}
+	assert.Equal(t, []string{"domain.com", "alias1.com", "alias2.com"}, installation.DNSNames())
+
+	installation.DNSRecords = []string{"old.com", "domain.com", "alias1.com", "alias3.com"}
+	assert.Equal(t, []string{"old.com", "alias3.com"}, installation.StaleDNSRecords())
+
+	installation.DNSRecords = []string{"Domain.com", "ALIAS1.com", "alias2.com"}
+	assert.Empty(t, installation.StaleDNSRecords())
+}
@@ -0,0 +1,26 @@
+package model
+
+// Instance is a provisioning server instance, identified by the ID it uses to
+// acquire locks.
+type Instance struct {
+	ID              string
+	CreateAt        int64
+	LastHeartbeatAt int64
+
+	// PartitionedSupervisors are the supervisors whose work the instance
+	// partitions with the other instances running them. It is empty for
+	// instances that don't partition work, such as API only instances.
+	PartitionedSupervisors []string
+}
+
+// PartitionsSupervisor returns whether the instance partitions the work of the
+// given supervisor with the other instances running it.
+func (i *Instance) PartitionsSupervisor(supervisor string) bool {
+	for _, partitionedSupervisor := range i.PartitionedSupervisors {
+		if partitionedSupervisor == supervisor {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,6 +2,11 @@ package model
 
 import (
 	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
 )
 
 // KopsMetadata is the provisioner metadata stored in a model.Cluster.
@@ -9,6 +14,59 @@ type KopsMetadata struct {
 	Name    string
 	Version string
 	AMI     string
+
+	// MasterInstanceType, MasterCount, NodeInstanceType, NodeMinCount and
+	// NodeMaxCount record the kops instance group configuration last applied
+	// to the cluster.
+	MasterInstanceType string `json:",omitempty"`
+	MasterCount        int64  `json:",omitempty"`
+	NodeInstanceType   string `json:",omitempty"`
+	NodeMinCount       int64  `json:",omitempty"`
+	NodeMaxCount       int64  `json:",omitempty"`
+
+	// ResizeSize is the size the cluster is being resized to. The size of the
+	// cluster is only updated to it once the resize has succeeded.
+	ResizeSize string `json:",omitempty"`
+
+	// InstanceGroups are the additional instance groups, or node pools,
+	// declared for the cluster alongside the default node instance group.
+	InstanceGroups []KopsInstanceGroup `json:",omitempty"`
+
+	// CapacityManaged records that the cluster was requested by the capacity
+	// manager, which may delete it again once it is empty.
+	CapacityManaged bool `json:",omitempty"`
+
+	// CapacityReservedFor is the waiting installation the capacity manager
+	// requested the cluster for. The cluster is kept for that installation
+	// until it is scheduled, and CapacityReservationExpired records that it
+	// was still waiting after the cluster became stable.
+	CapacityReservedFor        string `json:",omitempty"`
+	CapacityReservationExpired bool   `json:",omitempty"`
+}
+
+// GetInstanceGroup returns the additional instance group with the given name,
+// or nil if the cluster declares no such instance group.
+func (km *KopsMetadata) GetInstanceGroup(name string) *KopsInstanceGroup {
+	for i := range km.InstanceGroups {
+		if km.InstanceGroups[i].Name == name {
+			return &km.InstanceGroups[i]
+		}
+	}
+
+	return nil
+}
+
+// MergeInstanceGroups replaces the declared instance groups with the given
+// ones of the same name, and declares the rest as new instance groups.
+func (km *KopsMetadata) MergeInstanceGroups(instanceGroups []KopsInstanceGroup) {
+	for _, instanceGroup := range instanceGroups {
+		existing := km.GetInstanceGroup(instanceGroup.Name)
+		if existing != nil {
+			*existing = instanceGroup
+			continue
+		}
+		km.InstanceGroups = append(km.InstanceGroups, instanceGroup)
+	}
 }
 
 // NewKopsMetadata creates an instance of KopsMetadata given the raw provisioner metadata.
@@ -29,3 +87,68 @@ func NewKopsMetadata(provisionerMetadata []byte) (*KopsMetadata, error) {
 
 	return &kopsMetadata, nil
 }
+
+// KopsInstanceGroup is an additional kops instance group, or node pool, of a
+// cluster. Installations are pinned to a node pool by its name.
+type KopsInstanceGroup struct {
+	Name        string
+	MachineType string
+	MinCount    int64
+	MaxCount    int64
+
+	// MaxPrice is the maximum hourly price in USD to pay for spot instances.
+	// The instance group uses on-demand instances if left empty.
+	MaxPrice string `json:",omitempty"`
+}
+
+var instanceGroupNameMatcher = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// ValidInstanceGroupName returns true if the given name can be used for an
+// additional instance group. The names of the instance groups created by kops
+// itself are reserved.
+func ValidInstanceGroupName(name string) bool {
+	if name == "nodes" || strings.HasPrefix(name, "master") {
+		return false
+	}
+
+	return instanceGroupNameMatcher.MatchString(name)
+}
+
+// Validate validates the values of an additional instance group.
+func (ig *KopsInstanceGroup) Validate() error {
+	if !ValidInstanceGroupName(ig.Name) {
+		return errors.Errorf("invalid instance group name %s", ig.Name)
+	}
+	if ig.MachineType == "" {
+		return errors.Errorf("must specify a machine type for instance group %s", ig.Name)
+	}
+	if ig.MinCount < 0 || ig.MaxCount < 1 || ig.MinCount > ig.MaxCount {
+		return errors.Errorf("invalid instance counts %d-%d for instance group %s", ig.MinCount, ig.MaxCount, ig.Name)
+	}
+	if ig.MaxPrice != "" {
+		price, err := strconv.ParseFloat(ig.MaxPrice, 64)
+		if err != nil || price <= 0 {
+			return errors.Errorf("invalid max price %s for instance group %s", ig.MaxPrice, ig.Name)
+		}
+	}
+
+	return nil
+}
+
+// validateInstanceGroups validates a list of additional instance groups,
+// which must have unique names.
+func validateInstanceGroups(instanceGroups []KopsInstanceGroup) error {
+	seen := make(map[string]bool)
+	for _, instanceGroup := range instanceGroups {
+		err := instanceGroup.Validate()
+		if err != nil {
+			return err
+		}
+		if seen[instanceGroup.Name] {
+			return errors.Errorf("instance group %s was provided more than once", instanceGroup.Name)
+		}
+		seen[instanceGroup.Name] = true
+	}
+
+	return nil
+}
@@ -25,3 +25,56 @@ func TestNewKopsMetadata(t *testing.T) {
 		require.Equal(t, "name", kopsMetadata.Name)
 	})
 }
+
+func TestKopsInstanceGroupValidate(t *testing.T) {
+	var testCases = []struct {
+		testName      string
+		instanceGroup model.KopsInstanceGroup
+		requireError  bool
+	}{
+		{"valid", model.KopsInstanceGroup{Name: "memory", MachineType: "r5.large", MinCount: 1, MaxCount: 2}, false},
+		{"valid spot", model.KopsInstanceGroup{Name: "spot-1", MachineType: "m5.large", MinCount: 0, MaxCount: 4, MaxPrice: "0.05"}, false},
+		{"empty name", model.KopsInstanceGroup{MachineType: "r5.large", MinCount: 1, MaxCount: 2}, true},
+		{"invalid name", model.KopsInstanceGroup{Name: "Memory_Pool", MachineType: "r5.large", MinCount: 1, MaxCount: 2}, true},
+		{"reserved nodes name", model.KopsInstanceGroup{Name: "nodes", MachineType: "r5.large", MinCount: 1, MaxCount: 2}, true},
+		{"reserved master name", model.KopsInstanceGroup{Name: "master-us-east-1a", MachineType: "r5.large", MinCount: 1, MaxCount: 2}, true},
+		{"no machine type", model.KopsInstanceGroup{Name: "memory", MinCount: 1, MaxCount: 2}, true},
+		{"negative min count", model.KopsInstanceGroup{Name: "memory", MachineType: "r5.large", MinCount: -1, MaxCount: 2}, true},
+		{"zero max count", model.KopsInstanceGroup{Name: "memory", MachineType: "r5.large", MinCount: 0, MaxCount: 0}, true},
+		{"min above max", model.KopsInstanceGroup{Name: "memory", MachineType: "r5.large", MinCount: 3, MaxCount: 2}, true},
+		{"invalid max price", model.KopsInstanceGroup{Name: "memory", MachineType: "r5.large", MinCount: 1, MaxCount: 2, MaxPrice: "cheap"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				require.Error(t, tc.instanceGroup.Validate())
+			} else {
+				require.NoError(t, tc.instanceGroup.Validate())
+			}
+		})
+	}
+}
+
+func TestKopsMetadataMergeInstanceGroups(t *testing.T) {
+	kopsMetadata := &model.KopsMetadata{
+		InstanceGroups: []model.KopsInstanceGroup{
+			{Name: "memory", MachineType: "r5.large", MinCount: 1, MaxCount: 1},
+			{Name: "spot", MachineType: "m5.large", MinCount: 1, MaxCount: 1, MaxPrice: "0.05"},
+		},
+	}
+
+	kopsMetadata.MergeInstanceGroups([]model.KopsInstanceGroup{
+		{Name: "memory", MachineType: "r5.xlarge", MinCount: 2, MaxCount: 4},
+		{Name: "compute", MachineType: "c5.large", MinCount: 1, MaxCount: 1},
+	})
+
+	require.Equal(t, []model.KopsInstanceGroup{
+		{Name: "memory", MachineType: "r5.xlarge", MinCount: 2, MaxCount: 4},
+		{Name: "spot", MachineType: "m5.large", MinCount: 1, MaxCount: 1, MaxPrice: "0.05"},
+		{Name: "compute", MachineType: "c5.large", MinCount: 1, MaxCount: 1},
+	}, kopsMetadata.InstanceGroups)
+
+	require.NotNil(t, kopsMetadata.GetInstanceGroup("compute"))
+	require.Nil(t, kopsMetadata.GetInstanceGroup("unknown"))
+}
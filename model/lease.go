@@ -0,0 +1,10 @@
+package model
+
+// Lease is a named, expiring claim held by a single provisioning server
+// instance, such as the lease identifying the leader.
+type Lease struct {
+	Name       string
+	HolderID   string
+	AcquiredAt int64
+	ExpiresAt  int64
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	// LockResourceTypeCluster is the resource type of cluster locks.
+	LockResourceTypeCluster = "cluster"
+	// LockResourceTypeInstallation is the resource type of installation locks.
+	LockResourceTypeInstallation = "installation"
+	// LockResourceTypeClusterInstallation is the resource type of cluster installation locks.
+	LockResourceTypeClusterInstallation = "cluster_installation"
+	// LockResourceTypeGroup is the resource type of group locks.
+	LockResourceTypeGroup = "group"
+	// LockResourceTypeInstallationBackup is the resource type of installation backup locks.
+	LockResourceTypeInstallationBackup = "installation_backup"
+	// LockResourceTypeWebhookDelivery is the resource type of webhook delivery locks.
+	LockResourceTypeWebhookDelivery = "webhook_delivery"
+)
+
+// AllLockResourceTypes is a list of all resource types that can be locked.
+var AllLockResourceTypes = []string{
+	LockResourceTypeCluster,
+	LockResourceTypeInstallation,
+	LockResourceTypeClusterInstallation,
+	LockResourceTypeGroup,
+	LockResourceTypeInstallationBackup,
+	LockResourceTypeWebhookDelivery,
+}
+
+// Lock describes a lock currently held on a resource.
+type Lock struct {
+	ResourceType   string
+	ResourceID     string
+	LockAcquiredBy string
+	LockAcquiredAt int64
+}
+
+// LockFilter describes the parameters used to constrain a set of locks.
+type LockFilter struct {
+	ResourceType   string
+	LockAcquiredBy string
+	// AcquiredBefore, if non-zero, limits the locks to those acquired before
+	// the given time in milliseconds.
+	AcquiredBefore int64
+}
+
+// IsValidLockResourceType returns whether the given resource type can be locked.
+func IsValidLockResourceType(resourceType string) bool {
+	return containsString(AllLockResourceTypes, resourceType)
+}
+
+// LocksFromReader decodes a json-encoded list of locks from the given io.Reader.
+func LocksFromReader(reader io.Reader) ([]*Lock, error) {
+	locks := []*Lock{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&locks)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return locks, nil
+}
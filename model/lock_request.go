@@ -0,0 +1,17 @@
+package model
+
+import "net/url"
+
+// GetLocksRequest describes the parameters to request a list of locks.
+type GetLocksRequest struct {
+	ResourceType   string
+	LockAcquiredBy string
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetLocksRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	addNonEmptyQueryParam(q, "resource_type", request.ResourceType)
+	addNonEmptyQueryParam(q, "locker", request.LockAcquiredBy)
+	u.RawQuery = q.Encode()
+}
@@ -0,0 +1,160 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/pkg/apis/mattermost/v1alpha1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SchedulingStrategyFirstFit schedules an installation onto the oldest
+	// cluster it fits on.
+	SchedulingStrategyFirstFit = "first-fit"
+	// SchedulingStrategyBinPack schedules an installation onto the most loaded
+	// cluster it fits on, keeping other clusters free for larger
+	// installations.
+	SchedulingStrategyBinPack = "bin-pack"
+	// SchedulingStrategySpread schedules an installation onto the least loaded
+	// cluster it fits on.
+	SchedulingStrategySpread = "spread"
+)
+
+// AllSchedulingStrategies is a list of all supported scheduling strategies.
+var AllSchedulingStrategies = []string{
+	SchedulingStrategyFirstFit,
+	SchedulingStrategyBinPack,
+	SchedulingStrategySpread,
+}
+
+// IsSupportedSchedulingStrategy returns true if the given scheduling strategy
+// is supported.
+func IsSupportedSchedulingStrategy(strategy string) bool {
+	for _, supportedStrategy := range AllSchedulingStrategies {
+		if strategy == supportedStrategy {
+			return true
+		}
+	}
+
+	return false
+}
+
+var zoneMatcher = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+[a-z]$`)
+
+// ValidZone returns true if the given name is a valid availability zone, such
+// as us-east-1a.
+func ValidZone(zone string) bool {
+	return zoneMatcher.MatchString(zone)
+}
+
+// SchedulingCandidate explains whether an installation fits on a cluster.
+type SchedulingCandidate struct {
+	ClusterID string
+	Fits      bool
+	// Reason explains why the installation does not fit on the cluster.
+	Reason string `json:",omitempty"`
+	// CPUPercent and MemoryPercent are the expected load of the cluster once
+	// the installation is scheduled onto it, when known.
+	CPUPercent    int
+	MemoryPercent int
+}
+
+// SchedulingDecision explains how an installation is scheduled.
+type SchedulingDecision struct {
+	Strategy string
+	// ClusterID is the cluster the installation is scheduled onto, or empty if
+	// the installation fits on no cluster.
+	ClusterID string
+	// Candidates are every cluster considered, in order of preference with
+	// the clusters the installation fits on first.
+	Candidates []*SchedulingCandidate
+}
+
+// SchedulingDecisionFromReader decodes a json-encoded scheduling decision from
+// the given io.Reader.
+func SchedulingDecisionFromReader(reader io.Reader) (*SchedulingDecision, error) {
+	decision := SchedulingDecision{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&decision)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &decision, nil
+}
+
+// ScheduleInstallationRequest specifies the parameters of an installation to
+// schedule without creating it.
+type ScheduleInstallationRequest struct {
+	Size               string
+	Affinity           string
+	NodePool           string
+	Zone               string
+	SchedulingStrategy string
+}
+
+// SetDefaults sets the default values for an installation schedule request.
+func (request *ScheduleInstallationRequest) SetDefaults() {
+	if request.Size == "" {
+		request.Size = InstallationDefaultSize
+	}
+	if request.Affinity == "" {
+		request.Affinity = InstallationAffinityIsolated
+	}
+}
+
+// Validate validates the values of an installation schedule request.
+func (request *ScheduleInstallationRequest) Validate() error {
+	_, err := mmv1alpha1.GetClusterSize(request.Size)
+	if err != nil {
+		return errors.Wrap(err, "invalid size")
+	}
+	if !IsSupportedAffinity(request.Affinity) {
+		return errors.Errorf("unsupported affinity %s", request.Affinity)
+	}
+	if request.NodePool != "" && !ValidInstanceGroupName(request.NodePool) {
+		return errors.Errorf("invalid node pool %s", request.NodePool)
+	}
+	if request.Zone != "" && !ValidZone(request.Zone) {
+		return errors.Errorf("invalid zone %s", request.Zone)
+	}
+	if request.SchedulingStrategy != "" && !IsSupportedSchedulingStrategy(request.SchedulingStrategy) {
+		return errors.Errorf("unsupported scheduling strategy %s", request.SchedulingStrategy)
+	}
+
+	return nil
+}
+
+// Installation returns an installation with the parameters of the request,
+// as used for scheduling.
+func (request *ScheduleInstallationRequest) Installation() *Installation {
+	return &Installation{
+		Size:               request.Size,
+		Affinity:           request.Affinity,
+		NodePool:           request.NodePool,
+		Zone:               request.Zone,
+		SchedulingStrategy: request.SchedulingStrategy,
+		Database:           InstallationDatabaseMysqlOperator,
+		Filestore:          InstallationFilestoreMinioOperator,
+	}
+}
+
+// NewScheduleInstallationRequestFromReader will create a
+// ScheduleInstallationRequest from an io.Reader with JSON data.
+func NewScheduleInstallationRequestFromReader(reader io.Reader) (*ScheduleInstallationRequest, error) {
+	var scheduleInstallationRequest ScheduleInstallationRequest
+	err := json.NewDecoder(reader).Decode(&scheduleInstallationRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode schedule installation request")
+	}
+
+	scheduleInstallationRequest.SetDefaults()
+	err = scheduleInstallationRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "schedule installation request failed validation")
+	}
+
+	return &scheduleInstallationRequest, nil
+}
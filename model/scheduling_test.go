@@ -0,0 +1,113 @@
+package model_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSupportedSchedulingStrategy(t *testing.T) {
+	for _, strategy := range model.AllSchedulingStrategies {
+		assert.True(t, model.IsSupportedSchedulingStrategy(strategy))
+	}
+	assert.False(t, model.IsSupportedSchedulingStrategy(""))
+	assert.False(t, model.IsSupportedSchedulingStrategy("random"))
+}
+
+func TestValidZone(t *testing.T) {
+	assert.True(t, model.ValidZone("us-east-1a"))
+	assert.True(t, model.ValidZone("ap-southeast-2c"))
+	assert.True(t, model.ValidZone("us-gov-west-1b"))
+	assert.False(t, model.ValidZone(""))
+	assert.False(t, model.ValidZone("us-east-1"))
+	assert.False(t, model.ValidZone("US-EAST-1A"))
+}
+
+func TestScheduleInstallationRequestValid(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		requireError bool
+		request      *model.ScheduleInstallationRequest
+	}{
+		{"defaults", false, &model.ScheduleInstallationRequest{}},
+		{"everything", false, &model.ScheduleInstallationRequest{
+			Size:               "1000users",
+			Affinity:           model.InstallationAffinityMultiTenant,
+			NodePool:           "memory",
+			Zone:               "us-east-1a",
+			SchedulingStrategy: model.SchedulingStrategyBinPack,
+		}},
+		{"invalid size", true, &model.ScheduleInstallationRequest{Size: "huge"}},
+		{"invalid affinity", true, &model.ScheduleInstallationRequest{Affinity: "shared"}},
+		{"invalid node pool", true, &model.ScheduleInstallationRequest{NodePool: "nodes"}},
+		{"invalid zone", true, &model.ScheduleInstallationRequest{Zone: "east"}},
+		{"invalid scheduling strategy", true, &model.ScheduleInstallationRequest{SchedulingStrategy: "random"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			tc.request.SetDefaults()
+
+			if tc.requireError {
+				assert.Error(t, tc.request.Validate())
+			} else {
+				assert.NoError(t, tc.request.Validate())
+			}
+		})
+	}
+}
+
+func TestScheduleInstallationRequestFromReader(t *testing.T) {
+	t.Run("empty request", func(t *testing.T) {
+		request, err := model.NewScheduleInstallationRequestFromReader(bytes.NewReader([]byte(``)))
+		require.NoError(t, err)
+		require.Equal(t, &model.ScheduleInstallationRequest{
+			Size:     model.InstallationDefaultSize,
+			Affinity: model.InstallationAffinityIsolated,
+		}, request)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		request, err := model.NewScheduleInstallationRequestFromReader(bytes.NewReader([]byte(
+			`{"Zone":"east"}`,
+		)))
+		require.Error(t, err)
+		require.Nil(t, request)
+	})
+
+	t.Run("request", func(t *testing.T) {
+		request, err := model.NewScheduleInstallationRequestFromReader(bytes.NewReader([]byte(
+			`{"Size":"1000users","Zone":"us-east-1a","SchedulingStrategy":"spread"}`,
+		)))
+		require.NoError(t, err)
+		require.Equal(t, &model.ScheduleInstallationRequest{
+			Size:               "1000users",
+			Affinity:           model.InstallationAffinityIsolated,
+			Zone:               "us-east-1a",
+			SchedulingStrategy: model.SchedulingStrategySpread,
+		}, request)
+
+		installation := request.Installation()
+		require.Equal(t, "us-east-1a", installation.Zone)
+		require.Equal(t, model.SchedulingStrategySpread, installation.SchedulingStrategy)
+		require.True(t, installation.InternalDatabase())
+	})
+}
+
+func TestSchedulingDecisionFromReader(t *testing.T) {
+	decision, err := model.SchedulingDecisionFromReader(bytes.NewReader([]byte(
+		`{"Strategy":"spread","ClusterID":"id1","Candidates":[{"ClusterID":"id1","Fits":true,"CPUPercent":20},{"ClusterID":"id2","Reason":"cluster is not stable"}]}`,
+	)))
+	require.NoError(t, err)
+	require.Equal(t, &model.SchedulingDecision{
+		Strategy:  model.SchedulingStrategySpread,
+		ClusterID: "id1",
+		Candidates: []*model.SchedulingCandidate{
+			{ClusterID: "id1", Fits: true, CPUPercent: 20},
+			{ClusterID: "id2", Reason: "cluster is not stable"},
+		},
+	}, decision)
+}
@@ -1,5 +1,7 @@
 package model
 
+import "strings"
+
 const (
 	// SizeAlefDev is the definition of a cluster supporting dev purposes.
 	SizeAlefDev = "SizeAlefDev"
@@ -13,17 +15,39 @@ const (
 	SizeAlef10000 = "SizeAlef10000"
 )
 
-// IsSupportedClusterSize returns true if the given size string is supported.
+// validSizeSuffixes are the suffixes selecting the number of masters of a size.
+var validSizeSuffixes = []string{"", "-HA2", "-HA3"}
+
+// IsBuiltinClusterSize returns true if the given name is one of the built-in
+// cluster sizes, ignoring HA variants.
+func IsBuiltinClusterSize(name string) bool {
+	switch name {
+	case SizeAlefDev, SizeAlef500, SizeAlef1000, SizeAlef5000, SizeAlef10000:
+		return true
+	}
+
+	return false
+}
+
+// ClusterSizeVariants returns the given size along with its HA variants.
+func ClusterSizeVariants(name string) []string {
+	var variants []string
+	for _, suffix := range validSizeSuffixes {
+		variants = append(variants, name+suffix)
+	}
+
+	return variants
+}
+
+// IsSupportedClusterSize returns true if the given size string is supported,
+// either as a built-in size or as a custom size defined by configuration.
 func IsSupportedClusterSize(size string) bool {
-	validSuffixes := []string{"", "-HA2", "-HA3"}
-	for _, suffix := range validSuffixes {
-		switch size {
-		case
-			SizeAlefDev + suffix,
-			SizeAlef500 + suffix,
-			SizeAlef1000 + suffix,
-			SizeAlef5000 + suffix,
-			SizeAlef10000 + suffix:
+	for _, suffix := range validSizeSuffixes {
+		if !strings.HasSuffix(size, suffix) {
+			continue
+		}
+		name := strings.TrimSuffix(size, suffix)
+		if IsBuiltinClusterSize(name) || GetCustomClusterSize(name) != nil {
 			return true
 		}
 	}
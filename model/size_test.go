@@ -24,3 +24,28 @@ func TestCheckSize(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckCustomSize(t *testing.T) {
+	model.SetCustomClusterSizes([]*model.ClusterSize{
+		{Name: "SizeCustom", NodeCount: 4, NodeInstanceType: "m5.2xlarge", MasterCount: 1, MasterInstanceType: "t3.large"},
+	})
+	defer model.SetCustomClusterSizes(nil)
+
+	var testCases = []struct {
+		size            string
+		expectSupported bool
+	}{
+		{"SizeCustom", true},
+		{"SizeCustom-HA2", true},
+		{"SizeCustom-HA3", true},
+		{"SizeCustom-HA4", false},
+		{"SizeOther", false},
+		{model.SizeAlef500 + "-HA3", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.size, func(t *testing.T) {
+			assert.Equal(t, tc.expectSupported, model.IsSupportedClusterSize(tc.size))
+		})
+	}
+}
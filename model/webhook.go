@@ -13,6 +13,9 @@ const (
 	// TypeClusterInstallation is the string value that represents a cluster
 	// installation.
 	TypeClusterInstallation = "cluster_installaton"
+	// TypeInstallationBackup is the string value that represents an
+	// installation backup.
+	TypeInstallationBackup = "installation_backup"
 )
 
 // Webhook is
@@ -22,6 +25,20 @@ type Webhook struct {
 	URL      string
 	CreateAt int64
 	DeleteAt int64
+
+	// Secret, if set, is used to sign webhook requests so that receivers can
+	// verify they came from the provisioner. It is never returned by the API.
+	Secret string `json:"-"`
+
+	// Types and NewStates, if set, limit the webhook to payloads of the given
+	// resource types and new states.
+	Types     []string `json:"Types,omitempty"`
+	NewStates []string `json:"NewStates,omitempty"`
+
+	// InstallationOwnerID, if set, limits the installation payloads sent to
+	// the webhook to those of installations with the given owner. Payloads of
+	// other resource types are not affected.
+	InstallationOwnerID string `json:"InstallationOwnerID,omitempty"`
 }
 
 // WebhookFilter describes the parameters used to constrain a set of webhooks.
@@ -47,6 +64,23 @@ func (w *Webhook) IsDeleted() bool {
 	return w.DeleteAt != 0
 }
 
+// IsSubscribedTo returns whether the webhook subscriptions match the given
+// payload or not.
+func (w *Webhook) IsSubscribedTo(payload *WebhookPayload) bool {
+	if len(w.Types) != 0 && !containsString(w.Types, payload.Type) {
+		return false
+	}
+	if len(w.NewStates) != 0 && !containsString(w.NewStates, payload.NewState) {
+		return false
+	}
+	if w.InstallationOwnerID != "" && payload.Type == TypeInstallation &&
+		payload.ExtraData["OwnerID"] != w.InstallationOwnerID {
+		return false
+	}
+
+	return true
+}
+
 // ToJSON returns a JSON string representation of the webhook payload.
 func (p *WebhookPayload) ToJSON() (string, error) {
 	b, err := json.Marshal(p)
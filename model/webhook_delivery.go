@@ -0,0 +1,64 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// WebhookDelivery is a webhook payload queued for delivery to a single
+// webhook.
+type WebhookDelivery struct {
+	ID             string
+	WebhookID      string
+	Payload        string
+	State          string
+	Attempts       int
+	LastAttemptAt  int64
+	NextAttemptAt  int64
+	LastError      string
+	CreateAt       int64
+	LockAcquiredBy *string
+	LockAcquiredAt int64
+}
+
+// WebhookDeliveryFilter describes the parameters used to constrain a set of
+// webhook deliveries.
+type WebhookDeliveryFilter struct {
+	WebhookID string
+	States    []string
+	Page      int
+	PerPage   int
+}
+
+// IsReplayable returns whether the webhook delivery was given up on and can be
+// queued for delivery again.
+func (d *WebhookDelivery) IsReplayable() bool {
+	return d.State == WebhookDeliveryStateFailed
+}
+
+// WebhookDeliveryFromReader decodes a json-encoded webhook delivery from the
+// given io.Reader.
+func WebhookDeliveryFromReader(reader io.Reader) (*WebhookDelivery, error) {
+	delivery := WebhookDelivery{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&delivery)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// WebhookDeliveriesFromReader decodes a json-encoded list of webhook
+// deliveries from the given io.Reader.
+func WebhookDeliveriesFromReader(reader io.Reader) ([]*WebhookDelivery, error) {
+	deliveries := []*WebhookDelivery{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&deliveries)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
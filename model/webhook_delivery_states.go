@@ -0,0 +1,33 @@
+package model
+
+const (
+	// WebhookDeliveryStatePending is a webhook delivery that is waiting to be
+	// sent, either for the first time or as a retry.
+	WebhookDeliveryStatePending = "pending"
+	// WebhookDeliveryStateDelivered is a webhook delivery that was accepted by
+	// the webhook receiver.
+	WebhookDeliveryStateDelivered = "delivered"
+	// WebhookDeliveryStateFailed is a webhook delivery that exhausted its
+	// retries and was parked in the dead-letter list.
+	WebhookDeliveryStateFailed = "failed"
+)
+
+// AllWebhookDeliveryStates is a list of all states a webhook delivery can be
+// in.
+// Warning:
+// When creating a new webhook delivery state, it must be added to this list.
+var AllWebhookDeliveryStates = []string{
+	WebhookDeliveryStatePending,
+	WebhookDeliveryStateDelivered,
+	WebhookDeliveryStateFailed,
+}
+
+// AllWebhookDeliveryStatesPendingWork is a list of all webhook delivery states
+// that the supervisor will attempt to deliver on the next "tick".
+// Warning:
+// When creating a new webhook delivery state, it must be added to this list
+// if the webhook delivery supervisor should perform some action on its next
+// work cycle.
+var AllWebhookDeliveryStatesPendingWork = []string{
+	WebhookDeliveryStatePending,
+}
@@ -12,8 +12,37 @@ import (
 
 // CreateWebhookRequest specifies the parameters for a new webhook.
 type CreateWebhookRequest struct {
-	OwnerID string
-	URL     string
+	OwnerID             string
+	URL                 string
+	Secret              string   `json:"Secret,omitempty"`
+	Types               []string `json:"Types,omitempty"`
+	NewStates           []string `json:"NewStates,omitempty"`
+	InstallationOwnerID string   `json:"InstallationOwnerID,omitempty"`
+}
+
+// webhookTypes is the list of resource types a webhook can subscribe to.
+var webhookTypes = []string{
+	TypeCluster,
+	TypeInstallation,
+	TypeClusterInstallation,
+	TypeInstallationBackup,
+}
+
+// isWebhookState returns whether the given state belongs to any resource type
+// a webhook can subscribe to.
+func isWebhookState(state string) bool {
+	for _, states := range [][]string{
+		AllClusterStates,
+		AllInstallationStates,
+		AllClusterInstallationStates,
+		AllInstallationBackupStates,
+	} {
+		if containsString(states, state) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // NewCreateWebhookRequestFromReader will create a CreateWebhookRequest from an io.Reader with JSON data.
@@ -42,6 +71,16 @@ func NewCreateWebhookRequestFromReader(reader io.Reader) (*CreateWebhookRequest,
 	if uri.Host == "" {
 		return nil, errors.New("must specify host")
 	}
+	for _, webhookType := range createWebhookRequest.Types {
+		if !containsString(webhookTypes, webhookType) {
+			return nil, errors.Errorf("unsupported webhook type %s", webhookType)
+		}
+	}
+	for _, state := range createWebhookRequest.NewStates {
+		if !isWebhookState(state) {
+			return nil, errors.Errorf("unsupported webhook state %s", state)
+		}
+	}
 
 	return &createWebhookRequest, nil
 }
@@ -65,3 +104,22 @@ func (request *GetWebhooksRequest) ApplyToURL(u *url.URL) {
 	}
 	u.RawQuery = q.Encode()
 }
+
+// GetWebhookDeliveriesRequest describes the parameters to request a list of
+// deliveries of a webhook.
+type GetWebhookDeliveriesRequest struct {
+	States  []string
+	Page    int
+	PerPage int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetWebhookDeliveriesRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	for _, state := range request.States {
+		q.Add("state", state)
+	}
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	u.RawQuery = q.Encode()
+}
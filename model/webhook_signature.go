@@ -0,0 +1,63 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// WebhookSignatureHeader is the request header carrying the hex-encoded
+	// HMAC-SHA256 signature of a webhook request sent to a webhook with a
+	// secret.
+	WebhookSignatureHeader = "X-Mattermost-Cloud-Signature"
+	// WebhookTimestampHeader is the request header carrying the time, in unix
+	// seconds, at which a webhook request was signed.
+	WebhookTimestampHeader = "X-Mattermost-Cloud-Timestamp"
+)
+
+// SignWebhookPayload returns the hex-encoded HMAC-SHA256 signature of the
+// given timestamp and request body using the webhook secret. The timestamp is
+// included in the signature so that a captured request cannot be replayed
+// with a different timestamp.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks that the signature and timestamp header
+// values of a received webhook request match the request body and the
+// webhook secret. If maxAge is non-zero, requests signed longer ago than
+// maxAge are also rejected.
+func VerifyWebhookSignature(secret, timestampHeader, signatureHeader string, body []byte, maxAge time.Duration) error {
+	if signatureHeader == "" {
+		return errors.New("missing webhook signature")
+	}
+
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "invalid webhook timestamp")
+	}
+
+	if maxAge != 0 {
+		age := time.Since(time.Unix(timestamp, 0))
+		if age > maxAge || age < -maxAge {
+			return errors.Errorf("webhook timestamp is outside the allowed window of %s", maxAge)
+		}
+	}
+
+	expected := SignWebhookPayload(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signatureHeader)) {
+		return errors.New("webhook signature does not match")
+	}
+
+	return nil
+}
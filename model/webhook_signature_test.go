@@ -0,0 +1,63 @@
+package model_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"id":"id","type":"installation"}`)
+	now := time.Now().Unix()
+	timestamp := strconv.FormatInt(now, 10)
+	signature := model.SignWebhookPayload("secret", now, body)
+
+	t.Run("valid", func(t *testing.T) {
+		err := model.VerifyWebhookSignature("secret", timestamp, signature, body, time.Minute)
+		require.NoError(t, err)
+	})
+
+	t.Run("no max age", func(t *testing.T) {
+		oldTimestamp := now - 3600
+		oldSignature := model.SignWebhookPayload("secret", oldTimestamp, body)
+
+		err := model.VerifyWebhookSignature("secret", strconv.FormatInt(oldTimestamp, 10), oldSignature, body, 0)
+		require.NoError(t, err)
+	})
+
+	t.Run("missing signature", func(t *testing.T) {
+		err := model.VerifyWebhookSignature("secret", timestamp, "", body, time.Minute)
+		require.EqualError(t, err, "missing webhook signature")
+	})
+
+	t.Run("invalid timestamp", func(t *testing.T) {
+		err := model.VerifyWebhookSignature("secret", "invalid", signature, body, time.Minute)
+		require.Error(t, err)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		err := model.VerifyWebhookSignature("other", timestamp, signature, body, time.Minute)
+		require.EqualError(t, err, "webhook signature does not match")
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		err := model.VerifyWebhookSignature("secret", timestamp, signature, []byte(`{"id":"other"}`), time.Minute)
+		require.EqualError(t, err, "webhook signature does not match")
+	})
+
+	t.Run("tampered timestamp", func(t *testing.T) {
+		err := model.VerifyWebhookSignature("secret", strconv.FormatInt(now+1, 10), signature, body, time.Minute)
+		require.EqualError(t, err, "webhook signature does not match")
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		oldTimestamp := now - 3600
+		oldSignature := model.SignWebhookPayload("secret", oldTimestamp, body)
+
+		err := model.VerifyWebhookSignature("secret", strconv.FormatInt(oldTimestamp, 10), oldSignature, body, time.Minute)
+		require.EqualError(t, err, "webhook timestamp is outside the allowed window of 1m0s")
+	})
+}
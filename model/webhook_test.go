@@ -164,3 +164,82 @@ func TestWebhookPayloadFromReader(t *testing.T) {
 		}, payload)
 	})
 }
+
+func TestWebhookIsSubscribedTo(t *testing.T) {
+	payload := &WebhookPayload{
+		ID:        "id",
+		Type:      TypeInstallation,
+		NewState:  InstallationStateStable,
+		OldState:  InstallationStateCreationRequested,
+		ExtraData: map[string]string{"OwnerID": "owner1"},
+	}
+
+	testCases := []struct {
+		Description string
+		Webhook     *Webhook
+		Payload     *WebhookPayload
+		Expected    bool
+	}{
+		{"no subscriptions", &Webhook{}, payload, true},
+		{"matching type", &Webhook{Types: []string{TypeCluster, TypeInstallation}}, payload, true},
+		{"other type", &Webhook{Types: []string{TypeCluster}}, payload, false},
+		{"matching state", &Webhook{NewStates: []string{InstallationStateStable}}, payload, true},
+		{"other state", &Webhook{NewStates: []string{InstallationStateDeleted}}, payload, false},
+		{"matching owner", &Webhook{InstallationOwnerID: "owner1"}, payload, true},
+		{"other owner", &Webhook{InstallationOwnerID: "owner2"}, payload, false},
+		{
+			"owner ignored for other types",
+			&Webhook{InstallationOwnerID: "owner2"},
+			&WebhookPayload{Type: TypeCluster, NewState: ClusterStateStable},
+			true,
+		},
+		{
+			"all subscriptions matching",
+			&Webhook{
+				Types:               []string{TypeInstallation},
+				NewStates:           []string{InstallationStateStable},
+				InstallationOwnerID: "owner1",
+			},
+			payload,
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Description, func(t *testing.T) {
+			require.Equal(t, tc.Expected, tc.Webhook.IsSubscribedTo(tc.Payload))
+		})
+	}
+}
+
+func TestNewCreateWebhookRequestFromReader(t *testing.T) {
+	t.Run("valid subscriptions", func(t *testing.T) {
+		request, err := NewCreateWebhookRequestFromReader(strings.NewReader(
+			`{"OwnerID":"owner","URL":"https://example.com","Types":["installation"],"NewStates":["stable"],"InstallationOwnerID":"owner1"}`,
+		))
+		require.NoError(t, err)
+		require.Equal(t, &CreateWebhookRequest{
+			OwnerID:             "owner",
+			URL:                 "https://example.com",
+			Types:               []string{TypeInstallation},
+			NewStates:           []string{InstallationStateStable},
+			InstallationOwnerID: "owner1",
+		}, request)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		request, err := NewCreateWebhookRequestFromReader(strings.NewReader(
+			`{"OwnerID":"owner","URL":"https://example.com","Types":["unknown"]}`,
+		))
+		require.EqualError(t, err, "unsupported webhook type unknown")
+		require.Nil(t, request)
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		request, err := NewCreateWebhookRequestFromReader(strings.NewReader(
+			`{"OwnerID":"owner","URL":"https://example.com","NewStates":["unknown"]}`,
+		))
+		require.EqualError(t, err, "unsupported webhook state unknown")
+		require.Nil(t, request)
+	})
+}